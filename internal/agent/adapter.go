@@ -28,22 +28,17 @@ const (
 	AgentCustom   AgentType = "custom"
 )
 
-// ParseAgentType parses an agent type string
+// ParseAgentType parses an agent type string. Besides opencode and custom,
+// any agent in the registry (built-in or from the agents: config) is valid.
 func ParseAgentType(s string) (AgentType, error) {
 	switch s {
-	case "claude":
-		return AgentClaude, nil
-	case "codex":
-		return AgentCodex, nil
-	case "gemini":
-		return AgentGemini, nil
-	case "opencode":
-		return AgentOpenCode, nil
-	case "custom":
-		return AgentCustom, nil
-	default:
-		return "", fmt.Errorf("unknown agent type: %s", s)
+	case string(AgentOpenCode), string(AgentCustom):
+		return AgentType(s), nil
 	}
+	if _, ok := LookupDefinition(s); ok {
+		return AgentType(s), nil
+	}
+	return "", fmt.Errorf("unknown agent type: %s", s)
 }
 
 // LaunchConfig holds configuration for launching an agent
@@ -64,6 +59,7 @@ type LaunchConfig struct {
 	Model           string // Model in provider/model format (e.g., anthropic/claude-opus-4-5)
 	ModelVariant    string // Model variant (e.g., "max" for max thinking)
 	ContinueSession bool
+	StreamLog       string // structured-output run: NDJSON log the output is tee'd to
 }
 
 // Env returns the environment variables to pass to the agent
@@ -98,26 +94,27 @@ type Adapter interface {
 	// Default implementations should return InjectionArg
 	PromptInjection() InjectionMethod
 
+	// Detector returns the status detector for the agent's screen
+	Detector() StatusDetector
+
 	// ReadyPattern returns a regex pattern to detect when the agent is ready for input
 	// The pattern is matched against the tmux pane content
 	// Return empty string if no detection is needed (prompt is passed via command line)
 	ReadyPattern() string
 }
 
-// GetAdapter returns the adapter for the given agent type
+// GetAdapter returns the adapter for the given agent type. opencode and
+// custom have dedicated adapters; every other agent is launched from its
+// registry definition.
 func GetAdapter(agentType AgentType) (Adapter, error) {
 	switch agentType {
-	case AgentClaude:
-		return &ClaudeAdapter{}, nil
-	case AgentCodex:
-		return &CodexAdapter{}, nil
-	case AgentGemini:
-		return &GeminiAdapter{}, nil
 	case AgentOpenCode:
 		return &OpenCodeAdapter{}, nil
 	case AgentCustom:
 		return &CustomAdapter{}, nil
-	default:
-		return nil, fmt.Errorf("unknown agent type: %s", agentType)
 	}
+	if def, ok := LookupDefinition(string(agentType)); ok {
+		return &DefinitionAdapter{Def: def}, nil
+	}
+	return nil, fmt.Errorf("unknown agent type: %s", agentType)
 }
@@ -12,7 +12,7 @@ func TestDoubleQuote(t *testing.T) {
 }
 
 func TestClaudeLaunchCommand(t *testing.T) {
-	adapter := &ClaudeAdapter{}
+	adapter := mustGetAdapter(t, AgentClaude)
 	cfg := &LaunchConfig{
 		Prompt:      "hello",
 		Profile:     "work",
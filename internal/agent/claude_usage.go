@@ -0,0 +1,109 @@
+package agent
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"regexp"
+	"time"
+
+	"github.com/s22625/orch/internal/model"
+)
+
+// claudeUsage totals the assistant turns in the Claude transcripts of the
+// run's worktree (~/.claude/projects/<encoded worktree>/*.jsonl). A
+// continued run shares the worktree, so turns from before the run started
+// belong to earlier runs and are left out.
+func claudeUsage(ctx context.Context, run *model.Run) ([]model.Usage, error) {
+	if run.WorktreePath == "" {
+		return nil, nil
+	}
+	return readClaudeUsage(claudeProjectDir(claudeConfigDir(), run.WorktreePath), run.StartedAt)
+}
+
+func claudeConfigDir() string {
+	if dir := os.Getenv("CLAUDE_CONFIG_DIR"); dir != "" {
+		return dir
+	}
+	home, _ := os.UserHomeDir()
+	return filepath.Join(home, ".claude")
+}
+
+var claudeProjectNameRegex = regexp.MustCompile(`[^a-zA-Z0-9]`)
+
+// claudeProjectDir returns where Claude keeps transcripts for a working
+// directory: every non-alphanumeric character of the path becomes '-'
+func claudeProjectDir(configDir, workDir string) string {
+	return filepath.Join(configDir, "projects", claudeProjectNameRegex.ReplaceAllString(workDir, "-"))
+}
+
+// claudeTranscriptLine is the subset of a transcript entry we need
+type claudeTranscriptLine struct {
+	Type      string    `json:"type"`
+	RequestID string    `json:"requestId"`
+	Timestamp time.Time `json:"timestamp"`
+	Message   struct {
+		ID    string `json:"id"`
+		Model string `json:"model"`
+		Usage *struct {
+			InputTokens              int64 `json:"input_tokens"`
+			OutputTokens             int64 `json:"output_tokens"`
+			CacheCreationInputTokens int64 `json:"cache_creation_input_tokens"`
+			CacheReadInputTokens     int64 `json:"cache_read_input_tokens"`
+		} `json:"usage"`
+	} `json:"message"`
+}
+
+// readClaudeUsage sums the usage of the transcripts in dir recorded since
+// since (all of them when zero). A response split over several entries
+// repeats its usage, so only the last entry per message is counted.
+// Transcripts untouched since then are skipped without being opened.
+func readClaudeUsage(dir string, since time.Time) ([]model.Usage, error) {
+	files, err := filepath.Glob(filepath.Join(dir, "*.jsonl"))
+	if err != nil || len(files) == 0 {
+		return nil, err
+	}
+
+	messages := make(map[string]model.Usage)
+	for _, path := range files {
+		if info, err := os.Stat(path); err != nil || info.ModTime().Before(since) {
+			continue
+		}
+		f, err := os.Open(path)
+		if err != nil {
+			continue
+		}
+		scanner := bufio.NewScanner(f)
+		scanner.Buffer(make([]byte, 64*1024), 16*1024*1024)
+		for scanner.Scan() {
+			var line claudeTranscriptLine
+			if json.Unmarshal(scanner.Bytes(), &line) != nil {
+				continue
+			}
+			u := line.Message.Usage
+			if line.Type != "assistant" || u == nil || line.Message.Model == "<synthetic>" {
+				continue
+			}
+			if !line.Timestamp.IsZero() && line.Timestamp.Before(since) {
+				continue
+			}
+			key := line.Message.ID + "\x00" + line.RequestID
+			messages[key] = model.Usage{
+				Model:            line.Message.Model,
+				InputTokens:      u.InputTokens,
+				OutputTokens:     u.OutputTokens,
+				CacheReadTokens:  u.CacheReadInputTokens,
+				CacheWriteTokens: u.CacheCreationInputTokens,
+			}
+		}
+		f.Close()
+	}
+
+	totals := make(usageTotals)
+	for _, u := range messages {
+		totals.add(u)
+	}
+	return totals.list(), nil
+}
@@ -3,7 +3,7 @@ package agent
 import "testing"
 
 func TestCodexLaunchCommand(t *testing.T) {
-	adapter := &CodexAdapter{}
+	adapter := mustGetAdapter(t, AgentCodex)
 	cfg := &LaunchConfig{Prompt: "hello 'world'"}
 	cmd, err := adapter.LaunchCommand(cfg)
 	if err != nil {
@@ -0,0 +1,118 @@
+package agent
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/s22625/orch/internal/model"
+)
+
+// codexUsage totals the Codex session logs
+// (~/.codex/sessions/**/rollout-*.jsonl) recorded in the run's worktree
+func codexUsage(ctx context.Context, run *model.Run) ([]model.Usage, error) {
+	if run.WorktreePath == "" {
+		return nil, nil
+	}
+	return readCodexUsage(filepath.Join(codexHome(), "sessions"), run.WorktreePath, run.StartedAt)
+}
+
+func codexHome() string {
+	if dir := os.Getenv("CODEX_HOME"); dir != "" {
+		return dir
+	}
+	home, _ := os.UserHomeDir()
+	return filepath.Join(home, ".codex")
+}
+
+// codexLogLine is the subset of a rollout entry we need
+type codexLogLine struct {
+	Type    string `json:"type"`
+	Payload struct {
+		Type  string `json:"type"`
+		Cwd   string `json:"cwd"`
+		Model string `json:"model"`
+		Info  *struct {
+			Total struct {
+				InputTokens       int64 `json:"input_tokens"`
+				CachedInputTokens int64 `json:"cached_input_tokens"`
+				OutputTokens      int64 `json:"output_tokens"`
+			} `json:"total_token_usage"`
+		} `json:"info"`
+	} `json:"payload"`
+}
+
+// readCodexUsage sums the sessions under sessionsDir whose working directory
+// is workDir. Logs untouched since the run started are skipped without being
+// opened.
+func readCodexUsage(sessionsDir, workDir string, since time.Time) ([]model.Usage, error) {
+	totals := make(usageTotals)
+	err := filepath.WalkDir(sessionsDir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			if path == sessionsDir {
+				return filepath.SkipAll
+			}
+			return nil
+		}
+		if d.IsDir() || !strings.HasPrefix(d.Name(), "rollout-") || !strings.HasSuffix(d.Name(), ".jsonl") {
+			return nil
+		}
+		if info, err := d.Info(); err != nil || info.ModTime().Before(since) {
+			return nil
+		}
+		if u, ok := readCodexSession(path, workDir); ok {
+			totals.add(u)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return totals.list(), nil
+}
+
+// readCodexSession returns the final token count of one session log. Token
+// counts are cumulative, so the last one is the session total; it is
+// attributed to the last model the session used.
+func readCodexSession(path, workDir string) (model.Usage, bool) {
+	f, err := os.Open(path)
+	if err != nil {
+		return model.Usage{}, false
+	}
+	defer f.Close()
+
+	var usage model.Usage
+	found := false
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(make([]byte, 64*1024), 16*1024*1024)
+	if !scanner.Scan() {
+		return model.Usage{}, false
+	}
+	var meta codexLogLine
+	if json.Unmarshal(scanner.Bytes(), &meta) != nil || meta.Type != "session_meta" ||
+		filepath.Clean(meta.Payload.Cwd) != filepath.Clean(workDir) {
+		return model.Usage{}, false
+	}
+	for scanner.Scan() {
+		var line codexLogLine
+		if json.Unmarshal(scanner.Bytes(), &line) != nil {
+			continue
+		}
+		switch {
+		case line.Type == "turn_context" && line.Payload.Model != "":
+			usage.Model = line.Payload.Model
+		case line.Type == "event_msg" && line.Payload.Type == "token_count" && line.Payload.Info != nil:
+			total := line.Payload.Info.Total
+			usage.InputTokens = total.InputTokens - total.CachedInputTokens
+			usage.CacheReadTokens = total.CachedInputTokens
+			usage.OutputTokens = total.OutputTokens
+			found = true
+		}
+	}
+	return usage, found
+}
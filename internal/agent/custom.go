@@ -31,4 +31,9 @@ func (a *CustomAdapter) ReadyPattern() string {
 	return "" // Not needed - prompt passed via command line
 }
 
+// Detector uses the generic patterns since the command is arbitrary
+func (a *CustomAdapter) Detector() StatusDetector {
+	return genericDetector
+}
+
 var _ Adapter = (*CustomAdapter)(nil)
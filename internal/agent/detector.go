@@ -0,0 +1,176 @@
+package agent
+
+import "strings"
+
+// StatusDetector classifies a captured pane of an agent. Every adapter has
+// one; agents must not be judged by another agent's screen text.
+type StatusDetector interface {
+	// IsWaitingForInput reports whether the agent shows its input prompt
+	IsWaitingForInput(output string) bool
+	// IsAgentExited reports whether the pane has fallen back to a shell
+	IsAgentExited(output string) bool
+	// IsCompleted reports whether the agent announced it finished
+	IsCompleted(output string) bool
+	// IsAPILimited reports whether the agent is rate or quota limited
+	IsAPILimited(output string) bool
+	// IsFailed reports whether the agent hit an unrecoverable error
+	IsFailed(output string) bool
+}
+
+// Patterns are case-sensitive (Prompt, Alive) or case-insensitive (the rest)
+// substrings searched for in the captured pane
+type Patterns struct {
+	Prompt    []string // agent is waiting for input
+	Alive     []string // agent TUI is on screen (besides the prompt)
+	Completed []string // agent finished the task (last 5 lines)
+	Failed    []string // agent hit an unrecoverable error (last 10 lines)
+	APILimit  []string // agent is rate or quota limited (last 30 lines)
+}
+
+// PatternSet is the screen text of one release line of an agent CLI. When a
+// CLI changes its TUI, add a set for the new version instead of editing the
+// old one, so panes of either version keep being detected.
+type PatternSet struct {
+	Version string // e.g. claude-code/2
+	Patterns
+}
+
+// patternDetector matches a pane against every pattern set of an agent
+type patternDetector struct {
+	sets []PatternSet
+}
+
+func newPatternDetector(sets ...PatternSet) *patternDetector {
+	return &patternDetector{sets: sets}
+}
+
+// Versions lists the pattern sets the detector checks
+func (d *patternDetector) Versions() []string {
+	versions := make([]string, len(d.sets))
+	for i, set := range d.sets {
+		versions[i] = set.Version
+	}
+	return versions
+}
+
+func (d *patternDetector) any(match func(p Patterns) bool) bool {
+	for _, set := range d.sets {
+		if match(set.Patterns) {
+			return true
+		}
+	}
+	return false
+}
+
+func (d *patternDetector) IsWaitingForInput(output string) bool {
+	return d.any(func(p Patterns) bool { return containsAny(output, p.Prompt) })
+}
+
+func (d *patternDetector) IsAgentExited(output string) bool {
+	if d.any(func(p Patterns) bool { return containsAny(output, p.Prompt) || containsAny(output, p.Alive) }) {
+		return false
+	}
+	return endsWithShellPrompt(output)
+}
+
+func (d *patternDetector) IsCompleted(output string) bool {
+	lower := strings.ToLower(getLastLines(output, 5))
+	return d.any(func(p Patterns) bool { return containsAny(lower, lowerAll(p.Completed)) })
+}
+
+func (d *patternDetector) IsAPILimited(output string) bool {
+	lower := strings.ToLower(getLastLines(output, 30))
+	return d.any(func(p Patterns) bool { return containsAny(lower, lowerAll(p.APILimit)) })
+}
+
+func (d *patternDetector) IsFailed(output string) bool {
+	lower := strings.ToLower(getLastLines(output, 10))
+	return d.any(func(p Patterns) bool { return containsAny(lower, lowerAll(p.Failed)) })
+}
+
+var _ StatusDetector = (*patternDetector)(nil)
+
+// overlay returns sets where every non-empty list of p replaces the
+// corresponding lists of base. Used for agents: config entries.
+func overlay(version string, p Patterns, base []PatternSet) []PatternSet {
+	sets := []PatternSet{{Version: version, Patterns: p}}
+	for _, set := range base {
+		if len(p.Prompt) > 0 {
+			set.Prompt = nil
+			set.Alive = nil // prompt text also marks the agent alive
+		}
+		if len(p.Alive) > 0 {
+			set.Alive = nil
+		}
+		if len(p.Completed) > 0 {
+			set.Completed = nil
+		}
+		if len(p.Failed) > 0 {
+			set.Failed = nil
+		}
+		if len(p.APILimit) > 0 {
+			set.APILimit = nil
+		}
+		sets = append(sets, set)
+	}
+	return sets
+}
+
+// endsWithShellPrompt reports whether the last non-empty line looks like a
+// shell prompt
+func endsWithShellPrompt(output string) bool {
+	lines := strings.Split(strings.TrimSpace(output), "\n")
+	if len(lines) == 0 {
+		return false
+	}
+
+	lastLine := ""
+	for i := len(lines) - 1; i >= 0; i-- {
+		line := strings.TrimSpace(lines[i])
+		if line != "" {
+			lastLine = line
+			break
+		}
+	}
+
+	if lastLine == "" {
+		return false
+	}
+
+	if strings.Contains(lastLine, "git:(") && strings.Contains(lastLine, ")") {
+		return true
+	}
+
+	trimmed := strings.TrimRight(lastLine, " ")
+	if strings.HasSuffix(lastLine, "$ ") ||
+		strings.HasSuffix(lastLine, "% ") ||
+		strings.HasSuffix(lastLine, "# ") ||
+		strings.HasSuffix(lastLine, "❯ ") ||
+		strings.HasSuffix(lastLine, "➜ ") ||
+		strings.HasSuffix(trimmed, "$") ||
+		strings.HasSuffix(trimmed, "%") ||
+		strings.HasSuffix(trimmed, "✗") ||
+		strings.HasSuffix(trimmed, "❯") ||
+		strings.HasSuffix(trimmed, "➜") {
+		return true
+	}
+
+	return false
+}
+
+func containsAny(s string, patterns []string) bool {
+	for _, pattern := range patterns {
+		if strings.Contains(s, pattern) {
+			return true
+		}
+	}
+	return false
+}
+
+func lowerAll(patterns []string) []string {
+	lower := make([]string, len(patterns))
+	for i, p := range patterns {
+		lower[i] = strings.ToLower(p)
+	}
+	return lower
+}
@@ -0,0 +1,222 @@
+package agent
+
+// Pattern sets of the built-in agents, newest release first. Each set is
+// backed by captured panes under testdata/panes/<agent>/.
+
+var claudePatternSets = []PatternSet{
+	{
+		Version: "claude-code/2",
+		Patterns: Patterns{
+			Prompt: []string{
+				"? for shortcuts",
+				"bypass permissions on",
+				"accept edits on",
+				"plan mode on",
+				"shift+tab to cycle",
+				"tell Claude what to do differently",
+				"to show all projects",
+			},
+			Alive: []string{
+				"esc to interrupt",
+			},
+			Failed: []string{
+				"invalid api key",
+				"please run /login",
+				"oauth token has expired",
+				"fatal error",
+			},
+			APILimit: []string{
+				"you've hit your limit",
+				"/rate-limit-options",
+				"stop and wait for limit to reset",
+				"api error: 429",
+				"api error: 529",
+			},
+		},
+	},
+	{
+		Version: "claude-code/1",
+		Patterns: Patterns{
+			Prompt: []string{
+				"? for shortcuts",
+				"No, and tell Claude what to do differently",
+				"auto-accept edits on",
+				"Esc to cancel",
+			},
+			Alive: []string{
+				"esc to interrupt",
+			},
+			Failed: []string{
+				"invalid api key",
+				"please run /login",
+				"fatal error",
+			},
+			APILimit: []string{
+				"claude ai usage limit reached",
+				"cost limit reached",
+				"rate limit reached",
+			},
+		},
+	},
+}
+
+var codexPatternSets = []PatternSet{
+	{
+		Version: "codex/0.4",
+		Patterns: Patterns{
+			Prompt: []string{
+				"? for shortcuts",
+				"⏎ send",
+				"context left",
+			},
+			Alive: []string{
+				"esc to interrupt",
+			},
+			Failed: []string{
+				"unexpected status 401",
+				"not logged in",
+			},
+			APILimit: []string{
+				"you've hit your usage limit",
+				"rate limit reached",
+				"exceeded retry limit, last status: 429",
+				"insufficient_quota",
+				"quota exceeded",
+			},
+		},
+	},
+	{
+		Version: "codex/0.1",
+		Patterns: Patterns{
+			Prompt: []string{
+				"↵ send",
+				"send q or ctrl+c to exit",
+			},
+			Alive: []string{
+				"ctrl+c to interrupt",
+			},
+			Failed: []string{
+				"unexpected status 401",
+			},
+			APILimit: []string{
+				"rate limit exceeded",
+				"insufficient quota",
+			},
+		},
+	},
+}
+
+var geminiPatternSets = []PatternSet{
+	{
+		Version: "gemini-cli/0",
+		Patterns: Patterns{
+			Prompt: []string{
+				"Type your message",
+			},
+			Alive: []string{
+				"esc to cancel",
+				"YOLO mode",
+				"context left)",
+			},
+			Completed: []string{
+				"agent powering down",
+			},
+			Failed: []string{
+				"api key not valid",
+				"failed to login",
+			},
+			APILimit: []string{
+				"resource_exhausted",
+				"resource exhausted",
+				"quota exceeded",
+				"rate limit exceeded",
+			},
+		},
+	},
+}
+
+// openCodePatternSets cover the opencode TUI and the log of `opencode
+// serve`; opencode runs are otherwise tracked through its HTTP API
+var openCodePatternSets = []PatternSet{
+	{
+		Version: "opencode/0",
+		Patterns: Patterns{
+			Prompt: []string{
+				"ctrl+s send",
+				"enter send",
+				"enter newline",
+				"ctrl+c interrupt",
+			},
+			Alive: []string{
+				"opencode server listening",
+				"POST /session",
+				"POST /message",
+			},
+			Failed: []string{
+				"authentication failed",
+			},
+			APILimit: []string{
+				"rate limit exceeded",
+				"quota exceeded",
+				"insufficient quota",
+			},
+		},
+	},
+}
+
+// genericPatternSet merges the screen text of all known agents. It is only
+// used for agents without their own patterns (custom commands and agents:
+// entries that configure none).
+var genericPatternSet = PatternSet{
+	Version: "generic",
+	Patterns: Patterns{
+		Prompt: []string{
+			"No, and tell Claude what to do differently",
+			"tell Claude what to do differently",
+			"↵ send",
+			"? for shortcuts",
+			"accept edits",
+			"bypass permissions",
+			"shift+tab to cycle",
+			"Esc to cancel",
+			"to show all projects",
+			"Type your message",
+			"ctrl+s send",
+			"enter newline",
+			"ctrl+c interrupt",
+		},
+		Alive: []string{
+			"tokens",
+			"opencode server listening",
+			"POST /session",
+			"POST /message",
+		},
+		Completed: []string{
+			"task completed successfully",
+			"all tasks completed",
+			"session ended",
+			"goodbye",
+		},
+		Failed: []string{
+			"fatal error",
+			"unrecoverable error",
+			"agent crashed",
+			"session terminated",
+			"authentication failed",
+		},
+		APILimit: []string{
+			"cost limit reached",
+			"rate limit exceeded",
+			"rate limit reached",
+			"quota exceeded",
+			"insufficient quota",
+			"resource exhausted",
+			"you've hit your limit",
+			"/rate-limit-options",
+			"stop and wait for limit to reset",
+		},
+	},
+}
+
+// genericDetector judges panes of agents without their own patterns
+var genericDetector = newPatternDetector(genericPatternSet)
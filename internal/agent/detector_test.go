@@ -0,0 +1,109 @@
+package agent
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+var updateGolden = flag.Bool("update", false, "rewrite the .golden files under testdata/panes")
+
+// describeDetection renders what a detector sees in a pane, plus the status
+// the tmux manager derives when the pane has not changed
+func describeDetection(d StatusDetector, pane string) string {
+	waiting := d.IsWaitingForInput(pane)
+	status := (&TerminalManager{Detector: d}).GetStatus(nil, pane, nil, false, waiting)
+	if status == "" {
+		status = "-"
+	}
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "waiting_for_input: %v\n", waiting)
+	fmt.Fprintf(&sb, "exited: %v\n", d.IsAgentExited(pane))
+	fmt.Fprintf(&sb, "completed: %v\n", d.IsCompleted(pane))
+	fmt.Fprintf(&sb, "api_limited: %v\n", d.IsAPILimited(pane))
+	fmt.Fprintf(&sb, "failed: %v\n", d.IsFailed(pane))
+	fmt.Fprintf(&sb, "status: %s\n", status)
+	return sb.String()
+}
+
+// TestDetectorGoldenPanes runs every captured pane under
+// testdata/panes/<agent>/ through that agent's detector. Run with -update
+// after adding a pane or changing a pattern set, and review the diff.
+func TestDetectorGoldenPanes(t *testing.T) {
+	dirs, err := os.ReadDir(filepath.Join("testdata", "panes"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, dir := range dirs {
+		agentName := dir.Name()
+		detector := mustGetAdapter(t, AgentType(agentName)).Detector()
+
+		panes, err := filepath.Glob(filepath.Join("testdata", "panes", agentName, "*.txt"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(panes) == 0 {
+			t.Errorf("no captured panes for %s", agentName)
+		}
+		for _, panePath := range panes {
+			name := agentName + "/" + strings.TrimSuffix(filepath.Base(panePath), ".txt")
+			t.Run(name, func(t *testing.T) {
+				pane, err := os.ReadFile(panePath)
+				if err != nil {
+					t.Fatal(err)
+				}
+				got := describeDetection(detector, string(pane))
+
+				goldenPath := strings.TrimSuffix(panePath, ".txt") + ".golden"
+				if *updateGolden {
+					if err := os.WriteFile(goldenPath, []byte(got), 0644); err != nil {
+						t.Fatal(err)
+					}
+					return
+				}
+				want, err := os.ReadFile(goldenPath)
+				if err != nil {
+					t.Fatalf("missing golden file (run go test -run TestDetectorGoldenPanes -update): %v", err)
+				}
+				if got != string(want) {
+					t.Errorf("detection changed for %s\n--- got\n%s--- want\n%s", panePath, got, want)
+				}
+			})
+		}
+	}
+}
+
+func TestDetectorsDoNotMatchOtherAgents(t *testing.T) {
+	// Codex editing a file that mentions Claude's prompt is still working
+	pane, err := os.ReadFile(filepath.Join("testdata", "panes", "codex", "v0.4-mentions-claude.txt"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if mustGetAdapter(t, AgentCodex).Detector().IsWaitingForInput(string(pane)) {
+		t.Error("codex detector matched a Claude prompt pattern")
+	}
+	if !IsWaitingForInput(string(pane)) {
+		t.Error("expected the generic patterns to match (the false positive this guards against)")
+	}
+
+	if mustGetAdapter(t, AgentClaude).Detector().IsCompleted("Thanks!\nGoodbye") {
+		t.Error("claude detector treats a generic goodbye as completion")
+	}
+	if !mustGetAdapter(t, AgentClaude).Detector().IsAgentExited("12 tokens\nuser@host:~$ ") {
+		t.Error("the word tokens should not keep a claude run alive")
+	}
+}
+
+func TestPatternDetectorVersions(t *testing.T) {
+	def, ok := LookupDefinition("claude")
+	if !ok {
+		t.Fatal("claude not registered")
+	}
+	got := strings.Join(def.detector.Versions(), ",")
+	if got != "claude-code/2,claude-code/1" {
+		t.Errorf("Versions() = %q", got)
+	}
+}
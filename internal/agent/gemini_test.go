@@ -3,7 +3,7 @@ package agent
 import "testing"
 
 func TestGeminiLaunchCommand(t *testing.T) {
-	adapter := &GeminiAdapter{}
+	adapter := mustGetAdapter(t, AgentGemini)
 	cfg := &LaunchConfig{Prompt: "hello 'world'"}
 	cmd, err := adapter.LaunchCommand(cfg)
 	if err != nil {
@@ -16,14 +16,14 @@ func TestGeminiLaunchCommand(t *testing.T) {
 }
 
 func TestGeminiPromptInjection(t *testing.T) {
-	adapter := &GeminiAdapter{}
+	adapter := mustGetAdapter(t, AgentGemini)
 	if adapter.PromptInjection() != InjectionArg {
 		t.Fatalf("PromptInjection() = %v, want %v", adapter.PromptInjection(), InjectionArg)
 	}
 }
 
 func TestGeminiReadyPattern(t *testing.T) {
-	adapter := &GeminiAdapter{}
+	adapter := mustGetAdapter(t, AgentGemini)
 	pattern := adapter.ReadyPattern()
 	if pattern != "" {
 		t.Fatalf("ReadyPattern() = %q, want %q", pattern, "")
@@ -6,7 +6,7 @@ import (
 	"time"
 
 	"github.com/s22625/orch/internal/model"
-	"github.com/s22625/orch/internal/tmux"
+	"github.com/s22625/orch/internal/terminal"
 )
 
 type RunState struct {
@@ -55,7 +55,41 @@ func GetManager(run *model.Run) AgentManager {
 			RunRef:    run.Ref().String(),
 		}
 	}
-	return &TmuxManager{SessionName: getSessionName(run)}
+	if run.StreamLog != "" {
+		return &StreamManager{
+			Terminal:    terminalFor(run),
+			SessionName: getSessionName(run),
+			LogPath:     run.StreamLog,
+			Detector:    detectorFor(run.Agent),
+		}
+	}
+	return &TerminalManager{Terminal: terminalFor(run), SessionName: getSessionName(run), Detector: detectorFor(run.Agent)}
+}
+
+// terminalFor returns the terminal backend hosting the run's session
+// (tmux for runs of a backend that is not registered)
+func terminalFor(run *model.Run) terminal.Backend {
+	if b, err := terminal.Get(run.Terminal); err == nil {
+		return b
+	}
+	return terminal.Default()
+}
+
+// orDefault returns b, or tmux for a zero-value manager
+func orDefault(b terminal.Backend) terminal.Backend {
+	if b == nil {
+		return terminal.Default()
+	}
+	return b
+}
+
+// detectorFor returns the status detector of an agent (the generic one for
+// agents that are not registered)
+func detectorFor(agentName string) StatusDetector {
+	if adapter, err := GetAdapter(AgentType(agentName)); err == nil {
+		return adapter.Detector()
+	}
+	return genericDetector
 }
 
 func getSessionName(run *model.Run) string {
@@ -65,33 +99,38 @@ func getSessionName(run *model.Run) string {
 	return model.GenerateTmuxSession(run.IssueID, run.RunID)
 }
 
-type TmuxManager struct {
+// TerminalManager manages agents running interactively in a terminal
+// session; status is judged from what the session shows
+type TerminalManager struct {
+	Terminal    terminal.Backend // nil is tmux
 	SessionName string
+	Detector    StatusDetector // judges the panes of the run's agent
 }
 
-func (m *TmuxManager) IsAlive(run *model.Run) bool {
-	return tmux.HasSession(m.SessionName)
+func (m *TerminalManager) IsAlive(run *model.Run) bool {
+	return orDefault(m.Terminal).HasSession(m.SessionName)
 }
 
-func (m *TmuxManager) CaptureOutput(run *model.Run) (string, error) {
-	return tmux.CapturePane(m.SessionName, 100)
+func (m *TerminalManager) CaptureOutput(run *model.Run) (string, error) {
+	return orDefault(m.Terminal).Capture(m.SessionName, 100)
 }
 
-func (m *TmuxManager) DetectPrompt(output string) bool {
-	return IsWaitingForInput(output)
+func (m *TerminalManager) DetectPrompt(output string) bool {
+	return m.detector().IsWaitingForInput(output)
 }
 
-func (m *TmuxManager) GetStatus(run *model.Run, output string, state *RunState, outputChanged, hasPrompt bool) model.Status {
-	if IsAgentExited(output) {
+func (m *TerminalManager) GetStatus(run *model.Run, output string, state *RunState, outputChanged, hasPrompt bool) model.Status {
+	d := m.detector()
+	if d.IsAgentExited(output) {
 		return model.StatusUnknown
 	}
-	if IsCompleted(output) {
+	if d.IsCompleted(output) {
 		return model.StatusDone
 	}
-	if IsAPILimited(output) {
+	if d.IsAPILimited(output) {
 		return model.StatusBlockedAPI
 	}
-	if IsFailed(output) {
+	if d.IsFailed(output) {
 		return model.StatusFailed
 	}
 	if outputChanged {
@@ -103,15 +142,20 @@ func (m *TmuxManager) GetStatus(run *model.Run, output string, state *RunState,
 	return ""
 }
 
-func (m *TmuxManager) SendMessage(ctx context.Context, run *model.Run, message string, opts *SendOptions) error {
-	if !tmux.HasSession(m.SessionName) {
-		return &SessionNotFoundError{SessionName: m.SessionName}
+// detector falls back to the generic detector for a zero-value manager
+func (m *TerminalManager) detector() StatusDetector {
+	if m.Detector == nil {
+		return genericDetector
 	}
+	return m.Detector
+}
 
-	if opts != nil && opts.NoEnter {
-		return tmux.SendKeysLiteral(m.SessionName, message)
+func (m *TerminalManager) SendMessage(ctx context.Context, run *model.Run, message string, opts *SendOptions) error {
+	term := orDefault(m.Terminal)
+	if !term.HasSession(m.SessionName) {
+		return &SessionNotFoundError{SessionName: m.SessionName}
 	}
-	return tmux.SendKeys(m.SessionName, message)
+	return term.SendKeys(m.SessionName, message, opts == nil || !opts.NoEnter)
 }
 
 type OpenCodeManager struct {
@@ -270,154 +314,28 @@ func (m *OpenCodeManager) SendMessage(ctx context.Context, run *model.Run, messa
 	return client.SendMessagePrompt(ctx, m.SessionID, message, run.WorktreePath)
 }
 
-func IsWaitingForInput(output string) bool {
-	promptPatterns := []string{
-		"No, and tell Claude what to do differently",
-		"tell Claude what to do differently",
-		"↵ send",
-		"? for shortcuts",
-		"accept edits",
-		"bypass permissions",
-		"shift+tab to cycle",
-		"Esc to cancel",
-		"to show all projects",
-		"Type your message",
-		"ctrl+s send",
-		"enter newline",
-		"ctrl+c interrupt",
-	}
+// IsWaitingForInput, IsAgentExited, IsCompleted, IsAPILimited and IsFailed
+// apply the generic patterns of all known agents. Prefer the Detector of the
+// agent's adapter, which only matches that agent's screen.
 
-	for _, pattern := range promptPatterns {
-		if strings.Contains(output, pattern) {
-			return true
-		}
-	}
-	return false
+func IsWaitingForInput(output string) bool {
+	return genericDetector.IsWaitingForInput(output)
 }
 
 func IsAgentExited(output string) bool {
-	agentPatterns := []string{
-		"↵ send",
-		"accept edits",
-		"? for shortcuts",
-		"tell Claude what to do differently",
-		"tokens",
-		"Esc to cancel",
-		"to show all projects",
-		"ctrl+s send",
-		"enter newline",
-		"ctrl+c interrupt",
-		"opencode server listening",
-		"POST /session",
-		"POST /message",
-	}
-
-	for _, pattern := range agentPatterns {
-		if strings.Contains(output, pattern) {
-			return false
-		}
-	}
-
-	lines := strings.Split(strings.TrimSpace(output), "\n")
-	if len(lines) == 0 {
-		return false
-	}
-
-	lastLine := ""
-	for i := len(lines) - 1; i >= 0; i-- {
-		line := strings.TrimSpace(lines[i])
-		if line != "" {
-			lastLine = line
-			break
-		}
-	}
-
-	if lastLine == "" {
-		return false
-	}
-
-	if strings.Contains(lastLine, "git:(") && strings.Contains(lastLine, ")") {
-		return true
-	}
-
-	trimmed := strings.TrimRight(lastLine, " ")
-	if strings.HasSuffix(lastLine, "$ ") ||
-		strings.HasSuffix(lastLine, "% ") ||
-		strings.HasSuffix(lastLine, "# ") ||
-		strings.HasSuffix(lastLine, "❯ ") ||
-		strings.HasSuffix(lastLine, "➜ ") ||
-		strings.HasSuffix(trimmed, "$") ||
-		strings.HasSuffix(trimmed, "%") ||
-		strings.HasSuffix(trimmed, "✗") ||
-		strings.HasSuffix(trimmed, "❯") ||
-		strings.HasSuffix(trimmed, "➜") {
-		return true
-	}
-
-	return false
+	return genericDetector.IsAgentExited(output)
 }
 
 func IsCompleted(output string) bool {
-	lines := getLastLines(output, 5)
-	lowerOutput := strings.ToLower(lines)
-
-	completionPatterns := []string{
-		"task completed successfully",
-		"all tasks completed",
-		"session ended",
-		"goodbye",
-	}
-
-	for _, pattern := range completionPatterns {
-		if strings.Contains(lowerOutput, pattern) {
-			return true
-		}
-	}
-	return false
+	return genericDetector.IsCompleted(output)
 }
 
 func IsAPILimited(output string) bool {
-	lines := getLastLines(output, 30)
-	lowerOutput := strings.ToLower(lines)
-
-	apiLimitPatterns := []string{
-		"cost limit reached",
-		"rate limit exceeded",
-		"rate limit reached",
-		"quota exceeded",
-		"insufficient quota",
-		"resource exhausted",
-		"you've hit your limit",
-		"/rate-limit-options",
-		"stop and wait for limit to reset",
-	}
-
-	for _, pattern := range apiLimitPatterns {
-		if strings.Contains(lowerOutput, pattern) {
-			return true
-		}
-	}
-	return false
+	return genericDetector.IsAPILimited(output)
 }
 
 func IsFailed(output string) bool {
-	lines := getLastLines(output, 10)
-	lowerOutput := strings.ToLower(lines)
-
-	errorPatterns := []string{
-		"fatal error",
-		"unrecoverable error",
-		"agent crashed",
-		"session terminated",
-		"authentication failed",
-	}
-
-	for _, pattern := range errorPatterns {
-		if strings.Contains(lowerOutput, pattern) {
-			return true
-		}
-	}
-	return false
+	return genericDetector.IsFailed(output)
 }
 
 func getLastLines(s string, n int) string {
@@ -26,12 +26,12 @@ func TestGetManager(t *testing.T) {
 			wantType: "*agent.OpenCodeManager",
 		},
 		{
-			name: "claude run returns TmuxManager",
+			name: "claude run returns TerminalManager",
 			run: &model.Run{
 				Agent:       "claude",
 				TmuxSession: "orch-test-001",
 			},
-			wantType: "*agent.TmuxManager",
+			wantType: "*agent.TerminalManager",
 		},
 		{
 			name: "opencode run missing session ID still returns OpenCodeManager",
@@ -66,8 +66,8 @@ func TestGetManager(t *testing.T) {
 
 func typeName(v interface{}) string {
 	switch v.(type) {
-	case *TmuxManager:
-		return "TmuxManager"
+	case *TerminalManager:
+		return "TerminalManager"
 	case *OpenCodeManager:
 		return "OpenCodeManager"
 	default:
@@ -407,8 +407,8 @@ func TestIsFailed(t *testing.T) {
 	}
 }
 
-func TestTmuxManagerGetStatus(t *testing.T) {
-	manager := &TmuxManager{SessionName: "test-session"}
+func TestTerminalManagerGetStatus(t *testing.T) {
+	manager := &TerminalManager{SessionName: "test-session"}
 	run := &model.Run{RunID: "test-run"}
 
 	tests := []struct {
@@ -1,11 +1,14 @@
 package agent
 
 import (
+	"context"
 	"fmt"
 	"os"
 	"os/exec"
 	"path/filepath"
 	"strings"
+
+	"github.com/s22625/orch/internal/model"
 )
 
 // OpenCodeAdapter handles OpenCode CLI with HTTP API
@@ -76,6 +79,13 @@ func (a *OpenCodeAdapter) ReadyPattern() string {
 	return ""
 }
 
+// Detector recognizes the opencode TUI and server log
+func (a *OpenCodeAdapter) Detector() StatusDetector {
+	return openCodeDetector
+}
+
+var openCodeDetector = newPatternDetector(openCodePatternSets...)
+
 // AttachCommand returns the command to attach to a running opencode server.
 // This launches the opencode TUI connected to the server.
 func (a *OpenCodeAdapter) AttachCommand(port int) string {
@@ -95,7 +105,47 @@ func (a *OpenCodeAdapter) Env() []string {
 	}
 }
 
+// Usage totals the token metadata of the assistant messages in the run's
+// opencode session
+func (a *OpenCodeAdapter) Usage(ctx context.Context, run *model.Run) ([]model.Usage, error) {
+	if run.ServerPort == 0 || run.OpenCodeSessionID == "" {
+		return nil, nil
+	}
+	client := NewOpenCodeClient(run.ServerPort)
+	messages, err := client.GetMessages(ctx, run.OpenCodeSessionID, run.WorktreePath)
+	if err != nil {
+		return nil, err
+	}
+	return openCodeMessagesUsage(messages), nil
+}
+
+// openCodeMessagesUsage sums message tokens per provider/model. Costs
+// reported by opencode are kept; zero costs are estimated from list prices.
+func openCodeMessagesUsage(messages []Message) []model.Usage {
+	totals := make(usageTotals)
+	for _, msg := range messages {
+		info := msg.Info
+		if info.Role != "assistant" || info.Tokens == nil {
+			continue
+		}
+		name := info.ModelID
+		if info.ProviderID != "" && name != "" {
+			name = info.ProviderID + "/" + name
+		}
+		totals.add(model.Usage{
+			Model:            name,
+			InputTokens:      info.Tokens.Input,
+			OutputTokens:     info.Tokens.Output + info.Tokens.Reasoning,
+			CacheReadTokens:  info.Tokens.Cache.Read,
+			CacheWriteTokens: info.Tokens.Cache.Write,
+			Cost:             info.Cost,
+		})
+	}
+	return totals.list()
+}
+
 var _ Adapter = (*OpenCodeAdapter)(nil)
+var _ UsageReporter = (*OpenCodeAdapter)(nil)
 
 func shellQuote(s string) string {
 	if s == "" {
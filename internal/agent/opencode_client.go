@@ -131,6 +131,23 @@ type MessageInfo struct {
 	SessionID string    `json:"sessionID"`
 	Role      string    `json:"role"`
 	CreatedAt time.Time `json:"createdAt"`
+
+	// Set on assistant messages
+	ProviderID string         `json:"providerID,omitempty"`
+	ModelID    string         `json:"modelID,omitempty"`
+	Cost       float64        `json:"cost,omitempty"` // USD
+	Tokens     *MessageTokens `json:"tokens,omitempty"`
+}
+
+// MessageTokens is the token accounting of an assistant message
+type MessageTokens struct {
+	Input     int64 `json:"input"`
+	Output    int64 `json:"output"`
+	Reasoning int64 `json:"reasoning"`
+	Cache     struct {
+		Read  int64 `json:"read"`
+		Write int64 `json:"write"`
+	} `json:"cache"`
 }
 
 // PromptRequest represents a request to send a prompt
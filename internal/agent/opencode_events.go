@@ -0,0 +1,252 @@
+package agent
+
+import (
+	"context"
+	"encoding/json"
+	"sync"
+	"time"
+
+	"github.com/s22625/orch/internal/model"
+)
+
+// OpenCode server events the monitor understands
+const (
+	OpenCodeEventSessionStatus     = "session.status"
+	OpenCodeEventSessionIdle       = "session.idle"
+	OpenCodeEventSessionError      = "session.error"
+	OpenCodeEventMessageUpdated    = "message.updated"
+	OpenCodeEventPermissionAsked   = "permission.asked"
+	OpenCodeEventPermissionUpdated = "permission.updated" // older servers
+	OpenCodeEventPermissionReplied = "permission.replied"
+)
+
+// OpenCodeSignal is what an opencode server event says about one session
+type OpenCodeSignal struct {
+	SessionID string
+	Status    model.Status // run status the event implies ("" for none)
+
+	// Record names a monitor event worth keeping in the run's history
+	// ("permission", "error"); Attrs are its details
+	Record string
+	Attrs  map[string]string
+
+	// Activity is set for events showing the agent is producing output
+	Activity bool
+}
+
+// ParseOpenCodeEvent translates a server event. It returns nil for events
+// that say nothing about a session's progress.
+func ParseOpenCodeEvent(e Event) *OpenCodeSignal {
+	switch e.Type {
+	case OpenCodeEventSessionStatus:
+		var props struct {
+			SessionID string `json:"sessionID"`
+			Status    struct {
+				Type    string `json:"type"`
+				Message string `json:"message"`
+			} `json:"status"`
+		}
+		if json.Unmarshal(e.Properties, &props) != nil || props.SessionID == "" {
+			return nil
+		}
+		sig := &OpenCodeSignal{SessionID: props.SessionID}
+		switch SessionStatus(props.Status.Type) {
+		case SessionStatusBusy:
+			sig.Status = model.StatusRunning
+		case SessionStatusIdle:
+			sig.Status = model.StatusBlocked
+		case SessionStatusRetry:
+			sig.Status = model.StatusBlockedAPI
+			if props.Status.Message != "" {
+				sig.Attrs = map[string]string{"message": props.Status.Message}
+			}
+		default:
+			return nil
+		}
+		return sig
+
+	case OpenCodeEventSessionIdle:
+		var props struct {
+			SessionID string `json:"sessionID"`
+		}
+		if json.Unmarshal(e.Properties, &props) != nil || props.SessionID == "" {
+			return nil
+		}
+		return &OpenCodeSignal{SessionID: props.SessionID, Status: model.StatusBlocked}
+
+	case OpenCodeEventMessageUpdated:
+		var props struct {
+			Info struct {
+				SessionID string `json:"sessionID"`
+				Role      string `json:"role"`
+				Time      struct {
+					Completed int64 `json:"completed"`
+				} `json:"time"`
+				Error *openCodeError `json:"error"`
+			} `json:"info"`
+		}
+		if json.Unmarshal(e.Properties, &props) != nil || props.Info.SessionID == "" || props.Info.Role != "assistant" {
+			return nil
+		}
+		if props.Info.Error != nil {
+			return props.Info.Error.signal(props.Info.SessionID)
+		}
+		if props.Info.Time.Completed != 0 {
+			// The session reports idle once the whole turn is over
+			return &OpenCodeSignal{SessionID: props.Info.SessionID, Activity: true}
+		}
+		return &OpenCodeSignal{SessionID: props.Info.SessionID, Status: model.StatusRunning, Activity: true}
+
+	case OpenCodeEventPermissionAsked, OpenCodeEventPermissionUpdated:
+		var props struct {
+			SessionID  string `json:"sessionID"`
+			Type       string `json:"type"`
+			Permission string `json:"permission"`
+			Title      string `json:"title"`
+		}
+		if json.Unmarshal(e.Properties, &props) != nil || props.SessionID == "" {
+			return nil
+		}
+		attrs := map[string]string{}
+		if p := firstNonEmpty(props.Permission, props.Type); p != "" {
+			attrs["permission"] = p
+		}
+		if props.Title != "" {
+			attrs["title"] = props.Title
+		}
+		return &OpenCodeSignal{SessionID: props.SessionID, Status: model.StatusBlocked, Record: "permission", Attrs: attrs}
+
+	case OpenCodeEventPermissionReplied:
+		var props struct {
+			SessionID string `json:"sessionID"`
+		}
+		if json.Unmarshal(e.Properties, &props) != nil || props.SessionID == "" {
+			return nil
+		}
+		return &OpenCodeSignal{SessionID: props.SessionID, Status: model.StatusRunning}
+
+	case OpenCodeEventSessionError:
+		var props struct {
+			SessionID string         `json:"sessionID"`
+			Error     *openCodeError `json:"error"`
+		}
+		if json.Unmarshal(e.Properties, &props) != nil || props.SessionID == "" || props.Error == nil {
+			return nil
+		}
+		return props.Error.signal(props.SessionID)
+	}
+	return nil
+}
+
+// openCodeError is the error of a session or assistant message
+type openCodeError struct {
+	Name string `json:"name"`
+	Data struct {
+		Message string `json:"message"`
+	} `json:"data"`
+}
+
+// signal records the error; rate limits block the run on the API, other
+// errors leave the session waiting for the user
+func (e *openCodeError) signal(sessionID string) *OpenCodeSignal {
+	if e.Name == "MessageAbortedError" {
+		return nil // interrupted on purpose; the session goes idle
+	}
+	sig := &OpenCodeSignal{
+		SessionID: sessionID,
+		Status:    model.StatusBlocked,
+		Record:    "error",
+		Attrs:     map[string]string{"name": e.Name},
+	}
+	if e.Data.Message != "" {
+		sig.Attrs["message"] = e.Data.Message
+	}
+	if openCodeDetector.IsAPILimited(e.Data.Message) {
+		sig.Status = model.StatusBlockedAPI
+	}
+	return sig
+}
+
+func firstNonEmpty(values ...string) string {
+	for _, v := range values {
+		if v != "" {
+			return v
+		}
+	}
+	return ""
+}
+
+// Reconnect backoff of OpenCodeEventStream
+const (
+	OpenCodeStreamMinBackoff = time.Second
+	OpenCodeStreamMaxBackoff = 30 * time.Second
+)
+
+// OpenCodeEventStream keeps one event subscription to an opencode server,
+// reconnecting with backoff while the server is down
+type OpenCodeEventStream struct {
+	Port int
+
+	mu          sync.Mutex
+	connectedAt time.Time // zero while disconnected
+}
+
+// NewOpenCodeEventStream returns a stream for the server on port
+func NewOpenCodeEventStream(port int) *OpenCodeEventStream {
+	return &OpenCodeEventStream{Port: port}
+}
+
+// ConnectedSince returns when the current subscription was established,
+// or the zero time while the stream is down
+func (s *OpenCodeEventStream) ConnectedSince() time.Time {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.connectedAt
+}
+
+func (s *OpenCodeEventStream) setConnected(at time.Time) {
+	s.mu.Lock()
+	s.connectedAt = at
+	s.mu.Unlock()
+}
+
+// Run subscribes until ctx is done, calling handle for every signal.
+// Connection changes are reported to logger (may be nil).
+func (s *OpenCodeEventStream) Run(ctx context.Context, handle func(*OpenCodeSignal), logger Logger) {
+	client := NewOpenCodeClient(s.Port)
+	backoff := OpenCodeStreamMinBackoff
+	for {
+		events, err := client.SubscribeEvents(ctx)
+		if err == nil {
+			s.setConnected(time.Now())
+			if logger != nil {
+				logger.Printf("opencode :%d: event stream connected", s.Port)
+			}
+			backoff = OpenCodeStreamMinBackoff
+			for e := range events {
+				if sig := ParseOpenCodeEvent(e); sig != nil {
+					handle(sig)
+				}
+			}
+			s.setConnected(time.Time{})
+			if ctx.Err() != nil {
+				return
+			}
+			if logger != nil {
+				logger.Printf("opencode :%d: event stream closed, reconnecting", s.Port)
+			}
+		} else if ctx.Err() != nil {
+			return
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(backoff):
+		}
+		backoff *= 2
+		if backoff > OpenCodeStreamMaxBackoff {
+			backoff = OpenCodeStreamMaxBackoff
+		}
+	}
+}
@@ -0,0 +1,132 @@
+package agent
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/s22625/orch/internal/model"
+)
+
+func TestParseOpenCodeEvent(t *testing.T) {
+	tests := []struct {
+		name     string
+		event    string
+		status   model.Status
+		record   string
+		activity bool
+		nilSig   bool
+	}{
+		{"busy", `{"type":"session.status","properties":{"sessionID":"s1","status":{"type":"busy"}}}`, model.StatusRunning, "", false, false},
+		{"idle status", `{"type":"session.status","properties":{"sessionID":"s1","status":{"type":"idle"}}}`, model.StatusBlocked, "", false, false},
+		{"retry", `{"type":"session.status","properties":{"sessionID":"s1","status":{"type":"retry","message":"rate limit exceeded"}}}`, model.StatusBlockedAPI, "", false, false},
+		{"idle", `{"type":"session.idle","properties":{"sessionID":"s1"}}`, model.StatusBlocked, "", false, false},
+		{"assistant streaming", `{"type":"message.updated","properties":{"info":{"sessionID":"s1","role":"assistant","time":{"created":1}}}}`, model.StatusRunning, "", true, false},
+		{"assistant completed", `{"type":"message.updated","properties":{"info":{"sessionID":"s1","role":"assistant","time":{"created":1,"completed":2}}}}`, "", "", true, false},
+		{"user message", `{"type":"message.updated","properties":{"info":{"sessionID":"s1","role":"user"}}}`, "", "", false, true},
+		{"message error", `{"type":"message.updated","properties":{"info":{"sessionID":"s1","role":"assistant","error":{"name":"APIError","data":{"message":"Rate limit exceeded"}}}}}`, model.StatusBlockedAPI, "error", false, false},
+		{"permission", `{"type":"permission.asked","properties":{"sessionID":"s1","permission":"bash","title":"rm -rf build"}}`, model.StatusBlocked, "permission", false, false},
+		{"permission legacy", `{"type":"permission.updated","properties":{"sessionID":"s1","type":"edit"}}`, model.StatusBlocked, "permission", false, false},
+		{"permission replied", `{"type":"permission.replied","properties":{"sessionID":"s1"}}`, model.StatusRunning, "", false, false},
+		{"session error", `{"type":"session.error","properties":{"sessionID":"s1","error":{"name":"ProviderAuthError","data":{"message":"invalid key"}}}}`, model.StatusBlocked, "error", false, false},
+		{"aborted", `{"type":"session.error","properties":{"sessionID":"s1","error":{"name":"MessageAbortedError"}}}`, "", "", false, true},
+		{"error without session", `{"type":"session.error","properties":{"error":{"name":"UnknownError"}}}`, "", "", false, true},
+		{"other", `{"type":"server.connected","properties":{}}`, "", "", false, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var e Event
+			if err := json.Unmarshal([]byte(tt.event), &e); err != nil {
+				t.Fatal(err)
+			}
+			sig := ParseOpenCodeEvent(e)
+			if tt.nilSig {
+				if sig != nil {
+					t.Fatalf("signal = %+v, want nil", sig)
+				}
+				return
+			}
+			if sig == nil {
+				t.Fatal("signal = nil")
+			}
+			if sig.SessionID != "s1" || sig.Status != tt.status || sig.Record != tt.record || sig.Activity != tt.activity {
+				t.Errorf("signal = %+v", sig)
+			}
+		})
+	}
+}
+
+func TestParseOpenCodePermissionAttrs(t *testing.T) {
+	sig := ParseOpenCodeEvent(Event{
+		Type:       OpenCodeEventPermissionAsked,
+		Properties: json.RawMessage(`{"sessionID":"s1","permission":"bash","title":"rm -rf build"}`),
+	})
+	if sig.Attrs["permission"] != "bash" || sig.Attrs["title"] != "rm -rf build" {
+		t.Errorf("attrs = %v", sig.Attrs)
+	}
+}
+
+func TestOpenCodeEventStreamReconnects(t *testing.T) {
+	var mu sync.Mutex
+	connections := 0
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/event" {
+			http.NotFound(w, r)
+			return
+		}
+		mu.Lock()
+		connections++
+		n := connections
+		mu.Unlock()
+		w.Header().Set("Content-Type", "text/event-stream")
+		fmt.Fprintf(w, "data: {\"type\":\"session.idle\",\"properties\":{\"sessionID\":\"s%d\"}}\n\n", n)
+		w.(http.Flusher).Flush()
+		if n > 1 {
+			<-r.Context().Done() // keep the second connection open
+		}
+		// the first connection drops right away
+	}))
+	defer srv.Close()
+	u, _ := url.Parse(srv.URL)
+	port, _ := strconv.Atoi(u.Port())
+
+	stream := NewOpenCodeEventStream(port)
+	signals := make(chan *OpenCodeSignal, 4)
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		stream.Run(ctx, func(sig *OpenCodeSignal) { signals <- sig }, nil)
+		close(done)
+	}()
+
+	for _, want := range []string{"s1", "s2"} {
+		select {
+		case sig := <-signals:
+			if sig.SessionID != want || sig.Status != model.StatusBlocked {
+				t.Errorf("signal = %+v, want %s blocked", sig, want)
+			}
+		case <-time.After(5 * time.Second):
+			t.Fatalf("no signal from %s", want)
+		}
+	}
+	if stream.ConnectedSince().IsZero() {
+		t.Error("stream should be connected")
+	}
+
+	cancel()
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("Run did not return after cancel")
+	}
+	if !stream.ConnectedSince().IsZero() {
+		t.Error("stream should be disconnected")
+	}
+}
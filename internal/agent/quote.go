@@ -11,3 +11,8 @@ func doubleQuote(s string) string {
 	s = strings.ReplaceAll(s, "$", "\\$")
 	return "\"" + s + "\""
 }
+
+// singleQuote wraps a string in single quotes, escaping embedded single quotes.
+func singleQuote(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", "'\"'\"'") + "'"
+}
@@ -0,0 +1,339 @@
+package agent
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os/exec"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+	"text/template"
+
+	"github.com/s22625/orch/internal/config"
+	"github.com/s22625/orch/internal/model"
+)
+
+// Definition declares an agent: how to launch it and which pane text
+// reveals its state. Built-in agents and agents from the agents: config
+// section are both definitions.
+type Definition struct {
+	Name string
+
+	// Command is a text/template rendered with the LaunchConfig, e.g.
+	// `aider --yes{{if .Prompt}} --message {{quote .Prompt}}{{end}}`.
+	// quote double-quotes a value for the shell; squote single-quotes it.
+	Command string
+
+	Injection    InjectionMethod // arg (prompt in Command) or tmux (send-keys once ready)
+	ReadyPattern string          // regex matched against the pane before tmux injection
+
+	// Patterns are configured patterns. Each non-empty list replaces the
+	// matching lists of the agent's built-in pattern sets (or, for agents
+	// without any, of the generic set).
+	Patterns Patterns
+
+	// sets are the versioned pattern sets of a built-in agent
+	sets []PatternSet
+
+	detector *patternDetector
+
+	// usage reports token usage (built-in agents only)
+	usage func(ctx context.Context, run *model.Run) ([]model.Usage, error)
+
+	// streamCommand launches the agent non-interactively with stream-json
+	// output (built-in agents only; see StreamPipeline)
+	streamCommand string
+
+	tmpl       *template.Template
+	streamTmpl *template.Template
+}
+
+// isZero reports whether no pattern list is set
+func (p Patterns) isZero() bool {
+	return len(p.Prompt) == 0 && len(p.Alive) == 0 && len(p.Completed) == 0 &&
+		len(p.Failed) == 0 && len(p.APILimit) == 0
+}
+
+// builtinDefinitions are registered before any configuration is applied
+func builtinDefinitions() []*Definition {
+	return []*Definition{
+		{
+			Name:      string(AgentClaude),
+			Command:   `claude --dangerously-skip-permissions{{if .Profile}} --profile {{.Profile}}{{end}}{{if and .Resume .SessionName}} --resume {{.SessionName}}{{end}}{{if .Prompt}} {{quote .Prompt}}{{end}}`,
+			Injection: InjectionArg,
+			sets:      claudePatternSets,
+			usage:     claudeUsage,
+
+			streamCommand: `claude -p --output-format stream-json --verbose --dangerously-skip-permissions{{if .Profile}} --profile {{.Profile}}{{end}}{{if and .Resume .SessionName}} --resume {{.SessionName}}{{end}}{{if .Prompt}} {{quote .Prompt}}{{end}}`,
+		},
+		{
+			Name:      string(AgentCodex),
+			Command:   `codex --yolo{{if .Prompt}} {{squote .Prompt}}{{end}}`,
+			Injection: InjectionArg,
+			sets:      codexPatternSets,
+			usage:     codexUsage,
+		},
+		{
+			Name:      string(AgentGemini),
+			Command:   `gemini --yolo{{if .Prompt}} --prompt-interactive {{quote .Prompt}}{{end}}`,
+			Injection: InjectionArg,
+			sets:      geminiPatternSets,
+		},
+	}
+}
+
+var templateFuncs = template.FuncMap{
+	"quote":  doubleQuote,
+	"squote": singleQuote,
+}
+
+var sampleLaunchConfig = &LaunchConfig{
+	Type: "sample", CustomCmd: "x", WorkDir: "x", IssueID: "x", RunID: "x", RunPath: "x",
+	VaultPath: "x", Branch: "x", Prompt: "x", Resume: true, SessionName: "x", Profile: "x",
+	Port: 1, Model: "x", ModelVariant: "x", ContinueSession: true, StreamLog: "x",
+}
+
+// compile parses the command template and checks the ready pattern
+func (d *Definition) compile() error {
+	if strings.TrimSpace(d.Command) == "" {
+		return fmt.Errorf("agent %s: command is required", d.Name)
+	}
+	tmpl, err := template.New(d.Name).Funcs(templateFuncs).Option("missingkey=error").Parse(d.Command)
+	if err != nil {
+		return fmt.Errorf("agent %s: command: %w", d.Name, err)
+	}
+	// Render once with every field set so unknown fields fail here rather
+	// than at launch
+	if err := tmpl.Execute(io.Discard, sampleLaunchConfig); err != nil {
+		return fmt.Errorf("agent %s: command: %w", d.Name, err)
+	}
+	if d.ReadyPattern != "" {
+		if _, err := regexp.Compile(d.ReadyPattern); err != nil {
+			return fmt.Errorf("agent %s: ready_pattern: %w", d.Name, err)
+		}
+	}
+	switch d.Injection {
+	case "":
+		d.Injection = InjectionArg
+	case InjectionArg, InjectionTmux:
+	default:
+		return fmt.Errorf("agent %s: unsupported injection %q (want arg or tmux)", d.Name, d.Injection)
+	}
+	base := d.sets
+	if len(base) == 0 {
+		base = []PatternSet{genericPatternSet}
+	}
+	if d.Patterns.isZero() {
+		d.detector = newPatternDetector(base...)
+	} else {
+		d.detector = newPatternDetector(overlay("config/"+d.Name, d.Patterns, base)...)
+	}
+	d.tmpl = tmpl
+	if d.streamCommand != "" {
+		d.streamTmpl = template.Must(template.New(d.Name + "-stream").Funcs(templateFuncs).Option("missingkey=error").Parse(d.streamCommand))
+	}
+	return nil
+}
+
+// registry holds the declarative agents by name
+var registry = struct {
+	sync.RWMutex
+	defs  map[string]*Definition
+	order []string // built-ins first, then configured agents by name
+}{}
+
+func init() {
+	resetRegistry()
+}
+
+func resetRegistry() {
+	registry.Lock()
+	defer registry.Unlock()
+	registry.defs = make(map[string]*Definition)
+	registry.order = nil
+	for _, def := range builtinDefinitions() {
+		if err := def.compile(); err != nil {
+			panic(err)
+		}
+		registry.defs[def.Name] = def
+		registry.order = append(registry.order, def.Name)
+	}
+}
+
+var agentNameRegex = regexp.MustCompile(`^[a-z0-9][a-z0-9_-]*$`)
+
+// Configure registers the agents of the agents: config section on top of
+// the built-ins. An entry named like a built-in overrides only the fields it
+// sets. opencode and custom are not declarative and cannot be redefined.
+func Configure(agents map[string]config.AgentDefinition) error {
+	names := make([]string, 0, len(agents))
+	for name := range agents {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	builtins := make(map[string]*Definition)
+	for _, def := range builtinDefinitions() {
+		builtins[def.Name] = def
+	}
+
+	defs := make([]*Definition, 0, len(names))
+	for _, name := range names {
+		if !agentNameRegex.MatchString(name) {
+			return fmt.Errorf("agent %q: name must be lowercase letters, digits, '-' or '_'", name)
+		}
+		if name == string(AgentOpenCode) || name == string(AgentCustom) {
+			return fmt.Errorf("agent %s: built-in agent cannot be redefined", name)
+		}
+		def := definitionFromConfig(name, agents[name])
+		if builtin, ok := builtins[name]; ok {
+			def.inherit(builtin)
+		}
+		if err := def.compile(); err != nil {
+			return err
+		}
+		defs = append(defs, def)
+	}
+
+	resetRegistry()
+	registry.Lock()
+	defer registry.Unlock()
+	for _, def := range defs {
+		if _, ok := registry.defs[def.Name]; !ok {
+			registry.order = append(registry.order, def.Name)
+		}
+		registry.defs[def.Name] = def
+	}
+	return nil
+}
+
+// LoadConfigured loads the agents: section of the orch configuration into
+// the registry
+func LoadConfigured() error {
+	cfg, err := config.Load()
+	if err != nil {
+		return err
+	}
+	return Configure(cfg.Agents)
+}
+
+// inherit fills unset fields from a built-in definition
+func (d *Definition) inherit(builtin *Definition) {
+	if d.Command == "" {
+		d.Command = builtin.Command
+	}
+	if d.Injection == "" {
+		d.Injection = builtin.Injection
+	}
+	if d.ReadyPattern == "" {
+		d.ReadyPattern = builtin.ReadyPattern
+	}
+	d.sets = builtin.sets
+	d.usage = builtin.usage
+	if d.Command == builtin.Command {
+		d.streamCommand = builtin.streamCommand
+	}
+}
+
+func definitionFromConfig(name string, c config.AgentDefinition) *Definition {
+	return &Definition{
+		Name:         name,
+		Command:      c.Command,
+		Injection:    InjectionMethod(c.Injection),
+		ReadyPattern: c.ReadyPattern,
+		Patterns: Patterns{
+			Prompt:    c.PromptPatterns,
+			Completed: c.CompletedPatterns,
+			Failed:    c.FailedPatterns,
+			APILimit:  c.APILimitPatterns,
+		},
+	}
+}
+
+// LookupDefinition returns the registered definition for an agent name
+func LookupDefinition(name string) (*Definition, bool) {
+	registry.RLock()
+	defer registry.RUnlock()
+	def, ok := registry.defs[name]
+	return def, ok
+}
+
+// AgentNames lists every agent that can be launched: registered
+// definitions, then opencode and custom
+func AgentNames() []string {
+	registry.RLock()
+	defer registry.RUnlock()
+	names := append([]string(nil), registry.order...)
+	return append(names, string(AgentOpenCode), string(AgentCustom))
+}
+
+// DefinitionAdapter launches an agent from its Definition
+type DefinitionAdapter struct {
+	Def *Definition
+}
+
+func (a *DefinitionAdapter) Type() AgentType {
+	return AgentType(a.Def.Name)
+}
+
+// IsAvailable reports whether the command's executable is on PATH
+func (a *DefinitionAdapter) IsAvailable() bool {
+	fields := strings.Fields(a.Def.Command)
+	if len(fields) == 0 || strings.Contains(fields[0], "{{") {
+		return true // executable decided by the template; checked at launch
+	}
+	_, err := exec.LookPath(fields[0])
+	return err == nil
+}
+
+// LaunchCommand renders the command template. With cfg.StreamLog set it
+// renders the structured-output command wrapped by StreamPipeline.
+func (a *DefinitionAdapter) LaunchCommand(cfg *LaunchConfig) (string, error) {
+	tmpl := a.Def.tmpl
+	if cfg.StreamLog != "" {
+		if !a.SupportsStream() {
+			return "", fmt.Errorf("agent %s has no structured-output mode", a.Def.Name)
+		}
+		tmpl = a.Def.streamTmpl
+	}
+	var sb strings.Builder
+	if err := tmpl.Execute(&sb, cfg); err != nil {
+		return "", fmt.Errorf("agent %s: %w", a.Def.Name, err)
+	}
+	cmd := strings.TrimSpace(sb.String())
+	if cfg.StreamLog != "" {
+		cmd = StreamPipeline(cmd, cfg.StreamLog)
+	}
+	return cmd, nil
+}
+
+// SupportsStream reports whether the agent can run in structured-output mode
+func (a *DefinitionAdapter) SupportsStream() bool {
+	return a.Def.streamTmpl != nil
+}
+
+func (a *DefinitionAdapter) PromptInjection() InjectionMethod {
+	return a.Def.Injection
+}
+
+func (a *DefinitionAdapter) ReadyPattern() string {
+	return a.Def.ReadyPattern
+}
+
+// Detector judges panes with the definition's pattern sets
+func (a *DefinitionAdapter) Detector() StatusDetector {
+	return a.Def.detector
+}
+
+// Usage reports token usage for built-in agents that record it
+func (a *DefinitionAdapter) Usage(ctx context.Context, run *model.Run) ([]model.Usage, error) {
+	if a.Def.usage == nil {
+		return nil, nil
+	}
+	return a.Def.usage(ctx, run)
+}
+
+var _ Adapter = (*DefinitionAdapter)(nil)
+var _ UsageReporter = (*DefinitionAdapter)(nil)
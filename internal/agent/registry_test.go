@@ -0,0 +1,173 @@
+package agent
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/s22625/orch/internal/config"
+	"github.com/s22625/orch/internal/model"
+)
+
+func mustGetAdapter(t *testing.T, agentType AgentType) Adapter {
+	t.Helper()
+	adapter, err := GetAdapter(agentType)
+	if err != nil {
+		t.Fatalf("GetAdapter(%s) error: %v", agentType, err)
+	}
+	return adapter
+}
+
+func configureForTest(t *testing.T, agents map[string]config.AgentDefinition) {
+	t.Helper()
+	t.Cleanup(resetRegistry)
+	if err := Configure(agents); err != nil {
+		t.Fatalf("Configure error: %v", err)
+	}
+}
+
+func TestConfigureCustomAgent(t *testing.T) {
+	configureForTest(t, map[string]config.AgentDefinition{
+		"aider": {
+			Command:        `aider --yes{{if .Model}} --model {{.Model}}{{end}}{{if .Prompt}} --message {{quote .Prompt}}{{end}}`,
+			PromptPatterns: []string{"aider>"},
+			FailedPatterns: []string{"Traceback"},
+		},
+	})
+
+	agentType, err := ParseAgentType("aider")
+	if err != nil {
+		t.Fatalf("ParseAgentType error: %v", err)
+	}
+	adapter := mustGetAdapter(t, agentType)
+	if adapter.Type() != "aider" {
+		t.Errorf("Type() = %q, want aider", adapter.Type())
+	}
+	if adapter.PromptInjection() != InjectionArg {
+		t.Errorf("PromptInjection() = %v, want arg", adapter.PromptInjection())
+	}
+
+	cmd, err := adapter.LaunchCommand(&LaunchConfig{Prompt: `fix "it"`, Model: "sonnet"})
+	if err != nil {
+		t.Fatalf("LaunchCommand error: %v", err)
+	}
+	want := `aider --yes --model sonnet --message "fix \"it\""`
+	if cmd != want {
+		t.Errorf("command = %q, want %q", cmd, want)
+	}
+
+	names := AgentNames()
+	if strings.Join(names, ",") != "claude,codex,gemini,aider,opencode,custom" {
+		t.Errorf("AgentNames() = %v", names)
+	}
+
+	// Configured patterns drive status detection; unset lists use the defaults
+	manager := GetManager(&model.Run{Agent: "aider", IssueID: "orch-1", RunID: "r1"}).(*TerminalManager)
+	if !manager.DetectPrompt("aider> ") {
+		t.Error("expected aider prompt to be detected")
+	}
+	if manager.DetectPrompt("? for shortcuts") {
+		t.Error("built-in prompt pattern should be replaced by the configured one")
+	}
+	if got := manager.GetStatus(nil, "Traceback (most recent call last):\naider> ", nil, false, true); got != model.StatusFailed {
+		t.Errorf("GetStatus(traceback) = %q, want failed", got)
+	}
+	if got := manager.GetStatus(nil, "Rate limit exceeded\naider> ", nil, false, true); got != model.StatusBlockedAPI {
+		t.Errorf("GetStatus(rate limit) = %q, want blocked_api", got)
+	}
+}
+
+func TestConfigureOverridesBuiltin(t *testing.T) {
+	configureForTest(t, map[string]config.AgentDefinition{
+		"claude": {Command: `claude --model opus{{if .Prompt}} {{quote .Prompt}}{{end}}`},
+		"codex":  {CompletedPatterns: []string{"codex is done"}},
+	})
+
+	cmd, err := mustGetAdapter(t, AgentClaude).LaunchCommand(&LaunchConfig{Prompt: "hi"})
+	if err != nil {
+		t.Fatalf("LaunchCommand error: %v", err)
+	}
+	if cmd != `claude --model opus "hi"` {
+		t.Errorf("command = %q", cmd)
+	}
+	if _, ok := mustGetAdapter(t, AgentClaude).(UsageReporter); !ok {
+		t.Error("overridden claude should keep usage reporting")
+	}
+	if def, _ := LookupDefinition("claude"); def.usage == nil {
+		t.Error("overridden claude lost its usage reader")
+	}
+
+	// Only the patterns are overridden; the built-in command is kept
+	cmd, err = mustGetAdapter(t, AgentCodex).LaunchCommand(&LaunchConfig{Prompt: "hi"})
+	if err != nil {
+		t.Fatalf("LaunchCommand error: %v", err)
+	}
+	if cmd != `codex --yolo 'hi'` {
+		t.Errorf("command = %q", cmd)
+	}
+	if d := detectorFor("codex"); !d.IsCompleted("codex is done") || d.IsCompleted("goodbye") {
+		t.Error("configured completed patterns should replace the built-in ones")
+	}
+	// Lists that were not configured keep the built-in codex patterns
+	if d := detectorFor("codex"); !d.IsAPILimited("■ You've hit your usage limit.") {
+		t.Error("built-in codex api limit patterns lost")
+	}
+
+	if names := AgentNames(); len(names) != 5 {
+		t.Errorf("AgentNames() = %v, want built-ins only", names)
+	}
+}
+
+func TestConfigureRejectsInvalidDefinitions(t *testing.T) {
+	t.Cleanup(resetRegistry)
+	tests := []struct {
+		name   string
+		agents map[string]config.AgentDefinition
+		want   string
+	}{
+		{"missing command", map[string]config.AgentDefinition{"aider": {}}, "command is required"},
+		{"bad template", map[string]config.AgentDefinition{"aider": {Command: "aider {{.Prompt"}}, "command"},
+		{"unknown field", map[string]config.AgentDefinition{"aider": {Command: "aider {{.Nope}}"}}, "command"},
+		{"bad injection", map[string]config.AgentDefinition{"aider": {Command: "aider", Injection: "stdin"}}, "unsupported injection"},
+		{"bad ready pattern", map[string]config.AgentDefinition{"aider": {Command: "aider", Injection: "tmux", ReadyPattern: "("}}, "ready_pattern"},
+		{"bad name", map[string]config.AgentDefinition{"Aider!": {Command: "aider"}}, "name must be"},
+		{"opencode", map[string]config.AgentDefinition{"opencode": {Command: "opencode"}}, "cannot be redefined"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := Configure(tt.agents)
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Fatalf("Configure() error = %v, want %q", err, tt.want)
+			}
+		})
+	}
+
+	// A failed Configure leaves the registry untouched
+	if _, err := ParseAgentType("aider"); err == nil {
+		t.Error("aider registered despite invalid configuration")
+	}
+}
+
+func TestTmuxInjectedAgent(t *testing.T) {
+	configureForTest(t, map[string]config.AgentDefinition{
+		"tool": {Command: "tool {{if .Prompt}}{{.Prompt}}{{end}}", Injection: "tmux", ReadyPattern: `\$ $`},
+	})
+	adapter := mustGetAdapter(t, "tool")
+	if adapter.PromptInjection() != InjectionTmux || adapter.ReadyPattern() != `\$ $` {
+		t.Errorf("injection = %v, ready = %q", adapter.PromptInjection(), adapter.ReadyPattern())
+	}
+	cmd, err := adapter.LaunchCommand(&LaunchConfig{})
+	if err != nil || cmd != "tool" {
+		t.Errorf("LaunchCommand() = %q, %v; want tool", cmd, err)
+	}
+}
+
+func TestParseAgentTypeUnknown(t *testing.T) {
+	if _, err := ParseAgentType("aider"); err == nil {
+		t.Error("expected error for unregistered agent")
+	}
+	for _, name := range []string{"claude", "codex", "gemini", "opencode", "custom"} {
+		if _, err := ParseAgentType(name); err != nil {
+			t.Errorf("ParseAgentType(%s) error: %v", name, err)
+		}
+	}
+}
@@ -0,0 +1,131 @@
+package agent
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Rate-limit messages that say when the limit lifts, e.g.
+//
+//	Claude AI usage limit reached|1760425200
+//	5-hour limit reached ∙ resets 3pm (Asia/Tokyo)
+//	Your limit will reset at 10:30am
+//	You've hit your usage limit. ... try again in 2 days 3 hours 4 minutes.
+//	Please retry in 41.5s
+var (
+	resetEpochRe    = regexp.MustCompile(`(?i)limit reached\|(\d{10})\b`)
+	resetRelativeRe = regexp.MustCompile(`(?i)(?:try again|retry|resets?|available)\s+in\s+((?:\d+(?:\.\d+)?\s*(?:days?|d|hours?|hrs?|h|minutes?|mins?|m|seconds?|secs?|s)\b[\s,]*(?:and\s+)?)+)`)
+	resetClockRe    = regexp.MustCompile(`(?i)(?:resets?|try again)(?:\s+at)?\s+(\d{1,2})(?::(\d{2}))?\s*(am|pm)?(?:\s*\(([A-Za-z_]+(?:/[A-Za-z_+\-0-9]+)*)\))?`)
+	durationPartRe  = regexp.MustCompile(`(?i)(\d+(?:\.\d+)?)\s*(days?|d|hours?|hrs?|h|minutes?|mins?|m|seconds?|secs?|s)\b`)
+)
+
+// ResetTime is when an agent said its rate limit lifts
+type ResetTime struct {
+	At   time.Time
+	Text string // the phrase it was parsed from
+}
+
+// ParseResetTime finds the last reset time an agent printed in its output.
+// Clock times ("resets 3pm") are the next such time after now, in the zone
+// given in parentheses or now's zone.
+func ParseResetTime(output string, now time.Time) (ResetTime, bool) {
+	var best ResetTime
+	bestPos := -1
+
+	consider := func(pos int, text string, at time.Time) {
+		if pos > bestPos {
+			bestPos = pos
+			best = ResetTime{At: at, Text: strings.TrimSpace(text)}
+		}
+	}
+
+	for _, m := range resetEpochRe.FindAllStringSubmatchIndex(output, -1) {
+		if sec, err := strconv.ParseInt(output[m[2]:m[3]], 10, 64); err == nil {
+			consider(m[0], output[m[0]:m[1]], time.Unix(sec, 0))
+		}
+	}
+	for _, m := range resetRelativeRe.FindAllStringSubmatchIndex(output, -1) {
+		if d, ok := parseResetDuration(output[m[2]:m[3]]); ok {
+			consider(m[0], output[m[0]:m[1]], now.Add(d))
+		}
+	}
+	for _, m := range resetClockRe.FindAllStringSubmatchIndex(output, -1) {
+		// A bare number ("resets 5") is not a time
+		if m[4] < 0 && m[6] < 0 {
+			continue
+		}
+		group := func(i int) string {
+			if m[2*i] < 0 {
+				return ""
+			}
+			return output[m[2*i]:m[2*i+1]]
+		}
+		if at, ok := nextClockTime(group(1), group(2), group(3), group(4), now); ok {
+			consider(m[0], output[m[0]:m[1]], at)
+		}
+	}
+
+	return best, bestPos >= 0
+}
+
+func parseResetDuration(s string) (time.Duration, bool) {
+	var total time.Duration
+	for _, part := range durationPartRe.FindAllStringSubmatch(s, -1) {
+		n, err := strconv.ParseFloat(part[1], 64)
+		if err != nil {
+			return 0, false
+		}
+		var unit time.Duration
+		switch strings.ToLower(part[2])[0] {
+		case 'd':
+			unit = 24 * time.Hour
+		case 'h':
+			unit = time.Hour
+		case 'm':
+			unit = time.Minute
+		default:
+			unit = time.Second
+		}
+		total += time.Duration(n * float64(unit))
+	}
+	return total, total > 0
+}
+
+func nextClockTime(hourStr, minStr, ampm, zone string, now time.Time) (time.Time, bool) {
+	hour, err := strconv.Atoi(hourStr)
+	if err != nil {
+		return time.Time{}, false
+	}
+	minute := 0
+	if minStr != "" {
+		minute, _ = strconv.Atoi(minStr)
+	}
+	switch strings.ToLower(ampm) {
+	case "am":
+		if hour == 12 {
+			hour = 0
+		}
+	case "pm":
+		if hour < 12 {
+			hour += 12
+		}
+	}
+	if hour > 23 || minute > 59 {
+		return time.Time{}, false
+	}
+
+	loc := now.Location()
+	if zone != "" {
+		if l, err := time.LoadLocation(zone); err == nil {
+			loc = l
+		}
+	}
+	local := now.In(loc)
+	at := time.Date(local.Year(), local.Month(), local.Day(), hour, minute, 0, 0, loc)
+	if !at.After(local) {
+		at = at.AddDate(0, 0, 1)
+	}
+	return at, true
+}
@@ -0,0 +1,94 @@
+package agent
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParseResetTime(t *testing.T) {
+	tokyo, err := time.LoadLocation("Asia/Tokyo")
+	if err != nil {
+		t.Skip("tzdata not available")
+	}
+	now := time.Date(2025, 10, 14, 13, 20, 0, 0, tokyo)
+
+	tests := []struct {
+		name   string
+		output string
+		want   time.Time
+		text   string
+	}{
+		{
+			name:   "claude epoch",
+			output: "Claude AI usage limit reached|1760425200",
+			want:   time.Unix(1760425200, 0),
+			text:   "limit reached|1760425200",
+		},
+		{
+			name:   "claude clock with zone",
+			output: "5-hour limit reached ∙ resets 3pm (Asia/Tokyo)\n  /upgrade to increase your usage limit.",
+			want:   time.Date(2025, 10, 14, 15, 0, 0, 0, tokyo),
+			text:   "resets 3pm (Asia/Tokyo)",
+		},
+		{
+			name:   "clock in another zone",
+			output: "Your limit will reset at 10:30am (America/New_York)",
+			want:   time.Date(2025, 10, 14, 23, 30, 0, 0, tokyo),
+			text:   "reset at 10:30am (America/New_York)",
+		},
+		{
+			name:   "clock already passed today",
+			output: "You've hit your limit · resets 9am",
+			want:   time.Date(2025, 10, 15, 9, 0, 0, 0, tokyo),
+			text:   "resets 9am",
+		},
+		{
+			name:   "codex relative",
+			output: "■ You've hit your usage limit. Upgrade to Pro (https://openai.com/chatgpt/pricing) or try again in 2 days 3 hours 4 minutes.",
+			want:   now.Add(51*time.Hour + 4*time.Minute),
+			text:   "try again in 2 days 3 hours 4 minutes",
+		},
+		{
+			name:   "short relative",
+			output: "Rate limited. Try again in 42m",
+			want:   now.Add(42 * time.Minute),
+			text:   "Try again in 42m",
+		},
+		{
+			name:   "gemini retry",
+			output: "Quota exceeded for quota metric 'Generate Content API requests per minute'. Please retry in 41.5s",
+			want:   now.Add(41500 * time.Millisecond),
+			text:   "retry in 41.5s",
+		},
+		{
+			name:   "latest message wins",
+			output: "try again in 5m\n...\nYou've hit your limit · resets 4pm",
+			want:   time.Date(2025, 10, 14, 16, 0, 0, 0, tokyo),
+			text:   "resets 4pm",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := ParseResetTime(tt.output, now)
+			if !ok {
+				t.Fatal("no reset time found")
+			}
+			if !got.At.Equal(tt.want) {
+				t.Errorf("At = %v, want %v", got.At, tt.want)
+			}
+			if got.Text != tt.text {
+				t.Errorf("Text = %q, want %q", got.Text, tt.text)
+			}
+		})
+	}
+
+	for _, output := range []string{
+		"API Error: 429 rate_limit_error",
+		"resets 5 times",
+		"› implement the reset button",
+	} {
+		if got, ok := ParseResetTime(output, now); ok {
+			t.Errorf("ParseResetTime(%q) = %+v, want none", output, got)
+		}
+	}
+}
@@ -0,0 +1,69 @@
+package agent
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/s22625/orch/internal/model"
+	"github.com/s22625/orch/internal/store"
+	"github.com/s22625/orch/internal/terminal"
+)
+
+// stopAttempts bounds how often StopRun re-reads a run whose status changed
+// while it was being stopped
+const stopAttempts = 3
+
+// StopResult is what StopRun did
+type StopResult struct {
+	Status        model.Status // status of the run afterwards
+	AlreadyDone   bool         // the run had already ended; nothing was done
+	KilledSession string       // session that was killed ("" if none was alive)
+	KillErr       error        // the session could not be killed
+}
+
+// StopRun stops a run as orch stop does: it kills the run's session, then
+// records the run canceled if its status is still the one last read. When
+// another process changed the status meanwhile the run is re-read, and a
+// run that ended on its own is reported AlreadyDone. A session that cannot
+// be killed is reported in KillErr; the run is still recorded canceled.
+func StopRun(st store.Store, run *model.Run) (*StopResult, error) {
+	if run.Status.IsFinished() {
+		return &StopResult{Status: run.Status, AlreadyDone: true}, nil
+	}
+
+	result := &StopResult{Status: model.StatusCanceled}
+	result.KilledSession, result.KillErr = KillSession(run)
+
+	current := run
+	for attempt := 1; ; attempt++ {
+		err := st.AppendEventIf(current.Ref(), current.Status, model.NewStatusEvent(model.StatusCanceled))
+		if err == nil {
+			return result, nil
+		}
+		if !errors.Is(err, store.ErrStatusConflict) || attempt == stopAttempts {
+			return nil, fmt.Errorf("failed to append canceled event: %w", err)
+		}
+		if current, err = st.GetRun(run.Ref()); err != nil {
+			return nil, err
+		}
+		if current.Status.IsFinished() {
+			result.Status = current.Status
+			result.AlreadyDone = true
+			return result, nil
+		}
+	}
+}
+
+// KillSession kills the run's terminal session if it is alive and returns
+// its name ("" if there was none)
+func KillSession(run *model.Run) (string, error) {
+	session := getSessionName(run)
+	term, err := terminal.Get(run.Terminal)
+	if err != nil {
+		return session, err
+	}
+	if !term.HasSession(session) {
+		return "", nil
+	}
+	return session, term.Kill(session)
+}
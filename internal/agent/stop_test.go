@@ -0,0 +1,61 @@
+package agent
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/s22625/orch/internal/model"
+	"github.com/s22625/orch/internal/store/file"
+)
+
+func TestStopRun(t *testing.T) {
+	vault := t.TempDir()
+	os.MkdirAll(filepath.Join(vault, "issues"), 0755)
+	os.WriteFile(filepath.Join(vault, "issues", "orch-1.md"), []byte("---\ntype: issue\n---\n# Test"), 0644)
+	st, err := file.New(vault)
+	if err != nil {
+		t.Fatal(err)
+	}
+	newRun := func(runID string, statuses ...model.Status) *model.Run {
+		run, err := st.CreateRun("orch-1", runID, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, s := range statuses {
+			st.AppendEvent(run.Ref(), model.NewStatusEvent(s))
+		}
+		run, _ = st.GetRun(run.Ref())
+		return run
+	}
+
+	run := newRun("20250101-090000", model.StatusRunning)
+	result, err := StopRun(st, run)
+	if err != nil {
+		t.Fatalf("StopRun: %v", err)
+	}
+	if result.AlreadyDone || result.Status != model.StatusCanceled {
+		t.Errorf("result = %+v", result)
+	}
+	if loaded, _ := st.GetRun(run.Ref()); loaded.Status != model.StatusCanceled {
+		t.Errorf("status = %s, want canceled", loaded.Status)
+	}
+
+	// A stale copy: the daemon moved the run on after it was read
+	stale := newRun("20250101-100000", model.StatusRunning)
+	st.AppendEvent(stale.Ref(), model.NewStatusEvent(model.StatusBlocked))
+	if result, err := StopRun(st, stale); err != nil || result.Status != model.StatusCanceled {
+		t.Errorf("StopRun(stale) = %+v, %v", result, err)
+	}
+
+	// ... or it finished on its own meanwhile
+	finished := newRun("20250101-110000", model.StatusRunning)
+	st.AppendEvent(finished.Ref(), model.NewStatusEvent(model.StatusDone))
+	result, err = StopRun(st, finished)
+	if err != nil || !result.AlreadyDone || result.Status != model.StatusDone {
+		t.Errorf("StopRun(finished meanwhile) = %+v, %v", result, err)
+	}
+	if loaded, _ := st.GetRun(finished.Ref()); loaded.Status != model.StatusDone {
+		t.Errorf("status = %s, want done", loaded.Status)
+	}
+}
@@ -0,0 +1,567 @@
+package agent
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/s22625/orch/internal/model"
+)
+
+// Structured-output runs start the agent non-interactively with NDJSON
+// output (claude -p --output-format stream-json). The output is tee'd to a
+// per-run log; the daemon tails it for events and exact status, and the tmux
+// pane shows it rendered by `orch stream-view`.
+
+// StreamRecord is one line of a stream-json log
+type StreamRecord struct {
+	Type    string // system, assistant, user, result; "text" for non-JSON lines
+	Subtype string // init (system); success, error_max_turns, ... (result)
+	Offset  int64  // byte offset just past the line in the log
+
+	SessionID string
+	Model     string // init and assistant records
+
+	Text      string       // assistant text, result text or a non-JSON line
+	ToolCalls []StreamTool // tool_use blocks (assistant)
+	Results   []StreamTool // tool_result blocks (user)
+
+	// Assistant usage, keyed by MessageID (a message split over several
+	// records repeats its usage)
+	MessageID string
+	Usage     *model.Usage
+
+	// Result records
+	IsError    bool
+	NumTurns   int
+	DurationMS int64
+	CostUSD    float64
+	ModelUsage []model.Usage
+}
+
+// StreamTool is a tool call or the result of one
+type StreamTool struct {
+	ID      string
+	Name    string // resolved from the call for results (see StreamTail)
+	Input   string // one-line summary of the call's input
+	Output  string // result content
+	IsError bool
+}
+
+// IsTerminal reports whether the record ends an agent invocation
+func (r *StreamRecord) IsTerminal() bool {
+	return r.Type == "result"
+}
+
+type streamLine struct {
+	Type      string `json:"type"`
+	Subtype   string `json:"subtype"`
+	SessionID string `json:"session_id"`
+	Model     string `json:"model"`
+	Message   *struct {
+		ID      string               `json:"id"`
+		Model   string               `json:"model"`
+		Content []streamContentBlock `json:"content"`
+		Usage   *claudeMessageUsage  `json:"usage"`
+	} `json:"message"`
+
+	IsError    bool                        `json:"is_error"`
+	Result     string                      `json:"result"`
+	NumTurns   int                         `json:"num_turns"`
+	DurationMS int64                       `json:"duration_ms"`
+	CostUSD    float64                     `json:"total_cost_usd"`
+	ModelUsage map[string]streamModelUsage `json:"modelUsage"`
+}
+
+type streamContentBlock struct {
+	Type      string          `json:"type"`
+	Text      string          `json:"text"`
+	ID        string          `json:"id"`
+	Name      string          `json:"name"`
+	Input     json.RawMessage `json:"input"`
+	ToolUseID string          `json:"tool_use_id"`
+	Content   json.RawMessage `json:"content"`
+	IsError   bool            `json:"is_error"`
+}
+
+type claudeMessageUsage struct {
+	InputTokens              int64 `json:"input_tokens"`
+	OutputTokens             int64 `json:"output_tokens"`
+	CacheCreationInputTokens int64 `json:"cache_creation_input_tokens"`
+	CacheReadInputTokens     int64 `json:"cache_read_input_tokens"`
+}
+
+type streamModelUsage struct {
+	InputTokens              int64   `json:"inputTokens"`
+	OutputTokens             int64   `json:"outputTokens"`
+	CacheReadInputTokens     int64   `json:"cacheReadInputTokens"`
+	CacheCreationInputTokens int64   `json:"cacheCreationInputTokens"`
+	CostUSD                  float64 `json:"costUSD"`
+}
+
+// ParseStreamLine parses one log line. Lines that are not stream-json
+// (stray output of the agent) become "text" records; blank lines are skipped.
+func ParseStreamLine(line []byte) (*StreamRecord, bool) {
+	line = bytes.TrimSpace(line)
+	if len(line) == 0 {
+		return nil, false
+	}
+	var l streamLine
+	if line[0] != '{' || json.Unmarshal(line, &l) != nil || l.Type == "" {
+		return &StreamRecord{Type: "text", Text: string(line)}, true
+	}
+
+	rec := &StreamRecord{
+		Type:      l.Type,
+		Subtype:   l.Subtype,
+		SessionID: l.SessionID,
+		Model:     l.Model,
+	}
+	switch l.Type {
+	case "assistant", "user":
+		if l.Message == nil {
+			break
+		}
+		if l.Message.Model != "" {
+			rec.Model = l.Message.Model
+		}
+		var texts []string
+		for _, block := range l.Message.Content {
+			switch block.Type {
+			case "text":
+				texts = append(texts, block.Text)
+			case "tool_use":
+				rec.ToolCalls = append(rec.ToolCalls, StreamTool{
+					ID:    block.ID,
+					Name:  block.Name,
+					Input: summarizeToolInput(block.Input),
+				})
+			case "tool_result":
+				rec.Results = append(rec.Results, StreamTool{
+					ID:      block.ToolUseID,
+					Output:  toolResultText(block.Content),
+					IsError: block.IsError,
+				})
+			}
+		}
+		rec.Text = strings.Join(texts, "\n")
+		if u := l.Message.Usage; u != nil && l.Type == "assistant" && rec.Model != "<synthetic>" {
+			rec.MessageID = l.Message.ID
+			rec.Usage = &model.Usage{
+				Model:            rec.Model,
+				InputTokens:      u.InputTokens,
+				OutputTokens:     u.OutputTokens,
+				CacheReadTokens:  u.CacheReadInputTokens,
+				CacheWriteTokens: u.CacheCreationInputTokens,
+			}
+		}
+	case "result":
+		rec.Text = l.Result
+		rec.IsError = l.IsError
+		rec.NumTurns = l.NumTurns
+		rec.DurationMS = l.DurationMS
+		rec.CostUSD = l.CostUSD
+		for name, mu := range l.ModelUsage {
+			rec.ModelUsage = append(rec.ModelUsage, model.Usage{
+				Model:            name,
+				InputTokens:      mu.InputTokens,
+				OutputTokens:     mu.OutputTokens,
+				CacheReadTokens:  mu.CacheReadInputTokens,
+				CacheWriteTokens: mu.CacheCreationInputTokens,
+				Cost:             mu.CostUSD,
+			})
+		}
+	}
+	return rec, true
+}
+
+// toolInputKeys are the input fields that best describe a tool call
+var toolInputKeys = []string{"command", "file_path", "path", "pattern", "url", "query", "description", "prompt"}
+
+func summarizeToolInput(raw json.RawMessage) string {
+	var input map[string]any
+	if json.Unmarshal(raw, &input) != nil {
+		return ""
+	}
+	for _, key := range toolInputKeys {
+		if s, ok := input[key].(string); ok && s != "" {
+			return s
+		}
+	}
+	return ""
+}
+
+// toolResultText flattens a tool_result content (a string or text blocks)
+func toolResultText(raw json.RawMessage) string {
+	var s string
+	if json.Unmarshal(raw, &s) == nil {
+		return s
+	}
+	var blocks []struct {
+		Type string `json:"type"`
+		Text string `json:"text"`
+	}
+	if json.Unmarshal(raw, &blocks) != nil {
+		return ""
+	}
+	var texts []string
+	for _, b := range blocks {
+		if b.Type == "text" {
+			texts = append(texts, b.Text)
+		}
+	}
+	return strings.Join(texts, "\n")
+}
+
+// StreamTail reads the records appended to a stream log since the last call.
+// It remembers tool names so results can be attributed to their call.
+type StreamTail struct {
+	Path string
+
+	offset  int64
+	partial []byte
+	tools   map[string]string
+}
+
+// NewStreamTail starts tailing a log from its beginning
+func NewStreamTail(path string) *StreamTail {
+	return &StreamTail{Path: path, tools: make(map[string]string)}
+}
+
+// Next returns the complete lines appended since the previous call. A log
+// that does not exist yet yields no records.
+func (t *StreamTail) Next() ([]*StreamRecord, error) {
+	f, err := os.Open(t.Path)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	if info, err := f.Stat(); err == nil && info.Size() < t.offset {
+		// Truncated or replaced: start over
+		t.offset, t.partial = 0, nil
+	}
+	if _, err := f.Seek(t.offset, io.SeekStart); err != nil {
+		return nil, err
+	}
+	data, err := io.ReadAll(f)
+	if err != nil {
+		return nil, err
+	}
+
+	var records []*StreamRecord
+	start := t.offset - int64(len(t.partial))
+	buf := append(t.partial, data...)
+	for {
+		i := bytes.IndexByte(buf, '\n')
+		if i < 0 {
+			break
+		}
+		start += int64(i + 1)
+		if rec, ok := ParseStreamLine(buf[:i]); ok {
+			rec.Offset = start
+			t.resolve(rec)
+			records = append(records, rec)
+		}
+		buf = buf[i+1:]
+	}
+	t.partial = append([]byte(nil), buf...)
+	t.offset += int64(len(data))
+	return records, nil
+}
+
+func (t *StreamTail) resolve(rec *StreamRecord) {
+	for _, call := range rec.ToolCalls {
+		t.tools[call.ID] = call.Name
+	}
+	for i := range rec.Results {
+		rec.Results[i].Name = t.tools[rec.Results[i].ID]
+	}
+}
+
+// ReadStreamLog parses a whole stream log
+func ReadStreamLog(path string) ([]*StreamRecord, error) {
+	return NewStreamTail(path).Next()
+}
+
+// streamWindow is how much of the end of a log is read to judge the state
+// of the latest invocation
+const streamWindow = 256 * 1024
+
+// readStreamWindow parses the last streamWindow bytes of a log. The first
+// (possibly partial) line of the window is dropped.
+func readStreamWindow(path string) ([]*StreamRecord, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	info, err := f.Stat()
+	if err != nil {
+		return nil, err
+	}
+	start := info.Size() - streamWindow
+	if start < 0 {
+		start = 0
+	}
+	if _, err := f.Seek(start, io.SeekStart); err != nil {
+		return nil, err
+	}
+
+	tail := NewStreamTail(path)
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(make([]byte, 64*1024), streamWindow+1)
+	first := start > 0
+	var records []*StreamRecord
+	for scanner.Scan() {
+		if first {
+			first = false
+			continue
+		}
+		if rec, ok := ParseStreamLine(scanner.Bytes()); ok {
+			tail.resolve(rec)
+			records = append(records, rec)
+		}
+	}
+	return records, scanner.Err()
+}
+
+// StreamState summarizes the latest agent invocation of a log
+type StreamState struct {
+	SessionID string        // agent session to resume
+	Started   bool          // any record has been written
+	Busy      bool          // the latest invocation has not produced a result
+	Result    *StreamRecord // result of the latest invocation, if finished
+}
+
+// streamStateOf folds records (oldest first) into the latest invocation
+func streamStateOf(records []*StreamRecord) StreamState {
+	var st StreamState
+	for _, rec := range records {
+		if rec.Type == "text" {
+			continue
+		}
+		st.Started = true
+		if rec.SessionID != "" {
+			st.SessionID = rec.SessionID
+		}
+		if rec.IsTerminal() {
+			st.Busy = false
+			st.Result = rec
+		} else if rec.Type == "system" && rec.Subtype == "init" {
+			st.Busy = true
+			st.Result = nil
+		} else if st.Result == nil {
+			st.Busy = true
+		}
+	}
+	return st
+}
+
+// ReadStreamState returns the state of the latest invocation in a log
+func ReadStreamState(path string) (StreamState, error) {
+	records, err := readStreamWindow(path)
+	if err != nil {
+		return StreamState{}, err
+	}
+	return streamStateOf(records), nil
+}
+
+// streamStatus maps the latest invocation to a run status. A successful
+// result leaves the agent waiting for the next instruction, like the input
+// prompt of an interactive run.
+func streamStatus(st StreamState, detector StatusDetector) model.Status {
+	if !st.Started {
+		return ""
+	}
+	if st.Busy || st.Result == nil {
+		return model.StatusRunning
+	}
+	res := st.Result
+	switch {
+	case res.IsError && detector.IsAPILimited(res.Text):
+		return model.StatusBlockedAPI
+	case res.Subtype == "success" && !res.IsError:
+		return model.StatusBlocked
+	case res.Subtype == "error_max_turns":
+		return model.StatusBlocked
+	default:
+		return model.StatusFailed
+	}
+}
+
+// AttrStreamOffset records on stream events how far into the log they were
+// read, so a restarted daemon does not record them twice
+const AttrStreamOffset = "offset"
+
+// StreamEvents converts a record into run events
+func StreamEvents(rec *StreamRecord) []*model.Event {
+	var events []*model.Event
+	switch rec.Type {
+	case "system":
+		if rec.Subtype == "init" && rec.SessionID != "" {
+			attrs := map[string]string{"id": rec.SessionID}
+			if rec.Model != "" {
+				attrs["model"] = rec.Model
+			}
+			events = append(events, model.NewArtifactEvent("agent_session", attrs))
+		}
+	case "assistant":
+		if text := eventText(rec.Text, 200); text != "" {
+			events = append(events, model.NewEvent(model.EventTypeMessage, "assistant", map[string]string{
+				"text": text,
+			}))
+		}
+		for _, call := range rec.ToolCalls {
+			attrs := map[string]string{"id": call.ID}
+			if input := eventText(call.Input, 120); input != "" {
+				attrs["input"] = input
+			}
+			events = append(events, model.NewEvent(model.EventTypeTool, eventName(call.Name), attrs))
+		}
+	case "user":
+		for _, res := range rec.Results {
+			if !res.IsError {
+				continue
+			}
+			events = append(events, model.NewEvent(model.EventTypeTool, eventName(res.Name), map[string]string{
+				"id":    res.ID,
+				"error": eventText(res.Output, 200),
+			}))
+		}
+	case "result":
+		attrs := map[string]string{
+			"subtype":     rec.Subtype,
+			"turns":       strconv.Itoa(rec.NumTurns),
+			"duration_ms": strconv.FormatInt(rec.DurationMS, 10),
+			"cost":        strconv.FormatFloat(rec.CostUSD, 'f', 4, 64),
+		}
+		if rec.IsError {
+			attrs["is_error"] = "true"
+		}
+		events = append(events, model.NewArtifactEvent("result", attrs))
+		if rec.IsError || rec.Subtype != "success" {
+			msg := eventText(rec.Text, 200)
+			if msg == "" {
+				msg = rec.Subtype
+			}
+			events = append(events, model.NewErrorArtifactEvent(msg))
+		}
+	}
+	for _, e := range events {
+		e.Attrs[AttrStreamOffset] = strconv.FormatInt(rec.Offset, 10)
+	}
+	return events
+}
+
+// StreamOffsetOf returns how far into the stream log a run's events reach
+func StreamOffsetOf(run *model.Run) int64 {
+	var offset int64
+	for _, e := range run.Events {
+		if v, ok := e.Attrs[AttrStreamOffset]; ok {
+			if n, err := strconv.ParseInt(v, 10, 64); err == nil && n > offset {
+				offset = n
+			}
+		}
+	}
+	return offset
+}
+
+// eventText squeezes free text into one event attribute value
+func eventText(s string, limit int) string {
+	s = strings.Join(strings.Fields(s), " ")
+	s = strings.NewReplacer(`"`, "'", "|", "/").Replace(s)
+	if r := []rune(s); len(r) > limit {
+		s = string(r[:limit-1]) + "…"
+	}
+	return s
+}
+
+// eventName makes a tool name usable as an event name (no spaces)
+func eventName(name string) string {
+	if name == "" {
+		return "tool"
+	}
+	return strings.Join(strings.Fields(name), "_")
+}
+
+// streamUsage totals a log's usage per model. A finished invocation reports
+// exact per-model usage and cost; a running one is summed from its
+// assistant messages and priced from list prices.
+func streamUsage(path string) ([]model.Usage, error) {
+	records, err := ReadStreamLog(path)
+	if err != nil || len(records) == 0 {
+		return nil, err
+	}
+
+	totals := make(usageTotals)
+	messages := make(map[string]model.Usage) // current invocation
+	flush := func() {
+		for _, u := range messages {
+			u.Cost = EstimateCost(u)
+			totals.add(u)
+		}
+		messages = make(map[string]model.Usage)
+	}
+	for _, rec := range records {
+		switch {
+		case rec.Usage != nil:
+			messages[rec.MessageID] = *rec.Usage
+		case rec.IsTerminal() && len(rec.ModelUsage) > 0:
+			messages = make(map[string]model.Usage)
+			for _, u := range rec.ModelUsage {
+				totals.add(u)
+			}
+		case rec.IsTerminal():
+			flush()
+		}
+	}
+	flush()
+	return totals.list(), nil
+}
+
+// StreamLogPath returns where the stream log of a run is kept
+func StreamLogPath(orchDir, issueID, runID string) string {
+	return filepath.Join(orchDir, "streams", issueID, runID+".ndjson")
+}
+
+// StreamStderrPath returns where the stderr of a structured-output agent is
+// kept, next to its stream log
+func StreamStderrPath(logPath string) string {
+	return logPath + ".stderr"
+}
+
+// StreamPipeline wraps a structured-output agent command so its output is
+// appended to logPath and rendered in the pane by `orch stream-view`.
+// stderr goes to its own file so the log stays NDJSON.
+func StreamPipeline(agentCmd, logPath string) string {
+	return fmt.Sprintf("%s 2>>%s | tee -a %s | %s stream-view -",
+		agentCmd, singleQuote(StreamStderrPath(logPath)), singleQuote(logPath), singleQuote(orchExecutable()))
+}
+
+func orchExecutable() string {
+	if exe, err := os.Executable(); err == nil {
+		return exe
+	}
+	return "orch"
+}
+
+// SupportsStream reports whether an agent has a structured-output mode
+func SupportsStream(agentType AgentType) bool {
+	adapter, err := GetAdapter(agentType)
+	if err != nil {
+		return false
+	}
+	s, ok := adapter.(interface{ SupportsStream() bool })
+	return ok && s.SupportsStream()
+}
@@ -0,0 +1,96 @@
+package agent
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/s22625/orch/internal/model"
+	"github.com/s22625/orch/internal/terminal"
+)
+
+// StreamManager manages structured-output runs. Status comes from the
+// result records of the stream log instead of the pane; the terminal
+// session only hosts the agent process and the viewer.
+type StreamManager struct {
+	Terminal    terminal.Backend // nil is tmux
+	SessionName string
+	LogPath     string
+	Detector    StatusDetector // classifies result errors (API limits)
+}
+
+// captureLines is how much rendered log CaptureOutput returns, matching the
+// pane capture of tmux runs
+const captureLines = 100
+
+func (m *StreamManager) IsAlive(run *model.Run) bool {
+	return orDefault(m.Terminal).HasSession(m.SessionName)
+}
+
+// CaptureOutput renders the end of the log
+func (m *StreamManager) CaptureOutput(run *model.Run) (string, error) {
+	records, err := readStreamWindow(m.LogPath)
+	if err != nil {
+		return "", err
+	}
+	var sb strings.Builder
+	RenderStream(&sb, records)
+	lines := strings.Split(strings.TrimRight(sb.String(), "\n"), "\n")
+	if len(lines) > captureLines {
+		lines = lines[len(lines)-captureLines:]
+	}
+	return strings.Join(lines, "\n"), nil
+}
+
+// DetectPrompt is always false: a finished invocation is reported by its
+// result record, not by a prompt
+func (m *StreamManager) DetectPrompt(output string) bool {
+	return false
+}
+
+func (m *StreamManager) GetStatus(run *model.Run, output string, state *RunState, outputChanged, hasPrompt bool) model.Status {
+	st, err := ReadStreamState(m.LogPath)
+	if err != nil {
+		return ""
+	}
+	detector := m.Detector
+	if detector == nil {
+		detector = genericDetector
+	}
+	return streamStatus(st, detector)
+}
+
+// SendMessage resumes the agent session with the message as the next
+// non-interactive invocation. It fails while an invocation is running.
+func (m *StreamManager) SendMessage(ctx context.Context, run *model.Run, message string, opts *SendOptions) error {
+	term := orDefault(m.Terminal)
+	if !term.HasSession(m.SessionName) {
+		return &SessionNotFoundError{SessionName: m.SessionName}
+	}
+	st, err := ReadStreamState(m.LogPath)
+	if err != nil {
+		return err
+	}
+	if st.Busy {
+		return fmt.Errorf("agent is still working on the previous message")
+	}
+
+	adapter, err := GetAdapter(AgentType(run.Agent))
+	if err != nil {
+		return err
+	}
+	cmd, err := adapter.LaunchCommand(&LaunchConfig{
+		Type:        AgentType(run.Agent),
+		WorkDir:     run.WorktreePath,
+		Prompt:      message,
+		Resume:      st.SessionID != "",
+		SessionName: st.SessionID,
+		StreamLog:   m.LogPath,
+	})
+	if err != nil {
+		return err
+	}
+	return term.SendKeys(m.SessionName, cmd, true)
+}
+
+var _ AgentManager = (*StreamManager)(nil)
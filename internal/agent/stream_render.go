@@ -0,0 +1,90 @@
+package agent
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"time"
+)
+
+// toolResultLines is how many lines of a tool result the viewer shows
+const toolResultLines = 4
+
+// RenderStreamRecord writes a record the way an interactive session would
+// show it
+func RenderStreamRecord(w io.Writer, rec *StreamRecord) {
+	switch rec.Type {
+	case "text":
+		fmt.Fprintln(w, rec.Text)
+	case "system":
+		if rec.Subtype == "init" {
+			fmt.Fprintf(w, "● session %s", rec.SessionID)
+			if rec.Model != "" {
+				fmt.Fprintf(w, " (%s)", rec.Model)
+			}
+			fmt.Fprintln(w)
+		}
+	case "assistant":
+		if text := strings.TrimSpace(rec.Text); text != "" {
+			fmt.Fprintln(w)
+			writeIndented(w, "⏺ ", "  ", text)
+		}
+		for _, call := range rec.ToolCalls {
+			fmt.Fprintln(w)
+			fmt.Fprintf(w, "⏺ %s(%s)\n", call.Name, firstLine(call.Input))
+		}
+	case "user":
+		for _, res := range rec.Results {
+			out := strings.TrimRight(res.Output, "\n")
+			if res.IsError {
+				out = "Error: " + out
+			}
+			lines := strings.Split(out, "\n")
+			if len(lines) > toolResultLines {
+				more := len(lines) - toolResultLines
+				lines = append(lines[:toolResultLines], fmt.Sprintf("… +%d lines", more))
+			}
+			writeIndented(w, "  ⎿  ", "     ", strings.Join(lines, "\n"))
+		}
+	case "result":
+		fmt.Fprintln(w)
+		summary := fmt.Sprintf("%d turns, %s, %s", rec.NumTurns,
+			(time.Duration(rec.DurationMS) * time.Millisecond).Round(time.Second), formatUSD(rec.CostUSD))
+		if rec.IsError || rec.Subtype != "success" {
+			fmt.Fprintf(w, "✗ %s (%s)\n", rec.Subtype, summary)
+			if text := strings.TrimSpace(rec.Text); text != "" {
+				writeIndented(w, "  ", "  ", text)
+			}
+		} else {
+			fmt.Fprintf(w, "✓ done (%s)\n", summary)
+		}
+	}
+}
+
+// RenderStream renders every record of a log
+func RenderStream(w io.Writer, records []*StreamRecord) {
+	for _, rec := range records {
+		RenderStreamRecord(w, rec)
+	}
+}
+
+func writeIndented(w io.Writer, first, rest, text string) {
+	for i, line := range strings.Split(text, "\n") {
+		prefix := rest
+		if i == 0 {
+			prefix = first
+		}
+		fmt.Fprintln(w, prefix+line)
+	}
+}
+
+func firstLine(s string) string {
+	if i := strings.IndexByte(s, '\n'); i >= 0 {
+		return s[:i] + " …"
+	}
+	return s
+}
+
+func formatUSD(cost float64) string {
+	return fmt.Sprintf("$%.2f", cost)
+}
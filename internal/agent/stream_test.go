@@ -0,0 +1,313 @@
+package agent
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/s22625/orch/internal/model"
+)
+
+func copyStreamLog(t *testing.T, name string) string {
+	t.Helper()
+	data, err := os.ReadFile(filepath.Join("testdata", "stream", name))
+	if err != nil {
+		t.Fatal(err)
+	}
+	path := filepath.Join(t.TempDir(), "run.ndjson")
+	if err := os.WriteFile(path, data, 0644); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestParseStreamLine(t *testing.T) {
+	if _, ok := ParseStreamLine([]byte("   ")); ok {
+		t.Error("blank line should be skipped")
+	}
+
+	rec, ok := ParseStreamLine([]byte("Error: unknown option '--bogus'"))
+	if !ok || rec.Type != "text" || rec.Text != "Error: unknown option '--bogus'" {
+		t.Errorf("non-JSON line = %+v", rec)
+	}
+
+	rec, _ = ParseStreamLine([]byte(`{"type":"assistant","message":{"id":"m1","model":"claude-sonnet-4-5","content":[{"type":"text","text":"hi"},{"type":"tool_use","id":"t1","name":"Bash","input":{"command":"ls -la"}}],"usage":{"input_tokens":3,"output_tokens":5}},"session_id":"s1"}`))
+	if rec.Type != "assistant" || rec.Text != "hi" || rec.SessionID != "s1" {
+		t.Errorf("assistant = %+v", rec)
+	}
+	if len(rec.ToolCalls) != 1 || rec.ToolCalls[0].Name != "Bash" || rec.ToolCalls[0].Input != "ls -la" {
+		t.Errorf("tool calls = %+v", rec.ToolCalls)
+	}
+	if rec.Usage == nil || rec.Usage.InputTokens != 3 || rec.Usage.OutputTokens != 5 || rec.MessageID != "m1" {
+		t.Errorf("usage = %+v (message %q)", rec.Usage, rec.MessageID)
+	}
+}
+
+func TestStreamTailPartialLines(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "run.ndjson")
+	tail := NewStreamTail(path)
+
+	// Missing log yields nothing
+	if records, err := tail.Next(); err != nil || len(records) != 0 {
+		t.Fatalf("missing log: %v, %v", records, err)
+	}
+
+	first := `{"type":"system","subtype":"init","session_id":"s1"}` + "\n"
+	second := `{"type":"assistant","message":{"id":"m1","content":[{"type":"tool_use","id":"t1","name":"Bash","input":{"command":"go test"}}]}}` + "\n"
+	third := `{"type":"user","message":{"content":[{"type":"tool_result","tool_use_id":"t1","content":"FAIL","is_error":true}]}}` + "\n"
+
+	appendFile(t, path, first+second[:20])
+	records, err := tail.Next()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(records) != 1 || records[0].Subtype != "init" {
+		t.Fatalf("first read = %+v", records)
+	}
+	if records[0].Offset != int64(len(first)) {
+		t.Errorf("offset = %d, want %d", records[0].Offset, len(first))
+	}
+
+	appendFile(t, path, second[20:]+third)
+	records, err = tail.Next()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(records) != 2 {
+		t.Fatalf("second read = %d records", len(records))
+	}
+	if records[1].Offset != int64(len(first+second+third)) {
+		t.Errorf("offset = %d, want %d", records[1].Offset, len(first+second+third))
+	}
+	if res := records[1].Results; len(res) != 1 || res[0].Name != "Bash" || !res[0].IsError {
+		t.Errorf("tool result = %+v", res)
+	}
+}
+
+func appendFile(t *testing.T, path, data string) {
+	t.Helper()
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0644)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	if _, err := f.WriteString(data); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestStreamEvents(t *testing.T) {
+	records, err := ReadStreamLog(copyStreamLog(t, "claude-success.ndjson"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var events []*model.Event
+	for _, rec := range records {
+		events = append(events, StreamEvents(rec)...)
+	}
+
+	var got []string
+	for _, e := range events {
+		got = append(got, string(e.Type)+"|"+e.Name)
+		if e.Attrs[AttrStreamOffset] == "" {
+			t.Errorf("%s|%s has no offset", e.Type, e.Name)
+		}
+	}
+	want := []string{
+		"artifact|agent_session",
+		"message|assistant",
+		"tool|Read",
+		"tool|Bash",
+		"tool|Bash", // failed result
+		"message|assistant",
+		"artifact|result",
+	}
+	if strings.Join(got, ",") != strings.Join(want, ",") {
+		t.Errorf("events =\n%v\nwant\n%v", got, want)
+	}
+
+	if e := events[0]; e.Attrs["id"] != "5b1e2f44-8d0a-4c3e-9a57-6f0c2d1e9b10" || e.Attrs["model"] != "claude-sonnet-4-5-20250929" {
+		t.Errorf("agent_session attrs = %v", e.Attrs)
+	}
+	if e := events[3]; !strings.HasPrefix(e.Attrs["input"], "go test ./internal/store/...") {
+		t.Errorf("tool input = %q", e.Attrs["input"])
+	}
+	if e := events[4]; !strings.Contains(e.Attrs["error"], "--- FAIL: TestWatch") || strings.Contains(e.Attrs["error"], "\n") {
+		t.Errorf("tool error = %q", e.Attrs["error"])
+	}
+	if e := events[6]; e.Attrs["subtype"] != "success" || e.Attrs["turns"] != "7" || e.Attrs["cost"] != "0.0871" {
+		t.Errorf("result attrs = %v", e.Attrs)
+	}
+
+	// Events can be serialized and parsed back
+	for _, e := range events {
+		line := e.String()
+		parsed, err := model.ParseEvent(line)
+		if err != nil {
+			t.Errorf("ParseEvent(%q): %v", line, err)
+			continue
+		}
+		if parsed.Attrs[AttrStreamOffset] != e.Attrs[AttrStreamOffset] {
+			t.Errorf("offset lost in %q", line)
+		}
+	}
+
+	run := &model.Run{Events: events}
+	if got, want := StreamOffsetOf(run), records[len(records)-1].Offset; got != want {
+		t.Errorf("StreamOffsetOf = %d, want %d", got, want)
+	}
+}
+
+func TestStreamStatus(t *testing.T) {
+	claude := mustGetAdapter(t, AgentClaude).Detector()
+
+	tests := []struct {
+		name string
+		log  string
+		want model.Status
+	}{
+		{"empty", "", ""},
+		{"stderr only", "zsh: command not found: claude\n", ""},
+		{"busy", `{"type":"system","subtype":"init","session_id":"s1"}` + "\n" + `{"type":"assistant","message":{"content":[{"type":"text","text":"working"}]}}` + "\n", model.StatusRunning},
+		{"success", `{"type":"system","subtype":"init","session_id":"s1"}` + "\n" + `{"type":"result","subtype":"success","session_id":"s1"}` + "\n", model.StatusBlocked},
+		{"max turns", `{"type":"result","subtype":"error_max_turns","is_error":true,"session_id":"s1"}` + "\n", model.StatusBlocked},
+		{"execution error", `{"type":"result","subtype":"error_during_execution","is_error":true,"session_id":"s1"}` + "\n", model.StatusFailed},
+		{"resumed after success", `{"type":"result","subtype":"success","session_id":"s1"}` + "\n" + `{"type":"system","subtype":"init","session_id":"s1"}` + "\n", model.StatusRunning},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "run.ndjson")
+			if err := os.WriteFile(path, []byte(tt.log), 0644); err != nil {
+				t.Fatal(err)
+			}
+			st, err := ReadStreamState(path)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := streamStatus(st, claude); got != tt.want {
+				t.Errorf("status = %q, want %q", got, tt.want)
+			}
+		})
+	}
+
+	st, err := ReadStreamState(copyStreamLog(t, "claude-rate-limit.ndjson"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := streamStatus(st, claude); got != model.StatusBlockedAPI {
+		t.Errorf("rate limited status = %q, want %q", got, model.StatusBlockedAPI)
+	}
+	if st.SessionID != "9c0d7a61-2b4e-4f1a-8e3d-0a5b6c7d8e9f" {
+		t.Errorf("session = %q", st.SessionID)
+	}
+}
+
+func TestStreamUsage(t *testing.T) {
+	// Finished: exact per-model usage from the result record
+	usage, err := streamUsage(copyStreamLog(t, "claude-success.ndjson"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(usage) != 1 {
+		t.Fatalf("usage = %+v", usage)
+	}
+	u := usage[0]
+	if u.Model != "claude-sonnet-4-5-20250929" || u.InputTokens != 18 || u.OutputTokens != 429 ||
+		u.CacheReadTokens != 47950 || u.CacheWriteTokens != 6850 || !approxEqual(u.Cost, 0.0871) {
+		t.Errorf("exact usage = %+v", u)
+	}
+
+	// Running: summed per message (repeated message usage counted once) and estimated
+	path := filepath.Join(t.TempDir(), "run.ndjson")
+	log := `{"type":"system","subtype":"init","session_id":"s1"}
+{"type":"assistant","message":{"id":"m1","model":"claude-sonnet-4-5","content":[{"type":"text","text":"a"}],"usage":{"input_tokens":1000,"output_tokens":10}}}
+{"type":"assistant","message":{"id":"m1","model":"claude-sonnet-4-5","content":[{"type":"tool_use","id":"t1","name":"Bash","input":{}}],"usage":{"input_tokens":1000,"output_tokens":100}}}
+{"type":"assistant","message":{"id":"m2","model":"claude-sonnet-4-5","content":[{"type":"text","text":"b"}],"usage":{"input_tokens":500,"output_tokens":50}}}
+`
+	if err := os.WriteFile(path, []byte(log), 0644); err != nil {
+		t.Fatal(err)
+	}
+	usage, err = streamUsage(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(usage) != 1 || usage[0].InputTokens != 1500 || usage[0].OutputTokens != 150 {
+		t.Fatalf("estimated usage = %+v", usage)
+	}
+	if want := EstimateCost(usage[0]); !approxEqual(usage[0].Cost, want) || want == 0 {
+		t.Errorf("estimated cost = %v, want %v", usage[0].Cost, want)
+	}
+}
+
+func TestRenderStream(t *testing.T) {
+	records, err := ReadStreamLog(copyStreamLog(t, "claude-success.ndjson"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var buf bytes.Buffer
+	RenderStream(&buf, records)
+	out := buf.String()
+
+	for _, want := range []string{
+		"● session 5b1e2f44-8d0a-4c3e-9a57-6f0c2d1e9b10 (claude-sonnet-4-5-20250929)",
+		"⏺ I'll start by reading the prompt file.",
+		"⏺ Bash(go test ./internal/store/... -run TestWatch -count=5)",
+		"  ⎿  Error: --- FAIL: TestWatch (0.21s)",
+		"✓ done (7 turns, 1m34s, $0.09)",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("rendered output missing %q:\n%s", want, out)
+		}
+	}
+
+	records, err = ReadStreamLog(copyStreamLog(t, "claude-rate-limit.ndjson"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	buf.Reset()
+	RenderStream(&buf, records)
+	if !strings.Contains(buf.String(), "✗ success (1 turns, 1s, $0.00)") {
+		t.Errorf("rendered error result:\n%s", buf.String())
+	}
+}
+
+func TestStreamLaunchCommand(t *testing.T) {
+	cmd, err := mustGetAdapter(t, AgentClaude).LaunchCommand(&LaunchConfig{
+		Type:      AgentClaude,
+		Prompt:    "fix it",
+		StreamLog: "/tmp/orch streams/run.ndjson",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(cmd, "claude -p --output-format stream-json --verbose") {
+		t.Errorf("command = %q", cmd)
+	}
+	if !strings.Contains(cmd, "\"fix it\" 2>>'/tmp/orch streams/run.ndjson.stderr' | tee -a '/tmp/orch streams/run.ndjson' | ") || !strings.HasSuffix(cmd, " stream-view -") {
+		t.Errorf("pipeline = %q", cmd)
+	}
+
+	cmd, err = mustGetAdapter(t, AgentClaude).LaunchCommand(&LaunchConfig{
+		Type:        AgentClaude,
+		Prompt:      "continue",
+		Resume:      true,
+		SessionName: "s1",
+		StreamLog:   "/tmp/run.ndjson",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(cmd, "--resume s1 \"continue\"") {
+		t.Errorf("resume command = %q", cmd)
+	}
+
+	if !SupportsStream(AgentClaude) || SupportsStream(AgentCodex) {
+		t.Error("only claude supports stream mode")
+	}
+	if _, err := mustGetAdapter(t, AgentCodex).LaunchCommand(&LaunchConfig{Type: AgentCodex, StreamLog: "/tmp/run.ndjson"}); err == nil {
+		t.Error("codex should reject stream mode")
+	}
+}
@@ -0,0 +1,105 @@
+package agent
+
+import (
+	"context"
+	"sort"
+	"strings"
+
+	"github.com/s22625/orch/internal/model"
+)
+
+// UsageReporter is implemented by adapters that can report the tokens and
+// cost a run has consumed so far. Each entry holds the cumulative totals of
+// one model; the daemon records them as usage events.
+type UsageReporter interface {
+	Usage(ctx context.Context, run *model.Run) ([]model.Usage, error)
+}
+
+// ReadUsage returns the usage of a run from its stream log or its agent's
+// adapter, or nil if the agent does not report usage
+func ReadUsage(ctx context.Context, run *model.Run) ([]model.Usage, error) {
+	if run.StreamLog != "" {
+		return streamUsage(run.StreamLog)
+	}
+	agentType, err := ParseAgentType(run.Agent)
+	if err != nil {
+		return nil, nil
+	}
+	adapter, err := GetAdapter(agentType)
+	if err != nil {
+		return nil, nil
+	}
+	reporter, ok := adapter.(UsageReporter)
+	if !ok {
+		return nil, nil
+	}
+	return reporter.Usage(ctx, run)
+}
+
+// modelPrice is a per-million-token price in USD
+type modelPrice struct {
+	input      float64
+	output     float64
+	cacheRead  float64
+	cacheWrite float64
+}
+
+// modelPrices maps model name prefixes to list prices. More specific
+// prefixes must come first.
+var modelPrices = []struct {
+	prefix string
+	price  modelPrice
+}{
+	{"claude-opus-4-5", modelPrice{5, 25, 0.5, 6.25}},
+	{"claude-opus-4", modelPrice{15, 75, 1.5, 18.75}},
+	{"claude-sonnet-4", modelPrice{3, 15, 0.3, 3.75}},
+	{"claude-3-7-sonnet", modelPrice{3, 15, 0.3, 3.75}},
+	{"claude-haiku-4", modelPrice{1, 5, 0.1, 1.25}},
+	{"claude-3-5-haiku", modelPrice{0.8, 4, 0.08, 1}},
+	{"gpt-5-mini", modelPrice{0.25, 2, 0.025, 0}},
+	{"gpt-5-nano", modelPrice{0.05, 0.4, 0.005, 0}},
+	{"gpt-5", modelPrice{1.25, 10, 0.125, 0}},
+	{"o4-mini", modelPrice{1.1, 4.4, 0.275, 0}},
+	{"o3", modelPrice{2, 8, 0.5, 0}},
+	{"gemini-2.5-pro", modelPrice{1.25, 10, 0.31, 0}},
+	{"gemini-2.5-flash", modelPrice{0.3, 2.5, 0.075, 0}},
+}
+
+// EstimateCost prices a usage from the list price of its model. Unknown
+// models cost 0. A "provider/" prefix on the model name is ignored.
+func EstimateCost(u model.Usage) float64 {
+	name := u.Model
+	if i := strings.LastIndex(name, "/"); i >= 0 {
+		name = name[i+1:]
+	}
+	for _, p := range modelPrices {
+		if strings.HasPrefix(name, p.prefix) {
+			return (float64(u.InputTokens)*p.price.input +
+				float64(u.OutputTokens)*p.price.output +
+				float64(u.CacheReadTokens)*p.price.cacheRead +
+				float64(u.CacheWriteTokens)*p.price.cacheWrite) / 1e6
+		}
+	}
+	return 0
+}
+
+// usageTotals accumulates usage per model
+type usageTotals map[string]model.Usage
+
+func (t usageTotals) add(u model.Usage) {
+	t[u.Model] = t[u.Model].Add(u)
+}
+
+// list returns the totals sorted by model, estimating cost where the
+// agent did not report one
+func (t usageTotals) list() []model.Usage {
+	usages := make([]model.Usage, 0, len(t))
+	for _, u := range t {
+		if u.Cost == 0 {
+			u.Cost = EstimateCost(u)
+		}
+		usages = append(usages, u)
+	}
+	sort.Slice(usages, func(i, j int) bool { return usages[i].Model < usages[j].Model })
+	return usages
+}
@@ -0,0 +1,215 @@
+package agent
+
+import (
+	"context"
+	"math"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/s22625/orch/internal/model"
+)
+
+func approxEqual(a, b float64) bool {
+	return math.Abs(a-b) < 1e-9
+}
+
+func TestEstimateCost(t *testing.T) {
+	tests := []struct {
+		name  string
+		usage model.Usage
+		want  float64
+	}{
+		{
+			name:  "opus 4.5 is not priced as opus 4",
+			usage: model.Usage{Model: "claude-opus-4-5-20251101", InputTokens: 1_000_000, OutputTokens: 1_000_000},
+			want:  30,
+		},
+		{
+			name:  "cache tokens",
+			usage: model.Usage{Model: "claude-sonnet-4-5", CacheReadTokens: 1_000_000, CacheWriteTokens: 1_000_000},
+			want:  4.05,
+		},
+		{
+			name:  "provider prefix",
+			usage: model.Usage{Model: "openai/gpt-5-codex", InputTokens: 2_000_000},
+			want:  2.5,
+		},
+		{
+			name:  "unknown model",
+			usage: model.Usage{Model: "mystery-1", InputTokens: 1_000_000},
+			want:  0,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := EstimateCost(tt.usage); !approxEqual(got, tt.want) {
+				t.Errorf("EstimateCost() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func writeLines(t *testing.T, path string, lines ...string) {
+	t.Helper()
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte(strings.Join(lines, "\n")+"\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestClaudeProjectDir(t *testing.T) {
+	got := claudeProjectDir("/home/u/.claude", "/home/u/.orch/worktrees/a_b")
+	want := "/home/u/.claude/projects/-home-u--orch-worktrees-a-b"
+	if got != want {
+		t.Errorf("claudeProjectDir() = %q, want %q", got, want)
+	}
+}
+
+func TestReadClaudeUsage(t *testing.T) {
+	dir := t.TempDir()
+	writeLines(t, filepath.Join(dir, "session.jsonl"),
+		`{"type":"user","message":{"role":"user","content":"hi"}}`,
+		// One response streamed as two entries repeating its usage
+		`{"type":"assistant","requestId":"req1","message":{"id":"msg1","model":"claude-sonnet-4-5","usage":{"input_tokens":10,"output_tokens":5,"cache_creation_input_tokens":100,"cache_read_input_tokens":1000}}}`,
+		`{"type":"assistant","requestId":"req1","message":{"id":"msg1","model":"claude-sonnet-4-5","usage":{"input_tokens":10,"output_tokens":7,"cache_creation_input_tokens":100,"cache_read_input_tokens":1000}}}`,
+		`{"type":"assistant","requestId":"req2","message":{"id":"msg2","model":"claude-haiku-4-5","usage":{"input_tokens":20,"output_tokens":3}}}`,
+		`{"type":"assistant","message":{"id":"msg3","model":"<synthetic>","usage":{"input_tokens":0,"output_tokens":0}}}`,
+		`not json`,
+	)
+
+	usages, err := readClaudeUsage(dir, time.Time{})
+	if err != nil {
+		t.Fatalf("readClaudeUsage() error = %v", err)
+	}
+	if len(usages) != 2 {
+		t.Fatalf("got %d models, want 2: %+v", len(usages), usages)
+	}
+
+	haiku, sonnet := usages[0], usages[1]
+	if sonnet.Model != "claude-sonnet-4-5" || sonnet.InputTokens != 10 || sonnet.OutputTokens != 7 ||
+		sonnet.CacheWriteTokens != 100 || sonnet.CacheReadTokens != 1000 {
+		t.Errorf("sonnet usage = %+v", sonnet)
+	}
+	if sonnet.Cost == 0 {
+		t.Error("expected estimated cost for sonnet")
+	}
+	if haiku.Model != "claude-haiku-4-5" || haiku.InputTokens != 20 || haiku.OutputTokens != 3 {
+		t.Errorf("haiku usage = %+v", haiku)
+	}
+}
+
+func TestClaudeUsageContinuedRun(t *testing.T) {
+	configDir := t.TempDir()
+	t.Setenv("CLAUDE_CONFIG_DIR", configDir)
+	worktree := "/tmp/worktrees/abc123_claude_20250101-090000"
+	dir := claudeProjectDir(configDir, worktree)
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	// The first run's session, then the continued run's session in the
+	// same worktree
+	first := time.Date(2025, 1, 1, 9, 0, 0, 0, time.UTC)
+	second := first.Add(2 * time.Hour)
+	writeLines(t, filepath.Join(dir, "first.jsonl"),
+		`{"type":"assistant","timestamp":"2025-01-01T09:05:00Z","requestId":"req1","message":{"id":"msg1","model":"claude-sonnet-4-5","usage":{"input_tokens":100,"output_tokens":50}}}`,
+	)
+	writeLines(t, filepath.Join(dir, "second.jsonl"),
+		`{"type":"assistant","timestamp":"2025-01-01T11:05:00Z","requestId":"req2","message":{"id":"msg2","model":"claude-sonnet-4-5","usage":{"input_tokens":10,"output_tokens":5}}}`,
+	)
+
+	firstRun := &model.Run{WorktreePath: worktree, StartedAt: first}
+	continued := &model.Run{WorktreePath: worktree, StartedAt: second}
+
+	usages, err := claudeUsage(context.Background(), continued)
+	if err != nil {
+		t.Fatalf("claudeUsage() error = %v", err)
+	}
+	if len(usages) != 1 || usages[0].InputTokens != 10 || usages[0].OutputTokens != 5 {
+		t.Errorf("continued run usage = %+v, want only its own turn", usages)
+	}
+
+	usages, err = claudeUsage(context.Background(), firstRun)
+	if err != nil {
+		t.Fatalf("claudeUsage() error = %v", err)
+	}
+	if len(usages) != 1 || usages[0].InputTokens != 110 {
+		t.Errorf("first run usage = %+v", usages)
+	}
+}
+
+func TestReadClaudeUsageMissingDir(t *testing.T) {
+	usages, err := readClaudeUsage(filepath.Join(t.TempDir(), "missing"), time.Time{})
+	if err != nil || usages != nil {
+		t.Errorf("readClaudeUsage() = %v, %v; want nil, nil", usages, err)
+	}
+}
+
+func TestReadCodexUsage(t *testing.T) {
+	sessions := t.TempDir()
+	worktree := "/tmp/worktrees/orch-1"
+
+	writeLines(t, filepath.Join(sessions, "2025", "01", "02", "rollout-a.jsonl"),
+		`{"type":"session_meta","payload":{"id":"a","cwd":"/tmp/worktrees/orch-1"}}`,
+		`{"type":"turn_context","payload":{"cwd":"/tmp/worktrees/orch-1","model":"gpt-5-codex"}}`,
+		`{"type":"event_msg","payload":{"type":"token_count","info":null}}`,
+		`{"type":"event_msg","payload":{"type":"token_count","info":{"total_token_usage":{"input_tokens":1000,"cached_input_tokens":400,"output_tokens":50}}}}`,
+		`{"type":"event_msg","payload":{"type":"token_count","info":{"total_token_usage":{"input_tokens":3000,"cached_input_tokens":1000,"output_tokens":200}}}}`,
+	)
+	// Another worktree
+	writeLines(t, filepath.Join(sessions, "2025", "01", "02", "rollout-b.jsonl"),
+		`{"type":"session_meta","payload":{"id":"b","cwd":"/tmp/worktrees/other"}}`,
+		`{"type":"event_msg","payload":{"type":"token_count","info":{"total_token_usage":{"input_tokens":9999,"output_tokens":9999}}}}`,
+	)
+
+	usages, err := readCodexUsage(sessions, worktree, time.Time{})
+	if err != nil {
+		t.Fatalf("readCodexUsage() error = %v", err)
+	}
+	if len(usages) != 1 {
+		t.Fatalf("got %d models, want 1: %+v", len(usages), usages)
+	}
+	u := usages[0]
+	if u.Model != "gpt-5-codex" || u.InputTokens != 2000 || u.CacheReadTokens != 1000 || u.OutputTokens != 200 {
+		t.Errorf("usage = %+v", u)
+	}
+
+	// Logs older than the run are not read
+	usages, err = readCodexUsage(sessions, worktree, time.Now().Add(time.Hour))
+	if err != nil || len(usages) != 0 {
+		t.Errorf("readCodexUsage(future) = %+v, %v; want none", usages, err)
+	}
+}
+
+func TestOpenCodeMessagesUsage(t *testing.T) {
+	tokens := func(in, out, reasoning, read int64) *MessageTokens {
+		tk := &MessageTokens{Input: in, Output: out, Reasoning: reasoning}
+		tk.Cache.Read = read
+		return tk
+	}
+	messages := []Message{
+		{Info: MessageInfo{Role: "user"}},
+		{Info: MessageInfo{Role: "assistant", ProviderID: "anthropic", ModelID: "claude-opus-4-5", Cost: 0.5, Tokens: tokens(100, 10, 5, 1000)}},
+		{Info: MessageInfo{Role: "assistant", ProviderID: "anthropic", ModelID: "claude-opus-4-5", Cost: 0.25, Tokens: tokens(50, 10, 0, 0)}},
+		{Info: MessageInfo{Role: "assistant", ProviderID: "openai", ModelID: "gpt-5", Tokens: tokens(1_000_000, 0, 0, 0)}},
+	}
+
+	usages := openCodeMessagesUsage(messages)
+	if len(usages) != 2 {
+		t.Fatalf("got %d models, want 2: %+v", len(usages), usages)
+	}
+	opus, gpt := usages[0], usages[1]
+	if opus.Model != "anthropic/claude-opus-4-5" || opus.InputTokens != 150 || opus.OutputTokens != 25 ||
+		opus.CacheReadTokens != 1000 || !approxEqual(opus.Cost, 0.75) {
+		t.Errorf("opus usage = %+v", opus)
+	}
+	// opencode reported no cost; estimated from list price
+	if gpt.Model != "openai/gpt-5" || !approxEqual(gpt.Cost, 1.25) {
+		t.Errorf("gpt usage = %+v", gpt)
+	}
+}
@@ -7,7 +7,7 @@ import (
 
 	"github.com/s22625/orch/internal/agent"
 	"github.com/s22625/orch/internal/model"
-	"github.com/s22625/orch/internal/tmux"
+	"github.com/s22625/orch/internal/terminal"
 	"github.com/spf13/cobra"
 )
 
@@ -23,10 +23,11 @@ func newAttachCmd() *cobra.Command {
 
 	cmd := &cobra.Command{
 		Use:   "attach RUN_REF",
-		Short: "Attach to a run's tmux session",
-		Long: `Attach to the tmux session for a run.
+		Short: "Attach to a run's session",
+		Long: `Attach to the terminal session for a run (tmux, zellij, or the daemon-hosted pty).
 
-This allows manual interaction with the agent, including image paste support.`,
+This allows manual interaction with the agent, including image paste support.
+Detach from a pty session with Ctrl-].`,
 		Args: cobra.ExactArgs(1),
 		RunE: func(cmd *cobra.Command, args []string) error {
 			return runAttach(args[0], opts)
@@ -64,8 +65,15 @@ func runAttach(refStr string, opts *attachOptions) error {
 		sessionName = model.GenerateTmuxSession(run.IssueID, run.RunID)
 	}
 
+	term, err := runTerminal(run)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "%v\n", err)
+		os.Exit(ExitTmuxError)
+		return err
+	}
+
 	// Check if session exists, auto-create if missing
-	if !tmux.HasSession(sessionName) {
+	if !term.HasSession(sessionName) {
 		if run.WorktreePath == "" {
 			fmt.Fprintf(os.Stderr, "session not found and no worktree path: %s\n", sessionName)
 			os.Exit(ExitRunNotFound)
@@ -74,9 +82,9 @@ func runAttach(refStr string, opts *attachOptions) error {
 
 		// Auto-create the session in the run's worktree
 		fmt.Fprintf(os.Stderr, "session not found, creating: %s\n", sessionName)
-		err := tmux.NewSession(&tmux.SessionConfig{
-			SessionName: sessionName,
-			WorkDir:     run.WorktreePath,
+		err := term.NewSession(&terminal.SessionConfig{
+			Name:    sessionName,
+			WorkDir: run.WorktreePath,
 		})
 		if err != nil {
 			fmt.Fprintf(os.Stderr, "failed to create session: %v\n", err)
@@ -85,17 +93,12 @@ func runAttach(refStr string, opts *attachOptions) error {
 		}
 	}
 
-	// Attach to session - use switch-client if already inside tmux
-	if tmux.IsInsideTmux() {
-		if err := tmux.SwitchClient(sessionName); err != nil {
-			os.Exit(ExitTmuxError)
-			return err
-		}
-	} else {
-		if err := tmux.AttachSession(sessionName); err != nil {
-			os.Exit(ExitTmuxError)
-			return err
-		}
+	// Attach to session (tmux switches the client if already inside tmux;
+	// zellij refuses to nest; pty sessions detach with Ctrl-])
+	if err := term.Attach(sessionName); err != nil {
+		fmt.Fprintf(os.Stderr, "failed to attach: %v\n", err)
+		os.Exit(ExitTmuxError)
+		return err
 	}
 
 	return nil
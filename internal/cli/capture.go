@@ -6,7 +6,6 @@ import (
 	"os"
 
 	"github.com/s22625/orch/internal/model"
-	"github.com/s22625/orch/internal/tmux"
 	"github.com/spf13/cobra"
 )
 
@@ -71,15 +70,22 @@ func runCapture(refStr string, opts *captureOptions) error {
 		return err
 	}
 
-	// Get tmux session name
+	// Get session name
 	sessionName := run.TmuxSession
 	if sessionName == "" {
 		sessionName = model.GenerateTmuxSession(run.IssueID, run.RunID)
 	}
 
+	term, err := runTerminal(run)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "error: %v\n", err)
+		os.Exit(ExitTmuxError)
+		return err
+	}
+
 	// Check if session exists
-	if !tmux.HasSession(sessionName) {
-		err := fmt.Errorf("tmux session %q not found (run may not be active)", sessionName)
+	if !term.HasSession(sessionName) {
+		err := fmt.Errorf("%s session %q not found (run may not be active)", term.Name(), sessionName)
 		if globalOpts.JSON {
 			result := map[string]interface{}{
 				"ok":    false,
@@ -96,7 +102,7 @@ func runCapture(refStr string, opts *captureOptions) error {
 	}
 
 	// Capture the pane content
-	content, err := tmux.CapturePane(sessionName, opts.Lines)
+	content, err := term.Capture(sessionName, opts.Lines)
 	if err != nil {
 		if globalOpts.JSON {
 			result := map[string]interface{}{
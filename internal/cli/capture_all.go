@@ -8,7 +8,6 @@ import (
 
 	"github.com/s22625/orch/internal/model"
 	"github.com/s22625/orch/internal/store"
-	"github.com/s22625/orch/internal/tmux"
 	"github.com/spf13/cobra"
 )
 
@@ -16,8 +15,8 @@ type captureAllOptions struct {
 	Lines int
 }
 
-var captureAllHasSession = tmux.HasSession
-var captureAllCapturePane = tmux.CapturePane
+// captureAllTerminal returns the backend hosting a run's session
+var captureAllTerminal = runTerminal
 
 func newCaptureAllCmd() *cobra.Command {
 	opts := &captureAllOptions{}
@@ -127,12 +126,17 @@ func captureAllItemForRun(run *model.Run, lines int) captureAllItem {
 		Lines:       lines,
 	}
 
-	if !captureAllHasSession(sessionName) {
-		item.Error = fmt.Sprintf("tmux session %q not found (run may not be active)", sessionName)
+	term, err := captureAllTerminal(run)
+	if err != nil {
+		item.Error = err.Error()
+		return item
+	}
+	if !term.HasSession(sessionName) {
+		item.Error = fmt.Sprintf("%s session %q not found (run may not be active)", term.Name(), sessionName)
 		return item
 	}
 
-	content, err := captureAllCapturePane(sessionName, lines)
+	content, err := term.Capture(sessionName, lines)
 	if err != nil {
 		item.Error = fmt.Sprintf("failed to capture pane: %v", err)
 		return item
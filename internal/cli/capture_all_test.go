@@ -6,6 +6,7 @@ import (
 	"testing"
 
 	"github.com/s22625/orch/internal/model"
+	"github.com/s22625/orch/internal/terminal"
 )
 
 func TestNewCaptureAllCmd(t *testing.T) {
@@ -71,6 +72,9 @@ func TestRunCaptureAllJSON(t *testing.T) {
 	if err := st.AppendEvent(run1.Ref(), model.NewStatusEvent(model.StatusRunning)); err != nil {
 		t.Fatalf("status run1: %v", err)
 	}
+	if err := st.AppendEvent(run2.Ref(), model.NewStatusEvent(model.StatusRunning)); err != nil {
+		t.Fatalf("status run2: %v", err)
+	}
 	if err := st.AppendEvent(run2.Ref(), model.NewStatusEvent(model.StatusBlocked)); err != nil {
 		t.Fatalf("status run2: %v", err)
 	}
@@ -82,22 +86,8 @@ func TestRunCaptureAllJSON(t *testing.T) {
 		model.GenerateTmuxSession("issue-1", "run-1"): "run-1 output\n",
 	}
 
-	origHasSession := captureAllHasSession
-	origCapturePane := captureAllCapturePane
-	t.Cleanup(func() {
-		captureAllHasSession = origHasSession
-		captureAllCapturePane = origCapturePane
-	})
-
-	var capturedLines []int
-	captureAllHasSession = func(session string) bool {
-		_, ok := outputs[session]
-		return ok
-	}
-	captureAllCapturePane = func(session string, lines int) (string, error) {
-		capturedLines = append(capturedLines, lines)
-		return outputs[session], nil
-	}
+	term := &captureLinesTerminal{Fake: fakeTerminal(t, outputs)}
+	captureAllTerminal = func(*model.Run) (terminal.Backend, error) { return term, nil }
 
 	out := captureStdout(t, func() {
 		if err := runCaptureAll(&captureAllOptions{Lines: 5}); err != nil {
@@ -119,8 +109,8 @@ func TestRunCaptureAllJSON(t *testing.T) {
 	if len(got.Items) != 2 {
 		t.Fatalf("expected 2 items, got %d", len(got.Items))
 	}
-	if len(capturedLines) != 1 || capturedLines[0] != 5 {
-		t.Fatalf("unexpected lines captured: %v", capturedLines)
+	if len(term.lines) != 1 || term.lines[0] != 5 {
+		t.Fatalf("unexpected lines captured: %v", term.lines)
 	}
 
 	items := make(map[string]captureAllItem, len(got.Items))
@@ -196,6 +186,9 @@ func TestRunCaptureAllPlain(t *testing.T) {
 	if err := st.AppendEvent(run1.Ref(), model.NewStatusEvent(model.StatusRunning)); err != nil {
 		t.Fatalf("status run1: %v", err)
 	}
+	if err := st.AppendEvent(run2.Ref(), model.NewStatusEvent(model.StatusRunning)); err != nil {
+		t.Fatalf("status run2: %v", err)
+	}
 	if err := st.AppendEvent(run2.Ref(), model.NewStatusEvent(model.StatusBlocked)); err != nil {
 		t.Fatalf("status run2: %v", err)
 	}
@@ -204,20 +197,8 @@ func TestRunCaptureAllPlain(t *testing.T) {
 		model.GenerateTmuxSession("issue-1", "run-1"): "run-1 output\n",
 	}
 
-	origHasSession := captureAllHasSession
-	origCapturePane := captureAllCapturePane
-	t.Cleanup(func() {
-		captureAllHasSession = origHasSession
-		captureAllCapturePane = origCapturePane
-	})
-
-	captureAllHasSession = func(session string) bool {
-		_, ok := outputs[session]
-		return ok
-	}
-	captureAllCapturePane = func(session string, lines int) (string, error) {
-		return outputs[session], nil
-	}
+	term := fakeTerminal(t, outputs)
+	captureAllTerminal = func(*model.Run) (terminal.Backend, error) { return term, nil }
 
 	out := captureStdout(t, func() {
 		if err := runCaptureAll(&captureAllOptions{Lines: 5}); err != nil {
@@ -238,3 +219,31 @@ func TestRunCaptureAllPlain(t *testing.T) {
 		t.Fatalf("missing issue-2 error: %q", out)
 	}
 }
+
+// fakeTerminal returns a tmux stand-in with a session per output, and
+// restores captureAllTerminal after the test
+func fakeTerminal(t *testing.T, outputs map[string]string) *terminal.Fake {
+	t.Helper()
+	orig := captureAllTerminal
+	t.Cleanup(func() { captureAllTerminal = orig })
+
+	fake := terminal.NewFake(terminal.BackendTmux)
+	for session, output := range outputs {
+		if err := fake.NewSession(&terminal.SessionConfig{Name: session}); err != nil {
+			t.Fatalf("new session: %v", err)
+		}
+		fake.SetOutput(session, output)
+	}
+	return fake
+}
+
+// captureLinesTerminal records how many lines each capture asked for
+type captureLinesTerminal struct {
+	*terminal.Fake
+	lines []int
+}
+
+func (c *captureLinesTerminal) Capture(name string, lines int) (string, error) {
+	c.lines = append(c.lines, lines)
+	return c.Fake.Capture(name, lines)
+}
@@ -0,0 +1,239 @@
+package cli
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/exec"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/s22625/orch/internal/agent"
+	"github.com/s22625/orch/internal/config"
+	"github.com/s22625/orch/internal/git"
+	"github.com/s22625/orch/internal/model"
+	"github.com/s22625/orch/internal/store"
+	"github.com/spf13/cobra"
+)
+
+type compareOptions struct {
+	Test string
+}
+
+func newCompareCmd() *cobra.Command {
+	opts := &compareOptions{}
+
+	cmd := &cobra.Command{
+		Use:   "compare GROUP",
+		Short: "Compare the runs of a fanout side by side",
+		Long: `Compare the sibling runs started by orch run --fanout.
+
+GROUP is the fanout group ID printed by orch run --fanout, or any run of the
+group. Each run is shown with its status, the size of its changes against the
+base branch (including uncommitted changes), its last test result, cost and
+duration. The run chosen with orch pick is marked with *.
+
+With --test, the command is run in each worktree (through sh -c) and its
+result is recorded on the run before comparing.`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runCompare(args[0], opts)
+		},
+	}
+
+	cmd.Flags().StringVar(&opts.Test, "test", "", "Run this test command in each worktree first (e.g. 'go test ./...')")
+
+	return cmd
+}
+
+// compareRow is one run of orch compare
+type compareRow struct {
+	ID       string  `json:"id"`
+	Run      string  `json:"run"`
+	Agent    string  `json:"agent"`
+	Status   string  `json:"status"`
+	Winner   bool    `json:"winner,omitempty"`
+	Files    int     `json:"files"`
+	Added    int     `json:"insertions"`
+	Deleted  int     `json:"deletions"`
+	Diff     string  `json:"diff"`
+	Tests    string  `json:"tests,omitempty"`
+	Cost     float64 `json:"cost"`
+	Duration string  `json:"duration"`
+}
+
+type compareResult struct {
+	Group string       `json:"group"`
+	Runs  []compareRow `json:"runs"`
+}
+
+func runCompare(groupRef string, opts *compareOptions) error {
+	st, err := getStore()
+	if err != nil {
+		return err
+	}
+
+	group, runs, err := resolveFanout(st, groupRef)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(ExitRunNotFound)
+		return err
+	}
+
+	if opts.Test != "" {
+		runFanoutTests(st, runs, opts.Test)
+		if group, runs, err = resolveFanout(st, group); err != nil {
+			return err
+		}
+	}
+
+	rows := compareRows(runs, time.Now())
+
+	if globalOpts.JSON {
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		return enc.Encode(&compareResult{Group: group, Runs: rows})
+	}
+
+	headers := []string{"ID", "RUN", "AGENT", "STATUS", "DIFF", "TESTS", "COST", "DURATION"}
+	table := make([][]string, 0, len(rows))
+	for _, row := range rows {
+		id := row.ID
+		if row.Winner {
+			id += " *"
+		}
+		tests := row.Tests
+		if tests == "" {
+			tests = "-"
+		}
+		table = append(table, []string{
+			id,
+			row.Run,
+			row.Agent,
+			colorStatus(model.Status(row.Status)),
+			row.Diff,
+			tests,
+			model.FormatCost(row.Cost),
+			row.Duration,
+		})
+	}
+
+	widths := make([]int, len(headers))
+	for i, h := range headers {
+		widths[i] = len(h)
+	}
+	for _, row := range table {
+		for i, cell := range row {
+			if l := visibleLen(cell); l > widths[i] {
+				widths[i] = l
+			}
+		}
+	}
+	fmt.Printf("Fanout %s\n\n", group)
+	printRow(headers, widths)
+	for _, row := range table {
+		printRow(row, widths)
+	}
+	return nil
+}
+
+// resolveFanout returns the group and its runs ordered by run ID. ref is a
+// group ID or any run of the group.
+func resolveFanout(st store.Store, ref string) (string, []*model.Run, error) {
+	all, err := st.ListRuns(&store.ListRunsFilter{})
+	if err != nil {
+		return "", nil, err
+	}
+	group := ref
+	if runs := fanoutRuns(all, group); len(runs) > 0 {
+		return group, runs, nil
+	}
+	if run, err := resolveRun(st, ref); err == nil && run.FanoutGroup != "" {
+		group = run.FanoutGroup
+		return group, fanoutRuns(all, group), nil
+	}
+	return "", nil, fmt.Errorf("fanout group not found: %s", ref)
+}
+
+func fanoutRuns(all []*model.Run, group string) []*model.Run {
+	var runs []*model.Run
+	for _, run := range all {
+		if run.FanoutGroup == group {
+			runs = append(runs, run)
+		}
+	}
+	sort.Slice(runs, func(i, j int) bool {
+		return runs[i].RunID < runs[j].RunID
+	})
+	return runs
+}
+
+// runFanoutTests runs the test command in each worktree in parallel and
+// records whether it passed
+func runFanoutTests(st store.Store, runs []*model.Run, command string) {
+	var wg sync.WaitGroup
+	for _, run := range runs {
+		if run.WorktreePath == "" {
+			continue
+		}
+		if _, err := os.Stat(run.WorktreePath); err != nil {
+			continue
+		}
+		wg.Add(1)
+		go func(run *model.Run) {
+			defer wg.Done()
+			cmd := exec.Command("sh", "-c", command)
+			cmd.Dir = run.WorktreePath
+			passed := cmd.Run() == nil
+			if err := st.AppendEvent(run.Ref(), model.NewTestsArtifactEvent(command, passed)); err != nil {
+				fmt.Fprintf(os.Stderr, "warning: %s: failed to record tests: %v\n", run.Ref(), err)
+			}
+		}(run)
+	}
+	wg.Wait()
+}
+
+func compareRows(runs []*model.Run, now time.Time) []compareRow {
+	defaultBase := ""
+	if cfg, err := config.Load(); err == nil {
+		defaultBase = cfg.BaseBranch
+	}
+	rows := make([]compareRow, 0, len(runs))
+	for _, run := range runs {
+		row := compareRow{
+			ID:       run.ShortID(),
+			Run:      run.Ref().String(),
+			Agent:    firstNonEmpty(run.FanoutSpec, agent.AgentDisplayName(run.Agent, run.Model, run.ModelVariant)),
+			Status:   string(run.Status),
+			Winner:   run.FanoutWinner,
+			Diff:     "-",
+			Tests:    run.Tests,
+			Cost:     run.Usage.Cost,
+			Duration: formatElapsed(run.Elapsed(now)),
+		}
+		if run.WorktreePath != "" {
+			if stat, err := git.GetDiffStat(run.WorktreePath, run.DiffBase(defaultBase)); err == nil {
+				row.Files, row.Added, row.Deleted = stat.Files, stat.Insertions, stat.Deletions
+				row.Diff = stat.String()
+			}
+		}
+		rows = append(rows, row)
+	}
+	return rows
+}
+
+// formatElapsed renders a duration to the minute, or to the second under a
+// minute ("-" for none)
+func formatElapsed(d time.Duration) string {
+	switch {
+	case d <= 0:
+		return "-"
+	case d < time.Minute:
+		return fmt.Sprintf("%ds", int(d.Seconds()))
+	case d < time.Hour:
+		return fmt.Sprintf("%dm", int(d.Minutes()))
+	default:
+		return fmt.Sprintf("%dh%02dm", int(d.Hours()), int(d.Minutes())%60)
+	}
+}
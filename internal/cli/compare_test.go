@@ -0,0 +1,129 @@
+package cli
+
+import (
+	"encoding/json"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/s22625/orch/internal/model"
+	"github.com/s22625/orch/internal/store"
+)
+
+func gitCmd(t *testing.T, dir string, args ...string) {
+	t.Helper()
+	out, err := exec.Command("git", append([]string{"-C", dir}, args...)...).CombinedOutput()
+	if err != nil {
+		t.Fatalf("git %v: %v (%s)", args, err, strings.TrimSpace(string(out)))
+	}
+}
+
+// initGitRepo makes repo a git repository with one commit on main
+func initGitRepo(t *testing.T, repo string) {
+	t.Helper()
+	gitCmd(t, repo, "init")
+	gitCmd(t, repo, "config", "user.email", "test@example.com")
+	gitCmd(t, repo, "config", "user.name", "Test")
+	if err := os.WriteFile(filepath.Join(repo, "README.md"), []byte("test\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	gitCmd(t, repo, "add", "README.md")
+	gitCmd(t, repo, "commit", "-m", "init")
+	gitCmd(t, repo, "branch", "-M", "main")
+}
+
+// newFanoutGroup creates a repo on main and a run per agent of a fanout
+// group, each with a worktree on its own branch
+func newFanoutGroup(t *testing.T, agents ...string) (store.Store, string, []*model.Run) {
+	t.Helper()
+	repo := useFanoutRepo(t)
+	initGitRepo(t, repo)
+
+	st, err := getStore()
+	if err != nil {
+		t.Fatal(err)
+	}
+	group := model.GenerateFanoutGroup("issue-1", "20250101-090000")
+	var runs []*model.Run
+	for _, name := range agents {
+		runID := "20250101-090000-" + name
+		run, err := st.CreateRun("issue-1", runID, map[string]string{"agent": name})
+		if err != nil {
+			t.Fatal(err)
+		}
+		worktree := filepath.Join(filepath.Dir(repo), "worktrees", name)
+		branch := model.GenerateBranchName("issue-1", runID)
+		gitCmd(t, repo, "worktree", "add", "-b", branch, worktree, "main")
+		st.AppendEvent(run.Ref(), model.NewStatusEvent(model.StatusRunning))
+		st.AppendEvent(run.Ref(), model.NewFanoutArtifactEvent(group, name, false))
+		st.AppendEvent(run.Ref(), model.NewArtifactEvent("worktree", map[string]string{"path": worktree}))
+		st.AppendEvent(run.Ref(), model.NewArtifactEvent("branch", map[string]string{"name": branch}))
+		run, _ = st.GetRun(run.Ref())
+		runs = append(runs, run)
+	}
+	return st, group, runs
+}
+
+func TestRunCompare(t *testing.T) {
+	st, group, runs := newFanoutGroup(t, "claude", "codex")
+
+	// claude adds a file that the test command looks for; codex changes nothing
+	if err := os.WriteFile(filepath.Join(runs[0].WorktreePath, "done.txt"), []byte("a\nb\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	gitCmd(t, runs[0].WorktreePath, "add", "done.txt")
+	gitCmd(t, runs[0].WorktreePath, "commit", "-m", "work")
+	st.AppendEvent(runs[0].Ref(), model.NewUsageEvent(model.Usage{Model: "claude-sonnet-4-5", Cost: 1.5}))
+
+	globalOpts.Quiet = false
+	globalOpts.JSON = true
+	out := captureStdout(t, func() {
+		if err := runCompare(group, &compareOptions{Test: "test -f done.txt"}); err != nil {
+			t.Fatalf("runCompare: %v", err)
+		}
+	})
+	var result compareResult
+	if err := json.Unmarshal([]byte(out), &result); err != nil {
+		t.Fatalf("decode %q: %v", out, err)
+	}
+	if result.Group != group || len(result.Runs) != 2 {
+		t.Fatalf("result = %+v", result)
+	}
+	claude, codex := result.Runs[0], result.Runs[1]
+	if claude.Agent != "claude" || claude.Diff != "1 file +2 -0" || claude.Tests != model.TestsPass || claude.Cost != 1.5 {
+		t.Errorf("claude = %+v", claude)
+	}
+	if codex.Diff != "0 files +0 -0" || codex.Tests != model.TestsFail {
+		t.Errorf("codex = %+v", codex)
+	}
+
+	// The results were recorded, and a run of the group finds it
+	globalOpts.JSON = false
+	out = captureStdout(t, func() {
+		if err := runCompare(runs[1].ShortID(), &compareOptions{}); err != nil {
+			t.Fatalf("runCompare: %v", err)
+		}
+	})
+	for _, want := range []string{group, "DIFF", "1 file +2 -0", model.TestsPass, model.TestsFail, "$1.50"} {
+		if !strings.Contains(out, want) {
+			t.Errorf("output missing %q:\n%s", want, out)
+		}
+	}
+}
+
+func TestFormatElapsed(t *testing.T) {
+	cases := map[time.Duration]string{
+		0:                            "-",
+		42 * time.Second:             "42s",
+		17*time.Minute + time.Second: "17m",
+		2*time.Hour + 5*time.Minute:  "2h05m",
+	}
+	for d, want := range cases {
+		if got := formatElapsed(d); got != want {
+			t.Errorf("formatElapsed(%v) = %q, want %q", d, got, want)
+		}
+	}
+}
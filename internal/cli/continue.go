@@ -13,7 +13,7 @@ import (
 	"github.com/s22625/orch/internal/git"
 	"github.com/s22625/orch/internal/model"
 	"github.com/s22625/orch/internal/store"
-	"github.com/s22625/orch/internal/tmux"
+	"github.com/s22625/orch/internal/terminal"
 	"github.com/spf13/cobra"
 )
 
@@ -66,7 +66,7 @@ Use --branch with an issue ID to continue from an untracked branch.`,
 		},
 	}
 
-	cmd.Flags().StringVar(&opts.Agent, "agent", "", "Agent type (claude|codex|gemini|custom)")
+	cmd.Flags().StringVar(&opts.Agent, "agent", "", "Agent type (claude|codex|gemini|custom, or a name from the agents: config)")
 	cmd.Flags().StringVar(&opts.AgentCmd, "agent-cmd", "", "Custom agent command (when --agent=custom)")
 	cmd.Flags().StringVar(&opts.AgentProfile, "profile", "", "Agent profile (e.g., claude --profile)")
 	cmd.Flags().BoolVar(&opts.Tmux, "tmux", true, "Run in tmux session")
@@ -187,9 +187,11 @@ func continueFromRun(st store.Store, refStr string, opts *continueOptions) error
 	st.AppendEvent(run.Ref(), model.NewArtifactEvent("worktree", map[string]string{
 		"path": fromRun.WorktreePath,
 	}))
-	st.AppendEvent(run.Ref(), model.NewArtifactEvent("branch", map[string]string{
-		"name": fromRun.Branch,
-	}))
+	branchAttrs := map[string]string{"name": fromRun.Branch}
+	if fromRun.BaseBranch != "" {
+		branchAttrs["base"] = fromRun.BaseBranch
+	}
+	st.AppendEvent(run.Ref(), model.NewArtifactEvent("branch", branchAttrs))
 
 	promptOpts := &promptOptions{
 		NoPR:           opts.NoPR,
@@ -237,52 +239,44 @@ func continueFromRun(st store.Store, refStr string, opts *continueOptions) error
 	st.AppendEvent(run.Ref(), model.NewStatusEvent(model.StatusBooting))
 
 	if opts.Tmux {
-		if !tmux.IsTmuxAvailable() {
-			return exitWithCode(fmt.Errorf("tmux is not available"), ExitTmuxError)
+		term, err := sessionTerminal()
+		if err != nil {
+			st.AppendEvent(run.Ref(), model.NewStatusEvent(model.StatusFailed))
+			return exitWithCode(err, ExitTmuxError)
 		}
 
-		err = tmux.NewSession(&tmux.SessionConfig{
-			SessionName: tmuxSession,
-			WorkDir:     fromRun.WorktreePath,
-			Command:     agentCmd,
-			Env:         launchCfg.Env(),
+		transcriptPaths := runTranscript(st, run, term)
+		err = term.NewSession(&terminal.SessionConfig{
+			Name:       tmuxSession,
+			WorkDir:    fromRun.WorktreePath,
+			Command:    agentCmd,
+			Env:        launchCfg.Env(),
+			Transcript: transcriptPaths,
 		})
 		if err != nil {
 			st.AppendEvent(run.Ref(), model.NewStatusEvent(model.StatusFailed))
-			return exitWithCode(fmt.Errorf("failed to create tmux session: %w", err), ExitTmuxError)
+			return exitWithCode(fmt.Errorf("failed to create %s session: %w", term.Name(), err), ExitTmuxError)
 		}
 
 		if adapter.PromptInjection() == agent.InjectionTmux && launchCfg.Prompt != "" {
 			if pattern := adapter.ReadyPattern(); pattern != "" {
-				if err := tmux.WaitForReady(tmuxSession, pattern, 30*time.Second); err != nil {
+				if err := terminal.WaitForReady(term, tmuxSession, pattern, 30*time.Second); err != nil {
 					st.AppendEvent(run.Ref(), model.NewStatusEvent(model.StatusFailed))
 					return exitWithCode(fmt.Errorf("agent did not become ready: %w", err), ExitAgentError)
 				}
 			}
-			if err := tmux.SendKeys(tmuxSession, launchCfg.Prompt); err != nil {
+			if err := term.SendKeys(tmuxSession, launchCfg.Prompt, true); err != nil {
 				st.AppendEvent(run.Ref(), model.NewStatusEvent(model.StatusFailed))
 				return exitWithCode(fmt.Errorf("failed to send prompt to session: %w", err), ExitTmuxError)
 			}
 		}
 
-		st.AppendEvent(run.Ref(), model.NewArtifactEvent("session", map[string]string{
-			"name": tmuxSession,
-		}))
-
-		windowID := ""
-		if windows, err := tmux.ListWindows(tmuxSession); err == nil {
-			for _, window := range windows {
-				if window.Index == 0 {
-					windowID = window.ID
-					break
-				}
-			}
-		}
-		if windowID != "" {
-			st.AppendEvent(run.Ref(), model.NewArtifactEvent("window", map[string]string{
-				"id": windowID,
-			}))
+		st.AppendEvent(run.Ref(), newSessionArtifactEvent(term, tmuxSession))
+		if transcriptPaths != nil {
+			st.AppendEvent(run.Ref(), newTranscriptArtifactEvent(*transcriptPaths))
 		}
+
+		recordWindow(st, run, term, tmuxSession)
 	}
 
 	st.AppendEvent(run.Ref(), model.NewStatusEvent(model.StatusRunning))
@@ -423,52 +417,39 @@ func continueFromBranch(st store.Store, refStr string, opts *continueOptions) er
 	st.AppendEvent(run.Ref(), model.NewStatusEvent(model.StatusBooting))
 
 	if opts.Tmux {
-		if !tmux.IsTmuxAvailable() {
-			return exitWithCode(fmt.Errorf("tmux is not available"), ExitTmuxError)
+		term, err := sessionTerminal()
+		if err != nil {
+			st.AppendEvent(run.Ref(), model.NewStatusEvent(model.StatusFailed))
+			return exitWithCode(err, ExitTmuxError)
 		}
 
-		err = tmux.NewSession(&tmux.SessionConfig{
-			SessionName: tmuxSession,
-			WorkDir:     worktreePath,
-			Command:     agentCmd,
-			Env:         launchCfg.Env(),
+		err = term.NewSession(&terminal.SessionConfig{
+			Name:    tmuxSession,
+			WorkDir: worktreePath,
+			Command: agentCmd,
+			Env:     launchCfg.Env(),
 		})
 		if err != nil {
 			st.AppendEvent(run.Ref(), model.NewStatusEvent(model.StatusFailed))
-			return exitWithCode(fmt.Errorf("failed to create tmux session: %w", err), ExitTmuxError)
+			return exitWithCode(fmt.Errorf("failed to create %s session: %w", term.Name(), err), ExitTmuxError)
 		}
 
 		if adapter.PromptInjection() == agent.InjectionTmux && launchCfg.Prompt != "" {
 			if pattern := adapter.ReadyPattern(); pattern != "" {
-				if err := tmux.WaitForReady(tmuxSession, pattern, 30*time.Second); err != nil {
+				if err := terminal.WaitForReady(term, tmuxSession, pattern, 30*time.Second); err != nil {
 					st.AppendEvent(run.Ref(), model.NewStatusEvent(model.StatusFailed))
 					return exitWithCode(fmt.Errorf("agent did not become ready: %w", err), ExitAgentError)
 				}
 			}
-			if err := tmux.SendKeys(tmuxSession, launchCfg.Prompt); err != nil {
+			if err := term.SendKeys(tmuxSession, launchCfg.Prompt, true); err != nil {
 				st.AppendEvent(run.Ref(), model.NewStatusEvent(model.StatusFailed))
 				return exitWithCode(fmt.Errorf("failed to send prompt to session: %w", err), ExitTmuxError)
 			}
 		}
 
-		st.AppendEvent(run.Ref(), model.NewArtifactEvent("session", map[string]string{
-			"name": tmuxSession,
-		}))
+		st.AppendEvent(run.Ref(), newSessionArtifactEvent(term, tmuxSession))
 
-		windowID := ""
-		if windows, err := tmux.ListWindows(tmuxSession); err == nil {
-			for _, window := range windows {
-				if window.Index == 0 {
-					windowID = window.ID
-					break
-				}
-			}
-		}
-		if windowID != "" {
-			st.AppendEvent(run.Ref(), model.NewArtifactEvent("window", map[string]string{
-				"id": windowID,
-			}))
-		}
+		recordWindow(st, run, term, tmuxSession)
 	}
 
 	st.AppendEvent(run.Ref(), model.NewStatusEvent(model.StatusRunning))
@@ -4,7 +4,10 @@ import (
 	"fmt"
 	"os"
 
+	"github.com/s22625/orch/internal/config"
 	"github.com/s22625/orch/internal/daemon"
+	"github.com/s22625/orch/internal/rpc"
+	"github.com/s22625/orch/internal/store"
 	"github.com/spf13/cobra"
 )
 
@@ -63,6 +66,15 @@ func runDaemon() error {
 
 	// Create and run daemon
 	d := daemon.New(vaultPath, st)
+	d.SetBackend(getBackend())
+	if cfg, err := config.Load(); err == nil {
+		d.SetConcurrency(cfg.MaxConcurrentRuns)
+		d.SetAutoResume(cfg.AutoResume)
+		d.SetTimeouts(cfg.Timeouts)
+		d.SetHooks(cfg.Hooks)
+	} else {
+		fmt.Fprintf(os.Stderr, "warning: failed to load config: %v\n", err)
+	}
 	return d.Run()
 }
 
@@ -80,6 +92,9 @@ func ensureDaemon() {
 		return
 	}
 
+	// The daemon inherits our environment; make sure it opens the same backend
+	os.Setenv("ORCH_BACKEND", getBackend())
+
 	// Start daemon in background
 	_, err = daemon.StartInBackground(vaultPath)
 	if err != nil {
@@ -89,3 +104,21 @@ func ensureDaemon() {
 		}
 	}
 }
+
+// dialDaemon connects to the daemon's control API when the daemon is up.
+// It returns nil when it is not, speaks an older protocol or serves another
+// backend than ours; callers then work on the vault directly.
+func dialDaemon(st store.Store) *rpc.Client {
+	if !daemon.IsDaemonSocketAvailable(st.VaultPath()) {
+		return nil
+	}
+	client, err := rpc.Dial(st.VaultPath())
+	if err != nil {
+		return nil
+	}
+	if client.Hello().Backend != getBackend() {
+		client.Close()
+		return nil
+	}
+	return client
+}
@@ -0,0 +1,49 @@
+package cli
+
+import (
+	"io"
+	"log"
+	"os"
+	"testing"
+
+	"github.com/s22625/orch/internal/daemon"
+	"github.com/s22625/orch/internal/store/file"
+)
+
+func TestDialDaemonChecksBackend(t *testing.T) {
+	resetGlobalOpts(t)
+	// Unix socket paths are short; keep the vault under /tmp
+	vault, err := os.MkdirTemp("/tmp", "orch-cli-")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { os.RemoveAll(vault) })
+	st, err := file.New(vault)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := daemon.EnsureOrchDir(vault); err != nil {
+		t.Fatal(err)
+	}
+
+	server := daemon.NewSocketServer(vault, st, log.New(io.Discard, "", 0))
+	server.SetBackend("file")
+	if err := server.Start(); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(server.Stop)
+
+	globalOpts.Backend = "file"
+	client := dialDaemon(st)
+	if client == nil {
+		t.Fatal("dialDaemon() = nil for the daemon's backend")
+	}
+	client.Close()
+
+	// A daemon serving another backend must not answer for this store
+	globalOpts.Backend = "sqlite"
+	if client := dialDaemon(st); client != nil {
+		client.Close()
+		t.Error("dialDaemon() connected to a daemon serving the file backend")
+	}
+}
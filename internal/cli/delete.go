@@ -11,10 +11,10 @@ import (
 	"strings"
 	"time"
 
+	"github.com/s22625/orch/internal/agent"
 	"github.com/s22625/orch/internal/git"
 	"github.com/s22625/orch/internal/model"
 	"github.com/s22625/orch/internal/store"
-	"github.com/s22625/orch/internal/tmux"
 	"github.com/spf13/cobra"
 )
 
@@ -318,7 +318,7 @@ func deleteRuns(st store.Store, runs []*model.Run, opts *deleteOptions) error {
 			if opts.WithBranch && run.Branch != "" {
 				extras = append(extras, "branch")
 			}
-			if run.TmuxSession != "" && tmux.HasSession(run.TmuxSession) {
+			if term, err := runTerminal(run); err == nil && run.TmuxSession != "" && term.HasSession(run.TmuxSession) {
 				extras = append(extras, "session")
 			}
 			extraStr := ""
@@ -392,18 +392,12 @@ func performDelete(st store.Store, run *model.Run, opts *deleteOptions) (*delete
 		ShortID: run.ShortID(),
 	}
 
-	// 1. Kill tmux session if running
-	sessionName := run.TmuxSession
-	if sessionName == "" {
-		sessionName = model.GenerateTmuxSession(run.IssueID, run.RunID)
-	}
-	if tmux.HasSession(sessionName) {
-		if err := tmux.KillSession(sessionName); err != nil {
-			// Log warning but continue
-			fmt.Fprintf(os.Stderr, "warning: failed to kill session %s: %v\n", sessionName, err)
-		} else {
-			result.SessionKilled = true
-		}
+	// 1. Kill the session if running
+	if session, err := agent.KillSession(run); err != nil {
+		// Log warning but continue
+		fmt.Fprintf(os.Stderr, "warning: failed to kill session %s: %v\n", session, err)
+	} else {
+		result.SessionKilled = session != ""
 	}
 
 	// 2. Remove worktree if requested
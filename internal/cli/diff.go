@@ -0,0 +1,122 @@
+package cli
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"github.com/s22625/orch/internal/config"
+	"github.com/s22625/orch/internal/git"
+	"github.com/spf13/cobra"
+)
+
+type diffOptions struct {
+	Stat     bool
+	NameOnly bool
+}
+
+func newDiffCmd() *cobra.Command {
+	opts := &diffOptions{}
+
+	cmd := &cobra.Command{
+		Use:   "diff RUN_REF",
+		Short: "Show what a run changed",
+		Long: `Show the changes of a run's worktree against the base branch it was
+created from, including uncommitted changes and untracked files. When the
+worktree is gone (e.g. removed by orch pick), the run's branch is diffed in
+the main repository instead, without uncommitted changes.
+
+Runs created before the base branch was recorded are diffed against the
+configured base_branch (default main).
+
+RUN_REF can be ISSUE_ID#RUN_ID, ISSUE_ID (for latest run) or a short ID.`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runDiff(args[0], opts)
+		},
+	}
+
+	cmd.Flags().BoolVar(&opts.Stat, "stat", false, "Show a diffstat instead of the patch")
+	cmd.Flags().BoolVar(&opts.NameOnly, "name-only", false, "Show only the names of changed files")
+	cmd.MarkFlagsMutuallyExclusive("stat", "name-only")
+
+	return cmd
+}
+
+type diffResult struct {
+	OK         bool   `json:"ok"`
+	Run        string `json:"run"`
+	BaseBranch string `json:"base_branch"`
+	Branch     string `json:"branch,omitempty"`
+	Worktree   string `json:"worktree_path,omitempty"`
+	Format     string `json:"format,omitempty"`
+	Diff       string `json:"diff"`
+}
+
+func runDiff(refStr string, opts *diffOptions) error {
+	st, err := getStore()
+	if err != nil {
+		return err
+	}
+
+	run, err := resolveRun(st, refStr)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "run not found: %s\n", refStr)
+		os.Exit(ExitRunNotFound)
+		return err
+	}
+
+	format := git.DiffPatch
+	if opts.Stat {
+		format = git.DiffSummary
+	} else if opts.NameOnly {
+		format = git.DiffNameOnly
+	}
+	defaultBase := ""
+	if cfg, err := config.Load(); err == nil {
+		defaultBase = cfg.BaseBranch
+	}
+	base := run.DiffBase(defaultBase)
+	worktree := run.WorktreePath
+	if worktree != "" {
+		if _, err := os.Stat(worktree); err != nil {
+			worktree = ""
+		}
+	}
+
+	var out string
+	if worktree != "" {
+		out, err = git.Diff(worktree, base, format)
+	} else {
+		// Only the branch is left; its commits are all there is to diff
+		if run.Branch == "" {
+			return exitWithCode(fmt.Errorf("run %s has neither a worktree nor a branch", run.Ref()), ExitWorktreeError)
+		}
+		repoRoot := run.Repo
+		if repoRoot == "" {
+			if repoRoot, err = git.FindMainRepoRoot(""); err != nil {
+				return exitWithCode(fmt.Errorf("worktree of %s is gone and no repository was found: %w", run.Ref(), err), ExitWorktreeError)
+			}
+		}
+		out, err = git.BranchDiff(repoRoot, base, run.Branch, format)
+	}
+	if err != nil {
+		return exitWithCode(fmt.Errorf("failed to diff %s: %w", run.Ref(), err), ExitWorktreeError)
+	}
+
+	if globalOpts.JSON {
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		return enc.Encode(&diffResult{
+			OK:         true,
+			Run:        run.Ref().String(),
+			BaseBranch: base,
+			Branch:     run.Branch,
+			Worktree:   worktree,
+			Format:     string(format),
+			Diff:       out,
+		})
+	}
+	fmt.Print(out)
+	return nil
+}
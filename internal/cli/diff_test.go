@@ -0,0 +1,79 @@
+package cli
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/s22625/orch/internal/model"
+)
+
+func TestRunDiff(t *testing.T) {
+	st, _, runs := newFanoutGroup(t, "claude")
+	run := runs[0]
+
+	// The recorded base wins over the configured default
+	gitCmd(t, run.WorktreePath, "branch", "develop", "main")
+	st.AppendEvent(run.Ref(), model.NewArtifactEvent("branch", map[string]string{"name": run.Branch, "base": "develop"}))
+	if err := os.WriteFile(filepath.Join(run.WorktreePath, "README.md"), []byte("changed\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(run.WorktreePath, "new.txt"), []byte("new\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	globalOpts.Quiet = false
+	globalOpts.JSON = true
+	out := captureStdout(t, func() {
+		if err := runDiff(run.ShortID(), &diffOptions{NameOnly: true}); err != nil {
+			t.Fatalf("runDiff: %v", err)
+		}
+	})
+	var result diffResult
+	if err := json.Unmarshal([]byte(out), &result); err != nil {
+		t.Fatalf("decode %q: %v", out, err)
+	}
+	if result.BaseBranch != "develop" || result.Diff != "README.md\nnew.txt\n" {
+		t.Errorf("result = %+v", result)
+	}
+
+	globalOpts.JSON = false
+	out = captureStdout(t, func() {
+		if err := runDiff(run.Ref().String(), &diffOptions{}); err != nil {
+			t.Fatalf("runDiff: %v", err)
+		}
+	})
+	if !strings.Contains(out, "+changed") || !strings.Contains(out, "+new") {
+		t.Errorf("patch = %q", out)
+	}
+}
+
+func TestRunDiffWithoutWorktree(t *testing.T) {
+	_, _, runs := newFanoutGroup(t, "claude")
+	run := runs[0]
+
+	// orch pick removes a loser's worktree but keeps its branch
+	if err := os.WriteFile(filepath.Join(run.WorktreePath, "done.txt"), []byte("done\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	gitCmd(t, run.WorktreePath, "add", "done.txt")
+	gitCmd(t, run.WorktreePath, "commit", "-m", "work")
+	gitCmd(t, ".", "worktree", "remove", "--force", run.WorktreePath)
+
+	globalOpts.Quiet = false
+	globalOpts.JSON = true
+	out := captureStdout(t, func() {
+		if err := runDiff(run.ShortID(), &diffOptions{NameOnly: true}); err != nil {
+			t.Fatalf("runDiff: %v", err)
+		}
+	})
+	var result diffResult
+	if err := json.Unmarshal([]byte(out), &result); err != nil {
+		t.Fatalf("decode %q: %v", out, err)
+	}
+	if result.Diff != "done.txt\n" || result.Branch != run.Branch || result.Worktree != "" {
+		t.Errorf("result = %+v", result)
+	}
+}
@@ -0,0 +1,185 @@
+package cli
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"time"
+
+	"github.com/s22625/orch/internal/model"
+	"github.com/s22625/orch/internal/store"
+	"github.com/s22625/orch/internal/transcript"
+	"github.com/spf13/cobra"
+)
+
+type logsOptions struct {
+	Follow bool
+	Raw    bool
+}
+
+func newLogsCmd() *cobra.Command {
+	opts := &logsOptions{}
+
+	cmd := &cobra.Command{
+		Use:   "logs <RUN_REF>",
+		Short: "Show the full transcript of a run",
+		Long: `Show everything a run's tmux pane has printed since it started, including
+output that has scrolled out of the pane and output of sessions that have ended.
+
+The transcript is plain text with terminal escape sequences stripped; --raw
+prints the output as the pane received it (colors and cursor movement
+included), e.g. to pipe into "less -R".
+
+Examples:
+  orch logs orch-023
+  orch logs orch-023 --follow
+  orch logs a3b4c5 --raw | less -R`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runLogs(args[0], opts)
+		},
+	}
+
+	cmd.Flags().BoolVarP(&opts.Follow, "follow", "f", false, "Keep printing output until the run ends")
+	cmd.Flags().BoolVar(&opts.Raw, "raw", false, "Print the raw output with escape sequences")
+
+	return cmd
+}
+
+// logsFollowInterval is how often --follow checks the transcript for output
+const logsFollowInterval = 500 * time.Millisecond
+
+func runLogs(refStr string, opts *logsOptions) error {
+	st, err := getStore()
+	if err != nil {
+		return exitWithCode(err, ExitInternalError)
+	}
+	run, err := resolveRun(st, refStr)
+	if err != nil {
+		return exitWithCode(err, ExitRunNotFound)
+	}
+	if err := writeLogs(os.Stdout, st, run, opts, logsFollowInterval); err != nil {
+		return exitWithCode(err, ExitInternalError)
+	}
+	return nil
+}
+
+// writeLogs copies the run's transcript to w. With Follow it keeps copying
+// what is appended until the run reaches a final status.
+func writeLogs(w io.Writer, st store.Store, run *model.Run, opts *logsOptions, interval time.Duration) error {
+	path := run.Transcript
+	if opts.Raw {
+		path = run.TranscriptRaw
+	}
+	if path == "" {
+		return fmt.Errorf("run %s has no transcript (it was not started in tmux)", run.Ref())
+	}
+
+	var offset int64
+	for {
+		n, err := copyFrom(w, path, offset)
+		if err != nil {
+			return err
+		}
+		offset += n
+		if !opts.Follow {
+			return nil
+		}
+		if n == 0 {
+			current, err := st.GetRun(run.Ref())
+			if err != nil {
+				return err
+			}
+			switch current.Status {
+			case model.StatusDone, model.StatusFailed, model.StatusCanceled:
+				// Output written just before the status change
+				_, err := copyFrom(w, path, offset)
+				return err
+			}
+		}
+		time.Sleep(interval)
+	}
+}
+
+// copyFrom copies path from offset to the end; a file that does not exist
+// yet has nothing to copy
+func copyFrom(w io.Writer, path string, offset int64) (int64, error) {
+	f, err := os.Open(path)
+	if os.IsNotExist(err) {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, err
+	}
+	defer f.Close()
+	if _, err := f.Seek(offset, io.SeekStart); err != nil {
+		return 0, err
+	}
+	return io.Copy(w, f)
+}
+
+type recordTranscriptOptions struct {
+	Cast string
+	Size string
+}
+
+func newRecordTranscriptCmd() *cobra.Command {
+	opts := &recordTranscriptOptions{}
+
+	cmd := &cobra.Command{
+		Use:   "record-transcript <RAW> <TEXT>",
+		Short: "Record pane output from stdin into a run's transcript",
+		Long: `Append stdin to RAW as-is and to TEXT with escape sequences stripped, and
+record it with timing to the asciicast file given by --cast.
+This is the tmux pipe-pane command of runs; it is not meant to be run by hand.`,
+		Args:   cobra.ExactArgs(2),
+		Hidden: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runRecordTranscript(args[0], args[1], opts)
+		},
+	}
+
+	cmd.Flags().StringVar(&opts.Cast, "cast", "", "asciicast v2 file to record to")
+	cmd.Flags().StringVar(&opts.Size, "size", "80x24", "Terminal size of the recording (WIDTHxHEIGHT)")
+
+	return cmd
+}
+
+func runRecordTranscript(rawPath, textPath string, opts *recordTranscriptOptions) error {
+	raw, err := os.OpenFile(rawPath, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0644)
+	if err != nil {
+		return err
+	}
+	defer raw.Close()
+	text, err := os.OpenFile(textPath, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0644)
+	if err != nil {
+		return err
+	}
+	defer text.Close()
+
+	var cast io.Writer
+	if opts.Cast != "" {
+		var width, height int
+		if _, err := fmt.Sscanf(opts.Size, "%dx%d", &width, &height); err != nil {
+			return fmt.Errorf("invalid --size %q: expected WIDTHxHEIGHT", opts.Size)
+		}
+		f, err := os.OpenFile(opts.Cast, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0644)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		if cast, err = transcript.AppendCast(f, width, height); err != nil {
+			return err
+		}
+	}
+	return transcript.Record(os.Stdin, raw, text, cast)
+}
+
+// newTranscriptArtifactEvent records where the run's transcript is kept
+func newTranscriptArtifactEvent(paths transcript.Paths) *model.Event {
+	return model.NewArtifactEvent("transcript", map[string]string{
+		"path": paths.Text,
+		"raw":  paths.Raw,
+		"cast": paths.Cast,
+	})
+}
@@ -0,0 +1,134 @@
+package cli
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/s22625/orch/internal/model"
+	"github.com/s22625/orch/internal/store"
+	"github.com/s22625/orch/internal/terminal"
+	"github.com/s22625/orch/internal/transcript"
+)
+
+// syncBuffer is a bytes.Buffer safe to read while writeLogs writes to it
+type syncBuffer struct {
+	mu  sync.Mutex
+	buf bytes.Buffer
+}
+
+func (b *syncBuffer) Write(p []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.Write(p)
+}
+
+func (b *syncBuffer) String() string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.String()
+}
+
+func newLogsTestRun(t *testing.T) (store.Store, *model.Run, transcript.Paths) {
+	t.Helper()
+	resetGlobalOpts(t)
+
+	vault := t.TempDir()
+	globalOpts.VaultPath = vault
+	globalOpts.Backend = "file"
+	writeIssue(t, vault, "issue-1")
+
+	st, err := getStore()
+	if err != nil {
+		t.Fatalf("getStore: %v", err)
+	}
+	run, err := st.CreateRun("issue-1", "20240101-000000", nil)
+	if err != nil {
+		t.Fatalf("CreateRun: %v", err)
+	}
+	st.AppendEvent(run.Ref(), model.NewStatusEvent(model.StatusRunning))
+
+	paths := runTranscript(st, run, terminal.NewFake("fake"))
+	if paths == nil {
+		t.Fatal("no transcript paths")
+	}
+	if info, err := os.Stat(filepath.Dir(paths.Raw)); err != nil || !info.IsDir() {
+		t.Fatalf("transcript dir not created: %v", err)
+	}
+	st.AppendEvent(run.Ref(), newTranscriptArtifactEvent(*paths))
+	run, _ = st.GetRun(run.Ref())
+	return st, run, *paths
+}
+
+func TestWriteLogs(t *testing.T) {
+	st, run, paths := newLogsTestRun(t)
+	if run.Transcript != paths.Text || run.TranscriptRaw != paths.Raw {
+		t.Fatalf("run transcript = %q, %q", run.Transcript, run.TranscriptRaw)
+	}
+	if filepath.Dir(paths.Raw) != filepath.Join(st.VaultPath(), ".orch", "transcripts", "issue-1") {
+		t.Errorf("transcript dir = %s", filepath.Dir(paths.Raw))
+	}
+
+	// Nothing recorded yet
+	var out bytes.Buffer
+	if err := writeLogs(&out, st, run, &logsOptions{}, time.Millisecond); err != nil || out.Len() != 0 {
+		t.Fatalf("empty transcript = %q, %v", out.String(), err)
+	}
+
+	os.WriteFile(paths.Raw, []byte("\x1b[1mhello\x1b[0m\r\n"), 0644)
+	os.WriteFile(paths.Text, []byte("hello\n"), 0644)
+
+	out.Reset()
+	if err := writeLogs(&out, st, run, &logsOptions{}, time.Millisecond); err != nil || out.String() != "hello\n" {
+		t.Errorf("text = %q, %v", out.String(), err)
+	}
+	out.Reset()
+	if err := writeLogs(&out, st, run, &logsOptions{Raw: true}, time.Millisecond); err != nil || out.String() != "\x1b[1mhello\x1b[0m\r\n" {
+		t.Errorf("raw = %q, %v", out.String(), err)
+	}
+
+	if err := writeLogs(&out, st, &model.Run{IssueID: "issue-1", RunID: "x"}, &logsOptions{}, time.Millisecond); err == nil {
+		t.Error("expected an error for a run without transcript")
+	}
+}
+
+func TestWriteLogsFollowStopsWhenRunEnds(t *testing.T) {
+	st, run, paths := newLogsTestRun(t)
+	os.WriteFile(paths.Text, []byte("one\n"), 0644)
+
+	out := &syncBuffer{}
+	done := make(chan error, 1)
+	go func() {
+		done <- writeLogs(out, st, run, &logsOptions{Follow: true}, 10*time.Millisecond)
+	}()
+
+	waitFor := func(want string) {
+		t.Helper()
+		deadline := time.Now().Add(5 * time.Second)
+		for out.String() != want {
+			if time.Now().After(deadline) {
+				t.Fatalf("output = %q, want %q", out.String(), want)
+			}
+			time.Sleep(5 * time.Millisecond)
+		}
+	}
+	waitFor("one\n")
+
+	f, _ := os.OpenFile(paths.Text, os.O_APPEND|os.O_WRONLY, 0644)
+	f.WriteString("two\n")
+	f.Close()
+	waitFor("one\ntwo\n")
+
+	st.AppendEvent(run.Ref(), model.NewStatusEvent(model.StatusDone))
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Fatal(err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("follow did not stop after the run finished")
+	}
+}
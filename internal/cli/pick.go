@@ -0,0 +1,153 @@
+package cli
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"github.com/s22625/orch/internal/agent"
+	"github.com/s22625/orch/internal/git"
+	"github.com/s22625/orch/internal/model"
+	"github.com/s22625/orch/internal/store"
+	"github.com/spf13/cobra"
+)
+
+type pickOptions struct {
+	Keep  bool
+	Force bool
+}
+
+func newPickCmd() *cobra.Command {
+	opts := &pickOptions{}
+
+	cmd := &cobra.Command{
+		Use:   "pick RUN_REF",
+		Short: "Keep one run of a fanout and clean up the rest",
+		Long: `Mark a run of a fanout (orch run --fanout) as the winner and clean up its
+siblings: active siblings are stopped like orch stop, and their worktrees are
+removed. Their branches and run documents are kept, so the work can still be
+looked at or continued. A worktree with uncommitted changes is left in place
+unless --force, since removing it would lose them.
+
+Picking another run of the same group later moves the mark.`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runPick(args[0], opts)
+		},
+	}
+
+	cmd.Flags().BoolVar(&opts.Keep, "keep", false, "Stop the other runs but keep their worktrees")
+	cmd.Flags().BoolVar(&opts.Force, "force", false, "Remove worktrees even when they have uncommitted changes")
+
+	return cmd
+}
+
+type pickResult struct {
+	OK      bool     `json:"ok"`
+	Group   string   `json:"group"`
+	Winner  string   `json:"winner"`
+	Stopped []string `json:"stopped,omitempty"`
+	Removed []string `json:"removed_worktrees,omitempty"`
+	Errors  []string `json:"errors,omitempty"`
+}
+
+func runPick(refStr string, opts *pickOptions) error {
+	st, err := getStore()
+	if err != nil {
+		return err
+	}
+
+	winner, err := resolveRun(st, refStr)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "run not found: %s\n", refStr)
+		os.Exit(ExitRunNotFound)
+		return err
+	}
+	if winner.FanoutGroup == "" {
+		return fmt.Errorf("%s is not part of a fanout (orch run --fanout)", winner.Ref())
+	}
+	_, siblings, err := resolveFanout(st, winner.FanoutGroup)
+	if err != nil {
+		return err
+	}
+
+	result := pickResult{OK: true, Group: winner.FanoutGroup, Winner: winner.Ref().String()}
+	for _, run := range siblings {
+		if run.RunID == winner.RunID {
+			continue
+		}
+		pickCleanup(st, run, opts, &result)
+	}
+	if err := st.AppendEvent(winner.Ref(), model.NewFanoutArtifactEvent(winner.FanoutGroup, winner.FanoutSpec, true)); err != nil {
+		return fmt.Errorf("failed to mark %s as the winner: %w", winner.Ref(), err)
+	}
+	result.OK = len(result.Errors) == 0
+
+	if globalOpts.JSON {
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		return enc.Encode(result)
+	}
+	for _, e := range result.Errors {
+		fmt.Fprintf(os.Stderr, "warning: %s\n", e)
+	}
+	if !globalOpts.Quiet {
+		fmt.Printf("picked: %s (%s)\n", result.Winner, result.Group)
+		for _, path := range result.Removed {
+			fmt.Printf("removed worktree: %s\n", path)
+		}
+	}
+	return nil
+}
+
+// pickCleanup unmarks a sibling that lost, stops it if it is still active
+// and removes its worktree unless --keep or it holds uncommitted work
+func pickCleanup(st store.Store, run *model.Run, opts *pickOptions, result *pickResult) {
+	if run.FanoutWinner {
+		if err := st.AppendEvent(run.Ref(), model.NewFanoutArtifactEvent(run.FanoutGroup, run.FanoutSpec, false)); err != nil {
+			result.Errors = append(result.Errors, fmt.Sprintf("%s: %v", run.Ref(), err))
+		}
+	}
+
+	if !run.Status.IsFinished() {
+		stopped, err := agent.StopRun(st, run)
+		if err != nil {
+			result.Errors = append(result.Errors, fmt.Sprintf("%s: failed to stop: %v", run.Ref(), err))
+			return
+		}
+		if stopped.KillErr != nil {
+			result.Errors = append(result.Errors, fmt.Sprintf("%s: failed to kill session %s: %v", run.Ref(), stopped.KilledSession, stopped.KillErr))
+		}
+		if !stopped.AlreadyDone {
+			result.Stopped = append(result.Stopped, run.Ref().String())
+		}
+	}
+
+	if opts.Keep || run.WorktreePath == "" {
+		return
+	}
+	if _, err := os.Stat(run.WorktreePath); err != nil {
+		return // already gone
+	}
+	repoRoot, err := git.FindMainRepoRoot(run.WorktreePath)
+	if err != nil {
+		result.Errors = append(result.Errors, fmt.Sprintf("%s: %v", run.Ref(), err))
+		return
+	}
+	if !opts.Force {
+		dirty, err := git.HasUncommittedChanges(run.WorktreePath)
+		if err != nil {
+			result.Errors = append(result.Errors, fmt.Sprintf("%s: %v", run.Ref(), err))
+			return
+		}
+		if dirty {
+			result.Errors = append(result.Errors, fmt.Sprintf("%s: worktree %s has uncommitted changes; kept (use --force to remove it)", run.Ref(), run.WorktreePath))
+			return
+		}
+	}
+	if err := git.RemoveWorktree(repoRoot, run.WorktreePath); err != nil {
+		result.Errors = append(result.Errors, fmt.Sprintf("%s: failed to remove worktree %s: %v", run.Ref(), run.WorktreePath, err))
+		return
+	}
+	result.Removed = append(result.Removed, run.WorktreePath)
+}
@@ -0,0 +1,90 @@
+package cli
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/s22625/orch/internal/model"
+)
+
+func TestRunPick(t *testing.T) {
+	st, group, runs := newFanoutGroup(t, "claude", "codex", "gemini")
+	claude, codex, gemini := runs[0], runs[1], runs[2]
+	st.AppendEvent(gemini.Ref(), model.NewStatusEvent(model.StatusFailed))
+
+	if err := runPick(codex.Ref().String(), &pickOptions{}); err != nil {
+		t.Fatalf("runPick: %v", err)
+	}
+	claude, _ = st.GetRun(claude.Ref())
+	codex, _ = st.GetRun(codex.Ref())
+	gemini, _ = st.GetRun(gemini.Ref())
+	if !codex.FanoutWinner || codex.FanoutGroup != group || codex.Status != model.StatusRunning {
+		t.Errorf("winner: marked %v, group %q, status %s", codex.FanoutWinner, codex.FanoutGroup, codex.Status)
+	}
+	if claude.Status != model.StatusCanceled {
+		t.Errorf("active sibling status = %s, want canceled", claude.Status)
+	}
+	if gemini.Status != model.StatusFailed {
+		t.Errorf("finished sibling status = %s, want it left failed", gemini.Status)
+	}
+	for _, run := range []*model.Run{claude, gemini} {
+		if _, err := os.Stat(run.WorktreePath); !os.IsNotExist(err) {
+			t.Errorf("%s: worktree not removed: %v", run.RunID, err)
+		}
+	}
+	if _, err := os.Stat(codex.WorktreePath); err != nil {
+		t.Errorf("winner worktree: %v", err)
+	}
+
+	// Picking again moves the mark; --keep leaves the worktree
+	if err := runPick(gemini.Ref().String(), &pickOptions{Keep: true}); err != nil {
+		t.Fatalf("runPick again: %v", err)
+	}
+	codex, _ = st.GetRun(codex.Ref())
+	gemini, _ = st.GetRun(gemini.Ref())
+	if codex.FanoutWinner || !gemini.FanoutWinner {
+		t.Errorf("winner marks: codex %v, gemini %v", codex.FanoutWinner, gemini.FanoutWinner)
+	}
+	if codex.Status != model.StatusCanceled {
+		t.Errorf("previous winner status = %s", codex.Status)
+	}
+	if _, err := os.Stat(codex.WorktreePath); err != nil {
+		t.Errorf("--keep removed the worktree: %v", err)
+	}
+}
+
+func TestRunPickOutsideFanout(t *testing.T) {
+	useFanoutRepo(t)
+	st, _ := getStore()
+	run, err := st.CreateRun("issue-1", "20250101-090000", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := runPick(run.Ref().String(), &pickOptions{}); err == nil {
+		t.Error("picking a run outside a fanout should fail")
+	}
+}
+
+func TestRunPickKeepsUncommittedWork(t *testing.T) {
+	st, _, runs := newFanoutGroup(t, "claude", "codex")
+	claude, codex := runs[0], runs[1]
+	st.AppendEvent(claude.Ref(), model.NewStatusEvent(model.StatusDone))
+	if err := os.WriteFile(filepath.Join(claude.WorktreePath, "wip.txt"), []byte("unsaved\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := runPick(codex.Ref().String(), &pickOptions{}); err != nil {
+		t.Fatalf("runPick: %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(claude.WorktreePath, "wip.txt")); err != nil {
+		t.Errorf("uncommitted work removed: %v", err)
+	}
+
+	if err := runPick(codex.Ref().String(), &pickOptions{Force: true}); err != nil {
+		t.Fatalf("runPick --force: %v", err)
+	}
+	if _, err := os.Stat(claude.WorktreePath); !os.IsNotExist(err) {
+		t.Errorf("--force kept the worktree: %v", err)
+	}
+}
@@ -1,18 +1,22 @@
 package cli
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"os"
+	"os/signal"
 	"path/filepath"
 	"regexp"
 	"strings"
+	"syscall"
 	"time"
 
 	"github.com/s22625/orch/internal/agent"
 	"github.com/s22625/orch/internal/config"
 	"github.com/s22625/orch/internal/git"
 	"github.com/s22625/orch/internal/model"
+	"github.com/s22625/orch/internal/rpc"
 	"github.com/s22625/orch/internal/store"
 	"github.com/spf13/cobra"
 )
@@ -26,8 +30,14 @@ type psOptions struct {
 	Since        string
 	AbsoluteTime bool
 	All          bool
+	Watch        bool
 }
 
+const (
+	psWatchRefreshInterval = 10 * time.Second
+	psWatchCoalesce        = 100 * time.Millisecond
+)
+
 type psIssueInfo struct {
 	status  string
 	display string
@@ -58,6 +68,7 @@ func newPsCmd() *cobra.Command {
 	cmd.Flags().StringVar(&opts.Since, "since", "", "Only show runs updated since (ISO8601)")
 	cmd.Flags().BoolVar(&opts.AbsoluteTime, "absolute-time", false, "Show absolute timestamps instead of relative")
 	cmd.Flags().BoolVarP(&opts.All, "all", "a", false, "Show all runs including those from resolved issues")
+	cmd.Flags().BoolVarP(&opts.Watch, "watch", "w", false, "Keep running and redraw whenever runs or issues change")
 
 	return cmd
 }
@@ -68,6 +79,88 @@ func runPs(opts *psOptions) error {
 		return err
 	}
 
+	if opts.Watch {
+		return watchPs(st, opts)
+	}
+	return listPs(st, opts)
+}
+
+// watchPs redraws the run list on every store change until interrupted.
+// Table output clears the screen between frames; JSON/TSV emit one
+// document per frame so the stream can be piped.
+func watchPs(st store.Store, opts *psOptions) error {
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	changes, err := st.Watch(ctx, &store.WatchFilter{IssueID: opts.Issue})
+	if err != nil {
+		return err
+	}
+
+	// Relative times and agent liveness change without store writes
+	ticker := time.NewTicker(psWatchRefreshInterval)
+	defer ticker.Stop()
+
+	clearScreen := !globalOpts.JSON && !globalOpts.TSV
+	for {
+		if clearScreen {
+			fmt.Print("\033[H\033[2J")
+		}
+		if err := listPs(st, opts); err != nil {
+			return err
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil
+		case _, ok := <-changes:
+			if !ok {
+				return nil
+			}
+			drainChanges(changes, psWatchCoalesce)
+		case <-ticker.C:
+		}
+	}
+}
+
+// drainChanges discards changes arriving within window so a burst of
+// events causes one redraw
+func drainChanges(changes <-chan store.Change, window time.Duration) {
+	timer := time.NewTimer(window)
+	defer timer.Stop()
+	for {
+		select {
+		case _, ok := <-changes:
+			if !ok {
+				return
+			}
+		case <-timer.C:
+			return
+		}
+	}
+}
+
+// listRuns lists runs through the daemon when it is up, which saves
+// re-reading the vault, and from the store otherwise
+func listRuns(st store.Store, filter *store.ListRunsFilter) ([]*model.Run, error) {
+	client := dialDaemon(st)
+	if client == nil {
+		return st.ListRuns(filter)
+	}
+	defer client.Close()
+
+	params := &rpc.ListRunsParams{IssueID: filter.IssueID, Limit: filter.Limit, Since: filter.Since}
+	for _, s := range filter.Status {
+		params.Status = append(params.Status, string(s))
+	}
+	runs, err := client.ListRuns(context.Background(), params)
+	if err != nil {
+		return st.ListRuns(filter)
+	}
+	return runs, nil
+}
+
+func listPs(st store.Store, opts *psOptions) error {
 	// Build filter
 	requestedLimit := opts.Limit
 	filter := &store.ListRunsFilter{
@@ -87,7 +180,7 @@ func runPs(opts *psOptions) error {
 		filter.Limit = 0
 	}
 
-	runs, err := st.ListRuns(filter)
+	runs, err := listRuns(st, filter)
 	if err != nil {
 		return err
 	}
@@ -168,22 +261,24 @@ func outputJSON(runs []*model.Run, now time.Time) error {
 
 func outputJSONWithIssueInfo(runs []*model.Run, now time.Time, issueCache map[string]psIssueInfo, aliveByRun map[string]agentAliveInfo) error {
 	type runOutput struct {
-		IssueID      string `json:"issue_id"`
-		IssueStatus  string `json:"issue_status"`
-		RunID        string `json:"run_id"`
-		ShortID      string `json:"short_id"`
-		Agent        string `json:"agent,omitempty"`
-		Model        string `json:"model,omitempty"`
-		ModelVariant string `json:"model_variant,omitempty"`
-		Status       string `json:"status"`
-		AgentAlive   string `json:"agent_alive"`
-		UpdatedAt    string `json:"updated_at"`
-		UpdatedAgo   string `json:"updated_ago"`
-		StartedAt    string `json:"started_at"`
-		PRUrl        string `json:"pr_url,omitempty"`
-		Branch       string `json:"branch,omitempty"`
-		WorktreePath string `json:"worktree_path,omitempty"`
-		TmuxSession  string `json:"tmux_session,omitempty"`
+		IssueID      string  `json:"issue_id"`
+		IssueStatus  string  `json:"issue_status"`
+		RunID        string  `json:"run_id"`
+		ShortID      string  `json:"short_id"`
+		Agent        string  `json:"agent,omitempty"`
+		Model        string  `json:"model,omitempty"`
+		ModelVariant string  `json:"model_variant,omitempty"`
+		Status       string  `json:"status"`
+		AgentAlive   string  `json:"agent_alive"`
+		UpdatedAt    string  `json:"updated_at"`
+		UpdatedAgo   string  `json:"updated_ago"`
+		StartedAt    string  `json:"started_at"`
+		PRUrl        string  `json:"pr_url,omitempty"`
+		Branch       string  `json:"branch,omitempty"`
+		WorktreePath string  `json:"worktree_path,omitempty"`
+		TmuxSession  string  `json:"tmux_session,omitempty"`
+		Cost         float64 `json:"cost,omitempty"`
+		Budget       float64 `json:"budget,omitempty"`
 	}
 
 	output := struct {
@@ -221,6 +316,8 @@ func outputJSONWithIssueInfo(runs []*model.Run, now time.Time, issueCache map[st
 			Branch:       r.Branch,
 			WorktreePath: r.WorktreePath,
 			TmuxSession:  r.TmuxSession,
+			Cost:         r.Usage.Cost,
+			Budget:       r.Budget,
 		}
 	}
 
@@ -230,7 +327,7 @@ func outputJSONWithIssueInfo(runs []*model.Run, now time.Time, issueCache map[st
 }
 
 // TSV columns (fixed order per spec):
-// issue_id, issue_status, run_id, short_id, agent, status, alive, started_at, updated_at, pr_url, branch, worktree_path, tmux_session
+// issue_id, issue_status, run_id, short_id, agent, model, model_variant, status, alive, started_at, updated_at, pr_url, branch, worktree_path, tmux_session, cost
 func outputTSV(runs []*model.Run) error {
 	return outputTSVWithIssueInfo(runs, nil, nil)
 }
@@ -246,7 +343,7 @@ func outputTSVWithIssueInfo(runs []*model.Run, issueCache map[string]psIssueInfo
 			aliveInfo = aliveByRun[r.RunID]
 		}
 
-		fmt.Printf("%s\t%s\t%s\t%s\t%s\t%s\t%s\t%s\t%s\t%s\t%s\t%s\t%s\t%s\t%s\n",
+		fmt.Printf("%s\t%s\t%s\t%s\t%s\t%s\t%s\t%s\t%s\t%s\t%s\t%s\t%s\t%s\t%s\t%.4f\n",
 			r.IssueID,
 			issueStatus,
 			r.RunID,
@@ -262,6 +359,7 @@ func outputTSVWithIssueInfo(runs []*model.Run, issueCache map[string]psIssueInfo
 			r.Branch,
 			r.WorktreePath,
 			r.TmuxSession,
+			r.Usage.Cost,
 		)
 	}
 	return nil
@@ -298,7 +396,7 @@ func outputTableWithIssueInfo(runs []*model.Run, now time.Time, absoluteTime boo
 	gitStates := gitStatesForRuns(runs, baseBranch)
 
 	// Collect data rows
-	headers := []string{"ID", "ISSUE", "ISSUE-ST", "AGENT", "MODEL", "STATUS", "ALIVE", "BRANCH", "WORKTREE", "PR", "MERGED", "STARTED", "UPDATED", "TOPIC"}
+	headers := []string{"ID", "ISSUE", "ISSUE-ST", "AGENT", "MODEL", "STATUS", "ALIVE", "COST", "BRANCH", "WORKTREE", "PR", "MERGED", "STARTED", "UPDATED", "TOPIC"}
 	var rows [][]string
 
 	for _, r := range runs {
@@ -355,6 +453,7 @@ func outputTableWithIssueInfo(runs []*model.Run, now time.Time, absoluteTime boo
 			modelDisplay,
 			colorStatus(r.Status),
 			colorAlive(aliveInfo),
+			model.FormatCost(r.Usage.Cost),
 			branch,
 			worktree,
 			pr,
@@ -445,3 +445,29 @@ func TestRunPsAllIncludesResolvedIssues(t *testing.T) {
 		t.Fatalf("missing issues in output: %#v", found)
 	}
 }
+
+func TestOutputTableShowsCostColumn(t *testing.T) {
+	resetGlobalOpts(t)
+
+	vault := t.TempDir()
+	globalOpts.VaultPath = vault
+
+	updatedAt := time.Date(2025, 1, 2, 3, 4, 0, 0, time.UTC)
+	run := &model.Run{
+		IssueID:   "issue-1",
+		RunID:     "run-1",
+		Status:    model.StatusRunning,
+		UpdatedAt: updatedAt,
+		Usage:     model.Usage{Model: "claude-sonnet-4-5", InputTokens: 100, Cost: 1.234},
+	}
+
+	out := captureStdout(t, func() {
+		if err := outputTable([]*model.Run{run}, updatedAt, false); err != nil {
+			t.Fatalf("outputTable: %v", err)
+		}
+	})
+
+	if !strings.Contains(out, "COST") || !strings.Contains(out, "$1.23") {
+		t.Fatalf("missing cost column: %q", out)
+	}
+}
@@ -3,6 +3,7 @@ package cli
 import (
 	"fmt"
 	"os"
+	"strings"
 	"time"
 
 	"github.com/s22625/orch/internal/agent"
@@ -14,29 +15,52 @@ import (
 )
 
 type repairOptions struct {
-	DryRun bool
-	Force  bool
+	DryRun    bool
+	Force     bool
+	SetStatus string
+	Reason    string
 }
 
 func newRepairCmd() *cobra.Command {
 	opts := &repairOptions{}
 
 	cmd := &cobra.Command{
-		Use:   "repair",
+		Use:   "repair [RUN_REF]",
 		Short: "Repair system state",
 		Long: `Repair system state by fixing inconsistencies.
 
 This command will:
 - Restart the daemon if it's not running or unhealthy
 - Mark "running" runs with no tmux session as failed
-- Report orphaned sessions and worktrees`,
+- Report runs whose history contains illegal status transitions
+- Report orphaned sessions and worktrees
+
+With --set-status, it instead sets the status of one run, bypassing the
+transition rules (e.g. to reopen a run wrongly marked failed). The event
+records force=true and the --reason given.
+
+Examples:
+  orch repair --dry-run
+  orch repair --set-status running --reason "agent still alive" a1b2c3`,
+		Args: cobra.MaximumNArgs(1),
 		RunE: func(cmd *cobra.Command, args []string) error {
+			if opts.SetStatus != "" {
+				if len(args) != 1 {
+					return fmt.Errorf("--set-status needs a RUN_REF")
+				}
+				return runRepairSetStatus(args[0], opts)
+			}
+			if len(args) > 0 {
+				return fmt.Errorf("RUN_REF is only used with --set-status")
+			}
 			return runRepair(opts)
 		},
 	}
 
 	cmd.Flags().BoolVar(&opts.DryRun, "dry-run", false, "Report problems without fixing them")
 	cmd.Flags().BoolVar(&opts.Force, "force", false, "Fix without confirmation")
+	cmd.Flags().StringVar(&opts.SetStatus, "set-status", "", "Force the status of RUN_REF, bypassing transition rules")
+	cmd.Flags().StringVar(&opts.Reason, "reason", "", "Why the status is forced (required with --set-status)")
 
 	return cmd
 }
@@ -75,7 +99,15 @@ func runRepair(opts *repairOptions) error {
 		problemsFixed += staleFixed
 	}
 
-	// 3. Report orphaned sessions
+	// 3. Report illegal status transitions
+	fmt.Println("Checking status transitions...")
+	illegal, err := reportIllegalTransitions(st)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "  error: %v\n", err)
+	}
+	problemsFound += illegal
+
+	// 4. Report orphaned sessions
 	fmt.Println("Checking for orphaned sessions...")
 	orphanedSessions := findOrphanedSessions(st)
 	if len(orphanedSessions) > 0 {
@@ -115,6 +147,40 @@ func runRepair(opts *repairOptions) error {
 	return nil
 }
 
+// runRepairSetStatus appends a forced status event to one run
+func runRepairSetStatus(refStr string, opts *repairOptions) error {
+	if !model.IsValidStatus(opts.SetStatus) {
+		return fmt.Errorf("invalid status %q", opts.SetStatus)
+	}
+	if strings.TrimSpace(opts.Reason) == "" {
+		return fmt.Errorf("--set-status needs a --reason")
+	}
+
+	st, err := getStore()
+	if err != nil {
+		return err
+	}
+	run, err := resolveRun(st, refStr)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "run not found: %s\n", refStr)
+		os.Exit(ExitRunNotFound)
+		return err
+	}
+
+	status := model.Status(opts.SetStatus)
+	if opts.DryRun {
+		fmt.Printf("%s: would force %s -> %s\n", run.Ref(), run.Status, status)
+		return nil
+	}
+	if err := st.AppendEvent(run.Ref(), model.NewForcedStatusEvent(status, opts.Reason)); err != nil {
+		return fmt.Errorf("failed to set status: %w", err)
+	}
+	if !globalOpts.Quiet {
+		fmt.Printf("%s: %s -> %s (forced)\n", run.Ref(), run.Status, status)
+	}
+	return nil
+}
+
 func repairDaemon(vaultPath string, opts *repairOptions) (bool, error) {
 	if daemon.IsRunning(vaultPath) {
 		pid := daemon.GetRunningPID(vaultPath)
@@ -215,6 +281,40 @@ func repairStaleRuns(st store.Store, opts *repairOptions) (int, error) {
 	return fixed, nil
 }
 
+// reportIllegalTransitions replays every run's status events through the
+// model transition table. History is append-only, so these are reported
+// rather than fixed; forced events (force=true) are not flagged.
+func reportIllegalTransitions(st store.Store) (int, error) {
+	runs, err := st.ListRuns(&store.ListRunsFilter{})
+	if err != nil {
+		return 0, err
+	}
+
+	found := 0
+	for _, summary := range runs {
+		// ListRuns may omit events; load the full history
+		run, err := st.GetRun(summary.Ref())
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "  %s#%s: %v\n", summary.IssueID, summary.RunID, err)
+			continue
+		}
+		invalid := run.InvalidTransitions()
+		if len(invalid) == 0 {
+			continue
+		}
+		found++
+		fmt.Printf("  %s#%s: %d illegal transition(s)\n", run.IssueID, run.RunID, len(invalid))
+		for _, it := range invalid {
+			fmt.Printf("    %s %s -> %s\n", it.Event.Timestamp.Format(time.RFC3339), it.Err.From, it.Err.To)
+		}
+	}
+
+	if found == 0 {
+		fmt.Println("  all status histories are valid")
+	}
+	return found, nil
+}
+
 // findOrphanedSessions finds tmux sessions that don't correspond to any run
 func findOrphanedSessions(st store.Store) []string {
 	// Get all tmux sessions
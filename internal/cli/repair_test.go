@@ -0,0 +1,66 @@
+package cli
+
+import (
+	"testing"
+
+	"github.com/s22625/orch/internal/model"
+	"github.com/s22625/orch/internal/store"
+)
+
+func newRepairRun(t *testing.T) (store.Store, *model.Run) {
+	t.Helper()
+	resetGlobalOpts(t)
+
+	vault := t.TempDir()
+	globalOpts.VaultPath = vault
+	globalOpts.Backend = "file"
+	globalOpts.Quiet = true
+	writeIssue(t, vault, "issue-1")
+
+	st, err := getStore()
+	if err != nil {
+		t.Fatalf("getStore: %v", err)
+	}
+	run, err := st.CreateRun("issue-1", "20250101-090000", nil)
+	if err != nil {
+		t.Fatalf("CreateRun: %v", err)
+	}
+	st.AppendEvent(run.Ref(), model.NewStatusEvent(model.StatusRunning))
+	return st, run
+}
+
+func TestRepairSetStatus(t *testing.T) {
+	st, run := newRepairRun(t)
+	st.AppendEvent(run.Ref(), model.NewStatusEvent(model.StatusFailed))
+
+	// failed -> running is not a legal transition without the override
+	if err := st.AppendEvent(run.Ref(), model.NewStatusEvent(model.StatusRunning)); err == nil {
+		t.Fatal("plain status event should be rejected")
+	}
+
+	opts := &repairOptions{SetStatus: string(model.StatusRunning), Reason: "agent still alive"}
+	if err := runRepairSetStatus(run.ShortID(), opts); err != nil {
+		t.Fatalf("runRepairSetStatus: %v", err)
+	}
+	loaded, err := st.GetRun(run.Ref())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if loaded.Status != model.StatusRunning {
+		t.Errorf("status = %s, want running", loaded.Status)
+	}
+	last := loaded.Events[len(loaded.Events)-1]
+	if !last.IsForced() || last.Attrs["reason"] != "agent still alive" {
+		t.Errorf("last event = %+v, want a forced status event with the reason", last)
+	}
+	if invalid := loaded.InvalidTransitions(); len(invalid) != 0 {
+		t.Errorf("forced event reported as illegal: %+v", invalid)
+	}
+
+	if err := runRepairSetStatus(run.ShortID(), &repairOptions{SetStatus: "done"}); err == nil {
+		t.Error("--set-status without --reason should fail")
+	}
+	if err := runRepairSetStatus(run.ShortID(), &repairOptions{SetStatus: "bogus", Reason: "x"}); err == nil {
+		t.Error("invalid status should fail")
+	}
+}
@@ -0,0 +1,94 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/signal"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/s22625/orch/internal/transcript"
+	"github.com/spf13/cobra"
+)
+
+type replayOptions struct {
+	Speed     string
+	IdleLimit time.Duration
+}
+
+func newReplayCmd() *cobra.Command {
+	opts := &replayOptions{}
+
+	cmd := &cobra.Command{
+		Use:   "replay <RUN_REF>",
+		Short: "Play back the terminal recording of a run",
+		Long: `Play back a run's tmux pane in this terminal as it was recorded, to see how
+the agent got to its result without attaching to the session.
+
+The recording is an asciicast v2 file (the "cast" of the run's transcript
+artifact), so it can also be shared and played with asciinema. Pauses longer than
+--idle-limit are shortened to it before --speed is applied.
+
+Examples:
+  orch replay orch-023
+  orch replay a3b4c5 --speed 4x
+  orch replay orch-023 --speed 2 --idle-limit 500ms`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runReplay(args[0], opts)
+		},
+	}
+
+	cmd.Flags().StringVar(&opts.Speed, "speed", "1x", "Playback speed (e.g. 2x, 0.5x)")
+	cmd.Flags().DurationVar(&opts.IdleLimit, "idle-limit", 2*time.Second, "Longest pause between frames (0 for none)")
+
+	return cmd
+}
+
+// replayReset restores the terminal after playback stops mid-frame
+const replayReset = "\x1b[0m\x1b[?25h\n"
+
+func runReplay(refStr string, opts *replayOptions) error {
+	speed, err := parseSpeed(opts.Speed)
+	if err != nil {
+		return exitWithCode(err, ExitInternalError)
+	}
+
+	st, err := getStore()
+	if err != nil {
+		return exitWithCode(err, ExitInternalError)
+	}
+	run, err := resolveRun(st, refStr)
+	if err != nil {
+		return exitWithCode(err, ExitRunNotFound)
+	}
+	if run.TranscriptCast == "" {
+		return exitWithCode(fmt.Errorf("run %s has no recording (it was not started in tmux)", run.Ref()), ExitInternalError)
+	}
+	f, err := os.Open(run.TranscriptCast)
+	if err != nil {
+		return exitWithCode(fmt.Errorf("failed to open recording: %w", err), ExitInternalError)
+	}
+	defer f.Close()
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	err = transcript.Play(ctx, os.Stdout, f, transcript.PlayOptions{Speed: speed, IdleLimit: opts.IdleLimit})
+	fmt.Fprint(os.Stdout, replayReset)
+	if err != nil && ctx.Err() == nil {
+		return exitWithCode(err, ExitInternalError)
+	}
+	return nil
+}
+
+// parseSpeed parses a playback speed such as "4x", "4" or "0.5x"
+func parseSpeed(s string) (float64, error) {
+	speed, err := strconv.ParseFloat(strings.TrimSuffix(strings.TrimSpace(s), "x"), 64)
+	if err != nil || speed <= 0 {
+		return 0, fmt.Errorf("invalid speed %q: expected a positive factor such as 4x", s)
+	}
+	return speed, nil
+}
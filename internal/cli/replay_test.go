@@ -0,0 +1,25 @@
+package cli
+
+import "testing"
+
+func TestParseSpeed(t *testing.T) {
+	tests := []struct {
+		in   string
+		want float64
+		ok   bool
+	}{
+		{"4x", 4, true},
+		{"4", 4, true},
+		{"0.5x", 0.5, true},
+		{" 2x ", 2, true},
+		{"0x", 0, false},
+		{"-1", 0, false},
+		{"fast", 0, false},
+	}
+	for _, tt := range tests {
+		got, err := parseSpeed(tt.in)
+		if (err == nil) != tt.ok || got != tt.want {
+			t.Errorf("parseSpeed(%q) = %v, %v", tt.in, got, err)
+		}
+	}
+}
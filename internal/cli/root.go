@@ -5,10 +5,14 @@ import (
 	"os"
 	"regexp"
 
+	"github.com/s22625/orch/internal/agent"
 	"github.com/s22625/orch/internal/config"
 	"github.com/s22625/orch/internal/model"
 	"github.com/s22625/orch/internal/store"
 	"github.com/s22625/orch/internal/store/file"
+	"github.com/s22625/orch/internal/store/github"
+	"github.com/s22625/orch/internal/store/linear"
+	"github.com/s22625/orch/internal/store/sqlite"
 	"github.com/spf13/cobra"
 )
 
@@ -21,6 +25,8 @@ const (
 	ExitAgentError       = 5
 	ExitRunNotFound      = 6
 	ExitQuestionNotFound = 7
+	ExitRunFailed        = 8
+	ExitTimeout          = 9
 	ExitInternalError    = 10
 )
 
@@ -38,13 +44,17 @@ var globalOpts = &GlobalOptions{}
 
 // Commands that should NOT auto-start the daemon
 var noDaemonCommands = map[string]bool{
-	"show":       true,
-	"daemon":     true,
-	"repair":     true,
-	"delete":     true,
-	"help":       true,
-	"completion": true,
-	"models":     true,
+	"show":              true,
+	"diff":              true,
+	"daemon":            true,
+	"repair":            true,
+	"delete":            true,
+	"help":              true,
+	"completion":        true,
+	"models":            true,
+	"store":             true,
+	"stream-view":       true,
+	"record-transcript": true,
 }
 
 // rootCmd represents the base command
@@ -59,6 +69,12 @@ and questions to handle human input requirements.`,
 	SilenceUsage:  true,
 	SilenceErrors: true,
 	PersistentPreRun: func(cmd *cobra.Command, args []string) {
+		// Register agents declared in the agents: config section
+		if err := agent.LoadConfigured(); err != nil {
+			fmt.Fprintf(os.Stderr, "warning: agents config: %v\n", err)
+		}
+		registerPTYClient()
+
 		// Auto-start daemon for most commands
 		if !noDaemonCommands[cmd.Name()] {
 			ensureDaemon()
@@ -69,7 +85,7 @@ and questions to handle human input requirements.`,
 func init() {
 	// Global flags
 	rootCmd.PersistentFlags().StringVar(&globalOpts.VaultPath, "vault", "", "Path to vault (or set ORCH_VAULT)")
-	rootCmd.PersistentFlags().StringVar(&globalOpts.Backend, "backend", "file", "Backend type (file|github|linear)")
+	rootCmd.PersistentFlags().StringVar(&globalOpts.Backend, "backend", "", "Backend type (file|sqlite|github|linear, or set ORCH_BACKEND)")
 	rootCmd.PersistentFlags().BoolVar(&globalOpts.JSON, "json", false, "Output in JSON format")
 	rootCmd.PersistentFlags().BoolVar(&globalOpts.TSV, "tsv", false, "Output in TSV format (for fzf)")
 	rootCmd.PersistentFlags().BoolVar(&globalOpts.Quiet, "quiet", false, "Suppress human-readable output")
@@ -96,6 +112,16 @@ func init() {
 	rootCmd.AddCommand(newCaptureCmd())
 	rootCmd.AddCommand(newCaptureAllCmd())
 	rootCmd.AddCommand(newModelsCmd())
+	rootCmd.AddCommand(newStoreCmd())
+	rootCmd.AddCommand(newStreamViewCmd())
+	rootCmd.AddCommand(newServeCmd())
+	rootCmd.AddCommand(newLogsCmd())
+	rootCmd.AddCommand(newReplayCmd())
+	rootCmd.AddCommand(newRecordTranscriptCmd())
+	rootCmd.AddCommand(newCompareCmd())
+	rootCmd.AddCommand(newPickCmd())
+	rootCmd.AddCommand(newWaitCmd())
+	rootCmd.AddCommand(newDiffCmd())
 }
 
 // Execute runs the root command
@@ -137,41 +163,96 @@ func getStore() (store.Store, error) {
 		return nil, err
 	}
 
-	switch globalOpts.Backend {
+	backend := getBackend()
+	switch backend {
 	case "file":
 		return file.New(vaultPath)
+	case "sqlite":
+		return sqlite.New(vaultPath)
+	case "github":
+		return newGitHubStore(vaultPath)
+	case "linear":
+		return newLinearStore(vaultPath)
 	default:
-		return nil, fmt.Errorf("unsupported backend: %s", globalOpts.Backend)
+		return nil, fmt.Errorf("unsupported backend: %s", backend)
 	}
 }
 
-// shortIDRegex matches a 2-6 char hex string (git-style short ID prefix)
-var shortIDRegex = regexp.MustCompile(`^[0-9a-f]{2,6}$`)
+// getBackend returns the store backend name
+// Precedence: --backend flag > config backend (or ORCH_BACKEND) > "file"
+func getBackend() string {
+	if globalOpts.Backend != "" {
+		return globalOpts.Backend
+	}
+	if cfg, err := config.Load(); err == nil && cfg.Backend != "" {
+		return cfg.Backend
+	}
+	return "file"
+}
 
-// resolveRun resolves a run by short ID or run reference (issue#run or issue)
-// Accepts:
-//   - 2-6 char hex short ID prefix (e.g., "a3", "a3b4", "a3b4c5")
-//   - Full run ref (e.g., "my-task#20231220-100000")
-//   - Issue ID for latest run (e.g., "my-task")
-func resolveRun(st store.Store, refStr string) (*model.Run, error) {
-	// First, try as a short ID prefix (2-6 hex chars)
-	if shortIDRegex.MatchString(refStr) {
-		run, err := st.GetRunByShortID(refStr)
-		if err == nil {
-			return run, nil
-		}
-		// If it's exactly 6 chars and failed, report the short ID error
-		// For shorter prefixes, fall through to try as regular ref
-		if len(refStr) == 6 {
-			return nil, err
-		}
+// newGitHubStore builds the github backend from the github: config section
+func newGitHubStore(vaultPath string) (store.Store, error) {
+	cfg, err := config.Load()
+	if err != nil {
+		return nil, err
+	}
+	gh := cfg.GitHub
+	if gh.Repo == "" {
+		return nil, fmt.Errorf("github backend requires github.repo in config (or ORCH_GITHUB_REPO)")
+	}
+	owner, repo, err := github.SplitRepo(gh.Repo)
+	if err != nil {
+		return nil, err
+	}
+
+	labels := github.DefaultStatusLabels()
+	if gh.OpenLabel != "" {
+		labels.Open = gh.OpenLabel
+	}
+	if gh.ResolvedLabel != "" {
+		labels.Resolved = gh.ResolvedLabel
+	}
+	if gh.ClosedLabel != "" {
+		labels.Closed = gh.ClosedLabel
 	}
 
-	// Try as a regular run reference
-	ref, err := model.ParseRunRef(refStr)
+	client := github.NewHTTPClient(gh.APIURL, owner, repo, github.TokenFromEnv())
+	return github.New(vaultPath, gh.Repo, client, labels)
+}
+
+// newLinearStore builds the linear backend from the linear: config section
+func newLinearStore(vaultPath string) (store.Store, error) {
+	cfg, err := config.Load()
 	if err != nil {
 		return nil, err
 	}
+	lc := cfg.Linear
+
+	mapping := linear.StateMapping{
+		ByName:  make(map[string]model.IssueStatus),
+		Targets: make(map[model.IssueStatus]string),
+	}
+	for name, status := range lc.States {
+		if !model.IsValidIssueStatus(status) {
+			return nil, fmt.Errorf("invalid status %q for linear state %q (use open|resolved|closed)", status, name)
+		}
+		mapping.ByName[name] = model.IssueStatus(status)
+	}
+	for status, name := range lc.StatusStates {
+		if !model.IsValidIssueStatus(status) {
+			return nil, fmt.Errorf("invalid status %q in linear.status_states (use open|resolved|closed)", status)
+		}
+		mapping.Targets[model.IssueStatus(status)] = name
+	}
+
+	client := linear.NewHTTPClient(lc.APIURL, os.Getenv("LINEAR_API_KEY"))
+	return linear.New(vaultPath, lc.Team, client, mapping)
+}
 
-	return st.GetRun(ref)
+// shortIDRegex matches a 2-6 char hex string (git-style short ID prefix)
+var shortIDRegex = regexp.MustCompile(`^[0-9a-f]{2,6}$`)
+
+// resolveRun resolves a run by short ID or run reference (see store.ResolveRun)
+func resolveRun(st store.Store, refStr string) (*model.Run, error) {
+	return store.ResolveRun(st, refStr)
 }
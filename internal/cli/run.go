@@ -13,9 +13,11 @@ import (
 
 	"github.com/s22625/orch/internal/agent"
 	"github.com/s22625/orch/internal/config"
+	"github.com/s22625/orch/internal/daemon"
 	"github.com/s22625/orch/internal/git"
 	"github.com/s22625/orch/internal/model"
-	"github.com/s22625/orch/internal/tmux"
+	"github.com/s22625/orch/internal/store"
+	"github.com/s22625/orch/internal/terminal"
 	"github.com/spf13/cobra"
 )
 
@@ -38,7 +40,19 @@ type runOptions struct {
 	PRTargetBranch string
 	Model          string
 	ModelVariant   string
+	Budget         float64
+	Timeout        time.Duration
+	StallTimeout   time.Duration
+	StreamJSON     bool
+	Queue          bool
+	Priority       int
+	StartQueued    bool
+	Fanout         string
 	Verbose        bool
+
+	// Set for each sibling of a fanout
+	FanoutGroup string
+	FanoutSpec  string
 }
 
 func newRunCmd() *cobra.Command {
@@ -51,6 +65,10 @@ func newRunCmd() *cobra.Command {
 
 The run will be started in a tmux session by default.
 
+With --fanout, one sibling run per listed agent is started, each in its own
+worktree and branch, sharing a fanout group. Compare them with orch compare
+and keep the best with orch pick.
+
 Debug output can be enabled with --verbose, --log-level debug, or ORCH_DEBUG=1.`,
 		Args: cobra.ExactArgs(1),
 		PreRun: func(cmd *cobra.Command, args []string) {
@@ -66,7 +84,7 @@ Debug output can be enabled with --verbose, --log-level debug, or ORCH_DEBUG=1.`
 	cmd.Flags().BoolVar(&opts.New, "new", true, "Always create a new run (default)")
 	cmd.Flags().BoolVar(&opts.Reuse, "reuse", false, "Reuse the latest run if blocked or blocked_api")
 	cmd.Flags().StringVar(&opts.RunID, "run-id", "", "Manually specify run ID")
-	cmd.Flags().StringVar(&opts.Agent, "agent", "", "Agent type (claude|codex|gemini|opencode|custom)")
+	cmd.Flags().StringVar(&opts.Agent, "agent", "", "Agent type (claude|codex|gemini|opencode|custom, or a name from the agents: config)")
 	cmd.Flags().StringVar(&opts.AgentCmd, "agent-cmd", "", "Custom agent command (when --agent=custom)")
 	cmd.Flags().StringVar(&opts.AgentProfile, "profile", "", "Agent profile (e.g., claude --profile)")
 	cmd.Flags().StringVar(&opts.BaseBranch, "base-branch", "", "Base branch for worktree")
@@ -80,6 +98,15 @@ Debug output can be enabled with --verbose, --log-level debug, or ORCH_DEBUG=1.`
 	cmd.Flags().StringVar(&opts.PromptTemplate, "prompt-template", "", "Custom prompt template file")
 	cmd.Flags().StringVar(&opts.Model, "model", "", "Model for opencode (provider/model format, e.g., anthropic/claude-opus-4-5)")
 	cmd.Flags().StringVar(&opts.ModelVariant, "model-variant", "", "Model variant (e.g., 'max' for max thinking)")
+	cmd.Flags().Float64Var(&opts.Budget, "budget", 0, "Fail the run once its estimated cost exceeds this many USD")
+	cmd.Flags().DurationVar(&opts.Timeout, "timeout", 0, "Fail the run once it has been running this long, e.g. 2h (default: timeouts.run)")
+	cmd.Flags().DurationVar(&opts.StallTimeout, "stall-timeout", 0, "Nudge the agent after this long without output, and fail the run if it stays silent as long again (default: timeouts.stall)")
+	cmd.Flags().BoolVar(&opts.StreamJSON, "stream-json", false, "Run the agent non-interactively with structured output (claude only)")
+	cmd.Flags().BoolVar(&opts.Queue, "queue", false, "Leave the run queued; the daemon starts it when max_concurrent_runs allows")
+	cmd.Flags().IntVar(&opts.Priority, "priority", 0, "Queue priority (higher starts first, with --queue)")
+	cmd.Flags().StringVar(&opts.Fanout, "fanout", "", "Start one run per agent, e.g. claude,codex,opencode:opus:high (compare with orch compare)")
+	cmd.Flags().BoolVar(&opts.StartQueued, "start-queued", false, "Start the queued run given by --run-id (used by the daemon)")
+	cmd.Flags().MarkHidden("start-queued")
 	cmd.Flags().BoolVarP(&opts.Verbose, "verbose", "v", false, "Enable debug output for troubleshooting")
 
 	return cmd
@@ -103,10 +130,20 @@ func runRun(issueID string, opts *runOptions) error {
 		return exitWithCode(err, ExitInternalError)
 	}
 
+	if opts.StartQueued {
+		return runStartQueued(st, issueID, opts)
+	}
+	if opts.Fanout != "" {
+		return runFanout(st, issueID, opts)
+	}
+
 	// Apply config defaults for prompt options
 	if err := applyPromptConfigDefaults(opts); err != nil {
 		return exitWithCode(err, ExitInternalError)
 	}
+	if err := checkRunOptions(opts); err != nil {
+		return err
+	}
 
 	// Resolve issue first
 	issue, err := st.ResolveIssue(issueID)
@@ -129,6 +166,53 @@ func runRun(issueID string, opts *runOptions) error {
 		}
 	}
 
+	result, repoRoot, err := planRun(issueID, runID, opts)
+	if err != nil {
+		return err
+	}
+
+	// Dry run - just output what would happen
+	if opts.DryRun {
+		if globalOpts.JSON {
+			enc := json.NewEncoder(os.Stdout)
+			enc.SetIndent("", "  ")
+			return enc.Encode(result)
+		}
+		printDryRun(st, result, opts)
+		return nil
+	}
+
+	run, err := createRun(st, result, opts)
+	if err != nil {
+		return err
+	}
+
+	if opts.Queue {
+		return queueRun(st, run, repoRoot, result, opts)
+	}
+	return launchRun(st, issue, run, repoRoot, result, opts)
+}
+
+// checkRunOptions rejects option combinations a run cannot start with
+func checkRunOptions(opts *runOptions) error {
+	if opts.Budget < 0 {
+		return exitWithCode(fmt.Errorf("--budget must not be negative"), ExitInternalError)
+	}
+	if opts.Timeout < 0 || opts.StallTimeout < 0 {
+		return exitWithCode(fmt.Errorf("--timeout and --stall-timeout must not be negative"), ExitInternalError)
+	}
+	if opts.Priority != 0 && !opts.Queue {
+		return exitWithCode(fmt.Errorf("--priority requires --queue"), ExitInternalError)
+	}
+	if opts.StreamJSON && !agent.SupportsStream(agent.AgentType(opts.Agent)) {
+		return exitWithCode(fmt.Errorf("--stream-json is not supported by agent %s", opts.Agent), ExitAgentError)
+	}
+	return nil
+}
+
+// planRun works out the branch, session and worktree of a new run and the
+// repository it is created in
+func planRun(issueID, runID string, opts *runOptions) (*runResult, string, error) {
 	// Determine branch name
 	branch := opts.Branch
 	if branch == "" {
@@ -144,9 +228,10 @@ func runRun(issueID string, opts *runOptions) error {
 	// Find repo root - use main repo root to handle running from inside worktrees
 	repoRoot := opts.RepoRoot
 	if repoRoot == "" {
+		var err error
 		repoRoot, err = git.FindMainRepoRoot("")
 		if err != nil {
-			return exitWithCode(fmt.Errorf("could not find git repository: %w", err), ExitWorktreeError)
+			return nil, "", exitWithCode(fmt.Errorf("could not find git repository: %w", err), ExitWorktreeError)
 		}
 	}
 
@@ -161,7 +246,7 @@ func runRun(issueID string, opts *runOptions) error {
 		worktreePath = filepath.Join(repoRoot, opts.WorktreeDir, issueID, worktreeName)
 	}
 
-	result := &runResult{
+	return &runResult{
 		OK:           true,
 		IssueID:      issueID,
 		RunID:        runID,
@@ -169,42 +254,46 @@ func runRun(issueID string, opts *runOptions) error {
 		WorktreePath: worktreePath,
 		TmuxSession:  tmuxSession,
 		Status:       string(model.StatusQueued),
-	}
-
-	// Dry run - just output what would happen
-	if opts.DryRun {
-		// Build the command that would be run (for display purposes)
-		agentType, _ := agent.ParseAgentType(opts.Agent)
-		adapter, _ := agent.GetAdapter(agentType)
-		launchCfg := &agent.LaunchConfig{
-			Type:      agentType,
-			CustomCmd: opts.AgentCmd,
-			WorkDir:   worktreePath,
-			IssueID:   issueID,
-			RunID:     runID,
-			VaultPath: "",
-			Branch:    branch,
-			Prompt:    promptFileInstruction,
-			Profile:   opts.AgentProfile,
-		}
-		agentCmd, _ := adapter.LaunchCommand(launchCfg)
+	}, repoRoot, nil
+}
 
-		if globalOpts.JSON {
-			enc := json.NewEncoder(os.Stdout)
-			enc.SetIndent("", "  ")
-			return enc.Encode(result)
-		}
-		fmt.Printf("Would create run:\n")
-		fmt.Printf("  Issue:     %s\n", issueID)
-		fmt.Printf("  Run ID:    %s\n", runID)
-		fmt.Printf("  Branch:    %s\n", branch)
-		fmt.Printf("  Worktree:  %s\n", worktreePath)
-		fmt.Printf("  Session:   %s\n", tmuxSession)
-		fmt.Printf("  Command:   %s\n", agentCmd)
-		return nil
-	}
+// printDryRun shows the run planRun worked out and the agent command it
+// would start
+func printDryRun(st store.Store, result *runResult, opts *runOptions) {
+	// Build the command that would be run (for display purposes)
+	agentType, _ := agent.ParseAgentType(opts.Agent)
+	adapter, _ := agent.GetAdapter(agentType)
+	launchCfg := &agent.LaunchConfig{
+		Type:      agentType,
+		CustomCmd: opts.AgentCmd,
+		WorkDir:   result.WorktreePath,
+		IssueID:   result.IssueID,
+		RunID:     result.RunID,
+		VaultPath: "",
+		Branch:    result.Branch,
+		Prompt:    promptFileInstruction,
+		Profile:   opts.AgentProfile,
+	}
+	if opts.StreamJSON {
+		launchCfg.StreamLog = agent.StreamLogPath(daemon.OrchDir(st.VaultPath()), result.IssueID, result.RunID)
+	}
+	agentCmd := ""
+	if adapter != nil {
+		agentCmd, _ = adapter.LaunchCommand(launchCfg)
+	}
+
+	fmt.Printf("Would create run:\n")
+	fmt.Printf("  Issue:     %s\n", result.IssueID)
+	fmt.Printf("  Run ID:    %s\n", result.RunID)
+	fmt.Printf("  Branch:    %s\n", result.Branch)
+	fmt.Printf("  Worktree:  %s\n", result.WorktreePath)
+	fmt.Printf("  Session:   %s\n", result.TmuxSession)
+	fmt.Printf("  Command:   %s\n", agentCmd)
+}
 
-	// Create run document
+// createRun records a new run as queued, with its budget, limits and
+// fanout group
+func createRun(st store.Store, result *runResult, opts *runOptions) (*model.Run, error) {
 	metadata := map[string]string{
 		"agent": opts.Agent,
 	}
@@ -214,31 +303,57 @@ func runRun(issueID string, opts *runOptions) error {
 	if opts.ModelVariant != "" {
 		metadata["model_variant"] = opts.ModelVariant
 	}
-	run, err := st.CreateRun(issueID, runID, metadata)
+	run, err := st.CreateRun(result.IssueID, result.RunID, metadata)
 	if err != nil {
-		return exitWithCode(fmt.Errorf("failed to create run: %w", err), ExitInternalError)
+		return nil, exitWithCode(fmt.Errorf("failed to create run: %w", err), ExitInternalError)
 	}
 	result.RunPath = run.Path
 
 	// Append initial status event
 	if err := st.AppendEvent(run.Ref(), model.NewStatusEvent(model.StatusQueued)); err != nil {
-		return exitWithCode(err, ExitInternalError)
+		return nil, exitWithCode(err, ExitInternalError)
+	}
+	if opts.Budget > 0 {
+		if err := st.AppendEvent(run.Ref(), model.NewBudgetArtifactEvent(opts.Budget)); err != nil {
+			return nil, exitWithCode(err, ExitInternalError)
+		}
+	}
+	if opts.Timeout > 0 || opts.StallTimeout > 0 {
+		if err := st.AppendEvent(run.Ref(), model.NewTimeoutArtifactEvent(opts.Timeout, opts.StallTimeout)); err != nil {
+			return nil, exitWithCode(err, ExitInternalError)
+		}
+	}
+	if opts.FanoutGroup != "" {
+		if err := st.AppendEvent(run.Ref(), model.NewFanoutArtifactEvent(opts.FanoutGroup, opts.FanoutSpec, false)); err != nil {
+			return nil, exitWithCode(err, ExitInternalError)
+		}
+	}
+	return run, nil
+}
+
+// createRunWorktree creates the worktree of a recorded run and records it.
+// A run whose worktree was created before it started (a fanout sibling)
+// keeps that worktree.
+func createRunWorktree(st store.Store, run *model.Run, repoRoot string, result *runResult, opts *runOptions) (*git.WorktreeResult, error) {
+	if run.WorktreePath != "" {
+		if _, err := os.Stat(run.WorktreePath); err == nil {
+			result.WorktreePath = run.WorktreePath
+			result.Branch = run.Branch
+			return &git.WorktreeResult{WorktreePath: run.WorktreePath, Branch: run.Branch, BaseBranch: run.BaseBranch}, nil
+		}
 	}
 
-	// Create worktree
 	worktreeResult, err := git.CreateWorktree(&git.WorktreeConfig{
 		RepoRoot:    repoRoot,
 		WorktreeDir: opts.WorktreeDir,
-		IssueID:     issueID,
-		RunID:       runID,
+		IssueID:     run.IssueID,
+		RunID:       run.RunID,
 		Agent:       opts.Agent,
 		BaseBranch:  opts.BaseBranch,
-		Branch:      branch,
+		Branch:      result.Branch,
 	})
 	if err != nil {
-		err = fmt.Errorf("failed to create worktree: %w", err)
-		setRunFailed(st, run, err)
-		return exitWithCode(err, ExitWorktreeError)
+		return nil, fmt.Errorf("failed to create worktree: %w", err)
 	}
 
 	result.WorktreePath = worktreeResult.WorktreePath
@@ -250,21 +365,40 @@ func runRun(issueID string, opts *runOptions) error {
 	}))
 	st.AppendEvent(run.Ref(), model.NewArtifactEvent("branch", map[string]string{
 		"name": worktreeResult.Branch,
+		"base": worktreeResult.BaseBranch,
 	}))
+	return worktreeResult, nil
+}
+
+// launchRun creates the worktree of a run recorded as queued and starts its
+// agent. It records the run failed if the agent cannot be started.
+func launchRun(st store.Store, issue *model.Issue, run *model.Run, repoRoot string, result *runResult, opts *runOptions) error {
+	issueID, runID := run.IssueID, run.RunID
+	tmuxSession := result.TmuxSession
+
+	worktreeResult, err := createRunWorktree(st, run, repoRoot, result, opts)
+	if err != nil {
+		setRunFailed(st, run, err)
+		return exitWithCode(err, ExitWorktreeError)
+	}
 
 	// Get agent adapter
 	agentType, err := agent.ParseAgentType(opts.Agent)
 	if err != nil {
+		setRunFailed(st, run, err)
 		return exitWithCode(err, ExitAgentError)
 	}
 
 	adapter, err := agent.GetAdapter(agentType)
 	if err != nil {
+		setRunFailed(st, run, err)
 		return exitWithCode(err, ExitAgentError)
 	}
 
 	if !adapter.IsAvailable() {
-		return exitWithCode(fmt.Errorf("agent %s is not available", opts.Agent), ExitAgentError)
+		err := fmt.Errorf("agent %s is not available", opts.Agent)
+		setRunFailed(st, run, err)
+		return exitWithCode(err, ExitAgentError)
 	}
 
 	// Build agent launch config
@@ -279,7 +413,9 @@ func runRun(issueID string, opts *runOptions) error {
 	agentPrompt := buildAgentPrompt(issue, promptOpts)
 	promptPath := filepath.Join(worktreeResult.WorktreePath, promptFileName)
 	if err := os.WriteFile(promptPath, []byte(agentPrompt), 0644); err != nil {
-		return exitWithCode(fmt.Errorf("failed to write prompt file: %w", err), ExitInternalError)
+		err = fmt.Errorf("failed to write prompt file: %w", err)
+		setRunFailed(st, run, err)
+		return exitWithCode(err, ExitInternalError)
 	}
 	launchCfg := &agent.LaunchConfig{
 		Type:         agentType,
@@ -296,9 +432,22 @@ func runRun(issueID string, opts *runOptions) error {
 		Model:        opts.Model,
 		ModelVariant: opts.ModelVariant,
 	}
+	if opts.StreamJSON {
+		launchCfg.StreamLog = agent.StreamLogPath(daemon.OrchDir(st.VaultPath()), issueID, runID)
+		if err := os.MkdirAll(filepath.Dir(launchCfg.StreamLog), 0755); err != nil {
+			err = fmt.Errorf("failed to create stream log directory: %w", err)
+			setRunFailed(st, run, err)
+			return exitWithCode(err, ExitInternalError)
+		}
+		st.AppendEvent(run.Ref(), model.NewArtifactEvent("stream", map[string]string{
+			"path":   launchCfg.StreamLog,
+			"stderr": agent.StreamStderrPath(launchCfg.StreamLog),
+		}))
+	}
 
 	agentCmd, err := adapter.LaunchCommand(launchCfg)
 	if err != nil {
+		setRunFailed(st, run, err)
 		return exitWithCode(err, ExitAgentError)
 	}
 
@@ -308,8 +457,10 @@ func runRun(issueID string, opts *runOptions) error {
 	debug := NewDebugLogger()
 
 	if opts.Tmux {
-		if !tmux.IsTmuxAvailable() {
-			return exitWithCode(fmt.Errorf("tmux is not available"), ExitTmuxError)
+		term, err := sessionTerminal()
+		if err != nil {
+			setRunFailed(st, run, err)
+			return exitWithCode(err, ExitTmuxError)
 		}
 
 		serverAlreadyRunning := false
@@ -345,23 +496,26 @@ func runRun(issueID string, opts *runOptions) error {
 				env = append(env, opencodeAdapter.Env()...)
 			}
 
-			// Create tmux session
-			err = tmux.NewSession(&tmux.SessionConfig{
-				SessionName: tmuxSession,
-				WorkDir:     worktreeResult.WorktreePath,
-				Command:     agentCmd,
-				Env:         env,
+			// Create the session, recording everything it prints
+			transcriptPaths := runTranscript(st, run, term)
+			err = term.NewSession(&terminal.SessionConfig{
+				Name:       tmuxSession,
+				WorkDir:    worktreeResult.WorktreePath,
+				Command:    agentCmd,
+				Env:        env,
+				Transcript: transcriptPaths,
 			})
 			if err != nil {
-				err = fmt.Errorf("failed to create tmux session: %w", err)
+				err = fmt.Errorf("failed to create %s session: %w", term.Name(), err)
 				setRunFailed(st, run, err)
 				return exitWithCode(err, ExitTmuxError)
 			}
 
 			// Record session artifact
-			st.AppendEvent(run.Ref(), model.NewArtifactEvent("session", map[string]string{
-				"name": tmuxSession,
-			}))
+			st.AppendEvent(run.Ref(), newSessionArtifactEvent(term, tmuxSession))
+			if transcriptPaths != nil {
+				st.AppendEvent(run.Ref(), newTranscriptArtifactEvent(*transcriptPaths))
+			}
 		}
 
 		// Handle prompt injection based on agent type
@@ -371,13 +525,13 @@ func runRun(issueID string, opts *runOptions) error {
 			if launchCfg.Prompt != "" {
 				// Wait for the agent to be ready before sending the prompt
 				if pattern := adapter.ReadyPattern(); pattern != "" {
-					if err := tmux.WaitForReady(tmuxSession, pattern, 30*time.Second); err != nil {
+					if err := terminal.WaitForReady(term, tmuxSession, pattern, 30*time.Second); err != nil {
 						err = fmt.Errorf("agent did not become ready: %w", err)
 						setRunFailed(st, run, err)
 						return exitWithCode(err, ExitAgentError)
 					}
 				}
-				if err := tmux.SendKeys(tmuxSession, launchCfg.Prompt); err != nil {
+				if err := term.SendKeys(tmuxSession, launchCfg.Prompt, true); err != nil {
 					err = fmt.Errorf("failed to send prompt to session: %w", err)
 					setRunFailed(st, run, err)
 					return exitWithCode(err, ExitTmuxError)
@@ -392,22 +546,9 @@ func runRun(issueID string, opts *runOptions) error {
 			}
 		}
 
-		// Record window ID only if we created a new tmux session
+		// Record window ID only if we created a new session
 		if !serverAlreadyRunning {
-			windowID := ""
-			if windows, err := tmux.ListWindows(tmuxSession); err == nil {
-				for _, window := range windows {
-					if window.Index == 0 {
-						windowID = window.ID
-						break
-					}
-				}
-			}
-			if windowID != "" {
-				st.AppendEvent(run.Ref(), model.NewArtifactEvent("window", map[string]string{
-					"id": windowID,
-				}))
-			}
+			recordWindow(st, run, term, tmuxSession)
 		}
 	}
 
@@ -606,6 +747,13 @@ func applyPromptConfigDefaults(opts *runOptions) error {
 		opts.PRTargetBranch = cfg.PRTargetBranch
 	}
 
+	if opts.Timeout == 0 {
+		opts.Timeout = cfg.Timeouts.Run
+	}
+	if opts.StallTimeout == 0 {
+		opts.StallTimeout = cfg.Timeouts.Stall
+	}
+
 	// For NoPR: config sets the default, but --no-pr flag overrides
 	// Since bool flags default to false, we apply config value if it's true
 	if cfg.NoPR && !opts.NoPR {
@@ -0,0 +1,281 @@
+package cli
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/exec"
+	"regexp"
+	"strings"
+	"sync"
+
+	"github.com/s22625/orch/internal/agent"
+	"github.com/s22625/orch/internal/config"
+	"github.com/s22625/orch/internal/model"
+	"github.com/s22625/orch/internal/store"
+)
+
+// fanoutSpec is one agent of --fanout
+type fanoutSpec struct {
+	Spec         string // as given, e.g. opencode:opus:high
+	Agent        string
+	Model        string
+	ModelVariant string
+	Suffix       string // run ID suffix, e.g. opencode-opus-high
+}
+
+var fanoutSuffixRegex = regexp.MustCompile(`[^a-z0-9]+`)
+
+// parseFanout splits the --fanout list into agents, resolving opencode
+// presets like the monitor's agent picker
+func parseFanout(list string, presets []config.OpenCodePreset) ([]fanoutSpec, error) {
+	var specs []fanoutSpec
+	used := make(map[string]int)
+	for _, raw := range strings.Split(list, ",") {
+		raw = strings.TrimSpace(raw)
+		if raw == "" {
+			continue
+		}
+		spec := fanoutSpec{Spec: raw}
+		spec.Agent, spec.Model, spec.ModelVariant = config.ParseAgentPreset(raw, presets)
+		if _, err := agent.ParseAgentType(spec.Agent); err != nil {
+			return nil, fmt.Errorf("--fanout %s: %w", raw, err)
+		}
+
+		suffix := strings.Trim(fanoutSuffixRegex.ReplaceAllString(strings.ToLower(raw), "-"), "-")
+		used[suffix]++
+		if n := used[suffix]; n > 1 {
+			suffix = fmt.Sprintf("%s-%d", suffix, n)
+		}
+		spec.Suffix = suffix
+		specs = append(specs, spec)
+	}
+	if len(specs) < 2 {
+		return nil, fmt.Errorf("--fanout needs at least two agents")
+	}
+	return specs, nil
+}
+
+// fanoutResult is the JSON output of orch run --fanout
+type fanoutResult struct {
+	OK     bool         `json:"ok"`
+	Group  string       `json:"group"`
+	Runs   []*runResult `json:"runs"`
+	Errors []string     `json:"errors,omitempty"`
+}
+
+// fanoutSibling is a run of the fanout with the options it starts with
+type fanoutSibling struct {
+	opts     *runOptions
+	result   *runResult
+	repoRoot string
+	run      *model.Run
+}
+
+// runFanout creates one run of the issue per agent, sharing a fanout group,
+// and starts them side by side
+func runFanout(st store.Store, issueID string, opts *runOptions) error {
+	conflicts := []struct {
+		flag string
+		set  bool
+	}{
+		{"--agent", opts.Agent != ""},
+		{"--reuse", opts.Reuse},
+		{"--branch", opts.Branch != ""},
+		{"--tmux-session", opts.TmuxSession != ""},
+	}
+	for _, c := range conflicts {
+		if c.set {
+			return exitWithCode(fmt.Errorf("%s cannot be used with --fanout", c.flag), ExitInternalError)
+		}
+	}
+
+	cfg, err := config.Load()
+	if err != nil {
+		return exitWithCode(err, ExitInternalError)
+	}
+	specs, err := parseFanout(opts.Fanout, cfg.OpenCodePresets)
+	if err != nil {
+		return exitWithCode(err, ExitAgentError)
+	}
+
+	issue, err := st.ResolveIssue(issueID)
+	if err != nil {
+		return exitWithCode(fmt.Errorf("issue not found: %s", issueID), ExitIssueNotFound)
+	}
+
+	baseRunID := opts.RunID
+	if baseRunID == "" {
+		baseRunID = model.GenerateRunID()
+	}
+	group := model.GenerateFanoutGroup(issueID, baseRunID)
+	output := &fanoutResult{OK: true, Group: group}
+
+	// Each sibling gets the shared options with its own agent; --model and
+	// --model-variant apply to agents whose spec names none
+	siblings := make([]*fanoutSibling, 0, len(specs))
+	for _, spec := range specs {
+		sibOpts := *opts
+		sibOpts.Fanout = ""
+		sibOpts.Agent = spec.Agent
+		if spec.Model != "" {
+			sibOpts.Model = spec.Model
+		}
+		if spec.ModelVariant != "" {
+			sibOpts.ModelVariant = spec.ModelVariant
+		}
+		sibOpts.RunID = baseRunID + "-" + spec.Suffix
+		sibOpts.FanoutGroup = group
+		sibOpts.FanoutSpec = spec.Spec
+		if err := applyPromptConfigDefaults(&sibOpts); err != nil {
+			return exitWithCode(err, ExitInternalError)
+		}
+		if err := checkRunOptions(&sibOpts); err != nil {
+			return err
+		}
+		result, repoRoot, err := planRun(issueID, sibOpts.RunID, &sibOpts)
+		if err != nil {
+			return err
+		}
+		siblings = append(siblings, &fanoutSibling{opts: &sibOpts, result: result, repoRoot: repoRoot})
+		output.Runs = append(output.Runs, result)
+	}
+
+	if opts.DryRun {
+		if globalOpts.JSON {
+			return writeFanoutResult(output)
+		}
+		fmt.Printf("Would fan out %s to %d runs (group %s)\n", issue.ID, len(siblings), group)
+		for _, sib := range siblings {
+			fmt.Println()
+			printDryRun(st, sib.result, sib.opts)
+		}
+		return nil
+	}
+
+	// Record every sibling before starting any, queued with its launch
+	// options so it starts like a queued run
+	for _, sib := range siblings {
+		run, err := createRun(st, sib.result, sib.opts)
+		if err != nil {
+			return err
+		}
+		event := model.NewQueueArtifactEvent(sib.opts.Priority, sib.repoRoot, queueOptions(sib.opts, sib.result))
+		if err := st.AppendEvent(run.Ref(), event); err != nil {
+			return exitWithCode(err, ExitInternalError)
+		}
+		sib.run = run
+	}
+
+	// With --queue the daemon starts them as max_concurrent_runs allows.
+	// Otherwise the worktrees are created one at a time first: concurrent
+	// git fetch and worktree add in the same repository can fail and fall
+	// back to a stale base.
+	errs := make([]error, len(siblings))
+	if !opts.Queue {
+		for i, sib := range siblings {
+			if _, err := createRunWorktree(st, sib.run, sib.repoRoot, sib.result, sib.opts); err != nil {
+				setRunFailed(st, sib.run, err)
+				errs[i] = err
+				continue
+			}
+			if run, err := st.GetRun(sib.run.Ref()); err == nil {
+				sib.run = run
+			}
+		}
+
+		var wg sync.WaitGroup
+		for i, sib := range siblings {
+			if errs[i] != nil {
+				continue
+			}
+			wg.Add(1)
+			go func(i int, run *model.Run) {
+				defer wg.Done()
+				errs[i] = startFanoutRun(st, run)
+			}(i, sib.run)
+		}
+		wg.Wait()
+	}
+
+	started := 0
+	for i, sib := range siblings {
+		if run, err := st.GetRun(sib.run.Ref()); err == nil {
+			sib.run = run
+		}
+		// A start that lost the claim to the daemon still started the run
+		if errs[i] != nil && (sib.run.Status == model.StatusFailed || sib.run.Status == model.StatusQueued) {
+			output.Errors = append(output.Errors, fmt.Sprintf("%s: %v", sib.run.Ref(), errs[i]))
+		}
+		sib.result.Status = string(sib.run.Status)
+		sib.result.Branch = firstNonEmpty(sib.run.Branch, sib.result.Branch)
+		sib.result.WorktreePath = firstNonEmpty(sib.run.WorktreePath, sib.result.WorktreePath)
+		if sib.run.Status != model.StatusFailed {
+			started++
+		}
+	}
+	output.OK = started > 0
+
+	if globalOpts.JSON {
+		if err := writeFanoutResult(output); err != nil {
+			return err
+		}
+	} else {
+		for _, e := range output.Errors {
+			fmt.Fprintf(os.Stderr, "warning: %s\n", e)
+		}
+		if !globalOpts.Quiet {
+			verb := "Fanned out"
+			if opts.Queue {
+				verb = "Queued"
+			}
+			fmt.Printf("%s %s to %d runs (group %s)\n", verb, issue.ID, len(siblings), group)
+			for _, sib := range siblings {
+				fmt.Printf("  %s  %-20s  %s\n", sib.run.ShortID(), sib.run.FanoutSpec, sib.run.Status)
+			}
+			fmt.Printf("\nCompare with: orch compare %s\n", group)
+		}
+	}
+
+	if started == 0 {
+		os.Exit(ExitAgentError)
+	}
+	return nil
+}
+
+func writeFanoutResult(result *fanoutResult) error {
+	enc := json.NewEncoder(os.Stdout)
+	enc.SetIndent("", "  ")
+	return enc.Encode(result)
+}
+
+func firstNonEmpty(values ...string) string {
+	for _, v := range values {
+		if v != "" {
+			return v
+		}
+	}
+	return ""
+}
+
+// startFanoutRun starts a recorded sibling the way the daemon starts a
+// queued run, so that siblings boot side by side and one failing does not
+// stop the others
+var startFanoutRun = func(st store.Store, run *model.Run) error {
+	executable, err := os.Executable()
+	if err != nil {
+		return err
+	}
+	args := []string{"run", run.IssueID, "--run-id", run.RunID, "--start-queued", "--quiet", "--vault", st.VaultPath()}
+	if globalOpts.Backend != "" {
+		args = append(args, "--backend", globalOpts.Backend)
+	}
+	out, err := exec.Command(executable, args...).CombinedOutput()
+	if err != nil {
+		if msg := strings.TrimSpace(string(out)); msg != "" {
+			return fmt.Errorf("%s", msg)
+		}
+		return err
+	}
+	return nil
+}
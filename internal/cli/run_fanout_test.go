@@ -0,0 +1,161 @@
+package cli
+
+import (
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/s22625/orch/internal/config"
+	"github.com/s22625/orch/internal/model"
+	"github.com/s22625/orch/internal/store"
+)
+
+func TestParseFanout(t *testing.T) {
+	presets := []config.OpenCodePreset{{Name: "opus:high", Model: "anthropic/claude-opus-4-5", Variant: "high"}}
+
+	specs, err := parseFanout("claude, codex,opencode:opus:high,claude", presets)
+	if err != nil {
+		t.Fatalf("parseFanout: %v", err)
+	}
+	want := []fanoutSpec{
+		{Spec: "claude", Agent: "claude", Suffix: "claude"},
+		{Spec: "codex", Agent: "codex", Suffix: "codex"},
+		{Spec: "opencode:opus:high", Agent: "opencode", Model: "anthropic/claude-opus-4-5", ModelVariant: "high", Suffix: "opencode-opus-high"},
+		{Spec: "claude", Agent: "claude", Suffix: "claude-2"},
+	}
+	if len(specs) != len(want) {
+		t.Fatalf("specs = %+v", specs)
+	}
+	for i := range want {
+		if specs[i] != want[i] {
+			t.Errorf("spec %d = %+v, want %+v", i, specs[i], want[i])
+		}
+	}
+
+	if _, err := parseFanout("claude", nil); err == nil {
+		t.Error("a single agent should be rejected")
+	}
+	if _, err := parseFanout("claude,nosuchagent", nil); err == nil {
+		t.Error("an unknown agent should be rejected")
+	}
+}
+
+// useFanoutRepo runs the test in a repo without config and a file vault
+func useFanoutRepo(t *testing.T) string {
+	t.Helper()
+	resetGlobalOpts(t)
+	temp := t.TempDir()
+	t.Setenv("HOME", filepath.Join(temp, "home"))
+	repo := filepath.Join(temp, "repo")
+	if err := os.MkdirAll(repo, 0755); err != nil {
+		t.Fatal(err)
+	}
+	cwd, _ := os.Getwd()
+	if err := os.Chdir(repo); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { _ = os.Chdir(cwd) })
+
+	globalOpts.VaultPath = filepath.Join(temp, "vault")
+	globalOpts.Backend = "file"
+	globalOpts.Quiet = true
+	writeIssue(t, globalOpts.VaultPath, "issue-1")
+	return repo
+}
+
+func TestRunFanout(t *testing.T) {
+	repo := useFanoutRepo(t)
+	initGitRepo(t, repo)
+
+	var mu sync.Mutex
+	var startedRuns []string
+	orig := startFanoutRun
+	startFanoutRun = func(st store.Store, run *model.Run) error {
+		// Worktrees are created one by one before any agent starts
+		if _, err := os.Stat(run.WorktreePath); run.WorktreePath == "" || err != nil {
+			t.Errorf("%s: started without a worktree (%q)", run.RunID, run.WorktreePath)
+		}
+		if run.Branch != model.GenerateBranchName("issue-1", run.RunID) || run.BaseBranch == "" {
+			t.Errorf("%s: branch %q, base %q", run.RunID, run.Branch, run.BaseBranch)
+		}
+		mu.Lock()
+		startedRuns = append(startedRuns, run.RunID)
+		mu.Unlock()
+		return st.AppendEvent(run.Ref(), model.NewStatusEvent(model.StatusRunning))
+	}
+	t.Cleanup(func() { startFanoutRun = orig })
+
+	opts := &runOptions{
+		Fanout:   "claude,codex",
+		RunID:    "20250101-090000",
+		RepoRoot: repo,
+		Tmux:     true,
+		Budget:   5,
+	}
+	if err := runRun("issue-1", opts); err != nil {
+		t.Fatalf("runRun: %v", err)
+	}
+
+	st, err := getStore()
+	if err != nil {
+		t.Fatal(err)
+	}
+	group := model.GenerateFanoutGroup("issue-1", "20250101-090000")
+	_, runs, err := resolveFanout(st, group)
+	if err != nil {
+		t.Fatalf("resolveFanout: %v", err)
+	}
+	if len(runs) != 2 {
+		t.Fatalf("got %d runs in the group", len(runs))
+	}
+	for i, agentName := range []string{"claude", "codex"} {
+		run := runs[i]
+		if run.RunID != "20250101-090000-"+agentName || run.Agent != agentName || run.FanoutSpec != agentName {
+			t.Errorf("run %d = %s agent %s spec %s", i, run.RunID, run.Agent, run.FanoutSpec)
+		}
+		if run.Status != model.StatusRunning || run.Budget != 5 {
+			t.Errorf("%s: status %s, budget %v", run.RunID, run.Status, run.Budget)
+		}
+		if branch := run.State().Artifacts[model.QueueArtifact][queueAttrBranch]; branch != model.GenerateBranchName("issue-1", run.RunID) {
+			t.Errorf("%s: queued branch %q", run.RunID, branch)
+		}
+	}
+	sort.Strings(startedRuns)
+	if strings.Join(startedRuns, ",") != "20250101-090000-claude,20250101-090000-codex" {
+		t.Errorf("started %v", startedRuns)
+	}
+
+	// A member's ref finds the group too
+	if g, _, err := resolveFanout(st, "issue-1#20250101-090000-codex"); err != nil || g != group {
+		t.Errorf("resolveFanout by run = %q, %v", g, err)
+	}
+}
+
+func TestRunFanoutQueue(t *testing.T) {
+	repo := useFanoutRepo(t)
+
+	orig := startFanoutRun
+	startFanoutRun = func(store.Store, *model.Run) error {
+		t.Error("queued fanout runs should be left to the daemon")
+		return nil
+	}
+	t.Cleanup(func() { startFanoutRun = orig })
+
+	opts := &runOptions{Fanout: "claude,gemini", RunID: "20250101-090000", RepoRoot: repo, Tmux: true, Queue: true, Priority: 2}
+	if err := runRun("issue-1", opts); err != nil {
+		t.Fatalf("runRun: %v", err)
+	}
+	st, _ := getStore()
+	_, runs, err := resolveFanout(st, "issue-1#20250101-090000-gemini")
+	if err != nil || len(runs) != 2 {
+		t.Fatalf("resolveFanout = %d runs, %v", len(runs), err)
+	}
+	for _, run := range runs {
+		if run.Status != model.StatusQueued || !run.Queued || run.Priority != 2 {
+			t.Errorf("%s: status %s, queued %v, priority %d", run.RunID, run.Status, run.Queued, run.Priority)
+		}
+	}
+}
@@ -0,0 +1,139 @@
+package cli
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"github.com/s22625/orch/internal/model"
+	"github.com/s22625/orch/internal/store"
+)
+
+// Launch options kept in the queue artifact, resolved (config defaults
+// applied) at queue time. Agent, model and variant are in the run metadata.
+const (
+	queueAttrAgentCmd       = "agent_cmd"
+	queueAttrProfile        = "profile"
+	queueAttrBaseBranch     = "base_branch"
+	queueAttrBranch         = "branch"
+	queueAttrWorktreeDir    = "worktree_dir"
+	queueAttrTmux           = "tmux"
+	queueAttrTmuxSession    = "tmux_session"
+	queueAttrNoPR           = "no_pr"
+	queueAttrPromptTemplate = "prompt_template"
+	queueAttrPRTarget       = "pr_target_branch"
+	queueAttrStreamJSON     = "stream_json"
+)
+
+func queueOptions(opts *runOptions, result *runResult) map[string]string {
+	attrs := map[string]string{
+		queueAttrAgentCmd:       opts.AgentCmd,
+		queueAttrProfile:        opts.AgentProfile,
+		queueAttrBaseBranch:     opts.BaseBranch,
+		queueAttrBranch:         result.Branch,
+		queueAttrWorktreeDir:    opts.WorktreeDir,
+		queueAttrTmuxSession:    result.TmuxSession,
+		queueAttrPromptTemplate: opts.PromptTemplate,
+		queueAttrPRTarget:       opts.PRTargetBranch,
+	}
+	if !opts.Tmux {
+		attrs[queueAttrTmux] = "false"
+	}
+	if opts.NoPR {
+		attrs[queueAttrNoPR] = "true"
+	}
+	if opts.StreamJSON {
+		attrs[queueAttrStreamJSON] = "true"
+	}
+	return attrs
+}
+
+// applyQueueOptions restores the launch options of a queued run
+func applyQueueOptions(opts *runOptions, run *model.Run) {
+	attrs := run.State().Artifacts[model.QueueArtifact]
+	opts.Agent = run.Agent
+	opts.Model = run.Model
+	opts.ModelVariant = run.ModelVariant
+	opts.AgentCmd = attrs[queueAttrAgentCmd]
+	opts.AgentProfile = attrs[queueAttrProfile]
+	opts.BaseBranch = attrs[queueAttrBaseBranch]
+	opts.Branch = attrs[queueAttrBranch]
+	opts.WorktreeDir = attrs[queueAttrWorktreeDir]
+	opts.Tmux = attrs[queueAttrTmux] != "false"
+	opts.TmuxSession = attrs[queueAttrTmuxSession]
+	opts.NoPR = attrs[queueAttrNoPR] == "true"
+	opts.PromptTemplate = attrs[queueAttrPromptTemplate]
+	opts.PRTargetBranch = attrs[queueAttrPRTarget]
+	opts.StreamJSON = attrs[queueAttrStreamJSON] == "true"
+}
+
+// queueRun leaves a created run queued for the daemon to start
+func queueRun(st store.Store, run *model.Run, repoRoot string, result *runResult, opts *runOptions) error {
+	event := model.NewQueueArtifactEvent(opts.Priority, repoRoot, queueOptions(opts, result))
+	if err := st.AppendEvent(run.Ref(), event); err != nil {
+		return exitWithCode(err, ExitInternalError)
+	}
+
+	if globalOpts.JSON {
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		return enc.Encode(result)
+	}
+	if !globalOpts.Quiet {
+		fmt.Printf("Run queued: %s#%s", run.IssueID, run.RunID)
+		if opts.Priority != 0 {
+			fmt.Printf(" (priority %d)", opts.Priority)
+		}
+		fmt.Println()
+		fmt.Printf("  Branch:   %s\n", result.Branch)
+		fmt.Printf("\nThe daemon starts it when max_concurrent_runs allows; check with: orch ps --status queued\n")
+	}
+	return nil
+}
+
+// runStartQueued starts a queued run. The daemon runs it after claiming the
+// run (status booting); it can also be run by hand for a run still queued.
+func runStartQueued(st store.Store, issueID string, opts *runOptions) error {
+	if opts.RunID == "" {
+		return exitWithCode(fmt.Errorf("--start-queued requires --run-id"), ExitInternalError)
+	}
+	ref := &model.RunRef{IssueID: issueID, RunID: opts.RunID}
+	run, err := st.GetRun(ref)
+	if err != nil {
+		return exitWithCode(fmt.Errorf("run not found: %s", ref), ExitRunNotFound)
+	}
+	if !run.Queued {
+		return exitWithCode(fmt.Errorf("run %s was not queued", ref), ExitInternalError)
+	}
+	if run.Status != model.StatusQueued && run.Status != model.StatusBooting {
+		return exitWithCode(fmt.Errorf("run %s is already %s", ref, run.Status), ExitInternalError)
+	}
+	issue, err := st.ResolveIssue(issueID)
+	if err != nil {
+		return exitWithCode(fmt.Errorf("issue not found: %s", issueID), ExitIssueNotFound)
+	}
+	if run.Status == model.StatusQueued {
+		// Started by hand: claim it so the daemon does not start it too
+		event := model.NewStatusEvent(model.StatusBooting)
+		event.Attrs["reason"] = model.DequeuedReason
+		if err := st.AppendEventIf(ref, model.StatusQueued, event); err != nil {
+			return exitWithCode(fmt.Errorf("failed to claim run %s: %w", ref, err), ExitInternalError)
+		}
+	}
+
+	applyQueueOptions(opts, run)
+	if err := applyPromptConfigDefaults(opts); err != nil {
+		return exitWithCode(err, ExitInternalError)
+	}
+
+	result := &runResult{
+		OK:          true,
+		IssueID:     issueID,
+		RunID:       run.RunID,
+		RunPath:     run.Path,
+		Branch:      opts.Branch,
+		TmuxSession: opts.TmuxSession,
+		Status:      string(run.Status),
+	}
+	return launchRun(st, issue, run, run.Repo, result, opts)
+}
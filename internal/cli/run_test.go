@@ -6,8 +6,11 @@ import (
 	"path/filepath"
 	"strings"
 	"testing"
+	"time"
 
+	"github.com/s22625/orch/internal/agent"
 	"github.com/s22625/orch/internal/model"
+	"github.com/s22625/orch/internal/terminal"
 )
 
 func TestBuildAgentPromptDefault(t *testing.T) {
@@ -99,7 +102,7 @@ func TestApplyPromptConfigDefaults(t *testing.T) {
 		t.Fatalf("write template: %v", err)
 	}
 
-	configData := fmt.Sprintf("prompt_template: %s\npr_target_branch: develop\nno_pr: true\n", tmplPath)
+	configData := fmt.Sprintf("prompt_template: %s\npr_target_branch: develop\nno_pr: true\ntimeouts:\n  run: 2h\n  stall: 20m\n", tmplPath)
 	if err := os.WriteFile(filepath.Join(repo, ".orch", "config.yaml"), []byte(configData), 0644); err != nil {
 		t.Fatalf("write config: %v", err)
 	}
@@ -128,8 +131,11 @@ func TestApplyPromptConfigDefaults(t *testing.T) {
 	if opts.PRTargetBranch != "develop" {
 		t.Fatalf("PRTargetBranch = %q, want develop", opts.PRTargetBranch)
 	}
+	if opts.Timeout != 2*time.Hour || opts.StallTimeout != 20*time.Minute {
+		t.Fatalf("timeouts = %v / %v, want 2h / 20m", opts.Timeout, opts.StallTimeout)
+	}
 
-	opts2 := &runOptions{PromptTemplate: "explicit", NoPR: true, PRTargetBranch: "release"}
+	opts2 := &runOptions{PromptTemplate: "explicit", NoPR: true, PRTargetBranch: "release", StallTimeout: 5 * time.Minute}
 	if err := applyPromptConfigDefaults(opts2); err != nil {
 		t.Fatalf("applyPromptConfigDefaults explicit: %v", err)
 	}
@@ -142,6 +148,9 @@ func TestApplyPromptConfigDefaults(t *testing.T) {
 	if !opts2.NoPR {
 		t.Fatalf("NoPR override = false, want true")
 	}
+	if opts2.StallTimeout != 5*time.Minute {
+		t.Fatalf("StallTimeout override = %v", opts2.StallTimeout)
+	}
 }
 
 func TestApplyConfigDefaultsBaseBranch(t *testing.T) {
@@ -261,3 +270,77 @@ func TestApplyConfigDefaultsFallbacks(t *testing.T) {
 		t.Fatalf("WorktreeDir fallback = %q, want %q", opts.WorktreeDir, wantWorktreeDir)
 	}
 }
+
+func TestQueueOptionsRoundTrip(t *testing.T) {
+	opts := &runOptions{
+		Agent:          "codex",
+		AgentProfile:   "work",
+		BaseBranch:     "develop",
+		WorktreeDir:    "/home/dev/.orch/worktrees",
+		Tmux:           true,
+		NoPR:           true,
+		PromptTemplate: "/home/dev/prompt.md",
+		PRTargetBranch: "release",
+		StreamJSON:     true,
+	}
+	result := &runResult{Branch: "issue/orch-1/run-20240101-000000", TmuxSession: "run-orch-1-20240101-000000"}
+
+	run := &model.Run{IssueID: "orch-1", RunID: "20240101-000000", Agent: "codex"}
+	run.ApplyEvents(model.NewQueueArtifactEvent(3, "/src/orch", queueOptions(opts, result)))
+
+	restored := &runOptions{}
+	applyQueueOptions(restored, run)
+	want := *opts
+	want.Branch = result.Branch
+	want.TmuxSession = result.TmuxSession
+	if *restored != want {
+		t.Errorf("restored options =\n%+v\nwant\n%+v", *restored, want)
+	}
+	if run.Priority != 3 || run.Repo != "/src/orch" {
+		t.Errorf("priority %d, repo %q", run.Priority, run.Repo)
+	}
+}
+
+func TestRunTmuxInjectionWaitsForReadyPattern(t *testing.T) {
+	repo := useFanoutRepo(t)
+	initGitRepo(t, repo)
+
+	fake := terminal.NewFake("fake-ready-run")
+	terminal.Register(fake)
+	if err := os.MkdirAll(filepath.Join(repo, ".orch"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	cfg := "terminal: fake-ready-run\nagents:\n  tool:\n    command: sh\n    injection: tmux\n    ready_pattern: '\\$ $'\n"
+	if err := os.WriteFile(filepath.Join(repo, ".orch", "config.yaml"), []byte(cfg), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := agent.LoadConfigured(); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { _ = agent.Configure(nil) })
+
+	// The agent prints its prompt a moment after the session starts
+	session := model.GenerateTmuxSession("issue-1", "20250101-090000")
+	go func() {
+		for i := 0; i < 100 && !fake.HasSession(session); i++ {
+			time.Sleep(10 * time.Millisecond)
+		}
+		fake.SetOutput(session, "starting\n$ ")
+	}()
+
+	opts := &runOptions{Agent: "tool", RunID: "20250101-090000", RepoRoot: repo, Tmux: true}
+	if err := runRun("issue-1", opts); err != nil {
+		t.Fatalf("runRun: %v", err)
+	}
+	st, _ := getStore()
+	run, err := st.GetRun(&model.RunRef{IssueID: "issue-1", RunID: "20250101-090000"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if run.Status != model.StatusRunning {
+		t.Errorf("status = %s, want running", run.Status)
+	}
+	if sent := fake.Session(session).Sent; len(sent) != 1 || !strings.Contains(sent[0], promptFileName) {
+		t.Errorf("sent = %q, want the prompt once the pattern matched", sent)
+	}
+}
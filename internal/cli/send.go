@@ -10,6 +10,8 @@ import (
 
 	"github.com/s22625/orch/internal/agent"
 	"github.com/s22625/orch/internal/daemon"
+	"github.com/s22625/orch/internal/model"
+	"github.com/s22625/orch/internal/store"
 	"github.com/spf13/cobra"
 )
 
@@ -74,7 +76,7 @@ func runSend(refStr, message string, opts *sendOptions) error {
 	isOpenCode := run.Agent == string(agent.AgentOpenCode)
 
 	if isOpenCode && daemon.IsDaemonSocketAvailable(st.VaultPath()) {
-		err = daemon.SendViaDaemon(st.VaultPath(), run, message, opts.NoEnter)
+		err = sendViaDaemon(st, run, message, opts.NoEnter)
 		if err != nil {
 			if globalOpts.JSON {
 				result := map[string]interface{}{
@@ -140,3 +142,14 @@ func runSend(refStr, message string, opts *sendOptions) error {
 
 	return nil
 }
+
+// sendViaDaemon sends through the control API, or the legacy send request
+// when the daemon predates it
+func sendViaDaemon(st store.Store, run *model.Run, message string, noEnter bool) error {
+	client := dialDaemon(st)
+	if client == nil {
+		return daemon.SendViaDaemon(st.VaultPath(), run, message, noEnter)
+	}
+	defer client.Close()
+	return client.Send(context.Background(), run.Ref().String(), message, noEnter)
+}
@@ -0,0 +1,112 @@
+package cli
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"net"
+	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/s22625/orch/internal/config"
+	"github.com/s22625/orch/internal/server"
+	"github.com/spf13/cobra"
+)
+
+type serveOptions struct {
+	Listen string
+}
+
+func newServeCmd() *cobra.Command {
+	opts := &serveOptions{}
+
+	cmd := &cobra.Command{
+		Use:   "serve",
+		Short: "Serve the HTTP API for remote dashboards",
+		Long: `Serve runs, issues, events and captures as JSON over HTTP, with live
+run events as Server-Sent Events.
+
+Read endpoints are open to anyone who can reach the address. Mutating
+endpoints (start, stop, send) require the serve.token from config as a
+bearer token and are disabled when no token is set. Actions go through
+the daemon, like the CLI commands.
+
+Endpoints:
+  GET  /api/issues               GET  /api/runs?issue=&status=&limit=&since=
+  GET  /api/issues/ID            GET  /api/runs/REF
+  GET  /api/runs/REF/capture     GET  /api/runs/REF/events   (SSE)
+  GET  /api/events?issue=&run=&types=                        (SSE)
+  POST /api/runs                 POST /api/runs/REF/stop
+  POST /api/runs/REF/send`,
+		Example: `  orch serve
+  orch serve --listen 0.0.0.0:7777
+  curl -N localhost:7777/api/runs/a1b2c3/events
+  curl -X POST -H "Authorization: Bearer $TOKEN" localhost:7777/api/runs/a1b2c3/stop`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runServe(opts)
+		},
+	}
+
+	cmd.Flags().StringVar(&opts.Listen, "listen", "", "Address to listen on (default serve.listen or "+server.DefaultListen+")")
+
+	return cmd
+}
+
+func runServe(opts *serveOptions) error {
+	st, err := getStore()
+	if err != nil {
+		return err
+	}
+
+	cfg, err := config.Load()
+	if err != nil {
+		return fmt.Errorf("failed to load config: %w", err)
+	}
+	listen := opts.Listen
+	if listen == "" {
+		listen = cfg.Serve.Listen
+	}
+	if listen == "" {
+		listen = server.DefaultListen
+	}
+
+	dir, _ := os.Getwd()
+	logger := log.New(os.Stderr, "", log.LstdFlags)
+	actions := &server.DaemonActions{VaultPath: st.VaultPath(), Dir: dir}
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	httpServer := &http.Server{
+		Handler:           server.New(st, actions, cfg.Serve.Token, logger),
+		ReadHeaderTimeout: 10 * time.Second,
+		// Ends event streams on shutdown
+		BaseContext: func(net.Listener) context.Context { return ctx },
+	}
+
+	ln, err := net.Listen("tcp", listen)
+	if err != nil {
+		return err
+	}
+	if !globalOpts.Quiet {
+		fmt.Fprintf(os.Stderr, "serving %s on http://%s\n", st.VaultPath(), ln.Addr())
+		if cfg.Serve.Token == "" {
+			fmt.Fprintln(os.Stderr, "serve.token is not set: start/stop/send are disabled")
+		}
+	}
+
+	go func() {
+		<-ctx.Done()
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		httpServer.Shutdown(shutdownCtx)
+	}()
+
+	if err := httpServer.Serve(ln); err != nil && !errors.Is(err, http.ErrServerClosed) {
+		return err
+	}
+	return nil
+}
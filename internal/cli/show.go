@@ -75,6 +75,8 @@ func showJSON(run *model.Run, opts *showOptions) error {
 		WorktreePath  string        `json:"worktree_path,omitempty"`
 		TmuxSession   string        `json:"tmux_session,omitempty"`
 		PRUrl         string        `json:"pr_url,omitempty"`
+		Usage         *model.Usage  `json:"usage,omitempty"`
+		Budget        float64       `json:"budget,omitempty"`
 		Events        []eventOutput `json:"events,omitempty"`
 	}{
 		OK:            true,
@@ -87,6 +89,10 @@ func showJSON(run *model.Run, opts *showOptions) error {
 		WorktreePath:  run.WorktreePath,
 		TmuxSession:   run.TmuxSession,
 		PRUrl:         run.PRUrl,
+		Budget:        run.Budget,
+	}
+	if !run.Usage.IsZero() {
+		output.Usage = &run.Usage
 	}
 
 	// Add events (tail)
@@ -133,6 +139,13 @@ func showHuman(run *model.Run, opts *showOptions) error {
 		if run.PRUrl != "" {
 			fmt.Printf("PR:       %s\n", run.PRUrl)
 		}
+		if !run.Usage.IsZero() || run.Budget > 0 {
+			fmt.Printf("Cost:     %s (%d tokens)", model.FormatCost(run.Usage.Cost), run.Usage.TotalTokens())
+			if run.Budget > 0 {
+				fmt.Printf(" of $%.2f budget", run.Budget)
+			}
+			fmt.Println()
+		}
 		fmt.Println()
 	}
 
@@ -1,13 +1,14 @@
 package cli
 
 import (
+	"context"
 	"fmt"
 	"os"
-	"time"
 
+	"github.com/s22625/orch/internal/agent"
 	"github.com/s22625/orch/internal/model"
+	"github.com/s22625/orch/internal/rpc"
 	"github.com/s22625/orch/internal/store"
-	"github.com/s22625/orch/internal/tmux"
 	"github.com/spf13/cobra"
 )
 
@@ -147,46 +148,50 @@ func runStopAll(opts *stopOptions) error {
 	return nil
 }
 
-func stopRun(st interface {
-	AppendEvent(ref *model.RunRef, event *model.Event) error
-}, run *model.Run, opts *stopOptions) error {
-	// Skip if already terminal
-	if run.Status == model.StatusDone || run.Status == model.StatusFailed || run.Status == model.StatusCanceled {
-		if !globalOpts.Quiet {
-			fmt.Printf("%s#%s already %s\n", run.IssueID, run.RunID, run.Status)
-		}
-		return nil
+func stopRun(st store.Store, run *model.Run, opts *stopOptions) error {
+	// Let the daemon stop it when it is up, so the monitor sees the change
+	// through its own store
+	if client := dialDaemon(st); client != nil {
+		defer client.Close()
+		return stopRunViaDaemon(client, run)
 	}
 
-	sessionName := run.TmuxSession
-	if sessionName == "" {
-		sessionName = model.GenerateTmuxSession(run.IssueID, run.RunID)
+	result, err := agent.StopRun(st, run)
+	if err != nil {
+		return err
 	}
-
-	// Kill tmux session if it exists
-	if tmux.HasSession(sessionName) {
-		if err := tmux.KillSession(sessionName); err != nil {
-			fmt.Fprintf(os.Stderr, "warning: failed to kill session %s: %v\n", sessionName, err)
-		} else if !globalOpts.Quiet {
-			fmt.Printf("killed session: %s\n", sessionName)
-		}
+	if result.KillErr != nil {
+		fmt.Fprintf(os.Stderr, "warning: failed to kill session %s: %v\n", result.KilledSession, result.KillErr)
 	}
-
-	// Append canceled event
-	ref := &model.RunRef{IssueID: run.IssueID, RunID: run.RunID}
-	event := &model.Event{
-		Timestamp: time.Now(),
-		Type:      "status",
-		Name:      string(model.StatusCanceled),
+	if globalOpts.Quiet {
+		return nil
 	}
-
-	if err := st.AppendEvent(ref, event); err != nil {
-		return fmt.Errorf("failed to append canceled event: %w", err)
+	if result.AlreadyDone {
+		fmt.Printf("%s#%s already %s\n", run.IssueID, run.RunID, result.Status)
+		return nil
 	}
-
-	if !globalOpts.Quiet {
-		fmt.Printf("stopped: %s#%s\n", run.IssueID, run.RunID)
+	if result.KilledSession != "" && result.KillErr == nil {
+		fmt.Printf("killed session: %s\n", result.KilledSession)
 	}
+	fmt.Printf("stopped: %s#%s\n", run.IssueID, run.RunID)
+	return nil
+}
 
+func stopRunViaDaemon(client *rpc.Client, run *model.Run) error {
+	result, err := client.StopRun(context.Background(), run.Ref().String())
+	if err != nil {
+		return err
+	}
+	if globalOpts.Quiet {
+		return nil
+	}
+	if result.AlreadyDone {
+		fmt.Printf("%s#%s already %s\n", run.IssueID, run.RunID, result.Status)
+		return nil
+	}
+	if result.KilledSession != "" {
+		fmt.Printf("killed session: %s\n", result.KilledSession)
+	}
+	fmt.Printf("stopped: %s#%s\n", run.IssueID, run.RunID)
 	return nil
 }
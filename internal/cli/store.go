@@ -0,0 +1,167 @@
+package cli
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"github.com/s22625/orch/internal/store/file"
+	"github.com/s22625/orch/internal/store/sqlite"
+	"github.com/spf13/cobra"
+)
+
+type storeMigrateOptions struct {
+	Path string
+}
+
+func newStoreCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "store",
+		Short: "Manage store backends",
+		Long: `Move issues, runs and events between store backends.
+
+The sqlite backend keeps an indexed database at $VAULT/.orch/orch.db.
+Select it with --backend sqlite, ORCH_BACKEND=sqlite, or "backend: sqlite"
+in .orch/config.yaml.`,
+	}
+
+	cmd.AddCommand(newStoreImportCmd())
+	cmd.AddCommand(newStoreExportCmd())
+
+	return cmd
+}
+
+func newStoreImportCmd() *cobra.Command {
+	opts := &storeMigrateOptions{}
+
+	cmd := &cobra.Command{
+		Use:   "import",
+		Short: "Import a markdown vault into the sqlite database",
+		Long: `Import issues, runs and events from a markdown vault into the sqlite database.
+
+Runs already present in the database are skipped, so import can be re-run.
+
+Examples:
+  orch store import
+  orch store import --from ~/old-vault`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runStoreImport(opts)
+		},
+	}
+
+	cmd.Flags().StringVar(&opts.Path, "from", "", "Markdown vault to import (default: the current vault)")
+
+	return cmd
+}
+
+func newStoreExportCmd() *cobra.Command {
+	opts := &storeMigrateOptions{}
+
+	cmd := &cobra.Command{
+		Use:   "export",
+		Short: "Export the sqlite database to a markdown vault",
+		Long: `Write every issue and run in the sqlite database as markdown documents.
+
+Existing documents with the same IDs are overwritten.
+
+Examples:
+  orch store export
+  orch store export --to ~/vault-copy`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runStoreExport(opts)
+		},
+	}
+
+	cmd.Flags().StringVar(&opts.Path, "to", "", "Markdown vault to write (default: the current vault)")
+
+	return cmd
+}
+
+func runStoreImport(opts *storeMigrateOptions) error {
+	vaultPath, err := getVaultPath()
+	if err != nil {
+		return err
+	}
+
+	srcPath := vaultPath
+	if opts.Path != "" {
+		srcPath = opts.Path
+	}
+	src, err := file.New(srcPath)
+	if err != nil {
+		return err
+	}
+
+	dst, err := sqlite.New(vaultPath)
+	if err != nil {
+		return err
+	}
+	defer dst.Close()
+
+	stats, err := dst.Import(src)
+	if err != nil {
+		return fmt.Errorf("import failed: %w", err)
+	}
+
+	return outputStoreStats("imported", src.VaultPath(), dst.DBPath(), stats)
+}
+
+func runStoreExport(opts *storeMigrateOptions) error {
+	vaultPath, err := getVaultPath()
+	if err != nil {
+		return err
+	}
+
+	src, err := sqlite.New(vaultPath)
+	if err != nil {
+		return err
+	}
+	defer src.Close()
+
+	dstPath := vaultPath
+	if opts.Path != "" {
+		dstPath = opts.Path
+		if err := os.MkdirAll(dstPath, 0755); err != nil {
+			return fmt.Errorf("failed to create export directory: %w", err)
+		}
+	}
+
+	stats, err := src.Export(dstPath)
+	if err != nil {
+		return fmt.Errorf("export failed: %w", err)
+	}
+
+	return outputStoreStats("exported", src.DBPath(), dstPath, stats)
+}
+
+func outputStoreStats(action, from, to string, stats *sqlite.MigrationStats) error {
+	if globalOpts.JSON {
+		output := struct {
+			OK   bool   `json:"ok"`
+			From string `json:"from"`
+			To   string `json:"to"`
+			*sqlite.MigrationStats
+		}{
+			OK:             true,
+			From:           from,
+			To:             to,
+			MigrationStats: stats,
+		}
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		return enc.Encode(output)
+	}
+
+	if !globalOpts.Quiet {
+		fmt.Printf("%s %d issues, %d runs, %d events\n", action, stats.Issues, stats.Runs, stats.Events)
+		if stats.Skipped > 0 {
+			fmt.Printf("  skipped %d runs already present\n", stats.Skipped)
+		}
+		fmt.Printf("  from: %s\n", from)
+		fmt.Printf("  to:   %s\n", to)
+	}
+
+	return nil
+}
@@ -0,0 +1,93 @@
+package cli
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"time"
+
+	"github.com/s22625/orch/internal/agent"
+	"github.com/spf13/cobra"
+)
+
+type streamViewOptions struct {
+	Follow bool
+}
+
+func newStreamViewCmd() *cobra.Command {
+	opts := &streamViewOptions{}
+
+	cmd := &cobra.Command{
+		Use:   "stream-view <RUN_REF|->",
+		Short: "Render the stream-json log of a structured-output run",
+		Long: `Render the NDJSON log of a run started with --stream-json as a readable
+transcript: assistant messages, tool calls and their results, and the final result.
+
+With "-" the log is read from stdin; this is what the tmux pane of a
+structured-output run shows.
+
+Examples:
+  orch stream-view orch-023
+  orch stream-view orch-023 --follow`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runStreamView(args[0], opts)
+		},
+	}
+
+	cmd.Flags().BoolVarP(&opts.Follow, "follow", "f", false, "Keep rendering records as they are appended")
+
+	return cmd
+}
+
+// streamFollowInterval is how often --follow checks the log for new records
+const streamFollowInterval = 500 * time.Millisecond
+
+func runStreamView(refStr string, opts *streamViewOptions) error {
+	if refStr == "-" {
+		return renderStreamReader(os.Stdin, os.Stdout)
+	}
+
+	st, err := getStore()
+	if err != nil {
+		return exitWithCode(err, ExitInternalError)
+	}
+	run, err := resolveRun(st, refStr)
+	if err != nil {
+		return exitWithCode(err, ExitRunNotFound)
+	}
+	if run.StreamLog == "" {
+		return exitWithCode(fmt.Errorf("run %s was not started with --stream-json", run.Ref()), ExitInternalError)
+	}
+
+	tail := agent.NewStreamTail(run.StreamLog)
+	for {
+		records, err := tail.Next()
+		if err != nil {
+			return exitWithCode(err, ExitInternalError)
+		}
+		agent.RenderStream(os.Stdout, records)
+		if !opts.Follow {
+			return nil
+		}
+		time.Sleep(streamFollowInterval)
+	}
+}
+
+// renderStreamReader renders records as they arrive on r
+func renderStreamReader(r io.Reader, w io.Writer) error {
+	reader := bufio.NewReader(r)
+	for {
+		line, err := reader.ReadBytes('\n')
+		if rec, ok := agent.ParseStreamLine(line); ok {
+			agent.RenderStreamRecord(w, rec)
+		}
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+	}
+}
@@ -0,0 +1,84 @@
+package cli
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/s22625/orch/internal/config"
+	"github.com/s22625/orch/internal/daemon"
+	"github.com/s22625/orch/internal/model"
+	"github.com/s22625/orch/internal/rpc"
+	"github.com/s22625/orch/internal/store"
+	"github.com/s22625/orch/internal/terminal"
+	"github.com/s22625/orch/internal/transcript"
+)
+
+// registerPTYClient makes the pty backend reach the sessions hosted by the
+// vault's daemon. The daemon replaces it with the host itself.
+func registerPTYClient() {
+	vaultPath, err := getVaultPath()
+	if err != nil {
+		return
+	}
+	terminal.Register(terminal.NewPTYClient(rpc.SocketPath(vaultPath)))
+}
+
+// sessionTerminal returns the backend new sessions start in (terminal: in
+// config, tmux by default)
+func sessionTerminal() (terminal.Backend, error) {
+	name := ""
+	if cfg, err := config.Load(); err == nil {
+		name = cfg.Terminal
+	}
+	term, err := terminal.Get(name)
+	if err != nil {
+		return nil, err
+	}
+	if !term.Available() {
+		return nil, fmt.Errorf("%s is not available", term.Name())
+	}
+	return term, nil
+}
+
+// runTerminal returns the backend hosting a run's session
+func runTerminal(run *model.Run) (terminal.Backend, error) {
+	return terminal.Get(run.Terminal)
+}
+
+// newSessionArtifactEvent records the run's session and where it runs
+func newSessionArtifactEvent(term terminal.Backend, session string) *model.Event {
+	return model.NewArtifactEvent("session", map[string]string{
+		"name":    session,
+		"backend": term.Name(),
+	})
+}
+
+// recordWindow records the session's window for the monitor, for backends
+// with tmux windows
+func recordWindow(st store.Store, run *model.Run, term terminal.Backend, session string) {
+	wb, ok := term.(terminal.WindowBackend)
+	if !ok {
+		return
+	}
+	if windowID := wb.WindowID(session); windowID != "" {
+		st.AppendEvent(run.Ref(), model.NewArtifactEvent("window", map[string]string{
+			"id": windowID,
+		}))
+	}
+}
+
+// runTranscript returns where the run's session output is recorded, or nil
+// if the backend cannot record it or the transcript directory cannot be
+// created; the run then goes without a transcript
+func runTranscript(st store.Store, run *model.Run, term terminal.Backend) *transcript.Paths {
+	if !terminal.RecordsTranscript(term) {
+		return nil
+	}
+	paths := transcript.For(daemon.OrchDir(st.VaultPath()), run.IssueID, run.RunID)
+	if err := os.MkdirAll(filepath.Dir(paths.Raw), 0755); err != nil {
+		fmt.Fprintf(os.Stderr, "warning: not recording transcript: %v\n", err)
+		return nil
+	}
+	return &paths
+}
@@ -8,7 +8,7 @@ import (
 	"github.com/s22625/orch/internal/agent"
 	"github.com/s22625/orch/internal/model"
 	"github.com/s22625/orch/internal/store"
-	"github.com/s22625/orch/internal/tmux"
+	"github.com/s22625/orch/internal/terminal"
 	"github.com/spf13/cobra"
 )
 
@@ -161,6 +161,11 @@ func runTick(refStr string, opts *tickOptions) error {
 }
 
 func resumeRun(st store.Store, run *model.Run, agentType string) error {
+	// Refuse before launching anything if the run cannot go back to running
+	if err := model.ValidateTransition(run.Status, model.StatusRunning); err != nil {
+		return err
+	}
+
 	// Determine agent type
 	if agentType == "" {
 		agentType = "claude" // default
@@ -206,22 +211,36 @@ func resumeRun(st store.Store, run *model.Run, agentType string) error {
 		sessionName = model.GenerateTmuxSession(run.IssueID, run.RunID)
 	}
 
-	if tmux.HasSession(sessionName) {
+	term, err := runTerminal(run)
+	if err != nil {
+		return err
+	}
+	// The resumed agent is recorded to the run's transcript too
+	transcriptPaths := runTranscript(st, run, term)
+	sessionCfg := &terminal.SessionConfig{
+		Name:       sessionName,
+		WorkDir:    run.WorktreePath,
+		Command:    agentCmd,
+		Env:        launchCfg.Env(),
+		Transcript: transcriptPaths,
+	}
+	if tm, ok := term.(*terminal.Tmux); ok && term.HasSession(sessionName) {
 		// Create new window in existing session
-		err = tmux.NewWindow(sessionName, "resume", run.WorktreePath, agentCmd)
+		err = tm.NewWindow(sessionCfg, "resume")
 	} else {
-		// Create new session
-		err = tmux.NewSession(&tmux.SessionConfig{
-			SessionName: sessionName,
-			WorkDir:     run.WorktreePath,
-			Command:     agentCmd,
-			Env:         launchCfg.Env(),
-		})
+		// Start a new session; backends without windows replace the old one
+		if term.HasSession(sessionName) {
+			term.Kill(sessionName)
+		}
+		err = term.NewSession(sessionCfg)
 	}
 
 	if err != nil {
 		return fmt.Errorf("failed to resume agent: %w", err)
 	}
+	if transcriptPaths != nil && run.Transcript == "" {
+		st.AppendEvent(run.Ref(), newTranscriptArtifactEvent(*transcriptPaths))
+	}
 
 	// Update status
 	st.AppendEvent(run.Ref(), model.NewStatusEvent(model.StatusRunning))
@@ -0,0 +1,279 @@
+package cli
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/s22625/orch/internal/model"
+	"github.com/s22625/orch/internal/rpc"
+	"github.com/s22625/orch/internal/store"
+	"github.com/spf13/cobra"
+)
+
+type waitOptions struct {
+	For     []string
+	Timeout time.Duration
+	Any     bool
+	All     bool
+}
+
+const (
+	// waitPollInterval is how often runs are re-read without the daemon
+	waitPollInterval = 2 * time.Second
+	// waitResyncInterval re-reads runs while subscribed, in case a
+	// notification was missed
+	waitResyncInterval = 30 * time.Second
+)
+
+// defaultWaitStatuses are the statuses a run succeeds at on its own; failed
+// and canceled end the wait too, as failures
+var defaultWaitStatuses = []string{
+	string(model.StatusPROpen), string(model.StatusDone),
+}
+
+func newWaitCmd() *cobra.Command {
+	opts := &waitOptions{}
+
+	cmd := &cobra.Command{
+		Use:   "wait RUN_REF...",
+		Short: "Wait for runs to reach a status",
+		Long: `Block until the runs reach one of the --for statuses, for scripts and CI.
+
+By default it waits for every run (--all); with --any it returns as soon as
+one of them gets there. A run that fails or is canceled stops being waited
+for even if that status is not in --for, and counts as a failure unless it
+is; with --any the wait then fails only once no run can get there.
+
+Changes are followed through the daemon when it is up, and by polling the
+store otherwise.
+
+Exit codes:
+  0  the runs reached a --for status
+  8  a run ended failed or canceled without that being a --for status
+  9  --timeout expired first
+
+Examples:
+  orch run orch-1 --quiet && orch wait orch-1 --timeout 1h
+  orch wait a1b2c3 d4e5f6 --for pr_open,done --any
+  orch wait a1b2c3 d4e5f6 --for failed --any`,
+		Args: cobra.MinimumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runWait(args, opts)
+		},
+	}
+
+	cmd.Flags().StringSliceVar(&opts.For, "for", defaultWaitStatuses, "Statuses to wait for")
+	cmd.Flags().DurationVar(&opts.Timeout, "timeout", 0, "Give up after this long (e.g. 30m, 1h; 0 waits forever)")
+	cmd.Flags().BoolVar(&opts.Any, "any", false, "Return when any of the runs gets there")
+	cmd.Flags().BoolVar(&opts.All, "all", false, "Return when all of the runs get there (default)")
+	cmd.MarkFlagsMutuallyExclusive("any", "all")
+
+	return cmd
+}
+
+type waitedRun struct {
+	ID      string `json:"id"`
+	Run     string `json:"run"`
+	Status  string `json:"status"`
+	Reached bool   `json:"reached"`
+}
+
+type waitResult struct {
+	OK       bool        `json:"ok"`
+	TimedOut bool        `json:"timed_out,omitempty"`
+	Runs     []waitedRun `json:"runs"`
+}
+
+func runWait(refs []string, opts *waitOptions) error {
+	targets := make(map[model.Status]bool)
+	for _, s := range opts.For {
+		s = strings.TrimSpace(s)
+		if !model.IsValidStatus(s) {
+			return fmt.Errorf("invalid status %q in --for", s)
+		}
+		targets[model.Status(s)] = true
+	}
+	if len(targets) == 0 {
+		return fmt.Errorf("--for needs at least one status")
+	}
+
+	st, err := getStore()
+	if err != nil {
+		return err
+	}
+	runs := make([]*model.Run, 0, len(refs))
+	for _, ref := range refs {
+		run, err := resolveRun(st, ref)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "run not found: %s\n", ref)
+			os.Exit(ExitRunNotFound)
+			return err
+		}
+		runs = append(runs, run)
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	if opts.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, opts.Timeout)
+		defer cancel()
+	}
+
+	result, err := waitForRuns(ctx, st, runs, targets, opts.Any, subscribeRunChanges(ctx, st, runs))
+	if err != nil {
+		return err
+	}
+
+	if globalOpts.JSON {
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		if err := enc.Encode(result); err != nil {
+			return err
+		}
+	} else if !globalOpts.Quiet {
+		for _, run := range result.Runs {
+			fmt.Printf("%s  %s  %s\n", run.ID, run.Run, colorStatus(model.Status(run.Status)))
+		}
+	}
+	if result.TimedOut {
+		if !globalOpts.JSON {
+			fmt.Fprintf(os.Stderr, "timed out after %s\n", opts.Timeout)
+		}
+		os.Exit(ExitTimeout)
+	}
+	if !result.OK {
+		os.Exit(ExitRunFailed)
+	}
+	return nil
+}
+
+// subscribeRunChanges follows the runs' events through the daemon. It
+// returns nil when the daemon is not up, and waitForRuns then polls.
+var subscribeRunChanges = func(ctx context.Context, st store.Store, runs []*model.Run) <-chan rpc.Change {
+	client := dialDaemon(st)
+	if client == nil {
+		return nil
+	}
+	defer client.Close()
+
+	params := &rpc.SubscribeParams{Types: []string{string(store.ChangeEventAppended)}}
+	params.IssueID = runs[0].IssueID
+	for _, run := range runs[1:] {
+		if run.IssueID != params.IssueID {
+			params.IssueID = ""
+			break
+		}
+	}
+	changes, err := client.Subscribe(ctx, params)
+	if err != nil {
+		return nil
+	}
+	return changes
+}
+
+// waitForRuns re-reads the runs whenever changes reports one of them, or
+// on a timer, until they settle: reach a target status, or fail or get
+// canceled. A closed (or nil) changes channel falls back to polling.
+// The result is marked TimedOut when ctx's deadline passes first; an
+// interrupt returns ctx's error.
+func waitForRuns(ctx context.Context, st store.Store, runs []*model.Run, targets map[model.Status]bool, anyRun bool, changes <-chan rpc.Change) (*waitResult, error) {
+	interval := waitPollInterval
+	if changes != nil {
+		interval = waitResyncInterval
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	waited := make(map[string]bool, len(runs))
+	for _, run := range runs {
+		waited[run.Ref().String()] = true
+	}
+
+	for {
+		result, done, err := checkWaitedRuns(st, runs, targets, anyRun)
+		if err != nil {
+			return nil, err
+		}
+		if done {
+			return result, nil
+		}
+
+	wake:
+		for {
+			select {
+			case <-ctx.Done():
+				if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+					result.TimedOut = true
+					result.OK = false
+					return result, nil
+				}
+				return nil, ctx.Err()
+			case change, ok := <-changes:
+				if !ok {
+					// The daemon went away
+					changes = nil
+					ticker.Reset(waitPollInterval)
+					break wake
+				}
+				ref := &model.RunRef{IssueID: change.IssueID, RunID: change.RunID}
+				if waited[ref.String()] {
+					break wake
+				}
+			case <-ticker.C:
+				break wake
+			}
+		}
+	}
+}
+
+// checkWaitedRuns re-reads the runs and reports whether the wait is over
+func checkWaitedRuns(st store.Store, runs []*model.Run, targets map[model.Status]bool, anyRun bool) (*waitResult, bool, error) {
+	result := &waitResult{OK: true, Runs: make([]waitedRun, 0, len(runs))}
+	settled, succeeded, failures := 0, 0, 0
+	for i, run := range runs {
+		current, err := st.GetRun(run.Ref())
+		if err != nil {
+			return nil, false, err
+		}
+		runs[i] = current
+
+		// Failing is a failure only when it is not what was waited for
+		reached := targets[current.Status]
+		failed := !reached && (current.Status == model.StatusFailed || current.Status == model.StatusCanceled)
+		if reached || failed {
+			settled++
+			if failed {
+				failures++
+				result.OK = false
+			} else {
+				succeeded++
+			}
+		}
+		result.Runs = append(result.Runs, waitedRun{
+			ID:      current.ShortID(),
+			Run:     current.Ref().String(),
+			Status:  string(current.Status),
+			Reached: reached,
+		})
+	}
+
+	if anyRun {
+		// A failed run does not end the wait while another can still get there
+		done := succeeded > 0 || failures == len(runs)
+		result.OK = succeeded > 0
+		return result, done, nil
+	}
+	done := settled == len(runs)
+	if !done || settled == 0 {
+		result.OK = false
+	}
+	return result, done, nil
+}
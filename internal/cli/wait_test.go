@@ -0,0 +1,164 @@
+package cli
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/s22625/orch/internal/model"
+	"github.com/s22625/orch/internal/rpc"
+	"github.com/s22625/orch/internal/store"
+)
+
+func newWaitRuns(t *testing.T, runIDs ...string) (store.Store, []*model.Run) {
+	t.Helper()
+	useFanoutRepo(t)
+	st, err := getStore()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var runs []*model.Run
+	for _, runID := range runIDs {
+		run, err := st.CreateRun("issue-1", runID, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		st.AppendEvent(run.Ref(), model.NewStatusEvent(model.StatusRunning))
+		runs = append(runs, run)
+	}
+	return st, runs
+}
+
+func waitTargets(statuses ...model.Status) map[model.Status]bool {
+	targets := make(map[model.Status]bool)
+	for _, s := range statuses {
+		targets[s] = true
+	}
+	return targets
+}
+
+func TestWaitForRunsSubscription(t *testing.T) {
+	st, runs := newWaitRuns(t, "20250101-090000", "20250101-100000")
+	// Buffered: a re-read can already see the next run's status and return
+	changes := make(chan rpc.Change, len(runs))
+
+	type waited struct {
+		result *waitResult
+		err    error
+	}
+	done := make(chan waited, 1)
+	go func() {
+		result, err := waitForRuns(context.Background(), st, runs, waitTargets(model.StatusDone, model.StatusPROpen), false, changes)
+		done <- waited{result, err}
+	}()
+
+	// Polling would take seconds; each notification re-reads at once
+	for _, run := range runs {
+		status := model.StatusDone
+		if run.RunID == "20250101-100000" {
+			status = model.StatusPROpen
+		}
+		st.AppendEvent(run.Ref(), model.NewStatusEvent(status))
+		changes <- rpc.Change{Type: string(store.ChangeEventAppended), IssueID: run.IssueID, RunID: run.RunID}
+	}
+
+	select {
+	case w := <-done:
+		if w.err != nil {
+			t.Fatalf("waitForRuns: %v", w.err)
+		}
+		if !w.result.OK || len(w.result.Runs) != 2 {
+			t.Fatalf("result = %+v", w.result)
+		}
+		if r := w.result.Runs[1]; r.Status != string(model.StatusPROpen) || !r.Reached {
+			t.Errorf("second run = %+v", r)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("waitForRuns did not return after the notifications")
+	}
+}
+
+func TestWaitForRunsFailure(t *testing.T) {
+	st, runs := newWaitRuns(t, "20250101-090000", "20250101-100000")
+	st.AppendEvent(runs[0].Ref(), model.NewStatusEvent(model.StatusDone))
+	st.AppendEvent(runs[1].Ref(), model.NewStatusEvent(model.StatusFailed))
+
+	// A failed run is not waited for even though failed is not a target
+	result, err := waitForRuns(context.Background(), st, runs, waitTargets(model.StatusDone), false, nil)
+	if err != nil {
+		t.Fatalf("waitForRuns: %v", err)
+	}
+	if result.OK || result.TimedOut {
+		t.Errorf("result = %+v, want a failure", result)
+	}
+	if result.Runs[1].Reached {
+		t.Errorf("failed run marked reached: %+v", result.Runs[1])
+	}
+}
+
+func TestWaitForRunsTimeout(t *testing.T) {
+	st, runs := newWaitRuns(t, "20250101-090000", "20250101-100000")
+	st.AppendEvent(runs[0].Ref(), model.NewStatusEvent(model.StatusDone))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	result, err := waitForRuns(ctx, st, runs, waitTargets(model.StatusDone), false, nil)
+	if err != nil {
+		t.Fatalf("waitForRuns: %v", err)
+	}
+	if !result.TimedOut || result.OK {
+		t.Errorf("result = %+v, want timed out", result)
+	}
+
+	// With --any the finished run is enough
+	result, err = waitForRuns(context.Background(), st, runs, waitTargets(model.StatusDone), true, nil)
+	if err != nil {
+		t.Fatalf("waitForRuns --any: %v", err)
+	}
+	if !result.OK || !result.Runs[0].Reached || result.Runs[1].Reached {
+		t.Errorf("--any result = %+v", result)
+	}
+}
+
+func TestWaitForRunsAnyFailure(t *testing.T) {
+	check := func(st store.Store, runs []*model.Run, targets map[model.Status]bool, anyRun bool) (*waitResult, bool) {
+		t.Helper()
+		result, done, err := checkWaitedRuns(st, runs, targets, anyRun)
+		if err != nil {
+			t.Fatalf("checkWaitedRuns: %v", err)
+		}
+		return result, done
+	}
+	targets := waitTargets(model.StatusDone, model.StatusPROpen)
+
+	// One failure does not end --any while another run can still get there
+	st, runs := newWaitRuns(t, "20250101-090000", "20250101-100000")
+	st.AppendEvent(runs[0].Ref(), model.NewStatusEvent(model.StatusFailed))
+	if result, done := check(st, runs, targets, true); done || result.OK {
+		t.Errorf("done %v, result = %+v; want still waiting", done, result)
+	}
+	st.AppendEvent(runs[1].Ref(), model.NewStatusEvent(model.StatusDone))
+	if result, done := check(st, runs, targets, true); !done || !result.OK {
+		t.Errorf("done %v, result = %+v; want the finished run to end the wait", done, result)
+	}
+
+	// Only once every run failed or was canceled does --any fail
+	st, runs = newWaitRuns(t, "20250101-090000", "20250101-100000")
+	st.AppendEvent(runs[0].Ref(), model.NewStatusEvent(model.StatusFailed))
+	st.AppendEvent(runs[1].Ref(), model.NewStatusEvent(model.StatusCanceled))
+	if result, done := check(st, runs, targets, true); !done || result.OK {
+		t.Errorf("done %v, result = %+v; want a failure", done, result)
+	}
+
+	// Failing is reaching the target when it is waited for
+	failed := waitTargets(model.StatusFailed)
+	st, runs = newWaitRuns(t, "20250101-090000", "20250101-100000")
+	st.AppendEvent(runs[0].Ref(), model.NewStatusEvent(model.StatusFailed))
+	if result, done := check(st, runs, failed, true); !done || !result.OK || !result.Runs[0].Reached {
+		t.Errorf("--any --for failed: done %v, result = %+v; want the failed run to end the wait", done, result)
+	}
+	st.AppendEvent(runs[1].Ref(), model.NewStatusEvent(model.StatusFailed))
+	if result, done := check(st, runs, failed, false); !done || !result.OK {
+		t.Errorf("--all --for failed: done %v, result = %+v; want success", done, result)
+	}
+}
@@ -3,6 +3,9 @@ package config
 import (
 	"os"
 	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
 
 	"gopkg.in/yaml.v3"
 )
@@ -11,7 +14,7 @@ import (
 type MonitorConfig struct {
 	// PSColumns defines which columns to show and in what order.
 	// Available columns: index, id, issue, issue_status, agent, status, alive,
-	// branch, worktree, pr, merged, updated, topic
+	// branch, worktree, pr, merged, started, updated, cost, topic
 	PSColumns []string `yaml:"ps_columns,omitempty"`
 }
 
@@ -29,9 +32,109 @@ type OpenCodeConfig struct {
 	DefaultVariant string `yaml:"default_variant,omitempty"`
 }
 
+// GitHubConfig holds settings for the github store backend.
+// Issue status is mirrored with labels; an empty label is not applied.
+type GitHubConfig struct {
+	Repo          string `yaml:"repo,omitempty"`           // owner/name
+	APIURL        string `yaml:"api_url,omitempty"`        // default: https://api.github.com
+	OpenLabel     string `yaml:"open_label,omitempty"`     // default: none
+	ResolvedLabel string `yaml:"resolved_label,omitempty"` // default: orch:resolved
+	ClosedLabel   string `yaml:"closed_label,omitempty"`   // default: orch:closed
+}
+
+// LinearConfig holds settings for the linear store backend.
+type LinearConfig struct {
+	Team   string `yaml:"team,omitempty"`    // team key to list issues from (e.g. ENG)
+	APIURL string `yaml:"api_url,omitempty"` // default: https://api.linear.app/graphql
+
+	// States maps workflow state names to issue statuses (open/resolved/closed).
+	// Unlisted states map by type: completed -> resolved, canceled -> closed,
+	// everything else -> open.
+	States map[string]string `yaml:"states,omitempty"`
+
+	// StatusStates names the workflow state orch moves an issue into for a
+	// status (e.g. resolved: Done). Defaults to the first state of the
+	// matching type.
+	StatusStates map[string]string `yaml:"status_states,omitempty"`
+}
+
+// ConcurrencyConfig limits how many runs are active at once. The daemon only
+// starts queued runs (orch run --queue) while every applicable limit has room.
+// Zero means unlimited.
+type ConcurrencyConfig struct {
+	Global   int            `yaml:"global,omitempty"`
+	PerAgent map[string]int `yaml:"per_agent,omitempty"` // agent name -> limit
+	PerRepo  int            `yaml:"per_repo,omitempty"`  // limit for each repository
+}
+
+// AutoResumeConfig controls how the daemon resumes runs blocked by API
+// limits. A run is resumed at the reset time the agent printed, otherwise
+// after the next Backoff wait.
+type AutoResumeConfig struct {
+	Enabled     *bool           `yaml:"enabled,omitempty"`      // default true
+	Backoff     []time.Duration `yaml:"backoff,omitempty"`      // e.g. [5m, 15m, 30m, 1h]; the last repeats
+	MaxAttempts int             `yaml:"max_attempts,omitempty"` // per blocked_api episode
+	Message     string          `yaml:"message,omitempty"`      // sent to the agent
+}
+
+// IsEnabled reports whether auto-resume is on (the default)
+func (c AutoResumeConfig) IsEnabled() bool {
+	return c.Enabled == nil || *c.Enabled
+}
+
+// TimeoutConfig sets the default limits the daemon enforces on runs
+// (orch run --timeout / --stall-timeout override them per run). Zero means
+// no limit.
+type TimeoutConfig struct {
+	Run          time.Duration `yaml:"run,omitempty"`           // wall clock since the run started
+	Stall        time.Duration `yaml:"stall,omitempty"`         // no output while running
+	NudgeMessage string        `yaml:"nudge_message,omitempty"` // sent once a run has stalled
+}
+
+// HookConfig registers a shell command or HTTP webhook the daemon runs for
+// run lifecycle events. The JSON payload is written to the command's stdin
+// or POSTed to the URL.
+type HookConfig struct {
+	Name string `yaml:"name,omitempty"` // shown in the daemon log
+
+	OnStatus        []string `yaml:"on_status,omitempty"`         // run statuses, e.g. [blocked, pr_open, failed]
+	OnPR            bool     `yaml:"on_pr,omitempty"`             // a PR URL was detected
+	OnIssueResolved bool     `yaml:"on_issue_resolved,omitempty"` // an issue became resolved
+
+	Command string            `yaml:"command,omitempty"` // run with sh -c
+	URL     string            `yaml:"url,omitempty"`     // POSTed to
+	Headers map[string]string `yaml:"headers,omitempty"` // extra request headers
+
+	Timeout time.Duration `yaml:"timeout,omitempty"` // per attempt; default 10s
+	Retries int           `yaml:"retries,omitempty"` // after the first attempt; default 3
+}
+
+// ServeConfig configures orch serve, the HTTP API for remote dashboards.
+// Mutating endpoints (run, stop, send) require Token as a bearer token and
+// are disabled when it is empty.
+type ServeConfig struct {
+	Listen string `yaml:"listen,omitempty"` // default 127.0.0.1:7777
+	Token  string `yaml:"token,omitempty"`
+}
+
+// AgentDefinition declares an agent launched from a command template.
+// Entries named like a built-in agent (claude, codex, gemini) override the
+// fields they set; unset pattern lists fall back to the built-in patterns.
+type AgentDefinition struct {
+	Command      string `yaml:"command"`                 // text/template over the launch config (e.g. {{quote .Prompt}})
+	Injection    string `yaml:"injection,omitempty"`     // arg (default) or tmux
+	ReadyPattern string `yaml:"ready_pattern,omitempty"` // regex awaited before tmux injection; ^ and $ match per line
+
+	PromptPatterns    []string `yaml:"prompt_patterns,omitempty"`    // waiting for input
+	CompletedPatterns []string `yaml:"completed_patterns,omitempty"` // task finished
+	FailedPatterns    []string `yaml:"failed_patterns,omitempty"`    // unrecoverable error
+	APILimitPatterns  []string `yaml:"api_limit_patterns,omitempty"` // rate or quota limited
+}
+
 // Config holds orch configuration
 type Config struct {
 	Vault           string           `yaml:"vault"`
+	Backend         string           `yaml:"backend"`
 	Agent           string           `yaml:"agent"`
 	Model           string           `yaml:"model"`
 	ModelVariant    string           `yaml:"model_variant"`
@@ -41,9 +144,30 @@ type Config struct {
 	LogLevel        string           `yaml:"log_level"`
 	PromptTemplate  string           `yaml:"prompt_template"`
 	NoPR            bool             `yaml:"no_pr"`
+	Terminal        string           `yaml:"terminal"` // terminal backend of new runs (tmux|pty)
 	Monitor         MonitorConfig    `yaml:"monitor"`
 	OpenCodePresets []OpenCodePreset `yaml:"opencode_presets"`
 	OpenCode        OpenCodeConfig   `yaml:"opencode"`
+	GitHub          GitHubConfig     `yaml:"github"`
+	Linear          LinearConfig     `yaml:"linear"`
+
+	// Agents declares additional agents (or overrides built-in ones) by name
+	Agents map[string]AgentDefinition `yaml:"agents"`
+
+	// MaxConcurrentRuns limits how many queued runs the daemon starts
+	MaxConcurrentRuns ConcurrencyConfig `yaml:"max_concurrent_runs"`
+
+	// AutoResume controls resuming blocked_api runs
+	AutoResume AutoResumeConfig `yaml:"auto_resume"`
+
+	// Timeouts limits how long runs may take or stall
+	Timeouts TimeoutConfig `yaml:"timeouts"`
+
+	// Hooks run commands or webhooks on run lifecycle events
+	Hooks []HookConfig `yaml:"hooks"`
+
+	// Serve configures the HTTP API (orch serve)
+	Serve ServeConfig `yaml:"serve"`
 
 	// Control agent settings (for orch monitor 'c' keybinding)
 	// Falls back to run agent defaults if not set
@@ -56,6 +180,7 @@ type fileConfig struct {
 	Vault               string           `yaml:"vault"`
 	VaultLegacy         string           `yaml:"Vault"`
 	DefaultVault        string           `yaml:"default_vault"`
+	Backend             string           `yaml:"backend"`
 	Agent               string           `yaml:"agent"`
 	Model               string           `yaml:"model"`
 	ModelVariant        string           `yaml:"model_variant"`
@@ -66,12 +191,23 @@ type fileConfig struct {
 	LogLevel            string           `yaml:"log_level"`
 	PromptTemplate      string           `yaml:"prompt_template"`
 	NoPR                *bool            `yaml:"no_pr"`
+	Terminal            string           `yaml:"terminal"`
 	Monitor             MonitorConfig    `yaml:"monitor"`
 	OpenCodePresets     []OpenCodePreset `yaml:"opencode_presets"`
 	OpenCode            OpenCodeConfig   `yaml:"opencode"`
+	GitHub              GitHubConfig     `yaml:"github"`
+	Linear              LinearConfig     `yaml:"linear"`
 	ControlAgent        string           `yaml:"control_agent"`
 	ControlModel        string           `yaml:"control_model"`
 	ControlModelVariant string           `yaml:"control_model_variant"`
+
+	Agents map[string]AgentDefinition `yaml:"agents"`
+
+	MaxConcurrentRuns ConcurrencyConfig `yaml:"max_concurrent_runs"`
+	AutoResume        AutoResumeConfig  `yaml:"auto_resume"`
+	Timeouts          TimeoutConfig     `yaml:"timeouts"`
+	Hooks             []HookConfig      `yaml:"hooks"`
+	Serve             ServeConfig       `yaml:"serve"`
 }
 
 // configFile is the name of the config file
@@ -208,9 +344,15 @@ func loadFromFile(path string, cfg *Config) error {
 	if vault != "" {
 		cfg.Vault = resolvePathFromConfig(vault, baseDir)
 	}
+	if fileCfg.Backend != "" {
+		cfg.Backend = fileCfg.Backend
+	}
 	if fileCfg.Agent != "" {
 		cfg.Agent = fileCfg.Agent
 	}
+	if fileCfg.Terminal != "" {
+		cfg.Terminal = fileCfg.Terminal
+	}
 	if fileCfg.Model != "" {
 		cfg.Model = fileCfg.Model
 	}
@@ -251,6 +393,33 @@ func loadFromFile(path string, cfg *Config) error {
 	if fileCfg.OpenCode.DefaultVariant != "" {
 		cfg.OpenCode.DefaultVariant = fileCfg.OpenCode.DefaultVariant
 	}
+	if fileCfg.GitHub.Repo != "" {
+		cfg.GitHub.Repo = fileCfg.GitHub.Repo
+	}
+	if fileCfg.GitHub.APIURL != "" {
+		cfg.GitHub.APIURL = fileCfg.GitHub.APIURL
+	}
+	if fileCfg.GitHub.OpenLabel != "" {
+		cfg.GitHub.OpenLabel = fileCfg.GitHub.OpenLabel
+	}
+	if fileCfg.GitHub.ResolvedLabel != "" {
+		cfg.GitHub.ResolvedLabel = fileCfg.GitHub.ResolvedLabel
+	}
+	if fileCfg.GitHub.ClosedLabel != "" {
+		cfg.GitHub.ClosedLabel = fileCfg.GitHub.ClosedLabel
+	}
+	if fileCfg.Linear.Team != "" {
+		cfg.Linear.Team = fileCfg.Linear.Team
+	}
+	if fileCfg.Linear.APIURL != "" {
+		cfg.Linear.APIURL = fileCfg.Linear.APIURL
+	}
+	if len(fileCfg.Linear.States) > 0 {
+		cfg.Linear.States = fileCfg.Linear.States
+	}
+	if len(fileCfg.Linear.StatusStates) > 0 {
+		cfg.Linear.StatusStates = fileCfg.Linear.StatusStates
+	}
 	if fileCfg.ControlAgent != "" {
 		cfg.ControlAgent = fileCfg.ControlAgent
 	}
@@ -260,6 +429,55 @@ func loadFromFile(path string, cfg *Config) error {
 	if fileCfg.ControlModelVariant != "" {
 		cfg.ControlModelVariant = fileCfg.ControlModelVariant
 	}
+	// Agents merge by name; a closer config replaces the whole entry
+	for name, def := range fileCfg.Agents {
+		if cfg.Agents == nil {
+			cfg.Agents = make(map[string]AgentDefinition)
+		}
+		cfg.Agents[name] = def
+	}
+	if fileCfg.MaxConcurrentRuns.Global != 0 {
+		cfg.MaxConcurrentRuns.Global = fileCfg.MaxConcurrentRuns.Global
+	}
+	if fileCfg.MaxConcurrentRuns.PerRepo != 0 {
+		cfg.MaxConcurrentRuns.PerRepo = fileCfg.MaxConcurrentRuns.PerRepo
+	}
+	for name, limit := range fileCfg.MaxConcurrentRuns.PerAgent {
+		if cfg.MaxConcurrentRuns.PerAgent == nil {
+			cfg.MaxConcurrentRuns.PerAgent = make(map[string]int)
+		}
+		cfg.MaxConcurrentRuns.PerAgent[name] = limit
+	}
+	if fileCfg.AutoResume.Enabled != nil {
+		cfg.AutoResume.Enabled = fileCfg.AutoResume.Enabled
+	}
+	if len(fileCfg.AutoResume.Backoff) > 0 {
+		cfg.AutoResume.Backoff = fileCfg.AutoResume.Backoff
+	}
+	if fileCfg.AutoResume.MaxAttempts != 0 {
+		cfg.AutoResume.MaxAttempts = fileCfg.AutoResume.MaxAttempts
+	}
+	if fileCfg.AutoResume.Message != "" {
+		cfg.AutoResume.Message = fileCfg.AutoResume.Message
+	}
+	if fileCfg.Timeouts.Run != 0 {
+		cfg.Timeouts.Run = fileCfg.Timeouts.Run
+	}
+	if fileCfg.Timeouts.Stall != 0 {
+		cfg.Timeouts.Stall = fileCfg.Timeouts.Stall
+	}
+	if fileCfg.Timeouts.NudgeMessage != "" {
+		cfg.Timeouts.NudgeMessage = fileCfg.Timeouts.NudgeMessage
+	}
+	if len(fileCfg.Hooks) > 0 {
+		cfg.Hooks = fileCfg.Hooks
+	}
+	if fileCfg.Serve.Listen != "" {
+		cfg.Serve.Listen = fileCfg.Serve.Listen
+	}
+	if fileCfg.Serve.Token != "" {
+		cfg.Serve.Token = os.ExpandEnv(fileCfg.Serve.Token)
+	}
 
 	return nil
 }
@@ -292,9 +510,15 @@ func applyEnv(cfg *Config) {
 	if v := os.Getenv("ORCH_VAULT"); v != "" {
 		cfg.Vault = v
 	}
+	if v := os.Getenv("ORCH_BACKEND"); v != "" {
+		cfg.Backend = v
+	}
 	if v := os.Getenv("ORCH_AGENT"); v != "" {
 		cfg.Agent = v
 	}
+	if v := os.Getenv("ORCH_TERMINAL"); v != "" {
+		cfg.Terminal = v
+	}
 	if v := os.Getenv("ORCH_MODEL"); v != "" {
 		cfg.Model = v
 	}
@@ -321,12 +545,30 @@ func applyEnv(cfg *Config) {
 	if v := os.Getenv("ORCH_NO_PR"); v != "" {
 		cfg.NoPR = v == "true" || v == "1" || v == "yes"
 	}
+	if v := os.Getenv("ORCH_MAX_CONCURRENT_RUNS"); v != "" {
+		if n, err := strconv.Atoi(v); err == nil {
+			cfg.MaxConcurrentRuns.Global = n
+		}
+	}
+	if v := os.Getenv("ORCH_AUTO_RESUME"); v != "" {
+		enabled := v == "true" || v == "1" || v == "yes"
+		cfg.AutoResume.Enabled = &enabled
+	}
+	if v := os.Getenv("ORCH_SERVE_TOKEN"); v != "" {
+		cfg.Serve.Token = v
+	}
 	if v := os.Getenv("ORCH_OPENCODE_DEFAULT_MODEL"); v != "" {
 		cfg.OpenCode.DefaultModel = v
 	}
 	if v := os.Getenv("ORCH_OPENCODE_DEFAULT_VARIANT"); v != "" {
 		cfg.OpenCode.DefaultVariant = v
 	}
+	if v := os.Getenv("ORCH_GITHUB_REPO"); v != "" {
+		cfg.GitHub.Repo = v
+	}
+	if v := os.Getenv("ORCH_LINEAR_TEAM"); v != "" {
+		cfg.Linear.Team = v
+	}
 	if v := os.Getenv("ORCH_CONTROL_AGENT"); v != "" {
 		cfg.ControlAgent = v
 	}
@@ -348,6 +590,25 @@ func (c *Config) GetOpenCodePreset(name string) *OpenCodePreset {
 	return nil
 }
 
+// ParseAgentPreset splits an agent selection such as "opencode:opus:high"
+// into the agent and the model and variant of the preset named after the
+// first colon. A name that is not one of the presets is taken as the variant.
+func ParseAgentPreset(selection string, presets []OpenCodePreset) (agentName, model, variant string) {
+	idx := strings.Index(selection, ":")
+	if idx == -1 {
+		return selection, "", ""
+	}
+
+	agentName = selection[:idx]
+	presetName := selection[idx+1:]
+	for _, preset := range presets {
+		if preset.Name == presetName {
+			return agentName, preset.Model, preset.Variant
+		}
+	}
+	return agentName, "", presetName
+}
+
 // ExpandPath expands ~ and makes path absolute relative to base
 func ExpandPath(path, base string) string {
 	if path == "" {
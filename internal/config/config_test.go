@@ -4,6 +4,7 @@ import (
 	"os"
 	"path/filepath"
 	"testing"
+	"time"
 )
 
 func TestLoadPrecedence(t *testing.T) {
@@ -426,6 +427,432 @@ func TestOpenCodeConfigEnv(t *testing.T) {
 	}
 }
 
+func TestLinearConfig(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	t.Setenv("ORCH_VAULT", "")
+	t.Setenv("ORCH_BACKEND", "")
+	t.Setenv("ORCH_LINEAR_TEAM", "")
+
+	repo := t.TempDir()
+	if err := os.MkdirAll(filepath.Join(repo, ".orch"), 0755); err != nil {
+		t.Fatalf("mkdir repo: %v", err)
+	}
+	configContent := `vault: /repo
+backend: linear
+linear:
+  team: ENG
+  states:
+    In Review: resolved
+  status_states:
+    resolved: Done
+`
+	if err := os.WriteFile(filepath.Join(repo, ".orch", "config.yaml"), []byte(configContent), 0644); err != nil {
+		t.Fatalf("write repo config: %v", err)
+	}
+
+	cwd, err := os.Getwd()
+	if err != nil {
+		t.Fatalf("getwd: %v", err)
+	}
+	if err := os.Chdir(repo); err != nil {
+		t.Fatalf("chdir: %v", err)
+	}
+	t.Cleanup(func() {
+		_ = os.Chdir(cwd)
+	})
+
+	cfg, err := Load()
+	if err != nil {
+		t.Fatalf("Load error: %v", err)
+	}
+	if cfg.Backend != "linear" {
+		t.Fatalf("Backend = %q, want linear", cfg.Backend)
+	}
+	if cfg.Linear.Team != "ENG" {
+		t.Fatalf("Linear.Team = %q, want ENG", cfg.Linear.Team)
+	}
+	if cfg.Linear.States["In Review"] != "resolved" {
+		t.Fatalf("Linear.States = %v, want In Review: resolved", cfg.Linear.States)
+	}
+	if cfg.Linear.StatusStates["resolved"] != "Done" {
+		t.Fatalf("Linear.StatusStates = %v, want resolved: Done", cfg.Linear.StatusStates)
+	}
+}
+
+func TestAgentsConfigMergesByName(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	t.Setenv("ORCH_VAULT", "")
+
+	globalDir := filepath.Join(home, ".config", "orch")
+	if err := os.MkdirAll(globalDir, 0755); err != nil {
+		t.Fatalf("mkdir global: %v", err)
+	}
+	globalContent := `agents:
+  aider:
+    command: aider --yes
+  goose:
+    command: goose run
+    injection: tmux
+    ready_pattern: "> $"
+`
+	if err := os.WriteFile(filepath.Join(globalDir, "config.yaml"), []byte(globalContent), 0644); err != nil {
+		t.Fatalf("write global config: %v", err)
+	}
+
+	repo := t.TempDir()
+	if err := os.MkdirAll(filepath.Join(repo, ".orch"), 0755); err != nil {
+		t.Fatalf("mkdir repo: %v", err)
+	}
+	repoContent := `agents:
+  aider:
+    command: "aider --yes{{if .Prompt}} --message {{quote .Prompt}}{{end}}"
+    prompt_patterns: ["aider>"]
+    api_limit_patterns: ["RateLimitError"]
+`
+	if err := os.WriteFile(filepath.Join(repo, ".orch", "config.yaml"), []byte(repoContent), 0644); err != nil {
+		t.Fatalf("write repo config: %v", err)
+	}
+
+	cwd, err := os.Getwd()
+	if err != nil {
+		t.Fatalf("getwd: %v", err)
+	}
+	if err := os.Chdir(repo); err != nil {
+		t.Fatalf("chdir: %v", err)
+	}
+	t.Cleanup(func() {
+		_ = os.Chdir(cwd)
+	})
+
+	cfg, err := Load()
+	if err != nil {
+		t.Fatalf("Load error: %v", err)
+	}
+	if len(cfg.Agents) != 2 {
+		t.Fatalf("Agents = %v, want aider and goose", cfg.Agents)
+	}
+	aider := cfg.Agents["aider"]
+	if aider.Command != "aider --yes{{if .Prompt}} --message {{quote .Prompt}}{{end}}" {
+		t.Fatalf("aider.Command = %q", aider.Command)
+	}
+	if len(aider.PromptPatterns) != 1 || aider.APILimitPatterns[0] != "RateLimitError" {
+		t.Fatalf("aider patterns = %+v", aider)
+	}
+	goose := cfg.Agents["goose"]
+	if goose.Injection != "tmux" || goose.ReadyPattern != "> $" {
+		t.Fatalf("goose = %+v", goose)
+	}
+}
+
+func TestMaxConcurrentRunsConfig(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	t.Setenv("ORCH_VAULT", "")
+	t.Setenv("ORCH_MAX_CONCURRENT_RUNS", "")
+
+	globalDir := filepath.Join(home, ".config", "orch")
+	if err := os.MkdirAll(globalDir, 0755); err != nil {
+		t.Fatalf("mkdir global: %v", err)
+	}
+	globalContent := `max_concurrent_runs:
+  global: 8
+  per_agent:
+    claude: 3
+    codex: 2
+`
+	if err := os.WriteFile(filepath.Join(globalDir, "config.yaml"), []byte(globalContent), 0644); err != nil {
+		t.Fatalf("write global config: %v", err)
+	}
+
+	repo := t.TempDir()
+	if err := os.MkdirAll(filepath.Join(repo, ".orch"), 0755); err != nil {
+		t.Fatalf("mkdir repo: %v", err)
+	}
+	repoContent := `max_concurrent_runs:
+  per_repo: 2
+  per_agent:
+    claude: 1
+`
+	if err := os.WriteFile(filepath.Join(repo, ".orch", "config.yaml"), []byte(repoContent), 0644); err != nil {
+		t.Fatalf("write repo config: %v", err)
+	}
+
+	cwd, err := os.Getwd()
+	if err != nil {
+		t.Fatalf("getwd: %v", err)
+	}
+	if err := os.Chdir(repo); err != nil {
+		t.Fatalf("chdir: %v", err)
+	}
+	t.Cleanup(func() {
+		_ = os.Chdir(cwd)
+	})
+
+	cfg, err := Load()
+	if err != nil {
+		t.Fatalf("Load error: %v", err)
+	}
+	limits := cfg.MaxConcurrentRuns
+	if limits.Global != 8 || limits.PerRepo != 2 {
+		t.Fatalf("limits = %+v", limits)
+	}
+	if limits.PerAgent["claude"] != 1 || limits.PerAgent["codex"] != 2 {
+		t.Fatalf("per-agent limits = %v", limits.PerAgent)
+	}
+
+	t.Setenv("ORCH_MAX_CONCURRENT_RUNS", "5")
+	cfg, err = Load()
+	if err != nil {
+		t.Fatalf("Load error: %v", err)
+	}
+	if cfg.MaxConcurrentRuns.Global != 5 {
+		t.Fatalf("env should override the global config, got %d", cfg.MaxConcurrentRuns.Global)
+	}
+}
+
+func TestAutoResumeConfig(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	t.Setenv("ORCH_VAULT", "")
+	t.Setenv("ORCH_AUTO_RESUME", "")
+
+	cwd, err := os.Getwd()
+	if err != nil {
+		t.Fatalf("getwd: %v", err)
+	}
+	if err := os.Chdir(t.TempDir()); err != nil {
+		t.Fatalf("chdir: %v", err)
+	}
+	t.Cleanup(func() {
+		_ = os.Chdir(cwd)
+	})
+
+	cfg, err := Load()
+	if err != nil {
+		t.Fatalf("Load error: %v", err)
+	}
+	if !cfg.AutoResume.IsEnabled() {
+		t.Fatal("auto-resume should be enabled by default")
+	}
+
+	globalDir := filepath.Join(home, ".config", "orch")
+	if err := os.MkdirAll(globalDir, 0755); err != nil {
+		t.Fatalf("mkdir global: %v", err)
+	}
+	content := `auto_resume:
+  backoff: [5m, 1h30m]
+  max_attempts: 3
+  message: keep going
+`
+	if err := os.WriteFile(filepath.Join(globalDir, "config.yaml"), []byte(content), 0644); err != nil {
+		t.Fatalf("write global config: %v", err)
+	}
+	cfg, err = Load()
+	if err != nil {
+		t.Fatalf("Load error: %v", err)
+	}
+	ar := cfg.AutoResume
+	if len(ar.Backoff) != 2 || ar.Backoff[0] != 5*time.Minute || ar.Backoff[1] != 90*time.Minute {
+		t.Fatalf("backoff = %v", ar.Backoff)
+	}
+	if ar.MaxAttempts != 3 || ar.Message != "keep going" || !ar.IsEnabled() {
+		t.Fatalf("auto_resume = %+v", ar)
+	}
+
+	t.Setenv("ORCH_AUTO_RESUME", "false")
+	cfg, err = Load()
+	if err != nil {
+		t.Fatalf("Load error: %v", err)
+	}
+	if cfg.AutoResume.IsEnabled() {
+		t.Fatal("ORCH_AUTO_RESUME=false should disable auto-resume")
+	}
+}
+
+func TestTimeoutsConfig(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	t.Setenv("ORCH_VAULT", "")
+
+	cwd, err := os.Getwd()
+	if err != nil {
+		t.Fatalf("getwd: %v", err)
+	}
+	repo := t.TempDir()
+	if err := os.Chdir(repo); err != nil {
+		t.Fatalf("chdir: %v", err)
+	}
+	t.Cleanup(func() {
+		_ = os.Chdir(cwd)
+	})
+
+	globalDir := filepath.Join(home, ".config", "orch")
+	if err := os.MkdirAll(globalDir, 0755); err != nil {
+		t.Fatalf("mkdir global: %v", err)
+	}
+	global := `timeouts:
+  run: 2h
+  stall: 20m
+  nudge_message: are you stuck?
+`
+	if err := os.WriteFile(filepath.Join(globalDir, "config.yaml"), []byte(global), 0644); err != nil {
+		t.Fatalf("write global config: %v", err)
+	}
+	if err := os.MkdirAll(filepath.Join(repo, ".orch"), 0755); err != nil {
+		t.Fatalf("mkdir repo: %v", err)
+	}
+	local := `timeouts:
+  stall: 45m
+`
+	if err := os.WriteFile(filepath.Join(repo, ".orch", "config.yaml"), []byte(local), 0644); err != nil {
+		t.Fatalf("write repo config: %v", err)
+	}
+
+	cfg, err := Load()
+	if err != nil {
+		t.Fatalf("Load error: %v", err)
+	}
+	want := TimeoutConfig{Run: 2 * time.Hour, Stall: 45 * time.Minute, NudgeMessage: "are you stuck?"}
+	if cfg.Timeouts != want {
+		t.Fatalf("timeouts = %+v, want %+v", cfg.Timeouts, want)
+	}
+}
+
+func TestHooksConfig(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	t.Setenv("ORCH_VAULT", "")
+
+	cwd, err := os.Getwd()
+	if err != nil {
+		t.Fatalf("getwd: %v", err)
+	}
+	repo := t.TempDir()
+	if err := os.Chdir(repo); err != nil {
+		t.Fatalf("chdir: %v", err)
+	}
+	t.Cleanup(func() {
+		_ = os.Chdir(cwd)
+	})
+
+	if err := os.MkdirAll(filepath.Join(repo, ".orch"), 0755); err != nil {
+		t.Fatalf("mkdir repo: %v", err)
+	}
+	content := `hooks:
+  - name: slack
+    on_status: [blocked, pr_open, failed]
+    url: https://hooks.example.com/T000
+    headers:
+      Authorization: Bearer $TOKEN
+    retries: 5
+  - command: notify-send orch "$ORCH_ISSUE_ID"
+    on_pr: true
+    on_issue_resolved: true
+    timeout: 30s
+`
+	if err := os.WriteFile(filepath.Join(repo, ".orch", "config.yaml"), []byte(content), 0644); err != nil {
+		t.Fatalf("write repo config: %v", err)
+	}
+
+	cfg, err := Load()
+	if err != nil {
+		t.Fatalf("Load error: %v", err)
+	}
+	if len(cfg.Hooks) != 2 {
+		t.Fatalf("hooks = %+v", cfg.Hooks)
+	}
+	slack := cfg.Hooks[0]
+	if slack.Name != "slack" || len(slack.OnStatus) != 3 || slack.OnStatus[0] != "blocked" || slack.Retries != 5 || slack.Headers["Authorization"] != "Bearer $TOKEN" {
+		t.Errorf("slack hook = %+v", slack)
+	}
+	cmd := cfg.Hooks[1]
+	if !cmd.OnPR || !cmd.OnIssueResolved || cmd.Timeout != 30*time.Second || cmd.Command == "" {
+		t.Errorf("command hook = %+v", cmd)
+	}
+}
+
+func TestServeConfig(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	t.Setenv("ORCH_VAULT", "")
+	t.Setenv("ORCH_SERVE_TOKEN", "")
+	t.Setenv("DASHBOARD_TOKEN", "s3cret")
+
+	cwd, err := os.Getwd()
+	if err != nil {
+		t.Fatalf("getwd: %v", err)
+	}
+	repo := t.TempDir()
+	if err := os.Chdir(repo); err != nil {
+		t.Fatalf("chdir: %v", err)
+	}
+	t.Cleanup(func() {
+		_ = os.Chdir(cwd)
+	})
+
+	if err := os.MkdirAll(filepath.Join(repo, ".orch"), 0755); err != nil {
+		t.Fatalf("mkdir repo: %v", err)
+	}
+	content := `serve:
+  listen: 0.0.0.0:8080
+  token: $DASHBOARD_TOKEN
+`
+	if err := os.WriteFile(filepath.Join(repo, ".orch", "config.yaml"), []byte(content), 0644); err != nil {
+		t.Fatalf("write repo config: %v", err)
+	}
+
+	cfg, err := Load()
+	if err != nil {
+		t.Fatalf("Load error: %v", err)
+	}
+	if cfg.Serve.Listen != "0.0.0.0:8080" || cfg.Serve.Token != "s3cret" {
+		t.Errorf("serve = %+v", cfg.Serve)
+	}
+}
+
+func TestTerminalConfig(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	t.Setenv("ORCH_VAULT", "")
+	t.Setenv("ORCH_TERMINAL", "pty")
+
+	cwd, err := os.Getwd()
+	if err != nil {
+		t.Fatalf("getwd: %v", err)
+	}
+	repo := t.TempDir()
+	if err := os.Chdir(repo); err != nil {
+		t.Fatalf("chdir: %v", err)
+	}
+	t.Cleanup(func() {
+		_ = os.Chdir(cwd)
+	})
+
+	cfg, err := Load()
+	if err != nil {
+		t.Fatalf("Load error: %v", err)
+	}
+	if cfg.Terminal != "pty" {
+		t.Errorf("terminal from env = %q, want pty", cfg.Terminal)
+	}
+
+	if err := os.MkdirAll(filepath.Join(repo, ".orch"), 0755); err != nil {
+		t.Fatalf("mkdir repo: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(repo, ".orch", "config.yaml"), []byte("terminal: tmux\n"), 0644); err != nil {
+		t.Fatalf("write repo config: %v", err)
+	}
+	cfg, err = Load()
+	if err != nil {
+		t.Fatalf("Load error: %v", err)
+	}
+	if cfg.Terminal != "tmux" {
+		t.Errorf("terminal = %q, repo config should override env", cfg.Terminal)
+	}
+}
+
 func TestRelativePathFromSubdirectory(t *testing.T) {
 	t.Setenv("ORCH_VAULT", "")
 	t.Setenv("ORCH_AGENT", "")
@@ -1,6 +1,7 @@
 package daemon
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"log"
@@ -12,26 +13,42 @@ import (
 	"syscall"
 	"time"
 
+	"github.com/s22625/orch/internal/agent"
+	"github.com/s22625/orch/internal/config"
 	"github.com/s22625/orch/internal/git"
+	"github.com/s22625/orch/internal/hook"
 	"github.com/s22625/orch/internal/model"
 	"github.com/s22625/orch/internal/store"
+	"github.com/s22625/orch/internal/terminal"
 )
 
 const (
 	DefaultInterval = 5 * time.Second
 	StallThreshold  = 60 * time.Second
 	FetchInterval   = 90 * time.Second
+
+	// MinRescanInterval throttles store-triggered rescans so the daemon's
+	// own status writes do not immediately trigger another pass
+	MinRescanInterval = time.Second
+
+	// DaemonExitReason is the reason attribute of the failed status recorded
+	// for runs whose pty sessions end with the daemon
+	DaemonExitReason = "daemon_exit"
 )
 
 type Daemon struct {
 	vaultPath string
 	store     store.Store
+	backend   string // store backend name, reported to clients
 	interval  time.Duration
 	logger    *log.Logger
 	stopCh    chan struct{}
 	wg        sync.WaitGroup
 
 	runStates     map[string]*RunState
+	lastMonitorAt time.Time
+	// rescanTimer is the trailing pass armed for a throttled status change
+	rescanTimer   *time.Timer
 	lastFetchAt   map[string]time.Time
 	fetchInFlight map[string]bool
 	mu            sync.Mutex
@@ -39,8 +56,45 @@ type Daemon struct {
 	executablePath string
 	startupMtime   time.Time
 	staleLogged    bool
+	// restartPending is set by a SIGHUP deferred until no pty sessions are
+	// left to lose
+	restartPending bool
 
 	socketServer *SocketServer
+
+	// ptyHost runs the sessions of runs using the pty terminal backend;
+	// they end when the daemon exits
+	ptyHost *terminal.PTYHost
+
+	// readUsage reports a run's token usage (agent.ReadUsage)
+	readUsage func(ctx context.Context, run *model.Run) ([]model.Usage, error)
+
+	// Run queue: limits, how a claimed run is started (spawnRun) and the
+	// repository of each worktree
+	limits    config.ConcurrencyConfig
+	startRun  func(run *model.Run) error
+	repoRoots map[string]string
+
+	// autoResume controls resuming blocked_api runs
+	autoResume config.AutoResumeConfig
+	// timeouts holds the nudge sent to stalled runs
+	timeouts config.TimeoutConfig
+
+	// Lifecycle hooks: configured in SetHooks, dispatched once the log is
+	// open. issueStatuses is the last seen status of each issue, to notice
+	// issues becoming resolved.
+	hookConfigs   []config.HookConfig
+	hooks         *hook.Dispatcher
+	issueStatuses map[string]model.IssueStatus
+	// hookStatuses is the last status each run fired hooks for
+	hookStatuses map[string]model.Status
+
+	// OpenCode event streams, one per server port, the run of each session
+	// and the last status signaled per session
+	openCodeStreams  map[int]*openCodeStream
+	openCodeSessions map[string]*model.RunRef
+	openCodeLast     map[string]openCodeLastSignal
+	openCodeSignals  chan openCodeSignal
 }
 
 // RunState tracks the monitoring state of a single run
@@ -52,11 +106,22 @@ type RunState struct {
 	PRRecorded     bool
 	WasAlive       bool
 	DeadCheckCount int
+	LastUsageAt    time.Time
+
+	// Structured-output runs: log reader and how far events were recorded
+	StreamTail   *agent.StreamTail
+	StreamOffset int64
+
+	// Stall escalation (see checkStall)
+	Stalling   bool          // a stalling event was recorded
+	StalledFor time.Duration // idle time at the last check
+	NudgedAt   time.Time
+	ProgressAt time.Time // first output change after the nudge
 }
 
 // New creates a new Daemon instance
 func New(vaultPath string, st store.Store) *Daemon {
-	return &Daemon{
+	d := &Daemon{
 		vaultPath:     vaultPath,
 		store:         st,
 		interval:      DefaultInterval,
@@ -64,7 +129,21 @@ func New(vaultPath string, st store.Store) *Daemon {
 		runStates:     make(map[string]*RunState),
 		lastFetchAt:   make(map[string]time.Time),
 		fetchInFlight: make(map[string]bool),
+		readUsage:     agent.ReadUsage,
+		repoRoots:     make(map[string]string),
+		ptyHost:       terminal.NewPTYHost(),
+
+		openCodeStreams:  make(map[int]*openCodeStream),
+		openCodeSessions: make(map[string]*model.RunRef),
+		openCodeLast:     make(map[string]openCodeLastSignal),
 	}
+	d.startRun = d.spawnRun
+	return d
+}
+
+// SetBackend sets the store backend name the control API reports
+func (d *Daemon) SetBackend(backend string) {
+	d.backend = backend
 }
 
 // SetInterval sets the monitoring interval
@@ -100,7 +179,16 @@ func (d *Daemon) Run() error {
 
 	d.logger.Printf("daemon started (pid=%d, vault=%s, binary=%s)", os.Getpid(), d.vaultPath, d.executablePath)
 
+	d.hooks = hook.NewDispatcher(d.hookConfigs, d.logger)
+	d.seedIssueStatuses()
+
+	// Sessions of the pty backend live in this process; monitoring reaches
+	// them directly instead of through the socket
+	terminal.Register(d.ptyHost)
+
 	d.socketServer = NewSocketServer(d.vaultPath, d.store, d.logger)
+	d.socketServer.SetPTYHost(d.ptyHost)
+	d.socketServer.SetBackend(d.backend)
 	if err := d.socketServer.Start(); err != nil {
 		d.logger.Printf("warning: failed to start socket server: %v", err)
 	}
@@ -111,6 +199,19 @@ func (d *Daemon) Run() error {
 	ticker := time.NewTicker(d.interval)
 	defer ticker.Stop()
 
+	// Status events written by orch run/stop trigger a pass right away
+	// instead of waiting for the next tick. Status hooks fire from here too,
+	// for the daemon's own writes as well as everyone else's.
+	watchCtx, cancelWatch := context.WithCancel(context.Background())
+	defer cancelWatch()
+	changes, err := d.store.Watch(watchCtx, &store.WatchFilter{Types: []store.ChangeType{store.ChangeEventAppended, store.ChangeIssueChanged}})
+	if err != nil {
+		d.logger.Printf("warning: store watch unavailable, polling only: %v", err)
+	}
+
+	// OpenCode runs report progress through their servers' event streams
+	d.openCodeSignals = make(chan openCodeSignal, 64)
+
 	d.monitorAll()
 
 	for {
@@ -118,8 +219,38 @@ func (d *Daemon) Run() error {
 		case <-ticker.C:
 			d.monitorAll()
 			d.checkBinaryStaleness()
+			if d.restartPending && len(d.ptyHost.Sessions()) == 0 {
+				d.logger.Printf("pty sessions have ended, restarting with new binary")
+				if err := d.restartWithNewBinary(); err != nil {
+					d.logger.Printf("restart failed: %v", err)
+					d.restartPending = false
+				}
+			}
+		case change, ok := <-changes:
+			if !ok {
+				changes = nil
+				continue
+			}
+			if change.Type == store.ChangeIssueChanged {
+				d.checkIssueResolved(change.IssueID)
+				continue
+			}
+			d.fireStatusHooks(change)
+			if d.shouldRescan(change) {
+				d.monitorAll()
+			}
+		case <-d.pendingRescan():
+			d.monitorAll()
+		case s := <-d.openCodeSignals:
+			d.handleOpenCodeSignal(s.port, s.sig)
 		case sig := <-sigCh:
 			if sig == syscall.SIGHUP {
+				// exec would close the pty masters and kill their agents
+				if n := len(d.ptyHost.Sessions()); n > 0 {
+					d.logger.Printf("received SIGHUP, deferring restart until %d pty session(s) end", n)
+					d.restartPending = true
+					continue
+				}
 				d.logger.Printf("received SIGHUP, restarting with new binary")
 				if err := d.restartWithNewBinary(); err != nil {
 					d.logger.Printf("restart failed: %v", err)
@@ -139,10 +270,52 @@ func (d *Daemon) Run() error {
 
 func (d *Daemon) Stop() {
 	close(d.stopCh)
+	d.stopOpenCodeStreams()
 	if d.socketServer != nil {
 		d.socketServer.Stop()
 	}
+	d.failPTYRuns()
+	d.ptyHost.Close()
 	d.wg.Wait()
+	d.hooks.Wait()
+}
+
+// failPTYRuns records the runs whose pty sessions are about to end with the
+// daemon as failed, so they do not stay running without an agent
+func (d *Daemon) failPTYRuns() {
+	sessions := d.ptyHost.Sessions()
+	if len(sessions) == 0 {
+		return
+	}
+	live := make(map[string]bool, len(sessions))
+	for _, name := range sessions {
+		live[name] = true
+	}
+
+	runs, err := d.store.ListRuns(&store.ListRunsFilter{})
+	if err != nil {
+		d.logger.Printf("error listing runs: %v", err)
+		return
+	}
+	for _, run := range runs {
+		if run.Terminal != terminal.BackendPTY || run.Status.IsFinished() {
+			continue
+		}
+		session := run.TmuxSession
+		if session == "" {
+			session = model.GenerateTmuxSession(run.IssueID, run.RunID)
+		}
+		if !live[session] {
+			continue
+		}
+		event := model.NewStatusEvent(model.StatusFailed)
+		event.Attrs["reason"] = DaemonExitReason
+		if err := d.store.AppendEventIf(run.Ref(), run.Status, event); err != nil {
+			d.logger.Printf("%s#%s: failed to record daemon exit: %v", run.IssueID, run.RunID, err)
+			continue
+		}
+		d.logger.Printf("%s#%s: pty session ends with the daemon, marked failed", run.IssueID, run.RunID)
+	}
 }
 
 func (d *Daemon) initBinaryTracking() error {
@@ -208,7 +381,39 @@ func (d *Daemon) restartWithNewBinary() error {
 	return syscall.Exec(d.executablePath, args, os.Environ())
 }
 
+// shouldRescan reports whether a store change warrants an immediate pass.
+// A status change within MinRescanInterval of the last pass is not dropped:
+// it arms a single trailing pass for when the window ends.
+func (d *Daemon) shouldRescan(change store.Change) bool {
+	if change.Event == nil || change.Event.Type != model.EventTypeStatus {
+		return false
+	}
+	wait := MinRescanInterval - time.Since(d.lastMonitorAt)
+	if wait <= 0 {
+		return true
+	}
+	if d.rescanTimer == nil {
+		d.rescanTimer = time.NewTimer(wait)
+	}
+	return false
+}
+
+// pendingRescan fires when the trailing pass armed by shouldRescan is due;
+// it is nil (never ready) when none is armed
+func (d *Daemon) pendingRescan() <-chan time.Time {
+	if d.rescanTimer == nil {
+		return nil
+	}
+	return d.rescanTimer.C
+}
+
 func (d *Daemon) monitorAll() {
+	d.lastMonitorAt = time.Now()
+	if d.rescanTimer != nil {
+		// This pass covers whatever the trailing one was armed for
+		d.rescanTimer.Stop()
+		d.rescanTimer = nil
+	}
 	runs, err := d.store.ListRuns(&store.ListRunsFilter{
 		Status: []model.Status{model.StatusRunning, model.StatusBooting, model.StatusBlocked, model.StatusBlockedAPI, model.StatusPROpen, model.StatusUnknown},
 	})
@@ -220,6 +425,7 @@ func (d *Daemon) monitorAll() {
 	if len(runs) > 0 {
 		d.periodicFetch(runs)
 	}
+	d.syncOpenCodeStreams(runs)
 
 	for _, run := range runs {
 		if err := d.monitorRun(run); err != nil {
@@ -228,6 +434,7 @@ func (d *Daemon) monitorAll() {
 	}
 
 	d.cleanupStates(runs)
+	d.scheduleQueued()
 }
 
 func (d *Daemon) periodicFetch(runs []*model.Run) {
@@ -289,6 +496,11 @@ func (d *Daemon) cleanupStates(activeRuns []*model.Run) {
 			delete(d.runStates, key)
 		}
 	}
+	for key, status := range d.hookStatuses {
+		if !activeKeys[key] && status.IsFinished() {
+			delete(d.hookStatuses, key)
+		}
+	}
 }
 
 // getOrCreateState gets or creates run state tracking
@@ -0,0 +1,95 @@
+package daemon
+
+import (
+	"github.com/s22625/orch/internal/config"
+	"github.com/s22625/orch/internal/hook"
+	"github.com/s22625/orch/internal/model"
+	"github.com/s22625/orch/internal/store"
+)
+
+// SetHooks sets the commands and webhooks run on lifecycle events
+func (d *Daemon) SetHooks(hooks []config.HookConfig) {
+	d.hookConfigs = hooks
+}
+
+// fireStatusHooks notifies hooks of a status event appended to the store,
+// whoever wrote it: the daemon, orch stop, the monitor or events.append.
+// Each run fires once per status it changes to, so a change delivered twice
+// or a repeated status event does not notify again.
+func (d *Daemon) fireStatusHooks(change store.Change) {
+	if d.hooks == nil || change.Event == nil || change.Event.Type != model.EventTypeStatus {
+		return
+	}
+	status := model.Status(change.Event.Name)
+	key := change.IssueID + "#" + change.RunID
+
+	d.mu.Lock()
+	if d.hookStatuses == nil {
+		d.hookStatuses = make(map[string]model.Status)
+	}
+	if d.hookStatuses[key] == status {
+		d.mu.Unlock()
+		return
+	}
+	d.hookStatuses[key] = status
+	d.mu.Unlock()
+
+	run, err := d.store.GetRun(change.Ref())
+	if err != nil {
+		d.logger.Printf("%s: failed to load run for status hooks: %v", key, err)
+		return
+	}
+	run.Status = statusBefore(run, status)
+	d.hooks.Fire(hook.StatusPayload(run, status, change.Event.Attrs["reason"]))
+}
+
+// statusBefore returns the status run had before its latest change to status
+func statusBefore(run *model.Run, status model.Status) model.Status {
+	prev, before := model.StatusQueued, model.StatusQueued
+	for _, e := range run.Events {
+		if e.Type != model.EventTypeStatus {
+			continue
+		}
+		if model.Status(e.Name) == status {
+			before = prev
+		}
+		prev = model.Status(e.Name)
+	}
+	return before
+}
+
+// seedIssueStatuses records the current status of every issue so that only
+// later changes fire issue hooks
+func (d *Daemon) seedIssueStatuses() {
+	issues, err := d.store.ListIssues()
+	if err != nil {
+		d.logger.Printf("warning: failed to list issues: %v", err)
+		return
+	}
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.issueStatuses = make(map[string]model.IssueStatus, len(issues))
+	for _, issue := range issues {
+		d.issueStatuses[issue.ID] = issue.Status
+	}
+}
+
+// checkIssueResolved fires issue hooks when a changed issue became resolved
+func (d *Daemon) checkIssueResolved(issueID string) {
+	issue, err := d.store.ResolveIssue(issueID)
+	if err != nil {
+		return
+	}
+	d.mu.Lock()
+	if d.issueStatuses == nil {
+		d.issueStatuses = make(map[string]model.IssueStatus)
+	}
+	prev, known := d.issueStatuses[issueID]
+	d.issueStatuses[issueID] = issue.Status
+	d.mu.Unlock()
+
+	if issue.Status == model.IssueStatusResolved && (!known || prev != model.IssueStatusResolved) {
+		d.logger.Printf("%s: issue resolved", issueID)
+		d.hooks.Fire(hook.IssueResolvedPayload(issue))
+	}
+}
@@ -0,0 +1,141 @@
+package daemon
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/s22625/orch/internal/config"
+	"github.com/s22625/orch/internal/hook"
+	"github.com/s22625/orch/internal/model"
+	"github.com/s22625/orch/internal/store"
+)
+
+// hookRecorder is a stand-in webhook receiver
+type hookRecorder struct {
+	mu       sync.Mutex
+	payloads []hook.Payload
+}
+
+func (r *hookRecorder) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	var p hook.Payload
+	json.NewDecoder(req.Body).Decode(&p)
+	r.mu.Lock()
+	r.payloads = append(r.payloads, p)
+	r.mu.Unlock()
+}
+
+// lastChange is the notification Watch delivers for the run's latest event
+func lastChange(t *testing.T, st store.Store, ref *model.RunRef) store.Change {
+	t.Helper()
+	run, err := st.GetRun(ref)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return store.Change{Type: store.ChangeEventAppended, IssueID: ref.IssueID, RunID: ref.RunID, Event: run.Events[len(run.Events)-1]}
+}
+
+func TestLifecycleHooks(t *testing.T) {
+	rec := &hookRecorder{}
+	srv := httptest.NewServer(rec)
+	defer srv.Close()
+
+	st := newQueueTestStore(t)
+	run, err := st.CreateRun("orch-1", "20240101-000000", map[string]string{"agent": "claude"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	st.AppendEvent(run.Ref(), model.NewStatusEvent(model.StatusRunning))
+
+	d := newTestDaemon()
+	d.store = st
+	d.hooks = hook.NewDispatcher([]config.HookConfig{{
+		OnStatus:        []string{"blocked", "pr_open"},
+		OnPR:            true,
+		OnIssueResolved: true,
+		URL:             srv.URL,
+	}}, d.logger)
+	d.seedIssueStatuses()
+
+	run, _ = st.GetRun(run.Ref())
+	if err := d.updateStatus(run, model.StatusBlocked); err != nil {
+		t.Fatal(err)
+	}
+	// Status hooks fire from the store's change notifications; a change
+	// delivered twice fires once
+	blocked := lastChange(t, st, run.Ref())
+	d.fireStatusHooks(blocked)
+	d.fireStatusHooks(blocked)
+	run, _ = st.GetRun(run.Ref())
+	if err := d.recordPRArtifact(run, "https://github.com/org/repo/pull/9"); err != nil {
+		t.Fatal(err)
+	}
+	d.fireStatusHooks(lastChange(t, st, run.Ref()))
+	// Not registered
+	run, _ = st.GetRun(run.Ref())
+	d.updateStatus(run, model.StatusRunning)
+	d.fireStatusHooks(lastChange(t, st, run.Ref()))
+
+	// Only the change to resolved fires, not later edits of a resolved issue
+	d.checkIssueResolved("orch-1")
+	st.SetIssueStatus("orch-1", model.IssueStatusResolved)
+	d.checkIssueResolved("orch-1")
+	d.checkIssueResolved("orch-1")
+	d.hooks.Wait()
+
+	events := map[string]hook.Payload{}
+	for _, p := range rec.payloads {
+		if _, dup := events[p.Event]; dup {
+			t.Errorf("%s fired twice", p.Event)
+		}
+		events[p.Event] = p
+	}
+	if len(rec.payloads) != 3 {
+		t.Fatalf("payloads = %+v", rec.payloads)
+	}
+	if p := events[hook.EventStatus]; p.Status != "blocked" || p.PreviousStatus != "running" || p.RunID != "20240101-000000" {
+		t.Errorf("status payload = %+v", p)
+	}
+	if p := events[hook.EventPR]; p.PRUrl != "https://github.com/org/repo/pull/9" {
+		t.Errorf("pr payload = %+v", p)
+	}
+	if p := events[hook.EventIssueResolved]; p.IssueID != "orch-1" || p.Time.After(time.Now()) {
+		t.Errorf("issue payload = %+v", p)
+	}
+}
+
+func TestStatusHooksFireForOtherWriters(t *testing.T) {
+	rec := &hookRecorder{}
+	srv := httptest.NewServer(rec)
+	defer srv.Close()
+
+	st := newQueueTestStore(t)
+	run, err := st.CreateRun("orch-1", "20240101-000000", map[string]string{"agent": "claude"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	st.AppendEvent(run.Ref(), model.NewStatusEvent(model.StatusRunning))
+
+	d := newTestDaemon()
+	d.store = st
+	d.hooks = hook.NewDispatcher([]config.HookConfig{{OnStatus: []string{"canceled"}, URL: srv.URL}}, d.logger)
+
+	// As orch stop records it, without the daemon's involvement
+	canceled := model.NewStatusEvent(model.StatusCanceled)
+	canceled.Attrs["reason"] = "user"
+	if err := st.AppendEventIf(run.Ref(), model.StatusRunning, canceled); err != nil {
+		t.Fatal(err)
+	}
+	d.fireStatusHooks(lastChange(t, st, run.Ref()))
+	d.hooks.Wait()
+
+	if len(rec.payloads) != 1 {
+		t.Fatalf("payloads = %+v", rec.payloads)
+	}
+	if p := rec.payloads[0]; p.Status != "canceled" || p.PreviousStatus != "running" || p.Reason != "user" {
+		t.Errorf("status payload = %+v", p)
+	}
+}
@@ -3,18 +3,22 @@ package daemon
 import (
 	"crypto/md5"
 	"encoding/hex"
+	"errors"
 	"regexp"
 	"strings"
 	"time"
 
 	"github.com/s22625/orch/internal/agent"
+	"github.com/s22625/orch/internal/hook"
 	"github.com/s22625/orch/internal/model"
+	"github.com/s22625/orch/internal/store"
 )
 
 const deadChecksBeforeFailed = 3
 
 func (d *Daemon) monitorRun(run *model.Run) error {
 	state := d.getOrCreateState(run)
+	previousCheck := state.LastCheckAt
 	state.LastCheckAt = time.Now()
 
 	mgr := agent.GetManager(run)
@@ -40,6 +44,22 @@ func (d *Daemon) monitorRun(run *model.Run) error {
 		return d.updateStatus(run, model.StatusFailed)
 	}
 
+	if run.StreamLog != "" {
+		d.recordStreamEvents(run, state)
+	}
+
+	if time.Since(state.LastUsageAt) >= UsageInterval {
+		state.LastUsageAt = time.Now()
+		d.recordUsage(run)
+		if run.OverBudget() {
+			return d.stopOverBudget(run)
+		}
+	}
+
+	if run.Status != model.StatusPROpen && run.TimedOut(time.Now()) {
+		return d.stopTimedOut(run)
+	}
+
 	output, err := mgr.CaptureOutput(run)
 	if err != nil {
 		d.logger.Printf("%s#%s: failed to capture output: %v", run.IssueID, run.RunID, err)
@@ -78,21 +98,44 @@ func (d *Daemon) monitorRun(run *model.Run) error {
 		OutputHash:   state.OutputHash,
 		PRRecorded:   state.PRRecorded,
 	}
-	newStatus := mgr.GetStatus(run, output, agentState, outputChanged, hasPrompt)
+	var newStatus model.Status
+	if !d.openCodeEventsCover(run, previousCheck) {
+		newStatus = mgr.GetStatus(run, output, agentState, outputChanged, hasPrompt)
+	}
 
 	if newStatus != "" && newStatus != run.Status {
+		resetStall(state)
 		d.logger.Printf("%s#%s: status change %s -> %s", run.IssueID, run.RunID, run.Status, newStatus)
+		if newStatus == model.StatusBlockedAPI {
+			d.recordRateLimit(run, output, time.Now())
+		}
 		return d.updateStatus(run, newStatus)
 	}
 
+	switch run.Status {
+	case model.StatusRunning:
+		return d.checkStall(run, state, mgr, outputChanged, time.Now())
+	case model.StatusBlockedAPI:
+		return d.autoResumeRun(run, mgr, time.Now())
+	}
+
 	return nil
 }
 
-// updateStatus appends a status event to the run
+// updateStatus appends a status event to the run. The write only happens if
+// the run still has the status this check observed; if another process
+// changed it meanwhile (e.g. orch stop recorded canceled), the update is
+// dropped and the next check starts from the new status. Transitions the
+// state machine rejects are dropped the same way.
 func (d *Daemon) updateStatus(run *model.Run, status model.Status) error {
 	ref := &model.RunRef{IssueID: run.IssueID, RunID: run.RunID}
 	event := model.NewStatusEvent(status)
-	return d.store.AppendEvent(ref, event)
+	err := d.store.AppendEventIf(ref, run.Status, event)
+	if errors.Is(err, store.ErrStatusConflict) || errors.Is(err, model.ErrInvalidTransition) {
+		d.logger.Printf("%s#%s: skipping status %s: %v", run.IssueID, run.RunID, status, err)
+		return nil
+	}
+	return err
 }
 
 // hashString returns a simple hash of a string
@@ -131,5 +174,9 @@ func (d *Daemon) recordPRArtifact(run *model.Run, prURL string) error {
 	event := model.NewArtifactEvent("pr", map[string]string{
 		"url": prURL,
 	})
-	return d.store.AppendEvent(ref, event)
+	if err := d.store.AppendEvent(ref, event); err != nil {
+		return err
+	}
+	d.hooks.Fire(hook.PRPayload(run, prURL))
+	return nil
 }
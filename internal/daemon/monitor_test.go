@@ -1,13 +1,19 @@
 package daemon
 
 import (
+	"context"
 	"io"
 	"log"
+	"os"
+	"path/filepath"
 	"strings"
 	"testing"
 	"time"
 
 	"github.com/s22625/orch/internal/model"
+	"github.com/s22625/orch/internal/store"
+	"github.com/s22625/orch/internal/store/file"
+	"github.com/s22625/orch/internal/terminal"
 )
 
 func newTestDaemon() *Daemon {
@@ -16,6 +22,7 @@ func newTestDaemon() *Daemon {
 		runStates:     make(map[string]*RunState),
 		lastFetchAt:   make(map[string]time.Time),
 		fetchInFlight: make(map[string]bool),
+		repoRoots:     make(map[string]string),
 	}
 }
 
@@ -89,3 +96,172 @@ func TestPeriodicFetchTracking(t *testing.T) {
 		t.Fatal("lastFetchAt should start empty")
 	}
 }
+
+func TestUpdateStatusSkipsConcurrentChange(t *testing.T) {
+	vault := t.TempDir()
+	os.MkdirAll(filepath.Join(vault, "issues"), 0755)
+	os.WriteFile(filepath.Join(vault, "issues", "orch-1.md"), []byte("---\ntype: issue\n---\n# Test"), 0644)
+
+	st, err := file.New(vault)
+	if err != nil {
+		t.Fatal(err)
+	}
+	run, err := st.CreateRun("orch-1", "20231220-100000", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	d := newTestDaemon()
+	d.store = st
+
+	// The daemon observed queued, then orch stop recorded canceled
+	if err := st.AppendEvent(run.Ref(), model.NewStatusEvent(model.StatusCanceled)); err != nil {
+		t.Fatal(err)
+	}
+	if err := d.updateStatus(run, model.StatusRunning); err != nil {
+		t.Fatalf("updateStatus() error = %v", err)
+	}
+
+	loaded, err := st.GetRun(run.Ref())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if loaded.Status != model.StatusCanceled {
+		t.Errorf("status = %s, want canceled", loaded.Status)
+	}
+}
+
+func TestShouldRescan(t *testing.T) {
+	d := newTestDaemon()
+
+	status := store.Change{Type: store.ChangeEventAppended, Event: model.NewStatusEvent(model.StatusRunning)}
+	artifact := store.Change{Type: store.ChangeEventAppended, Event: model.NewArtifactEvent("pr", nil)}
+
+	if !d.shouldRescan(status) {
+		t.Error("status event should trigger a rescan")
+	}
+	if d.shouldRescan(artifact) {
+		t.Error("artifact event should not trigger a rescan")
+	}
+
+	d.lastMonitorAt = time.Now().Add(-MinRescanInterval + 50*time.Millisecond)
+	if d.shouldRescan(status) {
+		t.Error("rescan within MinRescanInterval should be throttled")
+	}
+	// The throttled change arms one trailing pass for the end of the window
+	pending := d.pendingRescan()
+	if pending == nil {
+		t.Fatal("throttled status change should arm a trailing rescan")
+	}
+	d.shouldRescan(status)
+	if d.pendingRescan() != pending {
+		t.Error("a second throttled change should reuse the armed rescan")
+	}
+	select {
+	case <-pending:
+	case <-time.After(time.Second):
+		t.Fatal("trailing rescan did not fire when the window ended")
+	}
+}
+
+func TestRecordUsageStopsRunOverBudget(t *testing.T) {
+	vault := t.TempDir()
+	os.MkdirAll(filepath.Join(vault, "issues"), 0755)
+	os.WriteFile(filepath.Join(vault, "issues", "orch-1.md"), []byte("---\ntype: issue\n---\n# Test"), 0644)
+
+	st, err := file.New(vault)
+	if err != nil {
+		t.Fatal(err)
+	}
+	run, err := st.CreateRun("orch-1", "20231220-100000", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, e := range []*model.Event{model.NewBudgetArtifactEvent(1), model.NewStatusEvent(model.StatusRunning)} {
+		if err := st.AppendEvent(run.Ref(), e); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	usage := model.Usage{Model: "claude-sonnet-4-5", InputTokens: 1000, Cost: 0.4}
+	d := newTestDaemon()
+	d.store = st
+	d.readUsage = func(ctx context.Context, run *model.Run) ([]model.Usage, error) {
+		return []model.Usage{usage}, nil
+	}
+
+	run, _ = st.GetRun(run.Ref())
+	d.recordUsage(run)
+	d.recordUsage(run) // unchanged totals are not re-recorded
+	if run.Usage.Cost != 0.4 || run.OverBudget() {
+		t.Fatalf("usage = %+v, budget %v", run.Usage, run.Budget)
+	}
+
+	usage.Cost = 1.5
+	d.recordUsage(run)
+	if !run.OverBudget() {
+		t.Fatalf("expected run over budget, cost %v", run.Usage.Cost)
+	}
+	if err := d.stopOverBudget(run); err != nil {
+		t.Fatalf("stopOverBudget() error = %v", err)
+	}
+
+	loaded, err := st.GetRun(run.Ref())
+	if err != nil {
+		t.Fatal(err)
+	}
+	var usageEvents int
+	for _, e := range loaded.Events {
+		if e.Type == model.EventTypeUsage {
+			usageEvents++
+		}
+	}
+	if usageEvents != 2 {
+		t.Errorf("recorded %d usage events, want 2", usageEvents)
+	}
+	last := loaded.Events[len(loaded.Events)-1]
+	if loaded.Status != model.StatusFailed || last.Attrs["reason"] != model.BudgetExceededReason {
+		t.Errorf("status = %s reason = %q, want failed/%s", loaded.Status, last.Attrs["reason"], model.BudgetExceededReason)
+	}
+}
+
+func TestFailPTYRunsOnExit(t *testing.T) {
+	t.Setenv("SHELL", "/bin/sh")
+	st := newQueueTestStore(t)
+	d := newTestDaemon()
+	d.store = st
+	d.ptyHost = terminal.NewPTYHost()
+	t.Cleanup(d.ptyHost.Close)
+
+	newRun := func(runID, backend string) *model.Run {
+		run, err := st.CreateRun("orch-1", runID, map[string]string{"agent": "claude"})
+		if err != nil {
+			t.Fatal(err)
+		}
+		session := model.GenerateTmuxSession(run.IssueID, run.RunID)
+		st.AppendEvent(run.Ref(), model.NewArtifactEvent("session", map[string]string{"name": session, "backend": backend}))
+		st.AppendEvent(run.Ref(), model.NewStatusEvent(model.StatusRunning))
+		return run
+	}
+	live := newRun("20240101-000000", terminal.BackendPTY)
+	gone := newRun("20240101-010000", terminal.BackendPTY)
+	tmuxRun := newRun("20240101-020000", terminal.BackendTmux)
+	if err := d.ptyHost.NewSession(&terminal.SessionConfig{Name: model.GenerateTmuxSession(live.IssueID, live.RunID)}); err != nil {
+		t.Fatalf("NewSession: %v", err)
+	}
+
+	d.failPTYRuns()
+
+	loaded, _ := st.GetRun(live.Ref())
+	if loaded.Status != model.StatusFailed {
+		t.Fatalf("live pty run status = %s, want failed", loaded.Status)
+	}
+	if last := loaded.Events[len(loaded.Events)-1]; last.Attrs["reason"] != DaemonExitReason {
+		t.Errorf("failed event = %+v, want reason %s", last, DaemonExitReason)
+	}
+	for _, run := range []*model.Run{gone, tmuxRun} {
+		if loaded, _ := st.GetRun(run.Ref()); loaded.Status != model.StatusRunning {
+			t.Errorf("%s status = %s, want running", run.RunID, loaded.Status)
+		}
+	}
+}
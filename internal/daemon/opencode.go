@@ -0,0 +1,169 @@
+package daemon
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/s22625/orch/internal/agent"
+	"github.com/s22625/orch/internal/model"
+)
+
+// openCodeStream is the event subscription to one opencode server port
+type openCodeStream struct {
+	stream *agent.OpenCodeEventStream
+	cancel context.CancelFunc
+}
+
+// openCodeSignal is a signal received from the server on port
+type openCodeSignal struct {
+	port int
+	sig  *agent.OpenCodeSignal
+}
+
+// openCodeLastSignal is the last status signal handled for a session
+type openCodeLastSignal struct {
+	status model.Status
+	at     time.Time
+}
+
+func openCodeSessionKey(port int, sessionID string) string {
+	return fmt.Sprintf("%d/%s", port, sessionID)
+}
+
+// syncOpenCodeStreams keeps one event stream per opencode server port that
+// active runs use and remembers which run each session belongs to. Streams
+// are only opened by a running daemon (Run creates the signal channel).
+func (d *Daemon) syncOpenCodeStreams(runs []*model.Run) {
+	if d.openCodeSignals == nil {
+		return
+	}
+	sessions := make(map[string]*model.RunRef)
+	ports := make(map[int]bool)
+	for _, run := range runs {
+		if run.Agent != string(agent.AgentOpenCode) || run.ServerPort == 0 || run.OpenCodeSessionID == "" {
+			continue
+		}
+		ports[run.ServerPort] = true
+		sessions[openCodeSessionKey(run.ServerPort, run.OpenCodeSessionID)] = run.Ref()
+	}
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.openCodeSessions = sessions
+	for port := range ports {
+		if _, ok := d.openCodeStreams[port]; !ok {
+			d.startOpenCodeStream(port)
+		}
+	}
+	for port, s := range d.openCodeStreams {
+		if !ports[port] {
+			s.cancel()
+			delete(d.openCodeStreams, port)
+		}
+	}
+}
+
+// startOpenCodeStream subscribes to the server on port; d.mu must be held
+func (d *Daemon) startOpenCodeStream(port int) {
+	ctx, cancel := context.WithCancel(context.Background())
+	s := &openCodeStream{stream: agent.NewOpenCodeEventStream(port), cancel: cancel}
+	d.openCodeStreams[port] = s
+
+	d.wg.Add(1)
+	go func() {
+		defer d.wg.Done()
+		s.stream.Run(ctx, func(sig *agent.OpenCodeSignal) {
+			select {
+			case d.openCodeSignals <- openCodeSignal{port: port, sig: sig}:
+			case <-ctx.Done():
+			}
+		}, d.logger)
+	}()
+}
+
+// stopOpenCodeStreams ends all subscriptions
+func (d *Daemon) stopOpenCodeStreams() {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	for port, s := range d.openCodeStreams {
+		s.cancel()
+		delete(d.openCodeStreams, port)
+	}
+}
+
+// openCodeEventsCover reports whether the run's status is kept up to date by
+// its server's event stream, so polling can be skipped. The stream must have
+// been up since before the previous check, which polled once to catch up on
+// anything missed while it was down.
+func (d *Daemon) openCodeEventsCover(run *model.Run, previousCheck time.Time) bool {
+	if run.Agent != string(agent.AgentOpenCode) {
+		return false
+	}
+	d.mu.Lock()
+	s := d.openCodeStreams[run.ServerPort]
+	d.mu.Unlock()
+	if s == nil {
+		return false
+	}
+	connectedAt := s.stream.ConnectedSince()
+	return !connectedAt.IsZero() && connectedAt.Before(previousCheck)
+}
+
+// handleOpenCodeSignal applies a server event to the run of its session:
+// activity counts as output, permission requests and errors are recorded,
+// and the implied status is written like a polled one. Repeats of the last
+// status (message.updated arrives for every streamed token) are only
+// re-checked once per monitor interval.
+func (d *Daemon) handleOpenCodeSignal(port int, sig *agent.OpenCodeSignal) {
+	key := openCodeSessionKey(port, sig.SessionID)
+	now := time.Now()
+
+	d.mu.Lock()
+	ref := d.openCodeSessions[key]
+	var state *RunState
+	if ref != nil {
+		state = d.runStates[ref.String()]
+	}
+	last := d.openCodeLast[key]
+	if sig.Status != "" && sig.Record == "" && last.status == sig.Status && now.Sub(last.at) < d.interval {
+		ref = nil
+	} else if sig.Status != "" {
+		d.openCodeLast[key] = openCodeLastSignal{status: sig.Status, at: now}
+	}
+	d.mu.Unlock()
+
+	if state != nil && sig.Activity {
+		state.LastOutputAt = now
+	}
+	if ref == nil || (sig.Status == "" && sig.Record == "") {
+		return
+	}
+
+	run, err := d.store.GetRun(ref)
+	if err != nil {
+		d.logger.Printf("%s: opencode event: %v", ref.String(), err)
+		return
+	}
+	if sig.Record != "" {
+		d.recordMonitorEvent(run, sig.Record, sig.Attrs)
+	}
+	if sig.Status == "" || sig.Status == run.Status {
+		return
+	}
+	switch run.Status {
+	case model.StatusDone, model.StatusFailed, model.StatusCanceled, model.StatusQueued:
+		return
+	}
+
+	if state != nil {
+		resetStall(state)
+	}
+	d.logger.Printf("%s#%s: status change %s -> %s (opencode event)", run.IssueID, run.RunID, run.Status, sig.Status)
+	if sig.Status == model.StatusBlockedAPI {
+		d.recordRateLimit(run, sig.Attrs["message"], now)
+	}
+	if err := d.updateStatus(run, sig.Status); err != nil {
+		d.logger.Printf("%s#%s: failed to update status: %v", run.IssueID, run.RunID, err)
+	}
+}
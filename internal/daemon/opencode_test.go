@@ -0,0 +1,164 @@
+package daemon
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/s22625/orch/internal/agent"
+	"github.com/s22625/orch/internal/model"
+	"github.com/s22625/orch/internal/store"
+)
+
+func newOpenCodeTestDaemon(t *testing.T) (*Daemon, store.Store, *model.Run) {
+	t.Helper()
+	st := newQueueTestStore(t)
+	run, err := st.CreateRun("orch-1", "20240101-000000", map[string]string{"agent": "opencode"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	st.AppendEvent(run.Ref(), model.NewStatusEvent(model.StatusRunning))
+	st.AppendEvent(run.Ref(), model.NewArtifactEvent("server", map[string]string{"port": "4096"}))
+	st.AppendEvent(run.Ref(), model.NewArtifactEvent("opencode_session", map[string]string{"id": "ses_1"}))
+	run, _ = st.GetRun(run.Ref())
+	if run.ServerPort != 4096 || run.OpenCodeSessionID != "ses_1" {
+		t.Fatalf("run port=%d session=%q", run.ServerPort, run.OpenCodeSessionID)
+	}
+
+	d := newTestDaemon()
+	d.store = st
+	d.interval = time.Minute
+	d.openCodeStreams = make(map[int]*openCodeStream)
+	d.openCodeLast = make(map[string]openCodeLastSignal)
+	d.openCodeSessions = map[string]*model.RunRef{openCodeSessionKey(4096, "ses_1"): run.Ref()}
+	return d, st, run
+}
+
+func TestHandleOpenCodeSignal(t *testing.T) {
+	d, st, run := newOpenCodeTestDaemon(t)
+	state := d.getOrCreateState(run)
+	state.LastOutputAt = time.Now().Add(-time.Hour)
+
+	// Activity counts as output without touching the store
+	d.handleOpenCodeSignal(4096, &agent.OpenCodeSignal{SessionID: "ses_1", Status: model.StatusRunning, Activity: true})
+	if time.Since(state.LastOutputAt) > time.Minute {
+		t.Error("activity should refresh LastOutputAt")
+	}
+
+	// Permission requests are recorded and block the run
+	d.handleOpenCodeSignal(4096, &agent.OpenCodeSignal{
+		SessionID: "ses_1",
+		Status:    model.StatusBlocked,
+		Record:    "permission",
+		Attrs:     map[string]string{"permission": "bash"},
+	})
+	got, _ := st.GetRun(run.Ref())
+	if got.Status != model.StatusBlocked {
+		t.Fatalf("status = %s, want blocked", got.Status)
+	}
+	if e := lastEvent(got, model.EventTypeMonitor); e == nil || e.Name != "permission" || e.Attrs["permission"] != "bash" {
+		t.Errorf("monitor event = %+v", e)
+	}
+
+	// Busy again
+	d.handleOpenCodeSignal(4096, &agent.OpenCodeSignal{SessionID: "ses_1", Status: model.StatusRunning, Activity: true})
+	if got, _ := st.GetRun(run.Ref()); got.Status != model.StatusRunning {
+		t.Fatalf("status = %s, want running", got.Status)
+	}
+
+	// Sessions of other runs (or subagents) are ignored
+	d.handleOpenCodeSignal(4096, &agent.OpenCodeSignal{SessionID: "ses_child", Status: model.StatusBlocked})
+	d.handleOpenCodeSignal(4097, &agent.OpenCodeSignal{SessionID: "ses_1", Status: model.StatusBlocked})
+	if got, _ := st.GetRun(run.Ref()); got.Status != model.StatusRunning {
+		t.Errorf("status = %s, want running", got.Status)
+	}
+}
+
+func TestHandleOpenCodeSignalThrottlesRepeats(t *testing.T) {
+	d, st, run := newOpenCodeTestDaemon(t)
+
+	d.handleOpenCodeSignal(4096, &agent.OpenCodeSignal{SessionID: "ses_1", Status: model.StatusBlocked})
+	// Another writer moves the run on; a repeat of the same signal within
+	// the interval is not re-checked
+	st.AppendEvent(run.Ref(), model.NewStatusEvent(model.StatusRunning))
+	d.handleOpenCodeSignal(4096, &agent.OpenCodeSignal{SessionID: "ses_1", Status: model.StatusBlocked})
+	if got, _ := st.GetRun(run.Ref()); got.Status != model.StatusRunning {
+		t.Fatalf("status = %s, want running", got.Status)
+	}
+
+	// Once the interval has passed it is
+	d.openCodeLast[openCodeSessionKey(4096, "ses_1")] = openCodeLastSignal{status: model.StatusBlocked, at: time.Now().Add(-2 * time.Minute)}
+	d.handleOpenCodeSignal(4096, &agent.OpenCodeSignal{SessionID: "ses_1", Status: model.StatusBlocked})
+	if got, _ := st.GetRun(run.Ref()); got.Status != model.StatusBlocked {
+		t.Errorf("status = %s, want blocked", got.Status)
+	}
+}
+
+func TestOpenCodeEventsCover(t *testing.T) {
+	d, _, run := newOpenCodeTestDaemon(t)
+
+	if d.openCodeEventsCover(run, time.Now()) {
+		t.Error("no stream: should poll")
+	}
+
+	// A server whose event stream stays open
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/event-stream")
+		w.(http.Flusher).Flush()
+		<-r.Context().Done()
+	}))
+	defer srv.Close()
+	u, _ := url.Parse(srv.URL)
+	run.ServerPort, _ = strconv.Atoi(u.Port())
+
+	stream := agent.NewOpenCodeEventStream(run.ServerPort)
+	d.openCodeStreams[run.ServerPort] = &openCodeStream{stream: stream, cancel: func() {}}
+	if d.openCodeEventsCover(run, time.Now()) {
+		t.Error("stream down: should poll")
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		stream.Run(ctx, func(*agent.OpenCodeSignal) {}, nil)
+		close(done)
+	}()
+	defer func() {
+		cancel()
+		<-done
+	}()
+	deadline := time.Now().Add(5 * time.Second)
+	for stream.ConnectedSince().IsZero() {
+		if time.Now().After(deadline) {
+			t.Fatal("stream did not connect")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	connectedAt := stream.ConnectedSince()
+
+	// The first check after connecting still polls to catch up
+	if d.openCodeEventsCover(run, connectedAt.Add(-time.Second)) {
+		t.Error("connected after the previous check: should poll")
+	}
+	if !d.openCodeEventsCover(run, connectedAt.Add(time.Second)) {
+		t.Error("connected before the previous check: events cover the run")
+	}
+
+	claude := &model.Run{Agent: "claude", ServerPort: run.ServerPort}
+	if d.openCodeEventsCover(claude, connectedAt.Add(time.Second)) {
+		t.Error("only opencode runs are covered")
+	}
+}
+
+func TestSyncOpenCodeStreamsNeedsRunningDaemon(t *testing.T) {
+	d, st, _ := newOpenCodeTestDaemon(t)
+	runs, _ := st.ListRuns(nil)
+	d.syncOpenCodeStreams(runs)
+	if len(d.openCodeStreams) != 0 {
+		t.Errorf("streams = %d, want none outside Run", len(d.openCodeStreams))
+	}
+}
@@ -0,0 +1,182 @@
+package daemon
+
+import (
+	"errors"
+	"os"
+	"os/exec"
+	"syscall"
+
+	"github.com/s22625/orch/internal/config"
+	"github.com/s22625/orch/internal/git"
+	"github.com/s22625/orch/internal/model"
+	"github.com/s22625/orch/internal/store"
+)
+
+// SetConcurrency sets the limits queued runs are started under
+func (d *Daemon) SetConcurrency(limits config.ConcurrencyConfig) {
+	d.limits = limits
+}
+
+// scheduleQueued starts queued runs (orch run --queue) while the concurrency
+// limits have room: highest priority first, FIFO within a priority. A run
+// held back by its agent or repo limit does not block runs behind it that
+// use another agent or repo.
+func (d *Daemon) scheduleQueued() {
+	queued, err := d.store.ListRuns(&store.ListRunsFilter{Status: []model.Status{model.StatusQueued}})
+	if err != nil {
+		d.logger.Printf("error listing queued runs: %v", err)
+		return
+	}
+	var pending []*model.Run
+	for _, run := range queued {
+		if run.Queued {
+			pending = append(pending, run)
+		}
+	}
+	if len(pending) == 0 {
+		return
+	}
+	model.SortQueue(pending)
+
+	active, err := d.store.ListRuns(&store.ListRunsFilter{Status: model.ActiveStatuses})
+	if err != nil {
+		d.logger.Printf("error listing active runs: %v", err)
+		return
+	}
+	slots := newSlots(d.limits)
+	for _, run := range active {
+		slots.take(run.Agent, d.repoOf(run))
+	}
+
+	for _, run := range pending {
+		if slots.full() {
+			return
+		}
+		if !slots.free(run.Agent, run.Repo) {
+			continue
+		}
+		if err := d.dequeue(run); err != nil {
+			d.logger.Printf("%s#%s: failed to start queued run: %v", run.IssueID, run.RunID, err)
+			continue
+		}
+		slots.take(run.Agent, run.Repo)
+	}
+}
+
+// dequeue claims a queued run by recording booting (so a second pass or
+// daemon does not start it again) and starts it
+func (d *Daemon) dequeue(run *model.Run) error {
+	event := model.NewStatusEvent(model.StatusBooting)
+	event.Attrs["reason"] = model.DequeuedReason
+	err := d.store.AppendEventIf(run.Ref(), model.StatusQueued, event)
+	if errors.Is(err, store.ErrStatusConflict) {
+		return nil // canceled or started meanwhile
+	}
+	if err != nil {
+		return err
+	}
+	d.logger.Printf("%s#%s: starting queued run (priority %d)", run.IssueID, run.RunID, run.Priority)
+
+	if err := d.startRun(run); err != nil {
+		d.store.AppendEvent(run.Ref(), model.NewErrorArtifactEvent("failed to start queued run: "+err.Error()))
+		d.store.AppendEvent(run.Ref(), model.NewStatusEvent(model.StatusFailed))
+		return err
+	}
+	return nil
+}
+
+// spawnRun launches `orch run --start-queued` for a claimed run. It runs
+// detached like the daemon itself; its output goes to the daemon log.
+func (d *Daemon) spawnRun(run *model.Run) error {
+	executable := d.executablePath
+	if executable == "" {
+		var err error
+		if executable, err = os.Executable(); err != nil {
+			return err
+		}
+	}
+
+	cmd := exec.Command(executable, "run", run.IssueID,
+		"--run-id", run.RunID, "--start-queued", "--quiet", "--vault", d.vaultPath)
+	if run.Repo != "" {
+		cmd.Dir = run.Repo
+	}
+	cmd.SysProcAttr = &syscall.SysProcAttr{Setsid: true}
+	logFile, err := os.OpenFile(LogFilePath(d.vaultPath), os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0644)
+	if err == nil {
+		cmd.Stdout = logFile
+		cmd.Stderr = logFile
+	}
+
+	if err := cmd.Start(); err != nil {
+		if logFile != nil {
+			logFile.Close()
+		}
+		return err
+	}
+	go func() {
+		cmd.Wait()
+		if logFile != nil {
+			logFile.Close()
+		}
+	}()
+	return nil
+}
+
+// repoOf returns the main repository root of a run, looked up from its
+// worktree for runs that were not queued
+func (d *Daemon) repoOf(run *model.Run) string {
+	if run.Repo != "" || run.WorktreePath == "" {
+		return run.Repo
+	}
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if repo, ok := d.repoRoots[run.WorktreePath]; ok {
+		return repo
+	}
+	repo, err := git.FindMainRepoRoot(run.WorktreePath)
+	if err != nil {
+		return "" // worktree removed; not counted per repo
+	}
+	d.repoRoots[run.WorktreePath] = repo
+	return repo
+}
+
+// slots counts active runs against the concurrency limits (0 = unlimited)
+type slots struct {
+	limits config.ConcurrencyConfig
+	total  int
+	agents map[string]int
+	repos  map[string]int
+}
+
+func newSlots(limits config.ConcurrencyConfig) *slots {
+	return &slots{limits: limits, agents: make(map[string]int), repos: make(map[string]int)}
+}
+
+func (s *slots) take(agent, repo string) {
+	s.total++
+	s.agents[agent]++
+	if repo != "" {
+		s.repos[repo]++
+	}
+}
+
+// full reports whether the global limit is reached
+func (s *slots) full() bool {
+	return s.limits.Global > 0 && s.total >= s.limits.Global
+}
+
+// free reports whether a run of the agent in the repo may start
+func (s *slots) free(agent, repo string) bool {
+	if s.full() {
+		return false
+	}
+	if limit := s.limits.PerAgent[agent]; limit > 0 && s.agents[agent] >= limit {
+		return false
+	}
+	if repo != "" && s.limits.PerRepo > 0 && s.repos[repo] >= s.limits.PerRepo {
+		return false
+	}
+	return true
+}
@@ -0,0 +1,133 @@
+package daemon
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/s22625/orch/internal/config"
+	"github.com/s22625/orch/internal/model"
+	"github.com/s22625/orch/internal/store"
+	"github.com/s22625/orch/internal/store/file"
+)
+
+func newQueueTestStore(t *testing.T) store.Store {
+	t.Helper()
+	vault := t.TempDir()
+	os.MkdirAll(filepath.Join(vault, "issues"), 0755)
+	os.WriteFile(filepath.Join(vault, "issues", "orch-1.md"), []byte("---\ntype: issue\n---\n# Test"), 0644)
+	st, err := file.New(vault)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return st
+}
+
+func addQueuedRun(t *testing.T, st store.Store, runID, agentName, repo string, priority int, statuses ...model.Status) {
+	t.Helper()
+	run, err := st.CreateRun("orch-1", runID, map[string]string{"agent": agentName})
+	if err != nil {
+		t.Fatal(err)
+	}
+	events := []*model.Event{
+		model.NewStatusEvent(model.StatusQueued),
+		model.NewQueueArtifactEvent(priority, repo, nil),
+	}
+	for _, status := range statuses {
+		events = append(events, model.NewStatusEvent(status))
+	}
+	for _, e := range events {
+		if err := st.AppendEvent(run.Ref(), e); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func TestScheduleQueuedRespectsLimitsAndPriority(t *testing.T) {
+	st := newQueueTestStore(t)
+	addQueuedRun(t, st, "20240101-000000", "claude", "/r1", 0, model.StatusBooting, model.StatusRunning)
+	addQueuedRun(t, st, "20240101-000001", "claude", "/r1", 0)
+	addQueuedRun(t, st, "20240101-000002", "codex", "/r2", 5)
+	addQueuedRun(t, st, "20240101-000003", "codex", "/r2", 0)
+	addQueuedRun(t, st, "20240101-000004", "claude", "/r1", 0)
+	addQueuedRun(t, st, "20240101-000005", "gemini", "/r2", 0)
+
+	// A run that orch run is launching right now (no queue artifact)
+	transient, _ := st.CreateRun("orch-1", "20240101-000006", map[string]string{"agent": "claude"})
+	st.AppendEvent(transient.Ref(), model.NewStatusEvent(model.StatusQueued))
+
+	var started []string
+	d := newTestDaemon()
+	d.store = st
+	d.startRun = func(run *model.Run) error {
+		started = append(started, run.RunID)
+		return nil
+	}
+	d.SetConcurrency(config.ConcurrencyConfig{
+		Global:   3,
+		PerAgent: map[string]int{"codex": 1},
+		PerRepo:  2,
+	})
+
+	d.scheduleQueued()
+	if got := strings.Join(started, ","); got != "20240101-000002,20240101-000001" {
+		t.Fatalf("started = %s, want the priority 5 codex run, then the oldest claude run", got)
+	}
+	run, _ := st.GetRun(&model.RunRef{IssueID: "orch-1", RunID: "20240101-000002"})
+	if run.Status != model.StatusBooting {
+		t.Fatalf("status = %s, want booting", run.Status)
+	}
+	if last := run.Events[len(run.Events)-1]; last.Attrs["reason"] != model.DequeuedReason {
+		t.Errorf("booting event attrs = %v", last.Attrs)
+	}
+
+	// Claimed runs count as active: nothing more fits
+	started = nil
+	d.scheduleQueued()
+	if len(started) != 0 {
+		t.Fatalf("started %v while at the global limit", started)
+	}
+
+	// Lifting the global limit starts gemini; codex and the second /r1 run stay queued
+	d.limits.Global = 0
+	d.scheduleQueued()
+	if got := strings.Join(started, ","); got != "20240101-000005" {
+		t.Fatalf("started = %s, want 20240101-000005", got)
+	}
+	for _, id := range []string{"20240101-000003", "20240101-000004", "20240101-000006"} {
+		run, _ := st.GetRun(&model.RunRef{IssueID: "orch-1", RunID: id})
+		if run.Status != model.StatusQueued {
+			t.Errorf("%s status = %s, want queued", id, run.Status)
+		}
+	}
+
+	// Blocked runs free their slot
+	st.AppendEvent(&model.RunRef{IssueID: "orch-1", RunID: "20240101-000002"}, model.NewStatusEvent(model.StatusBlocked))
+	started = nil
+	d.scheduleQueued()
+	if got := strings.Join(started, ","); got != "20240101-000003" {
+		t.Fatalf("started = %s, want 20240101-000003", got)
+	}
+}
+
+func TestScheduleQueuedStartFailure(t *testing.T) {
+	st := newQueueTestStore(t)
+	addQueuedRun(t, st, "20240101-000000", "claude", "/r1", 0)
+
+	d := newTestDaemon()
+	d.store = st
+	d.startRun = func(run *model.Run) error {
+		return errors.New("exec format error")
+	}
+	d.scheduleQueued()
+
+	run, _ := st.GetRun(&model.RunRef{IssueID: "orch-1", RunID: "20240101-000000"})
+	if run.Status != model.StatusFailed {
+		t.Fatalf("status = %s, want failed", run.Status)
+	}
+	if msg := run.State().Artifacts["error"]["message"]; !strings.Contains(msg, "exec format error") {
+		t.Errorf("error artifact = %q", msg)
+	}
+}
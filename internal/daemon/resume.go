@@ -0,0 +1,222 @@
+package daemon
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/s22625/orch/internal/agent"
+	"github.com/s22625/orch/internal/config"
+	"github.com/s22625/orch/internal/model"
+	"github.com/s22625/orch/internal/store"
+)
+
+// Auto-resume defaults (see config.AutoResumeConfig)
+var DefaultResumeBackoff = []time.Duration{5 * time.Minute, 15 * time.Minute, 30 * time.Minute, time.Hour}
+
+const (
+	DefaultResumeAttempts = 5
+	DefaultResumeMessage  = "The API usage limit has reset. Please continue from where you left off."
+
+	// ResumeGrace is waited past a printed reset time before resuming
+	ResumeGrace = time.Minute
+
+	// RateLimitArtifact records when a blocked_api run's limit resets
+	RateLimitArtifact = "rate_limit"
+	// AutoResumeEvent is the monitor event recorded for each resume attempt
+	AutoResumeEvent = "auto_resume"
+	// AutoResumeReason is the reason attribute of the running status recorded
+	// after a resume message was sent
+	AutoResumeReason = "auto_resume"
+)
+
+// SetAutoResume sets how blocked_api runs are resumed
+func (d *Daemon) SetAutoResume(cfg config.AutoResumeConfig) {
+	d.autoResume = cfg
+}
+
+func (d *Daemon) resumeBackoff() []time.Duration {
+	if len(d.autoResume.Backoff) > 0 {
+		return d.autoResume.Backoff
+	}
+	return DefaultResumeBackoff
+}
+
+func (d *Daemon) resumeAttempts() int {
+	if d.autoResume.MaxAttempts > 0 {
+		return d.autoResume.MaxAttempts
+	}
+	return DefaultResumeAttempts
+}
+
+func (d *Daemon) resumeMessage() string {
+	if d.autoResume.Message != "" {
+		return d.autoResume.Message
+	}
+	return DefaultResumeMessage
+}
+
+// recordRateLimit records the reset time an agent printed when its run
+// became blocked_api. A phrase already recorded is still on screen from an
+// earlier limit and is not recorded again.
+func (d *Daemon) recordRateLimit(run *model.Run, output string, now time.Time) {
+	reset, ok := agent.ParseResetTime(output, now)
+	if !ok {
+		return
+	}
+	if prev, ok := run.State().Artifacts[RateLimitArtifact]; ok && prev["text"] == reset.Text {
+		return
+	}
+	event := model.NewArtifactEvent(RateLimitArtifact, map[string]string{
+		"resets_at": reset.At.Format(time.RFC3339),
+		"text":      reset.Text,
+	})
+	if err := d.store.AppendEvent(run.Ref(), event); err != nil {
+		d.logger.Printf("%s#%s: failed to record rate limit: %v", run.IssueID, run.RunID, err)
+		return
+	}
+	run.ApplyEvents(event)
+	d.logger.Printf("%s#%s: rate limit resets at %s", run.IssueID, run.RunID, reset.At.Format(time.RFC3339))
+}
+
+// resumeState is the auto-resume progress of a blocked_api run, folded from
+// its events. An episode lasts while the run moves between blocked_api and
+// running; any other status starts a new one.
+type resumeState struct {
+	since     time.Time // blocked_api since
+	last      time.Time // last attempt
+	attempts  int
+	resetsAt  time.Time // printed reset time not yet acted on
+	exhausted bool
+}
+
+func resumeStateOf(run *model.Run) resumeState {
+	var rs resumeState
+	prev := model.Status("")
+	for _, e := range run.Events {
+		switch {
+		case e.Type == model.EventTypeStatus:
+			status := model.Status(e.Name)
+			switch status {
+			case model.StatusBlockedAPI:
+				if prev != model.StatusBlockedAPI {
+					rs.since = e.Timestamp
+				}
+			case model.StatusRunning:
+			default:
+				rs = resumeState{}
+			}
+			prev = status
+		case e.Type == model.EventTypeArtifact && e.Name == RateLimitArtifact:
+			if at, err := time.Parse(time.RFC3339, e.Attrs["resets_at"]); err == nil {
+				rs.resetsAt = at
+			}
+		case e.Type == model.EventTypeMonitor && e.Name == AutoResumeEvent:
+			if e.Attrs["result"] == "exhausted" {
+				rs.exhausted = true
+				continue
+			}
+			rs.attempts++
+			rs.last = e.Timestamp
+			rs.resetsAt = time.Time{}
+		}
+	}
+	return rs
+}
+
+// due returns when the next attempt should be made
+func (rs resumeState) due(backoff []time.Duration) time.Time {
+	if !rs.resetsAt.IsZero() {
+		return rs.resetsAt.Add(ResumeGrace)
+	}
+	base := rs.since
+	if rs.last.After(base) {
+		base = rs.last
+	}
+	i := rs.attempts
+	if i >= len(backoff) {
+		i = len(backoff) - 1
+	}
+	return base.Add(backoff[i])
+}
+
+// autoResumeRun sends the resume message to a blocked_api run once its
+// limit has reset (or the backoff has passed). Every attempt is recorded as
+// a `monitor | auto_resume` event; after the last allowed attempt one
+// `result=exhausted` event is recorded and the run is left to a human.
+func (d *Daemon) autoResumeRun(run *model.Run, mgr agent.AgentManager, now time.Time) error {
+	if !d.autoResume.IsEnabled() {
+		return nil
+	}
+	if len(run.Events) == 0 {
+		// Listed runs carry only their derived state; the episode is
+		// folded from the events
+		full, err := d.store.GetRun(run.Ref())
+		if err != nil {
+			return fmt.Errorf("failed to load events: %w", err)
+		}
+		run = full
+	}
+	rs := resumeStateOf(run)
+	if rs.exhausted || rs.since.IsZero() {
+		return nil
+	}
+	maxAttempts := d.resumeAttempts()
+	if rs.attempts >= maxAttempts {
+		d.logger.Printf("%s#%s: giving up auto-resume after %d attempts", run.IssueID, run.RunID, rs.attempts)
+		return d.recordResumeEvent(run, map[string]string{
+			"result":   "exhausted",
+			"attempts": strconv.Itoa(rs.attempts),
+		})
+	}
+	if now.Before(rs.due(d.resumeBackoff())) {
+		return nil
+	}
+
+	attempt := rs.attempts + 1
+	attrs := map[string]string{
+		"attempt": strconv.Itoa(attempt),
+		"max":     strconv.Itoa(maxAttempts),
+	}
+	if !rs.resetsAt.IsZero() {
+		attrs["resets_at"] = rs.resetsAt.Format(time.RFC3339)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+	sendErr := mgr.SendMessage(ctx, run, d.resumeMessage(), nil)
+	if sendErr != nil {
+		attrs["result"] = "error"
+		attrs["error"] = sendErr.Error()
+		d.logger.Printf("%s#%s: auto-resume attempt %d/%d failed: %v", run.IssueID, run.RunID, attempt, maxAttempts, sendErr)
+	} else {
+		attrs["result"] = "sent"
+		d.logger.Printf("%s#%s: auto-resume attempt %d/%d sent", run.IssueID, run.RunID, attempt, maxAttempts)
+	}
+	if err := d.recordResumeEvent(run, attrs); err != nil || sendErr != nil {
+		return err
+	}
+
+	event := model.NewStatusEvent(model.StatusRunning)
+	event.Attrs["reason"] = AutoResumeReason
+	err := d.store.AppendEventIf(run.Ref(), run.Status, event)
+	if errors.Is(err, store.ErrStatusConflict) || errors.Is(err, model.ErrInvalidTransition) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to record resumed status: %w", err)
+	}
+	run.ApplyEvents(event)
+	return nil
+}
+
+func (d *Daemon) recordResumeEvent(run *model.Run, attrs map[string]string) error {
+	event := model.NewEvent(model.EventTypeMonitor, AutoResumeEvent, attrs)
+	if err := d.store.AppendEvent(run.Ref(), event); err != nil {
+		return err
+	}
+	run.ApplyEvents(event)
+	return nil
+}
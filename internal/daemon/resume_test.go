@@ -0,0 +1,226 @@
+package daemon
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/s22625/orch/internal/agent"
+	"github.com/s22625/orch/internal/config"
+	"github.com/s22625/orch/internal/model"
+	"github.com/s22625/orch/internal/store"
+)
+
+// fakeManager records the messages sent to a run
+type fakeManager struct {
+	sent    []string
+	sendErr error
+}
+
+func (m *fakeManager) IsAlive(run *model.Run) bool { return true }
+func (m *fakeManager) GetStatus(run *model.Run, output string, state *agent.RunState, outputChanged, hasPrompt bool) model.Status {
+	return ""
+}
+func (m *fakeManager) CaptureOutput(run *model.Run) (string, error) { return "", nil }
+func (m *fakeManager) DetectPrompt(output string) bool              { return false }
+func (m *fakeManager) SendMessage(ctx context.Context, run *model.Run, message string, opts *agent.SendOptions) error {
+	if m.sendErr != nil {
+		return m.sendErr
+	}
+	m.sent = append(m.sent, message)
+	return nil
+}
+
+func appendAt(t *testing.T, st store.Store, ref *model.RunRef, at time.Time, e *model.Event) {
+	t.Helper()
+	e.Timestamp = at
+	if err := st.AppendEvent(ref, e); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func lastEvent(run *model.Run, typ model.EventType) *model.Event {
+	for i := len(run.Events) - 1; i >= 0; i-- {
+		if run.Events[i].Type == typ {
+			return run.Events[i]
+		}
+	}
+	return nil
+}
+
+func TestAutoResumeBackoffAndCap(t *testing.T) {
+	st := newQueueTestStore(t)
+	run, err := st.CreateRun("orch-1", "20240101-000000", map[string]string{"agent": "claude"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	ref := run.Ref()
+	base := time.Now().Add(-24 * time.Hour).Truncate(time.Second)
+	appendAt(t, st, ref, base, model.NewStatusEvent(model.StatusRunning))
+	appendAt(t, st, ref, base.Add(time.Minute), model.NewStatusEvent(model.StatusBlockedAPI))
+
+	d := newTestDaemon()
+	d.store = st
+	d.SetAutoResume(config.AutoResumeConfig{
+		Backoff:     []time.Duration{10 * time.Minute, 30 * time.Minute},
+		MaxAttempts: 2,
+		Message:     "continue",
+	})
+	mgr := &fakeManager{}
+	reload := func() *model.Run {
+		run, err := st.GetRun(ref)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return run
+	}
+
+	// Not due before the first backoff
+	if err := d.autoResumeRun(reload(), mgr, base.Add(5*time.Minute)); err != nil {
+		t.Fatal(err)
+	}
+	if len(mgr.sent) != 0 {
+		t.Fatalf("sent %v before the backoff passed", mgr.sent)
+	}
+
+	if err := d.autoResumeRun(reload(), mgr, base.Add(12*time.Minute)); err != nil {
+		t.Fatal(err)
+	}
+	if len(mgr.sent) != 1 || mgr.sent[0] != "continue" {
+		t.Fatalf("sent = %v", mgr.sent)
+	}
+	run = reload()
+	if run.Status != model.StatusRunning {
+		t.Fatalf("status = %s, want running", run.Status)
+	}
+	if e := lastEvent(run, model.EventTypeStatus); e.Attrs["reason"] != AutoResumeReason {
+		t.Errorf("status attrs = %v", e.Attrs)
+	}
+	if e := lastEvent(run, model.EventTypeMonitor); e.Name != AutoResumeEvent || e.Attrs["attempt"] != "1" || e.Attrs["max"] != "2" || e.Attrs["result"] != "sent" {
+		t.Errorf("attempt event = %s", e)
+	}
+
+	// Limited again: the second wait is counted from the last attempt
+	appendAt(t, st, ref, time.Now(), model.NewStatusEvent(model.StatusBlockedAPI))
+	lastAttempt := lastEvent(reload(), model.EventTypeMonitor).Timestamp
+	d.autoResumeRun(reload(), mgr, lastAttempt.Add(20*time.Minute))
+	if len(mgr.sent) != 1 {
+		t.Fatalf("second attempt sent before its backoff")
+	}
+	d.autoResumeRun(reload(), mgr, lastAttempt.Add(31*time.Minute))
+	if len(mgr.sent) != 2 {
+		t.Fatalf("second attempt not sent")
+	}
+
+	// Over the cap: one exhausted event, no more messages
+	appendAt(t, st, ref, time.Now(), model.NewStatusEvent(model.StatusBlockedAPI))
+	later := time.Now().Add(48 * time.Hour)
+	d.autoResumeRun(reload(), mgr, later)
+	d.autoResumeRun(reload(), mgr, later)
+	if len(mgr.sent) != 2 {
+		t.Fatalf("sent %d messages, want 2", len(mgr.sent))
+	}
+	exhausted := 0
+	for _, e := range reload().Events {
+		if e.Type == model.EventTypeMonitor && e.Attrs["result"] == "exhausted" {
+			exhausted++
+		}
+	}
+	if exhausted != 1 {
+		t.Errorf("exhausted events = %d, want 1", exhausted)
+	}
+
+	// A new episode (after the run was blocked on a human) starts over
+	appendAt(t, st, ref, time.Now(), model.NewStatusEvent(model.StatusBlocked))
+	appendAt(t, st, ref, time.Now(), model.NewStatusEvent(model.StatusBlockedAPI))
+	d.autoResumeRun(reload(), mgr, later)
+	if len(mgr.sent) != 3 {
+		t.Fatalf("new episode not resumed")
+	}
+}
+
+func TestAutoResumeAtPrintedResetTime(t *testing.T) {
+	st := newQueueTestStore(t)
+	run, err := st.CreateRun("orch-1", "20240101-000000", map[string]string{"agent": "claude"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	ref := run.Ref()
+	now := time.Now().Truncate(time.Second)
+	appendAt(t, st, ref, now, model.NewStatusEvent(model.StatusRunning))
+
+	d := newTestDaemon()
+	d.store = st
+	mgr := &fakeManager{}
+
+	run, _ = st.GetRun(ref)
+	output := "You've hit your usage limit. Upgrade to Pro or try again in 2 hours."
+	d.recordRateLimit(run, output, now)
+	d.recordRateLimit(run, output, now.Add(time.Minute)) // still on screen
+	appendAt(t, st, ref, now, model.NewStatusEvent(model.StatusBlockedAPI))
+
+	run, _ = st.GetRun(ref)
+	limits := 0
+	for _, e := range run.Events {
+		if e.Type == model.EventTypeArtifact && e.Name == RateLimitArtifact {
+			limits++
+		}
+	}
+	if limits != 1 {
+		t.Fatalf("rate_limit artifacts = %d, want 1", limits)
+	}
+	resetsAt := now.Add(2 * time.Hour)
+	if got := run.State().Artifacts[RateLimitArtifact]["resets_at"]; got != resetsAt.Format(time.RFC3339) {
+		t.Fatalf("resets_at = %s, want %s", got, resetsAt.Format(time.RFC3339))
+	}
+
+	// The default backoff (5m) does not apply while a reset time is known
+	d.autoResumeRun(run, mgr, now.Add(30*time.Minute))
+	if len(mgr.sent) != 0 {
+		t.Fatal("resumed before the printed reset time")
+	}
+	d.autoResumeRun(run, mgr, resetsAt.Add(ResumeGrace))
+	if len(mgr.sent) != 1 || mgr.sent[0] != DefaultResumeMessage {
+		t.Fatalf("sent = %v", mgr.sent)
+	}
+	if e := lastEvent(run, model.EventTypeMonitor); e.Attrs["resets_at"] != resetsAt.Format(time.RFC3339) {
+		t.Errorf("attempt event = %s", e)
+	}
+}
+
+func TestAutoResumeSendFailure(t *testing.T) {
+	st := newQueueTestStore(t)
+	run, _ := st.CreateRun("orch-1", "20240101-000000", map[string]string{"agent": "claude"})
+	ref := run.Ref()
+	base := time.Now().Add(-time.Hour).Truncate(time.Second)
+	appendAt(t, st, ref, base, model.NewStatusEvent(model.StatusRunning))
+	appendAt(t, st, ref, base, model.NewStatusEvent(model.StatusBlockedAPI))
+
+	d := newTestDaemon()
+	d.store = st
+	mgr := &fakeManager{sendErr: errors.New("session not found")}
+	// As the monitor loop sees it: listed runs come without their events
+	runs, err := st.ListRuns(&store.ListRunsFilter{Status: []model.Status{model.StatusBlockedAPI}})
+	if err != nil || len(runs) != 1 {
+		t.Fatalf("ListRuns = %v, %v", runs, err)
+	}
+	if err := d.autoResumeRun(runs[0], mgr, time.Now()); err != nil {
+		t.Fatal(err)
+	}
+	run, _ = st.GetRun(ref)
+	if run.Status != model.StatusBlockedAPI {
+		t.Errorf("status = %s, want blocked_api", run.Status)
+	}
+	if e := lastEvent(run, model.EventTypeMonitor); e.Attrs["result"] != "error" || e.Attrs["error"] != "session not found" {
+		t.Errorf("attempt event = %s", e)
+	}
+
+	disabled := false
+	d.SetAutoResume(config.AutoResumeConfig{Enabled: &disabled})
+	mgr.sendErr = nil
+	d.autoResumeRun(run, mgr, time.Now().Add(24*time.Hour))
+	if len(mgr.sent) != 0 {
+		t.Error("disabled auto-resume sent a message")
+	}
+}
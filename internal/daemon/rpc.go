@@ -0,0 +1,525 @@
+package daemon
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net"
+	"os"
+	"os/exec"
+	"strings"
+	"time"
+
+	"github.com/s22625/orch/internal/agent"
+	"github.com/s22625/orch/internal/model"
+	"github.com/s22625/orch/internal/rpc"
+	"github.com/s22625/orch/internal/store"
+	"github.com/s22625/orch/internal/terminal"
+	"github.com/s22625/orch/internal/transcript"
+)
+
+// rpcIdleTimeout closes a connection that sends no request for this long
+const rpcIdleTimeout = 10 * time.Minute
+
+// commandTimeout bounds orch run / orch continue started for runs.start and
+// runs.continue
+const commandTimeout = 2 * time.Minute
+
+type rpcHandler func(s *SocketServer, params json.RawMessage) (interface{}, *rpc.Error)
+
+var rpcHandlers = map[string]rpcHandler{
+	rpc.MethodHello:       (*SocketServer).rpcHello,
+	rpc.MethodListRuns:    (*SocketServer).rpcListRuns,
+	rpc.MethodGetRun:      (*SocketServer).rpcGetRun,
+	rpc.MethodStopRun:     (*SocketServer).rpcStopRun,
+	rpc.MethodStartRun:    (*SocketServer).rpcStartRun,
+	rpc.MethodContinueRun: (*SocketServer).rpcContinueRun,
+	rpc.MethodCapture:     (*SocketServer).rpcCapture,
+	rpc.MethodSend:        (*SocketServer).rpcSend,
+	rpc.MethodAppendEvent: (*SocketServer).rpcAppendEvent,
+	rpc.MethodPTYNew:      (*SocketServer).rpcPTYNew,
+	rpc.MethodPTYHas:      (*SocketServer).rpcPTYHas,
+	rpc.MethodPTYSend:     (*SocketServer).rpcPTYSend,
+	rpc.MethodPTYCapture:  (*SocketServer).rpcPTYCapture,
+	rpc.MethodPTYKill:     (*SocketServer).rpcPTYKill,
+}
+
+// serveRPC answers JSON-RPC requests on a connection until the client
+// closes it. events.subscribe turns the connection into a notification
+// stream, pty.attach into a terminal stream.
+func (s *SocketServer) serveRPC(conn net.Conn, dec *json.Decoder, enc *json.Encoder, first json.RawMessage) {
+	raw := first
+	for {
+		var req rpc.Request
+		if err := json.Unmarshal(raw, &req); err != nil || req.Method == "" {
+			enc.Encode(errorResponse(req.ID, rpc.Errorf(rpc.CodeInvalidRequest, "invalid request")))
+			return
+		}
+		if req.Method == rpc.MethodSubscribe {
+			conn.SetReadDeadline(time.Time{})
+			s.rpcSubscribe(dec, enc, req)
+			return
+		}
+		if req.Method == rpc.MethodPTYAttach {
+			conn.SetReadDeadline(time.Time{})
+			s.rpcPTYAttach(conn, dec, enc, req)
+			return
+		}
+		if err := enc.Encode(s.dispatch(req)); err != nil {
+			return
+		}
+
+		conn.SetReadDeadline(time.Now().Add(rpcIdleTimeout))
+		raw = nil
+		if err := dec.Decode(&raw); err != nil {
+			return
+		}
+	}
+}
+
+func (s *SocketServer) dispatch(req rpc.Request) *rpc.Response {
+	if req.Version > rpc.Version {
+		return errorResponse(req.ID, rpc.Errorf(rpc.CodeUnsupportedVersion, "protocol version %d is newer than the daemon's %d (run orch daemon-restart)", req.Version, rpc.Version))
+	}
+	handler, ok := rpcHandlers[req.Method]
+	if !ok {
+		return errorResponse(req.ID, rpc.Errorf(rpc.CodeMethodNotFound, "unknown method %q", req.Method))
+	}
+	result, rpcErr := handler(s, req.Params)
+	if rpcErr != nil {
+		return errorResponse(req.ID, rpcErr)
+	}
+	data, err := json.Marshal(result)
+	if err != nil {
+		return errorResponse(req.ID, rpc.Errorf(rpc.CodeInternalError, "%v", err))
+	}
+	return &rpc.Response{JSONRPC: "2.0", ID: req.ID, Result: data}
+}
+
+func errorResponse(id int64, err *rpc.Error) *rpc.Response {
+	return &rpc.Response{JSONRPC: "2.0", ID: id, Error: err}
+}
+
+func decodeParams(params json.RawMessage, v interface{}) *rpc.Error {
+	if len(params) == 0 {
+		return nil
+	}
+	if err := json.Unmarshal(params, v); err != nil {
+		return rpc.Errorf(rpc.CodeInvalidParams, "invalid params: %v", err)
+	}
+	return nil
+}
+
+func (s *SocketServer) resolveRun(ref string) (*model.Run, *rpc.Error) {
+	if ref == "" {
+		return nil, rpc.Errorf(rpc.CodeInvalidParams, "ref is required")
+	}
+	run, err := store.ResolveRun(s.store, ref)
+	if err != nil || run == nil {
+		return nil, rpc.Errorf(rpc.CodeRunNotFound, "run not found: %s", ref)
+	}
+	return run, nil
+}
+
+func (s *SocketServer) rpcHello(params json.RawMessage) (interface{}, *rpc.Error) {
+	return &rpc.HelloResult{Version: rpc.Version, PID: os.Getpid(), Vault: s.vaultPath, Backend: s.backend}, nil
+}
+
+func (s *SocketServer) rpcListRuns(params json.RawMessage) (interface{}, *rpc.Error) {
+	var p rpc.ListRunsParams
+	if err := decodeParams(params, &p); err != nil {
+		return nil, err
+	}
+	filter := &store.ListRunsFilter{IssueID: p.IssueID, Limit: p.Limit, Since: p.Since}
+	for _, status := range p.Status {
+		filter.Status = append(filter.Status, model.Status(status))
+	}
+	runs, err := s.store.ListRuns(filter)
+	if err != nil {
+		return nil, rpc.Errorf(rpc.CodeInternalError, "%v", err)
+	}
+	result := &rpc.ListRunsResult{Runs: make([]*rpc.Run, 0, len(runs))}
+	for _, run := range runs {
+		result.Runs = append(result.Runs, rpc.NewRun(run, false))
+	}
+	return result, nil
+}
+
+func (s *SocketServer) rpcGetRun(params json.RawMessage) (interface{}, *rpc.Error) {
+	var p rpc.RefParams
+	if err := decodeParams(params, &p); err != nil {
+		return nil, err
+	}
+	run, rpcErr := s.resolveRun(p.Ref)
+	if rpcErr != nil {
+		return nil, rpcErr
+	}
+	return rpc.NewRun(run, true), nil
+}
+
+// rpcStopRun does what orch stop does for one run: kill the session and
+// record the run canceled
+func (s *SocketServer) rpcStopRun(params json.RawMessage) (interface{}, *rpc.Error) {
+	var p rpc.RefParams
+	if err := decodeParams(params, &p); err != nil {
+		return nil, err
+	}
+	run, rpcErr := s.resolveRun(p.Ref)
+	if rpcErr != nil {
+		return nil, rpcErr
+	}
+	stopped, err := agent.StopRun(s.store, run)
+	if err != nil {
+		return nil, rpc.Errorf(rpc.CodeInternalError, "%v", err)
+	}
+	result := &rpc.StopResult{Status: string(stopped.Status), AlreadyDone: stopped.AlreadyDone}
+	if stopped.KillErr != nil {
+		s.logger.Printf("%s#%s: failed to kill session %s: %v", run.IssueID, run.RunID, stopped.KilledSession, stopped.KillErr)
+	} else {
+		result.KilledSession = stopped.KilledSession
+	}
+	if !stopped.AlreadyDone {
+		s.logger.Printf("%s#%s: stopped via socket", run.IssueID, run.RunID)
+	}
+	return result, nil
+}
+
+func (s *SocketServer) rpcStartRun(params json.RawMessage) (interface{}, *rpc.Error) {
+	var p rpc.StartParams
+	if err := decodeParams(params, &p); err != nil {
+		return nil, err
+	}
+	if p.IssueID == "" {
+		return nil, rpc.Errorf(rpc.CodeInvalidParams, "issue_id is required")
+	}
+	return s.runOrch(p.Dir, append([]string{"run", p.IssueID}, p.Args...))
+}
+
+func (s *SocketServer) rpcContinueRun(params json.RawMessage) (interface{}, *rpc.Error) {
+	var p rpc.StartParams
+	if err := decodeParams(params, &p); err != nil {
+		return nil, err
+	}
+	args := []string{"continue"}
+	if p.Ref != "" {
+		args = append(args, p.Ref)
+	}
+	return s.runOrch(p.Dir, append(args, p.Args...))
+}
+
+// runOrch runs an orch command with JSON output and returns its result
+func (s *SocketServer) runOrch(dir string, args []string) (interface{}, *rpc.Error) {
+	ctx, cancel := context.WithTimeout(context.Background(), commandTimeout)
+	defer cancel()
+
+	args = append(args, "--json", "--vault", s.vaultPath)
+	out, runErr := s.runCommand(ctx, dir, args)
+
+	var result struct {
+		rpc.StartResult
+		OK    bool   `json:"ok"`
+		Error string `json:"error"`
+	}
+	if err := json.Unmarshal(out, &result); err != nil {
+		msg := strings.TrimSpace(string(out))
+		if runErr != nil && msg == "" {
+			msg = runErr.Error()
+		}
+		return nil, rpc.Errorf(rpc.CodeCommandFailed, "orch %s: %s", args[0], msg)
+	}
+	if runErr != nil || !result.OK {
+		msg := result.Error
+		if msg == "" && runErr != nil {
+			msg = runErr.Error()
+		}
+		return nil, rpc.Errorf(rpc.CodeCommandFailed, "orch %s: %s", args[0], msg)
+	}
+	s.logger.Printf("%s#%s: started via socket (orch %s)", result.IssueID, result.RunID, args[0])
+	return &result.StartResult, nil
+}
+
+// execOrch runs this orch binary; stdout is returned, stderr goes to the log
+func (s *SocketServer) execOrch(ctx context.Context, dir string, args []string) ([]byte, error) {
+	executable, err := os.Executable()
+	if err != nil {
+		return nil, err
+	}
+	cmd := exec.CommandContext(ctx, executable, args...)
+	cmd.Dir = dir
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	out, err := cmd.Output()
+	if stderr.Len() > 0 {
+		s.logger.Printf("orch %s: %s", args[0], strings.TrimSpace(stderr.String()))
+	}
+	return out, err
+}
+
+func (s *SocketServer) rpcCapture(params json.RawMessage) (interface{}, *rpc.Error) {
+	var p rpc.CaptureParams
+	if err := decodeParams(params, &p); err != nil {
+		return nil, err
+	}
+	run, rpcErr := s.resolveRun(p.Ref)
+	if rpcErr != nil {
+		return nil, rpcErr
+	}
+	output, err := s.managerFor(run).CaptureOutput(run)
+	if err != nil {
+		return nil, rpc.Errorf(rpc.CodeAgentError, "%v", err)
+	}
+	lines := p.Lines
+	if lines <= 0 {
+		lines = 100
+	}
+	return &rpc.CaptureResult{Output: lastLines(output, lines)}, nil
+}
+
+func lastLines(s string, n int) string {
+	lines := strings.Split(strings.TrimRight(s, "\n"), "\n")
+	if len(lines) > n {
+		lines = lines[len(lines)-n:]
+	}
+	return strings.Join(lines, "\n") + "\n"
+}
+
+func (s *SocketServer) rpcSend(params json.RawMessage) (interface{}, *rpc.Error) {
+	var p rpc.SendParams
+	if err := decodeParams(params, &p); err != nil {
+		return nil, err
+	}
+	run, rpcErr := s.resolveRun(p.Ref)
+	if rpcErr != nil {
+		return nil, rpcErr
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+	if err := s.managerFor(run).SendMessage(ctx, run, p.Message, &agent.SendOptions{NoEnter: p.NoEnter}); err != nil {
+		return nil, rpc.Errorf(rpc.CodeAgentError, "%v", err)
+	}
+	return &rpc.OK{OK: true}, nil
+}
+
+func (s *SocketServer) rpcAppendEvent(params json.RawMessage) (interface{}, *rpc.Error) {
+	var p rpc.AppendEventParams
+	if err := decodeParams(params, &p); err != nil {
+		return nil, err
+	}
+	if p.Event == nil || p.Event.Type == "" || p.Event.Name == "" {
+		return nil, rpc.Errorf(rpc.CodeInvalidParams, "event type and name are required")
+	}
+	run, rpcErr := s.resolveRun(p.Ref)
+	if rpcErr != nil {
+		return nil, rpcErr
+	}
+	event := p.Event.ModelEvent()
+	if event.Timestamp.IsZero() {
+		event.Timestamp = time.Now()
+	}
+	if err := s.store.AppendEvent(run.Ref(), event); err != nil {
+		if errors.Is(err, model.ErrInvalidTransition) {
+			return nil, rpc.Errorf(rpc.CodeInvalidParams, "%v", err)
+		}
+		return nil, rpc.Errorf(rpc.CodeInternalError, "%v", err)
+	}
+	return &rpc.OK{OK: true}, nil
+}
+
+// rpcSubscribe acknowledges the subscription, then forwards store changes as
+// notifications until the client disconnects or the server stops
+func (s *SocketServer) rpcSubscribe(dec *json.Decoder, enc *json.Encoder, req rpc.Request) {
+	var p rpc.SubscribeParams
+	if err := decodeParams(req.Params, &p); err != nil {
+		enc.Encode(errorResponse(req.ID, err))
+		return
+	}
+	filter := &store.WatchFilter{IssueID: p.IssueID}
+	for _, t := range p.Types {
+		filter.Types = append(filter.Types, store.ChangeType(t))
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	changes, err := s.store.Watch(ctx, filter)
+	if err != nil {
+		enc.Encode(errorResponse(req.ID, rpc.Errorf(rpc.CodeInternalError, "%v", err)))
+		return
+	}
+	ok, _ := json.Marshal(&rpc.OK{OK: true})
+	if err := enc.Encode(&rpc.Response{JSONRPC: "2.0", ID: req.ID, Result: ok}); err != nil {
+		return
+	}
+
+	// Anything the client sends now is ignored; a read error means it is gone
+	go func() {
+		defer cancel()
+		for {
+			var raw json.RawMessage
+			if dec.Decode(&raw) != nil {
+				return
+			}
+		}
+	}()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-s.stopCh:
+			return
+		case change, ok := <-changes:
+			if !ok {
+				return
+			}
+			if p.RunID != "" && change.RunID != p.RunID {
+				continue
+			}
+			wire := &rpc.Change{Type: string(change.Type), IssueID: change.IssueID, RunID: change.RunID}
+			if change.Event != nil {
+				wire.Event = rpc.NewEvent(change.Event)
+			}
+			params, _ := json.Marshal(wire)
+			if err := enc.Encode(&rpc.Response{JSONRPC: "2.0", Method: rpc.NotificationChange, Params: params}); err != nil {
+				return
+			}
+		}
+	}
+}
+
+// host returns the pty host, or an error if this server does not host the
+// pty backend
+func (s *SocketServer) host() (*terminal.PTYHost, *rpc.Error) {
+	if s.ptyHost == nil {
+		return nil, rpc.Errorf(rpc.CodeInternalError, "pty sessions are not hosted by this daemon")
+	}
+	return s.ptyHost, nil
+}
+
+// sessionError maps a pty host error to a response error
+func sessionError(err error) *rpc.Error {
+	var notFound *terminal.SessionNotFoundError
+	if errors.As(err, &notFound) {
+		return rpc.Errorf(rpc.CodeSessionNotFound, "%v", err)
+	}
+	return rpc.Errorf(rpc.CodeInternalError, "%v", err)
+}
+
+func (s *SocketServer) rpcPTYNew(params json.RawMessage) (interface{}, *rpc.Error) {
+	var p rpc.PTYNewParams
+	if err := decodeParams(params, &p); err != nil {
+		return nil, err
+	}
+	if p.Name == "" {
+		return nil, rpc.Errorf(rpc.CodeInvalidParams, "name is required")
+	}
+	host, rpcErr := s.host()
+	if rpcErr != nil {
+		return nil, rpcErr
+	}
+	cfg := &terminal.SessionConfig{Name: p.Name, WorkDir: p.WorkDir, Command: p.Command, Env: p.Env}
+	if p.TranscriptRaw != "" && p.TranscriptText != "" {
+		cfg.Transcript = &transcript.Paths{Raw: p.TranscriptRaw, Text: p.TranscriptText, Cast: p.TranscriptCast}
+	}
+	if err := host.NewSession(cfg); err != nil {
+		return nil, rpc.Errorf(rpc.CodeInternalError, "%v", err)
+	}
+	s.logger.Printf("pty session %s started", p.Name)
+	return &rpc.OK{OK: true}, nil
+}
+
+func (s *SocketServer) rpcPTYHas(params json.RawMessage) (interface{}, *rpc.Error) {
+	var p rpc.PTYParams
+	if err := decodeParams(params, &p); err != nil {
+		return nil, err
+	}
+	host, rpcErr := s.host()
+	if rpcErr != nil {
+		return nil, rpcErr
+	}
+	return &rpc.PTYHasResult{Exists: host.HasSession(p.Name)}, nil
+}
+
+func (s *SocketServer) rpcPTYSend(params json.RawMessage) (interface{}, *rpc.Error) {
+	var p rpc.PTYSendParams
+	if err := decodeParams(params, &p); err != nil {
+		return nil, err
+	}
+	host, rpcErr := s.host()
+	if rpcErr != nil {
+		return nil, rpcErr
+	}
+	if err := host.SendKeys(p.Name, p.Keys, p.Enter); err != nil {
+		return nil, sessionError(err)
+	}
+	return &rpc.OK{OK: true}, nil
+}
+
+func (s *SocketServer) rpcPTYCapture(params json.RawMessage) (interface{}, *rpc.Error) {
+	var p rpc.PTYCaptureParams
+	if err := decodeParams(params, &p); err != nil {
+		return nil, err
+	}
+	host, rpcErr := s.host()
+	if rpcErr != nil {
+		return nil, rpcErr
+	}
+	lines := p.Lines
+	if lines <= 0 {
+		lines = 100
+	}
+	output, err := host.Capture(p.Name, lines)
+	if err != nil {
+		return nil, sessionError(err)
+	}
+	return &rpc.CaptureResult{Output: output}, nil
+}
+
+func (s *SocketServer) rpcPTYKill(params json.RawMessage) (interface{}, *rpc.Error) {
+	var p rpc.PTYParams
+	if err := decodeParams(params, &p); err != nil {
+		return nil, err
+	}
+	host, rpcErr := s.host()
+	if rpcErr != nil {
+		return nil, rpcErr
+	}
+	if err := host.Kill(p.Name); err != nil {
+		return nil, sessionError(err)
+	}
+	s.logger.Printf("pty session %s killed", p.Name)
+	return &rpc.OK{OK: true}, nil
+}
+
+// rpcPTYAttach acknowledges the attach, then relays terminal bytes between
+// the connection and the session until either ends
+func (s *SocketServer) rpcPTYAttach(conn net.Conn, dec *json.Decoder, enc *json.Encoder, req rpc.Request) {
+	var p rpc.PTYAttachParams
+	if err := decodeParams(req.Params, &p); err != nil {
+		enc.Encode(errorResponse(req.ID, err))
+		return
+	}
+	host, rpcErr := s.host()
+	if rpcErr != nil {
+		enc.Encode(errorResponse(req.ID, rpcErr))
+		return
+	}
+	if !host.HasSession(p.Name) {
+		enc.Encode(errorResponse(req.ID, sessionError(&terminal.SessionNotFoundError{Name: p.Name})))
+		return
+	}
+	ok, _ := json.Marshal(&rpc.OK{OK: true})
+	if err := enc.Encode(&rpc.Response{JSONRPC: "2.0", ID: req.ID, Result: ok}); err != nil {
+		return
+	}
+
+	// The decoder may have read keystrokes past the request
+	stream := struct {
+		io.Reader
+		io.Writer
+	}{io.MultiReader(dec.Buffered(), conn), conn}
+	if err := host.AttachStream(p.Name, stream, p.Width, p.Height); err != nil {
+		s.logger.Printf("pty session %s: attach: %v", p.Name, err)
+	}
+}
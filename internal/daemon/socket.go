@@ -12,6 +12,7 @@ import (
 	"github.com/s22625/orch/internal/agent"
 	"github.com/s22625/orch/internal/model"
 	"github.com/s22625/orch/internal/store"
+	"github.com/s22625/orch/internal/terminal"
 )
 
 const (
@@ -39,9 +40,19 @@ type SendResponse struct {
 type SocketServer struct {
 	vaultPath string
 	store     store.Store
+	backend   string // name of the store backend, reported by hello
 	listener  net.Listener
 	logger    Logger
 	stopCh    chan struct{}
+
+	// managerFor and runCommand reach agents and run orch commands for the
+	// control API (agent.GetManager and execOrch outside tests)
+	managerFor func(run *model.Run) agent.AgentManager
+	runCommand func(ctx context.Context, dir string, args []string) ([]byte, error)
+
+	// ptyHost runs the sessions of the pty terminal backend (nil: pty.*
+	// methods fail)
+	ptyHost *terminal.PTYHost
 }
 
 type Logger interface {
@@ -49,12 +60,35 @@ type Logger interface {
 }
 
 func NewSocketServer(vaultPath string, st store.Store, logger Logger) *SocketServer {
-	return &SocketServer{
-		vaultPath: vaultPath,
-		store:     st,
-		logger:    logger,
-		stopCh:    make(chan struct{}),
+	s := &SocketServer{
+		vaultPath:  vaultPath,
+		store:      st,
+		logger:     logger,
+		stopCh:     make(chan struct{}),
+		managerFor: agent.GetManager,
 	}
+	s.runCommand = s.execOrch
+	return s
+}
+
+// SetBackend sets the store backend name reported to clients
+func (s *SocketServer) SetBackend(backend string) {
+	s.backend = backend
+}
+
+// SetAgentManager sets how the control API reaches a run's agent
+func (s *SocketServer) SetAgentManager(managerFor func(run *model.Run) agent.AgentManager) {
+	s.managerFor = managerFor
+}
+
+// SetCommandRunner sets how runs.start and runs.continue run orch commands
+func (s *SocketServer) SetCommandRunner(run func(ctx context.Context, dir string, args []string) ([]byte, error)) {
+	s.runCommand = run
+}
+
+// SetPTYHost sets the host serving the pty.* methods
+func (s *SocketServer) SetPTYHost(host *terminal.PTYHost) {
+	s.ptyHost = host
 }
 
 func (s *SocketServer) Start() error {
@@ -118,13 +152,28 @@ func (s *SocketServer) handleConnection(conn net.Conn) {
 	decoder := json.NewDecoder(conn)
 	encoder := json.NewEncoder(conn)
 
-	var req SendRequest
-	if err := decoder.Decode(&req); err != nil {
+	var raw json.RawMessage
+	if err := decoder.Decode(&raw); err != nil {
 		s.logger.Printf("failed to decode request: %v", err)
 		encoder.Encode(SendResponse{OK: false, Error: "invalid request"})
 		return
 	}
 
+	// JSON-RPC requests carry "jsonrpc"; anything else is a legacy request
+	var probe struct {
+		JSONRPC string `json:"jsonrpc"`
+	}
+	if json.Unmarshal(raw, &probe) == nil && probe.JSONRPC != "" {
+		s.serveRPC(conn, decoder, encoder, raw)
+		return
+	}
+
+	var req SendRequest
+	if err := json.Unmarshal(raw, &req); err != nil {
+		encoder.Encode(SendResponse{OK: false, Error: "invalid request"})
+		return
+	}
+
 	switch req.Type {
 	case "send":
 		s.handleSend(req, encoder)
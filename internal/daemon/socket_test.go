@@ -1,6 +1,7 @@
 package daemon
 
 import (
+	"context"
 	"encoding/json"
 	"io"
 	"log"
@@ -38,6 +39,14 @@ func (m *mockStore) AppendEvent(ref *model.RunRef, event *model.Event) error {
 	return nil
 }
 
+func (m *mockStore) AppendEventIf(ref *model.RunRef, expected model.Status, event *model.Event) error {
+	return nil
+}
+
+func (m *mockStore) Watch(ctx context.Context, filter *store.WatchFilter) (<-chan store.Change, error) {
+	return nil, nil
+}
+
 func (m *mockStore) ListRuns(filter *store.ListRunsFilter) ([]*model.Run, error) {
 	return nil, nil
 }
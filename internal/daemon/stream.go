@@ -0,0 +1,53 @@
+package daemon
+
+import (
+	"github.com/s22625/orch/internal/agent"
+	"github.com/s22625/orch/internal/model"
+)
+
+// recordStreamEvents appends the events of the stream-json records written
+// since the last check. After a daemon restart the log is re-read from the
+// start and records up to the offset already recorded on the run are skipped.
+// A result record also refreshes usage, since it carries the exact totals.
+func (d *Daemon) recordStreamEvents(run *model.Run, state *RunState) {
+	if state.StreamTail == nil {
+		// Listed runs carry only their derived state; the recorded offset is
+		// read from the events
+		recorded := run
+		if len(run.Events) == 0 {
+			full, err := d.store.GetRun(run.Ref())
+			if err != nil {
+				d.logger.Printf("%s#%s: failed to load events: %v", run.IssueID, run.RunID, err)
+				return
+			}
+			recorded = full
+		}
+		state.StreamTail = agent.NewStreamTail(run.StreamLog)
+		state.StreamOffset = agent.StreamOffsetOf(recorded)
+	}
+
+	records, err := state.StreamTail.Next()
+	if err != nil {
+		d.logger.Printf("%s#%s: failed to read stream log: %v", run.IssueID, run.RunID, err)
+		return
+	}
+
+	finished := false
+	for _, rec := range records {
+		if rec.Offset <= state.StreamOffset {
+			continue
+		}
+		for _, event := range agent.StreamEvents(rec) {
+			if err := d.store.AppendEvent(run.Ref(), event); err != nil {
+				d.logger.Printf("%s#%s: failed to record stream event: %v", run.IssueID, run.RunID, err)
+				return
+			}
+			run.ApplyEvents(event)
+		}
+		state.StreamOffset = rec.Offset
+		finished = finished || rec.IsTerminal()
+	}
+	if finished {
+		d.recordUsage(run)
+	}
+}
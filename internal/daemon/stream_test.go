@@ -0,0 +1,112 @@
+package daemon
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/s22625/orch/internal/model"
+	"github.com/s22625/orch/internal/store"
+	"github.com/s22625/orch/internal/store/file"
+)
+
+func TestRecordStreamEvents(t *testing.T) {
+	vault := t.TempDir()
+	os.MkdirAll(filepath.Join(vault, "issues"), 0755)
+	os.WriteFile(filepath.Join(vault, "issues", "orch-1.md"), []byte("---\ntype: issue\n---\n# Test"), 0644)
+
+	st, err := file.New(vault)
+	if err != nil {
+		t.Fatal(err)
+	}
+	run, err := st.CreateRun("orch-1", "20231220-100000", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	logPath := filepath.Join(t.TempDir(), "run.ndjson")
+	for _, e := range []*model.Event{
+		model.NewArtifactEvent("stream", map[string]string{"path": logPath}),
+		model.NewStatusEvent(model.StatusRunning),
+	} {
+		if err := st.AppendEvent(run.Ref(), e); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	usageReads := 0
+	d := newTestDaemon()
+	d.store = st
+	d.readUsage = func(ctx context.Context, run *model.Run) ([]model.Usage, error) {
+		usageReads++
+		return []model.Usage{{Model: "claude-sonnet-4-5", InputTokens: 10, Cost: 0.01}}, nil
+	}
+
+	writeLog := func(lines string) {
+		f, err := os.OpenFile(logPath, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0644)
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer f.Close()
+		f.WriteString(lines)
+	}
+	countEvents := func(typ model.EventType) int {
+		run, err := st.GetRun(run.Ref())
+		if err != nil {
+			t.Fatal(err)
+		}
+		n := 0
+		for _, e := range run.Events {
+			if e.Type == typ {
+				n++
+			}
+		}
+		return n
+	}
+
+	run, _ = st.GetRun(run.Ref())
+	if run.StreamLog != logPath {
+		t.Fatalf("StreamLog = %q, want %q", run.StreamLog, logPath)
+	}
+
+	writeLog(`{"type":"system","subtype":"init","session_id":"s1","model":"claude-sonnet-4-5"}
+{"type":"assistant","message":{"id":"m1","content":[{"type":"tool_use","id":"t1","name":"Bash","input":{"command":"make test"}}]}}
+`)
+	state := &RunState{}
+	d.recordStreamEvents(run, state)
+	d.recordStreamEvents(run, state) // nothing new
+	if got := countEvents(model.EventTypeTool); got != 1 {
+		t.Fatalf("tool events = %d, want 1", got)
+	}
+	if usageReads != 0 {
+		t.Fatalf("usage read before a result record")
+	}
+
+	writeLog(`{"type":"result","subtype":"success","num_turns":2,"session_id":"s1","total_cost_usd":0.01}
+`)
+	d.recordStreamEvents(run, state)
+	if usageReads != 1 || run.Usage.Cost != 0.01 {
+		t.Fatalf("usage reads = %d, usage = %+v", usageReads, run.Usage)
+	}
+
+	// A restarted daemon re-reads the log but skips what is recorded. It
+	// sees the run as listed, without its events.
+	listed, err := st.ListRuns(&store.ListRunsFilter{IssueID: "orch-1"})
+	if err != nil || len(listed) != 1 {
+		t.Fatalf("ListRuns() = %d runs, %v", len(listed), err)
+	}
+	d.recordStreamEvents(listed[0], &RunState{})
+	if got := countEvents(model.EventTypeTool); got != 1 {
+		t.Errorf("tool events after restart = %d, want 1", got)
+	}
+	run, _ = st.GetRun(run.Ref())
+	results := 0
+	for _, e := range run.Events {
+		if e.Type == model.EventTypeArtifact && e.Name == "result" {
+			results++
+		}
+	}
+	if results != 1 {
+		t.Errorf("result artifacts = %d, want 1", results)
+	}
+}
@@ -0,0 +1,144 @@
+package daemon
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/s22625/orch/internal/agent"
+	"github.com/s22625/orch/internal/config"
+	"github.com/s22625/orch/internal/model"
+	"github.com/s22625/orch/internal/store"
+)
+
+// DefaultNudgeMessage is sent to a run that stalled past its stall timeout
+const DefaultNudgeMessage = "You have not produced any output for a while. Please continue with the task, or explain what is blocking you."
+
+// Monitor events of the stall escalation
+const (
+	StallingEvent = "stalling"
+	WorkingEvent  = "working"
+	NudgeEvent    = "nudge"
+)
+
+// SetTimeouts sets how stalled runs are nudged. The limits themselves are
+// per run (the timeout artifact).
+func (d *Daemon) SetTimeouts(cfg config.TimeoutConfig) {
+	d.timeouts = cfg
+}
+
+func (d *Daemon) nudgeMessage() string {
+	if d.timeouts.NudgeMessage != "" {
+		return d.timeouts.NudgeMessage
+	}
+	return DefaultNudgeMessage
+}
+
+// stopTimedOut records the run as failed with reason timeout and kills its
+// session
+func (d *Daemon) stopTimedOut(run *model.Run) error {
+	d.logger.Printf("%s#%s: running for more than %s, stopping", run.IssueID, run.RunID, run.Timeout)
+
+	event := model.NewStatusEvent(model.StatusFailed)
+	event.Attrs["reason"] = model.TimeoutReason
+	event.Attrs["timeout"] = run.Timeout.String()
+	return d.stopRun(run, event)
+}
+
+// checkStall escalates a running run whose output stopped changing: a
+// `monitor | stalling` event after StallThreshold, the nudge message once the
+// run's stall timeout passes, and failure with reason stalled if it stays
+// silent for another stall timeout after the nudge. Output only counts as
+// progress after the nudge once it keeps changing for StallThreshold, so the
+// echoed nudge alone does not reset the escalation.
+func (d *Daemon) checkStall(run *model.Run, state *RunState, mgr agent.AgentManager, outputChanged bool, now time.Time) error {
+	idle := now.Sub(state.LastOutputAt).Round(time.Second)
+
+	if outputChanged {
+		if state.Stalling {
+			state.Stalling = false
+			d.recordMonitorEvent(run, WorkingEvent, map[string]string{"idle": state.StalledFor.String()})
+		}
+		if !state.NudgedAt.IsZero() {
+			if state.ProgressAt.IsZero() {
+				state.ProgressAt = now
+			} else if now.Sub(state.ProgressAt) >= StallThreshold {
+				state.NudgedAt, state.ProgressAt = time.Time{}, time.Time{}
+			}
+		}
+		return nil
+	}
+	state.ProgressAt = time.Time{}
+
+	if idle < StallThreshold {
+		return nil
+	}
+	state.StalledFor = idle
+	if !state.Stalling {
+		state.Stalling = true
+		d.logger.Printf("%s#%s: no output for %s", run.IssueID, run.RunID, idle)
+		d.recordMonitorEvent(run, StallingEvent, map[string]string{"idle": idle.String()})
+	}
+	if run.StallTimeout <= 0 {
+		return nil
+	}
+
+	if state.NudgedAt.IsZero() {
+		if idle < run.StallTimeout {
+			return nil
+		}
+		state.NudgedAt = now
+		attrs := map[string]string{"idle": idle.String()}
+		ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+		defer cancel()
+		if err := mgr.SendMessage(ctx, run, d.nudgeMessage(), nil); err != nil {
+			attrs["error"] = err.Error()
+			d.logger.Printf("%s#%s: failed to nudge after %s: %v", run.IssueID, run.RunID, idle, err)
+		} else {
+			d.logger.Printf("%s#%s: nudged after %s without output", run.IssueID, run.RunID, idle)
+		}
+		d.recordMonitorEvent(run, NudgeEvent, attrs)
+		return nil
+	}
+
+	if now.Sub(state.NudgedAt) < run.StallTimeout {
+		return nil
+	}
+	d.logger.Printf("%s#%s: still stalled %s after the nudge, stopping", run.IssueID, run.RunID, now.Sub(state.NudgedAt).Round(time.Second))
+	event := model.NewStatusEvent(model.StatusFailed)
+	event.Attrs["reason"] = model.StalledReason
+	event.Attrs["stall_timeout"] = run.StallTimeout.String()
+	return d.stopRun(run, event)
+}
+
+// resetStall forgets the stall escalation of a run that is no longer running
+func resetStall(state *RunState) {
+	state.Stalling = false
+	state.StalledFor = 0
+	state.NudgedAt, state.ProgressAt = time.Time{}, time.Time{}
+}
+
+func (d *Daemon) recordMonitorEvent(run *model.Run, name string, attrs map[string]string) {
+	event := model.NewEvent(model.EventTypeMonitor, name, attrs)
+	if err := d.store.AppendEvent(run.Ref(), event); err != nil {
+		d.logger.Printf("%s#%s: failed to record monitor %s: %v", run.IssueID, run.RunID, name, err)
+	}
+}
+
+// stopRun records a failed status event if the run still has the status
+// this check observed, then kills its session
+func (d *Daemon) stopRun(run *model.Run, event *model.Event) error {
+	err := d.store.AppendEventIf(run.Ref(), run.Status, event)
+	if errors.Is(err, store.ErrStatusConflict) || errors.Is(err, model.ErrInvalidTransition) {
+		d.logger.Printf("%s#%s: skipping stop (%s): %v", run.IssueID, run.RunID, event.Attrs["reason"], err)
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	if session, err := agent.KillSession(run); err != nil {
+		d.logger.Printf("%s#%s: failed to kill session %s: %v", run.IssueID, run.RunID, session, err)
+	}
+	return nil
+}
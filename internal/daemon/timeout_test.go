@@ -0,0 +1,153 @@
+package daemon
+
+import (
+	"testing"
+	"time"
+
+	"github.com/s22625/orch/internal/config"
+	"github.com/s22625/orch/internal/model"
+)
+
+func TestStallEscalation(t *testing.T) {
+	st := newQueueTestStore(t)
+	run, err := st.CreateRun("orch-1", "20240101-000000", map[string]string{"agent": "claude"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	ref := run.Ref()
+	base := time.Now().Truncate(time.Second)
+	appendAt(t, st, ref, base, model.NewStatusEvent(model.StatusRunning))
+	appendAt(t, st, ref, base, model.NewTimeoutArtifactEvent(0, 10*time.Minute))
+
+	d := newTestDaemon()
+	d.store = st
+	d.SetTimeouts(config.TimeoutConfig{NudgeMessage: "still there?"})
+	mgr := &fakeManager{}
+	state := &RunState{LastOutputAt: base}
+	check := func(at time.Time, changed bool) {
+		t.Helper()
+		if changed {
+			state.LastOutputAt = at
+		}
+		run, err := st.GetRun(ref)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := d.checkStall(run, state, mgr, changed, at); err != nil {
+			t.Fatal(err)
+		}
+	}
+	monitorEvents := func() []string {
+		run, _ := st.GetRun(ref)
+		var names []string
+		for _, e := range run.Events {
+			if e.Type == model.EventTypeMonitor {
+				names = append(names, e.Name)
+			}
+		}
+		return names
+	}
+
+	check(base.Add(30*time.Second), false)
+	check(base.Add(2*time.Minute), false)
+	check(base.Add(3*time.Minute), false)
+	if got := monitorEvents(); len(got) != 1 || got[0] != StallingEvent {
+		t.Fatalf("monitor events = %v, want [stalling]", got)
+	}
+
+	// Output resumes, then stops again for the stall timeout
+	check(base.Add(4*time.Minute), true)
+	check(base.Add(15*time.Minute), false)
+	if len(mgr.sent) != 1 || mgr.sent[0] != "still there?" {
+		t.Fatalf("sent = %v", mgr.sent)
+	}
+	if got := monitorEvents(); len(got) != 4 || got[1] != WorkingEvent || got[3] != NudgeEvent {
+		t.Fatalf("monitor events = %v", got)
+	}
+
+	// The echoed nudge is not progress: still failed a stall timeout later
+	check(base.Add(15*time.Minute+5*time.Second), true)
+	check(base.Add(20*time.Minute), false)
+	run, _ = st.GetRun(ref)
+	if run.Status != model.StatusRunning {
+		t.Fatalf("failed before the stall timeout passed again")
+	}
+	check(base.Add(26*time.Minute), false)
+	run, _ = st.GetRun(ref)
+	if run.Status != model.StatusFailed {
+		t.Fatalf("status = %s, want failed", run.Status)
+	}
+	if e := lastEvent(run, model.EventTypeStatus); e.Attrs["reason"] != model.StalledReason || e.Attrs["stall_timeout"] != "10m0s" {
+		t.Errorf("failed attrs = %v", e.Attrs)
+	}
+	if len(mgr.sent) != 1 {
+		t.Errorf("nudged %d times, want 1", len(mgr.sent))
+	}
+}
+
+func TestStallProgressAfterNudge(t *testing.T) {
+	st := newQueueTestStore(t)
+	run, _ := st.CreateRun("orch-1", "20240101-000000", map[string]string{"agent": "claude"})
+	ref := run.Ref()
+	base := time.Now().Truncate(time.Second)
+	appendAt(t, st, ref, base, model.NewStatusEvent(model.StatusRunning))
+	appendAt(t, st, ref, base, model.NewTimeoutArtifactEvent(0, 5*time.Minute))
+
+	d := newTestDaemon()
+	d.store = st
+	mgr := &fakeManager{}
+	state := &RunState{LastOutputAt: base}
+	check := func(at time.Time, changed bool) {
+		t.Helper()
+		if changed {
+			state.LastOutputAt = at
+		}
+		run, _ := st.GetRun(ref)
+		if err := d.checkStall(run, state, mgr, changed, at); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	check(base.Add(6*time.Minute), false) // nudged
+	// The agent picks up and keeps working past StallThreshold
+	for i := 1; i <= 4; i++ {
+		check(base.Add(6*time.Minute+time.Duration(i)*30*time.Second), true)
+	}
+	check(base.Add(13*time.Minute), false)
+	run, _ = st.GetRun(ref)
+	if run.Status != model.StatusRunning {
+		t.Fatalf("status = %s; progress after the nudge should restart the escalation", run.Status)
+	}
+	// The next stall is nudged again rather than failed
+	check(base.Add(14*time.Minute), false)
+	if len(mgr.sent) != 2 || mgr.sent[1] != DefaultNudgeMessage {
+		t.Fatalf("sent = %v", mgr.sent)
+	}
+}
+
+func TestStopTimedOut(t *testing.T) {
+	st := newQueueTestStore(t)
+	run, _ := st.CreateRun("orch-1", "20240101-000000", map[string]string{"agent": "claude"})
+	ref := run.Ref()
+	launched := time.Now().Add(-3 * time.Hour)
+	appendAt(t, st, ref, launched.Add(-time.Hour), model.NewStatusEvent(model.StatusQueued))
+	appendAt(t, st, ref, launched.Add(-time.Hour), model.NewTimeoutArtifactEvent(2*time.Hour, 0))
+	appendAt(t, st, ref, launched, model.NewStatusEvent(model.StatusRunning))
+
+	run, _ = st.GetRun(ref)
+	if !run.TimedOut(time.Now()) {
+		t.Fatal("run should have timed out")
+	}
+	d := newTestDaemon()
+	d.store = st
+	if err := d.stopTimedOut(run); err != nil {
+		t.Fatal(err)
+	}
+	run, _ = st.GetRun(ref)
+	if run.Status != model.StatusFailed {
+		t.Fatalf("status = %s, want failed", run.Status)
+	}
+	if e := lastEvent(run, model.EventTypeStatus); e.Attrs["reason"] != model.TimeoutReason || e.Attrs["timeout"] != "2h0m0s" {
+		t.Errorf("failed attrs = %v", e.Attrs)
+	}
+}
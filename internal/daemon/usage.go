@@ -0,0 +1,55 @@
+package daemon
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/s22625/orch/internal/model"
+)
+
+// UsageInterval is how often a run's token usage is re-read. Reading it
+// means parsing transcripts or an HTTP call, so it is slower than monitoring.
+const UsageInterval = 30 * time.Second
+
+// recordUsage appends a usage event for every model whose totals changed
+// since the last report, and folds them into run so its totals are current
+func (d *Daemon) recordUsage(run *model.Run) {
+	if d.readUsage == nil {
+		return
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	usages, err := d.readUsage(ctx, run)
+	if err != nil {
+		d.logger.Printf("%s#%s: failed to read usage: %v", run.IssueID, run.RunID, err)
+		return
+	}
+
+	recorded := run.State().Usage
+	for _, u := range usages {
+		event := model.NewUsageEvent(u)
+		// Compare at the precision the event stores
+		if u, _ = model.ParseUsageEvent(event); recorded[u.Model] == u {
+			continue
+		}
+		if err := d.store.AppendEvent(run.Ref(), event); err != nil {
+			d.logger.Printf("%s#%s: failed to record usage: %v", run.IssueID, run.RunID, err)
+			return
+		}
+		run.ApplyEvents(event)
+	}
+}
+
+// stopOverBudget records the run as failed with reason budget_exceeded and
+// kills its session
+func (d *Daemon) stopOverBudget(run *model.Run) error {
+	d.logger.Printf("%s#%s: spent $%.2f of $%.2f budget, stopping", run.IssueID, run.RunID, run.Usage.Cost, run.Budget)
+
+	event := model.NewStatusEvent(model.StatusFailed)
+	event.Attrs["reason"] = model.BudgetExceededReason
+	event.Attrs["cost"] = fmt.Sprintf("%.4f", run.Usage.Cost)
+	event.Attrs["budget"] = fmt.Sprintf("%.2f", run.Budget)
+	return d.stopRun(run, event)
+}
@@ -0,0 +1,230 @@
+package git
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// DiffStat summarizes the changes of a worktree
+type DiffStat struct {
+	Files      int
+	Insertions int
+	Deletions  int
+}
+
+// String renders the stat like "3 files +120 -4"
+func (s *DiffStat) String() string {
+	files := "files"
+	if s.Files == 1 {
+		files = "file"
+	}
+	return fmt.Sprintf("%d %s +%d -%d", s.Files, files, s.Insertions, s.Deletions)
+}
+
+// DiffFormat selects the output of Diff
+type DiffFormat string
+
+const (
+	DiffPatch    DiffFormat = ""
+	DiffSummary  DiffFormat = "stat"      // git diff --stat
+	DiffNameOnly DiffFormat = "name-only" // git diff --name-only
+
+	// DiffSummaryPatch is the diffstat followed by the patch, from one diff
+	DiffSummaryPatch DiffFormat = "stat-patch"
+)
+
+// MergeBase returns the commit the worktree's HEAD forked from target,
+// falling back to the usual default branches when target does not exist
+func MergeBase(worktreePath, target string) (string, error) {
+	return mergeBaseOf(worktreePath, target, "HEAD")
+}
+
+// mergeBaseOf returns the commit rev forked from target, as MergeBase
+func mergeBaseOf(repoPath, target, rev string) (string, error) {
+	candidates := mergeTargetCandidates(target)
+	if target != "" && target != candidates[0] {
+		// A recorded base is exact; try it before its remote/local twin
+		candidates = append([]string{target}, candidates...)
+	}
+	for _, candidate := range candidates {
+		if !revExists(repoPath, candidate) {
+			continue
+		}
+		out, err := exec.Command("git", "-C", repoPath, "merge-base", candidate, rev).Output()
+		if err == nil {
+			return strings.TrimSpace(string(out)), nil
+		}
+	}
+	return "", fmt.Errorf("no merge base with %s found", target)
+}
+
+// GetDiffStat summarizes the changes in a worktree since it forked from
+// target, including uncommitted changes and untracked files
+func GetDiffStat(worktreePath, target string) (*DiffStat, error) {
+	out, err := worktreeDiff(worktreePath, target, "--numstat")
+	if err != nil {
+		return nil, err
+	}
+	return parseNumstat(string(out)), nil
+}
+
+// Diff returns the changes in a worktree since it forked from target in the
+// given format, including uncommitted changes and untracked files
+func Diff(worktreePath, target string, format DiffFormat) (string, error) {
+	args, err := diffFormatArgs(format)
+	if err != nil {
+		return "", err
+	}
+	out, err := worktreeDiff(worktreePath, target, args...)
+	if err != nil {
+		return "", err
+	}
+	return string(out), nil
+}
+
+// BranchDiff returns the commits of branch since it forked from target in
+// the given format, read from the repository rather than a worktree
+func BranchDiff(repoRoot, target, branch string, format DiffFormat) (string, error) {
+	args, err := diffFormatArgs(format)
+	if err != nil {
+		return "", err
+	}
+	base, err := mergeBaseOf(repoRoot, target, branch)
+	if err != nil {
+		return "", err
+	}
+	out, err := exec.Command("git", append(append([]string{"-C", repoRoot, "diff", "--no-color"}, args...), base, branch)...).Output()
+	if err != nil {
+		return "", fmt.Errorf("git diff: %w", err)
+	}
+	return string(out), nil
+}
+
+func diffFormatArgs(format DiffFormat) ([]string, error) {
+	switch format {
+	case DiffPatch:
+		return nil, nil
+	case DiffSummary, DiffNameOnly:
+		return []string{"--" + string(format)}, nil
+	case DiffSummaryPatch:
+		return []string{"--stat", "--patch"}, nil
+	default:
+		return nil, fmt.Errorf("unknown diff format %q", format)
+	}
+}
+
+// WorktreeState fingerprints a worktree's HEAD and uncommitted changes
+// without diffing it: the state changes when HEAD moves or when a file is
+// changed, added or removed, so a diff can be reused while it is the same
+func WorktreeState(worktreePath string) (string, error) {
+	head, err := exec.Command("git", "-C", worktreePath, "rev-parse", "HEAD").Output()
+	if err != nil {
+		return "", fmt.Errorf("git rev-parse: %w", err)
+	}
+	status, err := exec.Command("git", "-C", worktreePath, "--no-optional-locks", "status", "--porcelain", "-z", "--untracked-files=all").Output()
+	if err != nil {
+		return "", fmt.Errorf("git status: %w", err)
+	}
+
+	// The status alone misses a changed file being changed again
+	var b strings.Builder
+	b.Write(head)
+	for _, entry := range strings.Split(string(status), "\x00") {
+		if entry == "" {
+			continue
+		}
+		b.WriteString(entry)
+		if len(entry) > 3 {
+			if info, err := os.Lstat(filepath.Join(worktreePath, entry[3:])); err == nil {
+				fmt.Fprintf(&b, " %d %d", info.Size(), info.ModTime().UnixNano())
+			}
+		}
+		b.WriteByte(0)
+	}
+	return b.String(), nil
+}
+
+// worktreeDiff runs git diff from the merge base with target to the
+// working tree. Untracked files are added intent-to-add to a copy of the
+// index so they show up as new files without touching the real index.
+func worktreeDiff(worktreePath, target string, args ...string) ([]byte, error) {
+	base, err := MergeBase(worktreePath, target)
+	if err != nil {
+		return nil, err
+	}
+
+	cmd := exec.Command("git", append(append([]string{"-C", worktreePath, "diff", "--no-color"}, args...), base)...)
+	if index, err := intentToAddIndex(worktreePath); err == nil {
+		defer os.Remove(index)
+		cmd.Env = append(os.Environ(), "GIT_INDEX_FILE="+index)
+	}
+	out, err := cmd.Output()
+	if err != nil {
+		return nil, fmt.Errorf("git diff: %w", err)
+	}
+	return out, nil
+}
+
+// intentToAddIndex copies the worktree's index to a temporary file and
+// marks its untracked (not ignored) files intent-to-add there
+func intentToAddIndex(worktreePath string) (string, error) {
+	out, err := exec.Command("git", "-C", worktreePath, "rev-parse", "--git-path", "index").Output()
+	if err != nil {
+		return "", err
+	}
+	indexPath := strings.TrimSpace(string(out))
+	if !filepath.IsAbs(indexPath) {
+		indexPath = filepath.Join(worktreePath, indexPath)
+	}
+
+	src, err := os.Open(indexPath)
+	if err != nil {
+		return "", err
+	}
+	defer src.Close()
+	tmp, err := os.CreateTemp("", "orch-index-*")
+	if err != nil {
+		return "", err
+	}
+	_, err = io.Copy(tmp, src)
+	if cerr := tmp.Close(); err == nil {
+		err = cerr
+	}
+	if err != nil {
+		os.Remove(tmp.Name())
+		return "", err
+	}
+
+	cmd := exec.Command("git", "-C", worktreePath, "add", "--intent-to-add", "--all", ".")
+	cmd.Env = append(os.Environ(), "GIT_INDEX_FILE="+tmp.Name())
+	if err := cmd.Run(); err != nil {
+		os.Remove(tmp.Name())
+		return "", err
+	}
+	return tmp.Name(), nil
+}
+
+// parseNumstat totals `git diff --numstat` output; binary files count as
+// changed files without lines
+func parseNumstat(output string) *DiffStat {
+	stat := &DiffStat{}
+	for _, line := range strings.Split(output, "\n") {
+		fields := strings.Fields(line)
+		if len(fields) < 3 {
+			continue
+		}
+		stat.Files++
+		if n, err := strconv.Atoi(fields[0]); err == nil {
+			stat.Insertions += n
+		}
+		if n, err := strconv.Atoi(fields[1]); err == nil {
+			stat.Deletions += n
+		}
+	}
+	return stat
+}
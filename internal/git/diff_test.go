@@ -0,0 +1,139 @@
+package git
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestGetDiffStat(t *testing.T) {
+	repo := initRepo(t)
+	runGit(t, repo, "checkout", "-b", "feature")
+
+	// Committed: one new file with two lines
+	if err := os.WriteFile(filepath.Join(repo, "a.txt"), []byte("one\ntwo\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	runGit(t, repo, "add", "a.txt")
+	runGit(t, repo, "commit", "-m", "add a")
+	// Uncommitted: README rewritten
+	if err := os.WriteFile(filepath.Join(repo, "README.md"), []byte("changed\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	stat, err := GetDiffStat(repo, "main")
+	if err != nil {
+		t.Fatalf("GetDiffStat: %v", err)
+	}
+	if *stat != (DiffStat{Files: 2, Insertions: 3, Deletions: 1}) {
+		t.Errorf("stat = %+v", *stat)
+	}
+	if got := stat.String(); got != "2 files +3 -1" {
+		t.Errorf("String() = %q", got)
+	}
+
+	// An unknown target falls back to the default branch
+	if _, err := GetDiffStat(repo, "no-such-branch"); err != nil {
+		t.Errorf("GetDiffStat with fallback: %v", err)
+	}
+}
+
+func TestDiff(t *testing.T) {
+	repo := initRepo(t)
+	runGit(t, repo, "checkout", "-b", "feature")
+	if err := os.WriteFile(filepath.Join(repo, "README.md"), []byte("changed\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	// Never added: still part of the run's changes
+	if err := os.WriteFile(filepath.Join(repo, "new.txt"), []byte("new\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	names, err := Diff(repo, "main", DiffNameOnly)
+	if err != nil {
+		t.Fatalf("Diff --name-only: %v", err)
+	}
+	if names != "README.md\nnew.txt\n" {
+		t.Errorf("names = %q", names)
+	}
+	patch, err := Diff(repo, "main", DiffPatch)
+	if err != nil {
+		t.Fatalf("Diff: %v", err)
+	}
+	for _, want := range []string{"+changed", "+new", "new file mode"} {
+		if !strings.Contains(patch, want) {
+			t.Errorf("patch missing %q:\n%s", want, patch)
+		}
+	}
+	if stat, err := GetDiffStat(repo, "main"); err != nil || stat.Files != 2 {
+		t.Errorf("GetDiffStat = %+v, %v", stat, err)
+	}
+	both, err := Diff(repo, "main", DiffSummaryPatch)
+	if err != nil {
+		t.Fatalf("Diff stat-patch: %v", err)
+	}
+	summary, _ := Diff(repo, "main", DiffSummary)
+	if both != summary+"\n"+patch {
+		t.Errorf("stat-patch = %q, want the stat then the patch", both)
+	}
+
+	// The real index is left alone
+	out, err := exec.Command("git", "-C", repo, "status", "--porcelain").Output()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(out), "?? new.txt") {
+		t.Errorf("status = %q, want new.txt untracked", out)
+	}
+
+	if _, err := Diff(repo, "main", DiffFormat("bogus")); err == nil {
+		t.Error("an unknown format should be rejected")
+	}
+}
+
+func TestWorktreeState(t *testing.T) {
+	repo := initRepo(t)
+	state := func() string {
+		t.Helper()
+		s, err := WorktreeState(repo)
+		if err != nil {
+			t.Fatalf("WorktreeState: %v", err)
+		}
+		return s
+	}
+
+	clean := state()
+	if state() != clean {
+		t.Error("state changed without any change")
+	}
+	if err := os.WriteFile(filepath.Join(repo, "README.md"), []byte("changed\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	modified := state()
+	if modified == clean {
+		t.Error("state unchanged after modifying a file")
+	}
+	// Same status line, new content
+	if err := os.WriteFile(filepath.Join(repo, "README.md"), []byte("changed again\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if state() == modified {
+		t.Error("state unchanged after modifying a modified file")
+	}
+	runGit(t, repo, "commit", "-am", "change")
+	if state() == clean {
+		t.Error("state unchanged after a commit")
+	}
+}
+
+func TestParseNumstat(t *testing.T) {
+	stat := parseNumstat("10\t2\tmain.go\n-\t-\tlogo.png\n\n")
+	if *stat != (DiffStat{Files: 2, Insertions: 10, Deletions: 2}) {
+		t.Errorf("stat = %+v", *stat)
+	}
+	if got := (&DiffStat{Files: 1, Insertions: 1}).String(); got != "1 file +1 -0" {
+		t.Errorf("String() = %q", got)
+	}
+}
@@ -275,6 +275,16 @@ func RemoveWorktree(repoRoot, worktreePath string) error {
 	return cmd.Run()
 }
 
+// HasUncommittedChanges reports whether a worktree has modified, staged or
+// untracked files
+func HasUncommittedChanges(worktreePath string) (bool, error) {
+	out, err := execCommand("git", "-C", worktreePath, "status", "--porcelain", "--untracked-files=all").Output()
+	if err != nil {
+		return false, fmt.Errorf("git status: %w", err)
+	}
+	return len(strings.TrimSpace(string(out))) > 0, nil
+}
+
 // ListWorktreeInfos returns detailed worktree information for a repository.
 func ListWorktreeInfos(repoRoot string) ([]WorktreeInfo, error) {
 	if repoRoot == "" {
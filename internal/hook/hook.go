@@ -0,0 +1,301 @@
+package hook
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"os"
+	"os/exec"
+	"sync"
+	"time"
+
+	"github.com/s22625/orch/internal/config"
+	"github.com/s22625/orch/internal/model"
+)
+
+// Hook events
+const (
+	EventStatus        = "status"
+	EventPR            = "pr"
+	EventIssueResolved = "issue_resolved"
+)
+
+const (
+	DefaultTimeout = 10 * time.Second
+	DefaultRetries = 3
+	// DefaultBackoff is the wait before the first retry; it doubles after
+	// every failed attempt
+	DefaultBackoff = 2 * time.Second
+)
+
+// Payload is the JSON document a hook receives on stdin or in the POST body
+type Payload struct {
+	Event string    `json:"event"`
+	Time  time.Time `json:"time"`
+	Text  string    `json:"text"` // one-line summary (also what Slack webhooks display)
+
+	IssueID    string `json:"issue_id"`
+	IssueTitle string `json:"issue_title,omitempty"`
+	RunID      string `json:"run_id,omitempty"`
+	RunRef     string `json:"run_ref,omitempty"` // ISSUE#RUN
+	ShortID    string `json:"short_id,omitempty"`
+	Agent      string `json:"agent,omitempty"`
+	Branch     string `json:"branch,omitempty"`
+
+	Status         string `json:"status,omitempty"`
+	PreviousStatus string `json:"previous_status,omitempty"`
+	Reason         string `json:"reason,omitempty"`
+	PRUrl          string `json:"pr_url,omitempty"`
+}
+
+// Matches reports whether a hook is registered for the payload's event
+func Matches(h config.HookConfig, p *Payload) bool {
+	switch p.Event {
+	case EventStatus:
+		for _, s := range h.OnStatus {
+			if s == p.Status {
+				return true
+			}
+		}
+	case EventPR:
+		return h.OnPR
+	case EventIssueResolved:
+		return h.OnIssueResolved
+	}
+	return false
+}
+
+// Validate reports a hook that cannot be delivered
+func Validate(h config.HookConfig) error {
+	switch {
+	case h.Command == "" && h.URL == "":
+		return fmt.Errorf("hook %s: command or url is required", name(h))
+	case h.Command != "" && h.URL != "":
+		return fmt.Errorf("hook %s: set only one of command and url", name(h))
+	case len(h.OnStatus) == 0 && !h.OnPR && !h.OnIssueResolved:
+		return fmt.Errorf("hook %s: no events (on_status, on_pr, on_issue_resolved)", name(h))
+	}
+	return nil
+}
+
+func name(h config.HookConfig) string {
+	switch {
+	case h.Name != "":
+		return h.Name
+	case h.URL != "":
+		return h.URL
+	default:
+		return h.Command
+	}
+}
+
+// Dispatcher delivers payloads to the registered hooks in the background,
+// retrying failed deliveries. A nil Dispatcher fires nothing.
+type Dispatcher struct {
+	hooks   []config.HookConfig
+	logger  *log.Logger
+	client  *http.Client
+	backoff time.Duration
+	wg      sync.WaitGroup
+}
+
+// NewDispatcher returns a dispatcher for the valid hooks; invalid ones are
+// logged and skipped
+func NewDispatcher(hooks []config.HookConfig, logger *log.Logger) *Dispatcher {
+	if logger == nil {
+		logger = log.New(io.Discard, "", 0)
+	}
+	d := &Dispatcher{
+		logger:  logger,
+		client:  &http.Client{},
+		backoff: DefaultBackoff,
+	}
+	for _, h := range hooks {
+		if err := Validate(h); err != nil {
+			logger.Printf("warning: skipping %v", err)
+			continue
+		}
+		d.hooks = append(d.hooks, h)
+	}
+	return d
+}
+
+// SetBackoff sets the wait before the first retry
+func (d *Dispatcher) SetBackoff(backoff time.Duration) {
+	d.backoff = backoff
+}
+
+// Fire delivers p to every matching hook without waiting for delivery
+func (d *Dispatcher) Fire(p *Payload) {
+	if d == nil {
+		return
+	}
+	if p.Time.IsZero() {
+		p.Time = time.Now()
+	}
+	body, err := json.Marshal(p)
+	if err != nil {
+		d.logger.Printf("hook: failed to encode payload: %v", err)
+		return
+	}
+	for _, h := range d.hooks {
+		if !Matches(h, p) {
+			continue
+		}
+		d.wg.Add(1)
+		go func(h config.HookConfig) {
+			defer d.wg.Done()
+			d.deliver(h, p, body)
+		}(h)
+	}
+}
+
+// Wait blocks until all fired deliveries have finished
+func (d *Dispatcher) Wait() {
+	if d == nil {
+		return
+	}
+	d.wg.Wait()
+}
+
+func (d *Dispatcher) deliver(h config.HookConfig, p *Payload, body []byte) {
+	retries := h.Retries
+	if retries <= 0 {
+		retries = DefaultRetries
+	}
+	timeout := h.Timeout
+	if timeout <= 0 {
+		timeout = DefaultTimeout
+	}
+	what := fmt.Sprintf("hook %s (%s %s)", name(h), p.Event, p.subject())
+
+	wait := d.backoff
+	for attempt := 1; ; attempt++ {
+		ctx, cancel := context.WithTimeout(context.Background(), timeout)
+		var err error
+		if h.URL != "" {
+			err = d.post(ctx, h, body)
+		} else {
+			err = runCommand(ctx, h, p, body)
+		}
+		cancel()
+		if err == nil {
+			d.logger.Printf("%s: delivered", what)
+			return
+		}
+		if attempt > retries {
+			d.logger.Printf("%s: giving up after %d attempts: %v", what, attempt, err)
+			return
+		}
+		d.logger.Printf("%s: attempt %d/%d failed: %v", what, attempt, retries+1, err)
+		time.Sleep(wait)
+		wait *= 2
+	}
+}
+
+func (p *Payload) subject() string {
+	switch {
+	case p.RunRef != "" && p.Status != "":
+		return p.RunRef + " " + p.Status
+	case p.RunRef != "":
+		return p.RunRef
+	default:
+		return p.IssueID
+	}
+}
+
+func (d *Dispatcher) post(ctx context.Context, h config.HookConfig, body []byte) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, h.URL, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "orch-hook")
+	for k, v := range h.Headers {
+		req.Header.Set(k, os.ExpandEnv(v))
+	}
+	resp, err := d.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	io.Copy(io.Discard, io.LimitReader(resp.Body, 64<<10))
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("%s returned %s", h.URL, resp.Status)
+	}
+	return nil
+}
+
+func runCommand(ctx context.Context, h config.HookConfig, p *Payload, body []byte) error {
+	cmd := exec.CommandContext(ctx, "sh", "-c", h.Command)
+	cmd.Stdin = bytes.NewReader(body)
+	cmd.Env = append(os.Environ(),
+		"ORCH_HOOK_EVENT="+p.Event,
+		"ORCH_ISSUE_ID="+p.IssueID,
+		"ORCH_RUN_ID="+p.RunID,
+		"ORCH_STATUS="+p.Status,
+		"ORCH_PR_URL="+p.PRUrl,
+	)
+	out, err := cmd.CombinedOutput()
+	if err != nil {
+		if len(out) > 0 {
+			return fmt.Errorf("%w: %s", err, bytes.TrimSpace(out))
+		}
+		return err
+	}
+	return nil
+}
+
+func runPayload(event string, run *model.Run) *Payload {
+	return &Payload{
+		Event:   event,
+		IssueID: run.IssueID,
+		RunID:   run.RunID,
+		RunRef:  run.Ref().String(),
+		ShortID: run.ShortID(),
+		Agent:   run.Agent,
+		Branch:  run.Branch,
+		PRUrl:   run.PRUrl,
+	}
+}
+
+// StatusPayload describes a run moving from its current status to status
+func StatusPayload(run *model.Run, status model.Status, reason string) *Payload {
+	p := runPayload(EventStatus, run)
+	p.Status = string(status)
+	p.PreviousStatus = string(run.Status)
+	p.Reason = reason
+	p.Text = fmt.Sprintf("%s (%s) is %s", p.RunRef, p.ShortID, status)
+	if reason != "" {
+		p.Text += " (" + reason + ")"
+	}
+	return p
+}
+
+// PRPayload describes a PR detected for a run
+func PRPayload(run *model.Run, url string) *Payload {
+	p := runPayload(EventPR, run)
+	p.Status = string(run.Status)
+	p.PRUrl = url
+	p.Text = fmt.Sprintf("%s (%s) opened %s", p.RunRef, p.ShortID, url)
+	return p
+}
+
+// IssueResolvedPayload describes an issue that became resolved
+func IssueResolvedPayload(issue *model.Issue) *Payload {
+	p := &Payload{
+		Event:      EventIssueResolved,
+		IssueID:    issue.ID,
+		IssueTitle: issue.Title,
+		Status:     string(issue.Status),
+	}
+	p.Text = fmt.Sprintf("%s resolved", issue.ID)
+	if issue.Title != "" {
+		p.Text += ": " + issue.Title
+	}
+	return p
+}
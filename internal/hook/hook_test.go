@@ -0,0 +1,184 @@
+package hook
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/s22625/orch/internal/config"
+	"github.com/s22625/orch/internal/model"
+)
+
+// syncBuffer is a log destination safe for concurrent deliveries
+type syncBuffer struct {
+	mu  sync.Mutex
+	buf bytes.Buffer
+}
+
+func (b *syncBuffer) Write(p []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.Write(p)
+}
+
+func (b *syncBuffer) String() string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.String()
+}
+
+func testRun() *model.Run {
+	run := &model.Run{IssueID: "orch-1", RunID: "20240101-000000", Events: []*model.Event{
+		model.NewStatusEvent(model.StatusRunning),
+		model.NewArtifactEvent("branch", map[string]string{"name": "issue/orch-1/run-20240101-000000"}),
+	}}
+	run.Agent = "claude"
+	run.DeriveState()
+	return run
+}
+
+func TestWebhookRetriedUntilDelivered(t *testing.T) {
+	var (
+		mu       sync.Mutex
+		attempts int
+		payload  Payload
+		header   http.Header
+	)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+		attempts++
+		if attempts < 3 {
+			http.Error(w, "try later", http.StatusServiceUnavailable)
+			return
+		}
+		header = r.Header
+		body, _ := io.ReadAll(r.Body)
+		if err := json.Unmarshal(body, &payload); err != nil {
+			t.Errorf("payload: %v", err)
+		}
+	}))
+	defer srv.Close()
+
+	t.Setenv("SLACK_TOKEN", "secret")
+	logs := &syncBuffer{}
+	d := NewDispatcher([]config.HookConfig{{
+		Name:     "slack",
+		OnStatus: []string{"blocked"},
+		URL:      srv.URL,
+		Headers:  map[string]string{"Authorization": "Bearer $SLACK_TOKEN"},
+	}}, log.New(logs, "", 0))
+	d.SetBackoff(time.Millisecond)
+
+	d.Fire(StatusPayload(testRun(), model.StatusRunning, "")) // not registered
+	d.Fire(StatusPayload(testRun(), model.StatusBlocked, ""))
+	d.Wait()
+
+	if attempts != 3 {
+		t.Fatalf("attempts = %d, want 3", attempts)
+	}
+	if payload.Event != EventStatus || payload.Status != "blocked" || payload.PreviousStatus != "running" {
+		t.Errorf("payload = %+v", payload)
+	}
+	if payload.RunRef != "orch-1#20240101-000000" || payload.Branch != "issue/orch-1/run-20240101-000000" {
+		t.Errorf("payload = %+v", payload)
+	}
+	if !strings.Contains(payload.Text, "is blocked") {
+		t.Errorf("text = %q", payload.Text)
+	}
+	if got := header.Get("Authorization"); got != "Bearer secret" {
+		t.Errorf("Authorization = %q", got)
+	}
+	out := logs.String()
+	if !strings.Contains(out, "hook slack (status orch-1#20240101-000000 blocked): attempt 2/4 failed") ||
+		!strings.Contains(out, "hook slack (status orch-1#20240101-000000 blocked): delivered") {
+		t.Errorf("log:\n%s", out)
+	}
+}
+
+func TestWebhookGivesUp(t *testing.T) {
+	attempts := 0
+	var mu sync.Mutex
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		attempts++
+		mu.Unlock()
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer srv.Close()
+
+	logs := &syncBuffer{}
+	d := NewDispatcher([]config.HookConfig{{OnPR: true, URL: srv.URL, Retries: 1}}, log.New(logs, "", 0))
+	d.SetBackoff(time.Millisecond)
+	d.Fire(PRPayload(testRun(), "https://github.com/org/repo/pull/1"))
+	d.Wait()
+
+	if attempts != 2 {
+		t.Errorf("attempts = %d, want 2", attempts)
+	}
+	if !strings.Contains(logs.String(), "giving up after 2 attempts") {
+		t.Errorf("log:\n%s", logs.String())
+	}
+}
+
+func TestCommandHookReadsPayload(t *testing.T) {
+	out := filepath.Join(t.TempDir(), "payload.json")
+	d := NewDispatcher([]config.HookConfig{{
+		OnIssueResolved: true,
+		Command:         `cat > "$OUT"; echo "$ORCH_HOOK_EVENT $ORCH_ISSUE_ID" >> "$OUT.env"`,
+	}}, nil)
+	t.Setenv("OUT", out)
+
+	d.Fire(IssueResolvedPayload(&model.Issue{ID: "orch-7", Title: "Add hooks", Status: model.IssueStatusResolved}))
+	d.Wait()
+
+	data, err := os.ReadFile(out)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var p Payload
+	if err := json.Unmarshal(data, &p); err != nil {
+		t.Fatal(err)
+	}
+	if p.Event != EventIssueResolved || p.IssueID != "orch-7" || p.Text != "orch-7 resolved: Add hooks" {
+		t.Errorf("payload = %+v", p)
+	}
+	env, _ := os.ReadFile(out + ".env")
+	if strings.TrimSpace(string(env)) != "issue_resolved orch-7" {
+		t.Errorf("env = %q", env)
+	}
+}
+
+func TestValidate(t *testing.T) {
+	tests := []struct {
+		hook config.HookConfig
+		ok   bool
+	}{
+		{config.HookConfig{OnPR: true, URL: "http://x"}, true},
+		{config.HookConfig{OnStatus: []string{"failed"}, Command: "true"}, true},
+		{config.HookConfig{OnPR: true}, false},
+		{config.HookConfig{OnPR: true, URL: "http://x", Command: "true"}, false},
+		{config.HookConfig{URL: "http://x"}, false},
+	}
+	for _, tt := range tests {
+		if err := Validate(tt.hook); (err == nil) != tt.ok {
+			t.Errorf("Validate(%+v) = %v", tt.hook, err)
+		}
+	}
+
+	// Invalid hooks are skipped rather than failing every delivery
+	logs := &syncBuffer{}
+	d := NewDispatcher([]config.HookConfig{{Name: "broken", OnPR: true}}, log.New(logs, "", 0))
+	if len(d.hooks) != 0 || !strings.Contains(logs.String(), "skipping hook broken") {
+		t.Errorf("hooks = %v, log = %q", d.hooks, logs.String())
+	}
+}
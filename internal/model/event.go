@@ -17,6 +17,10 @@ const (
 	EventTypeArtifact EventType = "artifact"
 	EventTypeTest     EventType = "test"
 	EventTypeNote     EventType = "note"
+	EventTypeUsage    EventType = "usage"
+	EventTypeTool     EventType = "tool"    // tool call of a structured-output run
+	EventTypeMessage  EventType = "message" // assistant message of a structured-output run
+	EventTypeMonitor  EventType = "monitor" // daemon observation or action (e.g. auto_resume)
 )
 
 // Status represents run operational lifecycle states
@@ -0,0 +1,58 @@
+package model
+
+// FanoutArtifact groups the sibling runs `orch run --fanout` starts for one
+// issue, one per agent
+const FanoutArtifact = "fanout"
+
+// Attributes of the fanout artifact
+const (
+	AttrGroup  = "group"  // shared by the siblings
+	AttrSpec   = "spec"   // agent the run was started with, as given to --fanout
+	AttrWinner = "winner" // "true" on the run chosen with orch pick
+)
+
+// TestsArtifact records the result of the test command `orch compare --test`
+// ran in the run's worktree
+const TestsArtifact = "tests"
+
+// Attributes of the tests artifact
+const (
+	AttrResult  = "result" // TestsPass or TestsFail
+	AttrCommand = "command"
+)
+
+// Results of the tests artifact
+const (
+	TestsPass = "pass"
+	TestsFail = "fail"
+)
+
+// GenerateFanoutGroup generates the group ID of the runs fanned out from
+// the given issue and base run ID
+func GenerateFanoutGroup(issueID, runID string) string {
+	return "fo-" + GenerateShortID(issueID, "fanout-"+runID)
+}
+
+// NewFanoutArtifactEvent records a run's membership of a fanout group
+func NewFanoutArtifactEvent(group, spec string, winner bool) *Event {
+	attrs := map[string]string{
+		AttrGroup: group,
+		AttrSpec:  spec,
+	}
+	if winner {
+		attrs[AttrWinner] = "true"
+	}
+	return NewArtifactEvent(FanoutArtifact, attrs)
+}
+
+// NewTestsArtifactEvent records whether the test command passed
+func NewTestsArtifactEvent(command string, passed bool) *Event {
+	result := TestsFail
+	if passed {
+		result = TestsPass
+	}
+	return NewArtifactEvent(TestsArtifact, map[string]string{
+		AttrResult:  result,
+		AttrCommand: command,
+	})
+}
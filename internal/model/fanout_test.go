@@ -0,0 +1,69 @@
+package model
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestFanoutArtifacts(t *testing.T) {
+	group := GenerateFanoutGroup("orch-1", "20250101-090000")
+	if !strings.HasPrefix(group, "fo-") || group != GenerateFanoutGroup("orch-1", "20250101-090000") {
+		t.Fatalf("group = %q, want a stable fo- ID", group)
+	}
+	if group == GenerateFanoutGroup("orch-1", "20250101-090001") {
+		t.Fatal("groups of different fanouts collide")
+	}
+
+	run := &Run{}
+	run.ApplyEvents(
+		NewStatusEvent(StatusRunning),
+		NewFanoutArtifactEvent(group, "opencode:opus:high", false),
+	)
+	if run.FanoutGroup != group || run.FanoutSpec != "opencode:opus:high" || run.FanoutWinner {
+		t.Fatalf("fanout = %q %q %v", run.FanoutGroup, run.FanoutSpec, run.FanoutWinner)
+	}
+	if run.Tests != "" {
+		t.Fatalf("Tests = %q before any test run", run.Tests)
+	}
+
+	run.ApplyEvents(
+		NewTestsArtifactEvent("go test ./...", false),
+		NewTestsArtifactEvent("go test ./...", true),
+		NewFanoutArtifactEvent(group, "opencode:opus:high", true),
+	)
+	if !run.FanoutWinner || run.FanoutGroup != group {
+		t.Errorf("winner = %v, group %q", run.FanoutWinner, run.FanoutGroup)
+	}
+	if run.Tests != TestsPass {
+		t.Errorf("Tests = %q, want the last result", run.Tests)
+	}
+}
+
+func TestRunElapsed(t *testing.T) {
+	created := time.Date(2025, 10, 14, 9, 0, 0, 0, time.UTC)
+	at := func(ts time.Time, e *Event) *Event {
+		e.Timestamp = ts
+		return e
+	}
+	run := &Run{Events: []*Event{
+		at(created, NewStatusEvent(StatusQueued)),
+		at(created.Add(time.Minute), NewStatusEvent(StatusBooting)),
+		at(created.Add(2*time.Minute), NewStatusEvent(StatusRunning)),
+	}}
+	run.DeriveState()
+
+	now := created.Add(time.Hour)
+	if got := run.Elapsed(now); got != 59*time.Minute {
+		t.Errorf("running Elapsed = %v, want 59m", got)
+	}
+
+	run.ApplyEvents(at(created.Add(31*time.Minute), NewStatusEvent(StatusDone)))
+	if got := run.Elapsed(now); got != 30*time.Minute {
+		t.Errorf("done Elapsed = %v, want 30m", got)
+	}
+
+	if got := (&Run{}).Elapsed(now); got != 0 {
+		t.Errorf("Elapsed without events = %v", got)
+	}
+}
@@ -0,0 +1,65 @@
+package model
+
+import (
+	"sort"
+	"strconv"
+)
+
+// QueueArtifact is the artifact of a run created with `orch run --queue`.
+// Besides the scheduling attributes below it carries the launch options the
+// daemon passes back to `orch run` when it starts the run.
+const QueueArtifact = "queue"
+
+// Scheduling attributes of the queue artifact
+const (
+	AttrPriority = "priority" // higher starts first
+	AttrRepo     = "repo"     // main repository root, for per-repo limits
+)
+
+// DequeuedReason is the reason attribute of the booting status event the
+// daemon records when it takes a run off the queue
+const DequeuedReason = "dequeued"
+
+// NewQueueArtifactEvent records that a run waits for the scheduler.
+// opts holds the launch options; empty values are dropped.
+func NewQueueArtifactEvent(priority int, repo string, opts map[string]string) *Event {
+	attrs := map[string]string{
+		AttrPriority: strconv.Itoa(priority),
+		AttrRepo:     repo,
+	}
+	for k, v := range opts {
+		if v != "" {
+			attrs[k] = v
+		}
+	}
+	return NewArtifactEvent(QueueArtifact, attrs)
+}
+
+// ActiveStatuses are the statuses that occupy a concurrency slot: the agent
+// is starting or working. Runs waiting on a human (blocked, pr_open) do not.
+var ActiveStatuses = []Status{StatusBooting, StatusRunning, StatusBlockedAPI, StatusUnknown}
+
+// IsActive reports whether the status is one of ActiveStatuses
+func (s Status) IsActive() bool {
+	for _, active := range ActiveStatuses {
+		if s == active {
+			return true
+		}
+	}
+	return false
+}
+
+// SortQueue orders queued runs by priority (highest first), then by the time
+// they were queued
+func SortQueue(runs []*Run) {
+	sort.SliceStable(runs, func(i, j int) bool {
+		a, b := runs[i], runs[j]
+		if a.Priority != b.Priority {
+			return a.Priority > b.Priority
+		}
+		if !a.StartedAt.Equal(b.StartedAt) {
+			return a.StartedAt.Before(b.StartedAt)
+		}
+		return a.Ref().String() < b.Ref().String()
+	})
+}
@@ -0,0 +1,60 @@
+package model
+
+import (
+	"testing"
+	"time"
+)
+
+func TestQueueArtifactDerivesScheduling(t *testing.T) {
+	run := &Run{IssueID: "orch-1", RunID: "20231220-100000"}
+	run.ApplyEvents(
+		NewStatusEvent(StatusQueued),
+		NewQueueArtifactEvent(5, "/src/orch", map[string]string{"branch": "feature/x", "profile": ""}),
+	)
+	if !run.Queued || run.Priority != 5 || run.Repo != "/src/orch" {
+		t.Fatalf("run = queued %v, priority %d, repo %q", run.Queued, run.Priority, run.Repo)
+	}
+	attrs := run.State().Artifacts[QueueArtifact]
+	if attrs["branch"] != "feature/x" {
+		t.Errorf("branch = %q", attrs["branch"])
+	}
+	if _, ok := attrs["profile"]; ok {
+		t.Error("empty options should not be recorded")
+	}
+}
+
+func TestSortQueue(t *testing.T) {
+	base := time.Date(2025, 1, 1, 10, 0, 0, 0, time.UTC)
+	newRun := func(id string, priority int, queuedAt time.Duration) *Run {
+		return &Run{IssueID: "orch-1", RunID: id, Priority: priority, StartedAt: base.Add(queuedAt)}
+	}
+	runs := []*Run{
+		newRun("a", 0, 2*time.Minute),
+		newRun("b", 0, time.Minute),
+		newRun("c", 10, 3*time.Minute),
+		newRun("d", -1, 0),
+		newRun("e", 0, time.Minute),
+	}
+	SortQueue(runs)
+
+	var got string
+	for _, run := range runs {
+		got += run.RunID
+	}
+	if got != "cbead" {
+		t.Errorf("order = %s, want cbead", got)
+	}
+}
+
+func TestStatusIsActive(t *testing.T) {
+	active := map[Status]bool{
+		StatusQueued: false, StatusBooting: true, StatusRunning: true, StatusBlocked: false,
+		StatusBlockedAPI: true, StatusPROpen: false, StatusUnknown: true,
+		StatusDone: false, StatusFailed: false, StatusCanceled: false,
+	}
+	for status, want := range active {
+		if got := status.IsActive(); got != want {
+			t.Errorf("%s.IsActive() = %v, want %v", status, got, want)
+		}
+	}
+}
@@ -52,26 +52,59 @@ type Run struct {
 	Path    string // File path to run document
 
 	// Derived from events
-	Status    Status
-	Phase     Phase
-	Events    []*Event
-	StartedAt time.Time
-	UpdatedAt time.Time
+	Status     Status
+	Phase      Phase
+	Events     []*Event
+	StartedAt  time.Time
+	UpdatedAt  time.Time
+	LaunchedAt time.Time // first status after queued
 
 	// Artifacts (from events)
 	Agent             string
 	Model             string
 	ModelVariant      string
 	Branch            string
+	BaseBranch        string // what the branch was created from ("" if not recorded)
 	WorktreePath      string
 	TmuxSession       string
 	TmuxWindowID      string
+	Terminal          string // terminal backend of the session ("" is tmux)
 	PRUrl             string
 	ServerPort        int    // Port for HTTP-based agents (e.g., opencode)
 	OpenCodeSessionID string // Session ID for opencode agent
+	StreamLog         string // NDJSON log of a structured-output (stream-json) run
+	StreamStderr      string // stderr of a structured-output run, kept out of StreamLog
+	Transcript        string // pane output with control sequences stripped
+	TranscriptRaw     string // pane output as received
+	TranscriptCast    string // asciicast v2 recording of the pane
+
+	// Spend (from usage events and the budget artifact)
+	Usage  Usage   // totals across all models
+	Budget float64 // USD limit; 0 means unlimited
+
+	// Limits enforced by the daemon (from the timeout artifact); 0 means none
+	Timeout      time.Duration
+	StallTimeout time.Duration
+
+	// Scheduling (from the queue artifact of orch run --queue)
+	Queued   bool   // waits for or was started by the daemon scheduler
+	Priority int    // higher starts first
+	Repo     string // main repository root
+
+	// Fanout (from the fanout artifact of orch run --fanout)
+	FanoutGroup  string // shared by the sibling runs
+	FanoutSpec   string // agent the run was started with
+	FanoutWinner bool   // chosen with orch pick
+
+	// Result of the last orch compare --test (TestsPass, TestsFail or "")
+	Tests string
 
 	// Frontmatter metadata
 	ContinuedFrom string
+
+	// Incremental derivation state (see DeriveState)
+	state   *RunState
+	applied int // number of Events folded into state
 }
 
 // Ref returns the RunRef for this run
@@ -87,6 +120,35 @@ func (r *Run) ShortID() string {
 	return GenerateShortID(r.IssueID, r.RunID)
 }
 
+// DiffBase returns the branch the run's changes are measured against: the
+// one recorded when its worktree was created, or defaultBase (the configured
+// base_branch) for runs created before it was recorded, or main
+func (r *Run) DiffBase(defaultBase string) string {
+	if r.BaseBranch != "" {
+		return r.BaseBranch
+	}
+	if defaultBase != "" {
+		return defaultBase
+	}
+	return "main"
+}
+
+// Elapsed returns how long the run has been out of the queue: until its
+// last event once it has finished, until now otherwise
+func (r *Run) Elapsed(now time.Time) time.Duration {
+	start := r.LaunchedAt
+	if start.IsZero() {
+		start = r.StartedAt
+	}
+	if start.IsZero() {
+		return 0
+	}
+	if r.Status.IsFinished() {
+		return r.UpdatedAt.Sub(start)
+	}
+	return now.Sub(start)
+}
+
 // GenerateShortID generates a 6-char hex ID from issue and run IDs
 func GenerateShortID(issueID, runID string) string {
 	h := sha256.Sum256([]byte(issueID + "#" + runID))
@@ -102,53 +164,134 @@ func GenerateWorktreeName(issueID, runID, agent string) string {
 	return fmt.Sprintf("%s_%s_%s", GenerateShortID(issueID, runID), agent, runID)
 }
 
-// GetStatus derives status from events (last status event wins)
-func (r *Run) GetStatus() Status {
-	for i := len(r.Events) - 1; i >= 0; i-- {
-		e := r.Events[i]
-		if e.Type == EventTypeStatus {
-			return Status(e.Name)
-		}
+// RunState is the result of folding a run's events. It can be persisted
+// and extended with newly appended events without replaying earlier ones.
+type RunState struct {
+	Status    Status                       `json:"status"`
+	Phase     Phase                        `json:"phase,omitempty"`
+	Artifacts map[string]map[string]string `json:"artifacts,omitempty"`
+	Usage     map[string]Usage             `json:"usage,omitempty"` // latest usage per model
+	StartedAt time.Time                    `json:"started_at"`
+	UpdatedAt time.Time                    `json:"updated_at"`
+	// LaunchedAt is when the run first left queued
+	LaunchedAt time.Time `json:"launched_at,omitempty"`
+	Events     int       `json:"events"` // number of events folded in
+}
+
+// NewRunState returns the state of a run with no events
+func NewRunState() *RunState {
+	return &RunState{
+		Status:    StatusQueued,
+		Artifacts: make(map[string]map[string]string),
 	}
-	return StatusQueued
 }
 
-// GetPhase derives phase from events (last phase event wins).
-func (r *Run) GetPhase() Phase {
-	for i := len(r.Events) - 1; i >= 0; i-- {
-		e := r.Events[i]
-		if e.Type == EventTypePhase {
-			return Phase(e.Name)
+// Apply folds one event into the state (last status/phase/artifact and
+// last usage per model wins)
+func (s *RunState) Apply(e *Event) {
+	switch e.Type {
+	case EventTypeStatus:
+		s.Status = Status(e.Name)
+		if s.LaunchedAt.IsZero() && s.Status != StatusQueued {
+			s.LaunchedAt = e.Timestamp
+		}
+	case EventTypePhase:
+		s.Phase = Phase(e.Name)
+	case EventTypeArtifact:
+		if s.Artifacts == nil {
+			s.Artifacts = make(map[string]map[string]string)
+		}
+		s.Artifacts[e.Name] = e.Attrs
+	case EventTypeUsage:
+		if s.Usage == nil {
+			s.Usage = make(map[string]Usage)
 		}
+		u, _ := ParseUsageEvent(e)
+		s.Usage[u.Model] = u
 	}
-	return ""
+	if s.Events == 0 {
+		s.StartedAt = e.Timestamp
+	}
+	s.UpdatedAt = e.Timestamp
+	s.Events++
 }
 
-// GetArtifacts extracts artifacts from events
-func (r *Run) GetArtifacts() map[string]map[string]string {
-	artifacts := make(map[string]map[string]string)
-	for _, e := range r.Events {
-		if e.Type == EventTypeArtifact {
-			artifacts[e.Name] = e.Attrs
+// Clone returns a deep copy of the state
+func (s *RunState) Clone() *RunState {
+	c := *s
+	c.Artifacts = make(map[string]map[string]string, len(s.Artifacts))
+	for name, attrs := range s.Artifacts {
+		copied := make(map[string]string, len(attrs))
+		for k, v := range attrs {
+			copied[k] = v
+		}
+		c.Artifacts[name] = copied
+	}
+	if s.Usage != nil {
+		c.Usage = make(map[string]Usage, len(s.Usage))
+		for name, u := range s.Usage {
+			c.Usage[name] = u
 		}
 	}
-	return artifacts
+	return &c
 }
 
-// DeriveState updates Status and artifacts from events
+// DeriveState updates Status and artifacts from events. Events already
+// folded by an earlier call are not replayed, so calling DeriveState after
+// appending to Events only processes the new tail.
 func (r *Run) DeriveState() {
-	r.Status = r.GetStatus()
-	r.Phase = r.GetPhase()
+	if r.state == nil || r.applied > len(r.Events) {
+		r.state = NewRunState()
+		r.applied = 0
+	}
+	for _, e := range r.Events[r.applied:] {
+		r.state.Apply(e)
+	}
+	r.applied = len(r.Events)
+	r.applyState()
+}
+
+// ApplyEvents appends events and folds them into the derived state
+func (r *Run) ApplyEvents(events ...*Event) {
+	if r.state == nil {
+		r.DeriveState()
+	}
+	r.Events = append(r.Events, events...)
+	r.DeriveState()
+}
+
+// RestoreState seeds the derived state from a persisted RunState without
+// loading the events it was built from. Subsequent ApplyEvents calls extend it.
+func (r *Run) RestoreState(state *RunState) {
+	r.state = state.Clone()
+	r.applied = len(r.Events)
+	r.applyState()
+}
+
+// State returns a copy of the derived state for persistence
+func (r *Run) State() *RunState {
+	if r.state == nil {
+		r.DeriveState()
+	}
+	return r.state.Clone()
+}
+
+// applyState copies the folded state onto the run's fields
+func (r *Run) applyState() {
+	r.Status = r.state.Status
+	r.Phase = r.state.Phase
 
-	artifacts := r.GetArtifacts()
+	artifacts := r.state.Artifacts
 	if worktree, ok := artifacts["worktree"]; ok {
 		r.WorktreePath = worktree["path"]
 	}
 	if branch, ok := artifacts["branch"]; ok {
 		r.Branch = branch["name"]
+		r.BaseBranch = branch["base"]
 	}
 	if session, ok := artifacts["session"]; ok {
 		r.TmuxSession = session["name"]
+		r.Terminal = session["backend"]
 	}
 	if window, ok := artifacts["window"]; ok {
 		r.TmuxWindowID = window["id"]
@@ -166,6 +309,15 @@ func (r *Run) DeriveState() {
 	if opencodeSession, ok := artifacts["opencode_session"]; ok {
 		r.OpenCodeSessionID = opencodeSession["id"]
 	}
+	if stream, ok := artifacts["stream"]; ok {
+		r.StreamLog = stream["path"]
+		r.StreamStderr = stream["stderr"]
+	}
+	if transcript, ok := artifacts["transcript"]; ok {
+		r.Transcript = transcript["path"]
+		r.TranscriptRaw = transcript["raw"]
+		r.TranscriptCast = transcript["cast"]
+	}
 	if agentModel, ok := artifacts["agent_model"]; ok {
 		if r.Model == "" {
 			r.Model = agentModel["model"]
@@ -175,10 +327,36 @@ func (r *Run) DeriveState() {
 		}
 	}
 
+	if queue, ok := artifacts[QueueArtifact]; ok {
+		r.Queued = true
+		r.Priority, _ = strconv.Atoi(queue[AttrPriority])
+		r.Repo = queue[AttrRepo]
+	}
+	if fanout, ok := artifacts[FanoutArtifact]; ok {
+		r.FanoutGroup = fanout[AttrGroup]
+		r.FanoutSpec = fanout[AttrSpec]
+		r.FanoutWinner = fanout[AttrWinner] == "true"
+	}
+	if tests, ok := artifacts[TestsArtifact]; ok {
+		r.Tests = tests[AttrResult]
+	}
+	if budget, ok := artifacts["budget"]; ok {
+		r.Budget, _ = strconv.ParseFloat(budget["limit"], 64)
+	}
+	if timeout, ok := artifacts[TimeoutArtifact]; ok {
+		r.Timeout, _ = time.ParseDuration(timeout[AttrTimeout])
+		r.StallTimeout, _ = time.ParseDuration(timeout[AttrStallTimeout])
+	}
+	r.Usage = Usage{}
+	for _, u := range r.state.Usage {
+		r.Usage = r.Usage.Add(u)
+	}
+
 	// Derive timestamps
-	if len(r.Events) > 0 {
-		r.StartedAt = r.Events[0].Timestamp
-		r.UpdatedAt = r.Events[len(r.Events)-1].Timestamp
+	if r.state.Events > 0 {
+		r.StartedAt = r.state.StartedAt
+		r.UpdatedAt = r.state.UpdatedAt
+		r.LaunchedAt = r.state.LaunchedAt
 	}
 }
 
@@ -98,8 +98,8 @@ func TestRunDeriveState(t *testing.T) {
 			{Timestamp: ts, Type: EventTypeStatus, Name: "queued"},
 			{Timestamp: ts.Add(time.Second), Type: EventTypeStatus, Name: "running"},
 			{Timestamp: ts.Add(3 * time.Second), Type: EventTypeArtifact, Name: "worktree", Attrs: map[string]string{"path": "/tmp/wt"}},
-			{Timestamp: ts.Add(4 * time.Second), Type: EventTypeArtifact, Name: "branch", Attrs: map[string]string{"name": "feature/test"}},
-			{Timestamp: ts.Add(5 * time.Second), Type: EventTypeArtifact, Name: "session", Attrs: map[string]string{"name": "run-plc124"}},
+			{Timestamp: ts.Add(4 * time.Second), Type: EventTypeArtifact, Name: "branch", Attrs: map[string]string{"name": "feature/test", "base": "origin/main"}},
+			{Timestamp: ts.Add(5 * time.Second), Type: EventTypeArtifact, Name: "session", Attrs: map[string]string{"name": "run-plc124", "backend": "pty"}},
 		},
 	}
 
@@ -114,9 +114,81 @@ func TestRunDeriveState(t *testing.T) {
 	if run.Branch != "feature/test" {
 		t.Errorf("Branch = %v, want feature/test", run.Branch)
 	}
+	if run.BaseBranch != "origin/main" {
+		t.Errorf("BaseBranch = %v, want origin/main", run.BaseBranch)
+	}
 	if run.TmuxSession != "run-plc124" {
 		t.Errorf("TmuxSession = %v, want run-plc124", run.TmuxSession)
 	}
+	if run.Terminal != "pty" {
+		t.Errorf("Terminal = %v, want pty", run.Terminal)
+	}
+}
+
+func TestRunDeriveStateIncremental(t *testing.T) {
+	ts := time.Now()
+	run := &Run{
+		IssueID: "plc124",
+		RunID:   "20231220",
+		Events: []*Event{
+			{Timestamp: ts, Type: EventTypeStatus, Name: "running"},
+		},
+	}
+	run.DeriveState()
+
+	run.ApplyEvents(
+		&Event{Timestamp: ts.Add(time.Second), Type: EventTypeArtifact, Name: "pr", Attrs: map[string]string{"url": "https://example.com/pr/1"}},
+		&Event{Timestamp: ts.Add(2 * time.Second), Type: EventTypeStatus, Name: "pr_open"},
+	)
+	if run.Status != StatusPROpen {
+		t.Errorf("Status = %v, want pr_open", run.Status)
+	}
+	if run.PRUrl != "https://example.com/pr/1" {
+		t.Errorf("PRUrl = %v", run.PRUrl)
+	}
+	if !run.StartedAt.Equal(ts) || !run.UpdatedAt.Equal(ts.Add(2*time.Second)) {
+		t.Errorf("timestamps = %v..%v", run.StartedAt, run.UpdatedAt)
+	}
+
+	// A persisted state can be restored and extended without the old events
+	restored := &Run{IssueID: "plc124", RunID: "20231220"}
+	restored.RestoreState(run.State())
+	restored.ApplyEvents(&Event{Timestamp: ts.Add(3 * time.Second), Type: EventTypeStatus, Name: "done"})
+	if restored.Status != StatusDone {
+		t.Errorf("restored Status = %v, want done", restored.Status)
+	}
+	if restored.PRUrl != "https://example.com/pr/1" {
+		t.Errorf("restored PRUrl = %v", restored.PRUrl)
+	}
+	if !restored.StartedAt.Equal(ts) {
+		t.Errorf("restored StartedAt = %v, want %v", restored.StartedAt, ts)
+	}
+	if got := restored.State().Events; got != 4 {
+		t.Errorf("State().Events = %d, want 4", got)
+	}
+
+	// Replacing the event slice replays from scratch
+	run.Events = run.Events[:1]
+	run.DeriveState()
+	if run.Status != StatusRunning {
+		t.Errorf("Status after replay = %v, want running", run.Status)
+	}
+}
+
+func TestRunDiffBase(t *testing.T) {
+	tests := []struct {
+		recorded, configured, want string
+	}{
+		{"origin/develop", "release", "origin/develop"},
+		{"", "release", "release"},
+		{"", "", "main"},
+	}
+	for _, tt := range tests {
+		run := &Run{BaseBranch: tt.recorded}
+		if got := run.DiffBase(tt.configured); got != tt.want {
+			t.Errorf("DiffBase(%q) with %q recorded = %q, want %q", tt.configured, tt.recorded, got, tt.want)
+		}
+	}
 }
 
 func TestGenerateRunID(t *testing.T) {
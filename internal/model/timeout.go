@@ -0,0 +1,38 @@
+package model
+
+import "time"
+
+// TimeoutArtifact records the limits of a run (orch run --timeout /
+// --stall-timeout) that the daemon enforces
+const TimeoutArtifact = "timeout"
+
+// Attributes of the timeout artifact
+const (
+	AttrTimeout      = "timeout"       // wall clock since the run left the queue
+	AttrStallTimeout = "stall_timeout" // no output while running
+)
+
+// Reason attributes of the failed status event recorded when a limit passes
+const (
+	TimeoutReason = "timeout"
+	StalledReason = "stalled"
+)
+
+// NewTimeoutArtifactEvent records the limits of a run; zero limits are
+// dropped
+func NewTimeoutArtifactEvent(timeout, stallTimeout time.Duration) *Event {
+	attrs := map[string]string{}
+	if timeout > 0 {
+		attrs[AttrTimeout] = timeout.String()
+	}
+	if stallTimeout > 0 {
+		attrs[AttrStallTimeout] = stallTimeout.String()
+	}
+	return NewArtifactEvent(TimeoutArtifact, attrs)
+}
+
+// TimedOut reports whether the run has a timeout and has been out of the
+// queue for longer than it
+func (r *Run) TimedOut(now time.Time) bool {
+	return r.Timeout > 0 && !r.LaunchedAt.IsZero() && now.Sub(r.LaunchedAt) > r.Timeout
+}
@@ -0,0 +1,50 @@
+package model
+
+import (
+	"testing"
+	"time"
+)
+
+func TestRunTimeouts(t *testing.T) {
+	created := time.Date(2025, 10, 14, 9, 0, 0, 0, time.UTC)
+	launched := created.Add(3 * time.Hour) // waited in the queue
+	at := func(ts time.Time, e *Event) *Event {
+		e.Timestamp = ts
+		return e
+	}
+	run := &Run{Events: []*Event{
+		at(created, NewStatusEvent(StatusQueued)),
+		at(created, NewTimeoutArtifactEvent(2*time.Hour, 20*time.Minute)),
+		at(launched, NewStatusEvent(StatusBooting)),
+		at(launched.Add(time.Minute), NewStatusEvent(StatusRunning)),
+	}}
+	run.DeriveState()
+
+	if run.Timeout != 2*time.Hour || run.StallTimeout != 20*time.Minute {
+		t.Fatalf("timeouts = %v / %v", run.Timeout, run.StallTimeout)
+	}
+	if !run.LaunchedAt.Equal(launched) {
+		t.Fatalf("LaunchedAt = %v, want %v", run.LaunchedAt, launched)
+	}
+	if run.TimedOut(launched.Add(time.Hour)) {
+		t.Error("timed out while queue time is not counted")
+	}
+	if !run.TimedOut(launched.Add(2*time.Hour + time.Second)) {
+		t.Error("not timed out past the limit")
+	}
+
+	restored := &Run{}
+	restored.RestoreState(run.State())
+	if !restored.LaunchedAt.Equal(launched) || restored.Timeout != run.Timeout {
+		t.Errorf("restored = %v / %v", restored.LaunchedAt, restored.Timeout)
+	}
+
+	queued := &Run{Events: []*Event{
+		at(created, NewStatusEvent(StatusQueued)),
+		at(created, NewTimeoutArtifactEvent(time.Minute, 0)),
+	}}
+	queued.DeriveState()
+	if queued.StallTimeout != 0 || queued.TimedOut(created.Add(time.Hour)) {
+		t.Error("a queued run cannot time out")
+	}
+}
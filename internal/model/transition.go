@@ -0,0 +1,151 @@
+package model
+
+import (
+	"errors"
+	"fmt"
+)
+
+// AttrForce marks a status event that bypasses transition validation.
+// It is reserved for repairs (e.g. reviving a run stuck in a terminal state);
+// the event should also carry a "reason" attribute.
+const AttrForce = "force"
+
+// ErrInvalidTransition is matched (via errors.Is) by TransitionError
+var ErrInvalidTransition = errors.New("invalid status transition")
+
+// TransitionError reports a status change the state machine does not allow
+type TransitionError struct {
+	From Status
+	To   Status
+}
+
+func (e *TransitionError) Error() string {
+	if _, ok := transitions[e.To]; !ok {
+		return fmt.Sprintf("invalid status transition: unknown status %q", e.To)
+	}
+	return fmt.Sprintf("invalid status transition: %s -> %s", e.From, e.To)
+}
+
+// Is makes errors.Is(err, ErrInvalidTransition) true
+func (e *TransitionError) Is(target error) bool {
+	return target == ErrInvalidTransition
+}
+
+// transitions lists, for each status, the statuses a run may move to.
+// Re-recording the current status is always allowed.
+//
+//	queued -> booting -> running <-> blocked / blocked_api / pr_open / unknown
+//	any active status -> done / failed / canceled
+//	done -> pr_open (PR opened for finished work)
+var transitions = map[Status][]Status{
+	StatusQueued: {
+		StatusBooting, StatusRunning, StatusDone, StatusFailed, StatusCanceled,
+	},
+	StatusBooting: {
+		StatusRunning, StatusBlocked, StatusBlockedAPI, StatusPROpen, StatusUnknown,
+		StatusDone, StatusFailed, StatusCanceled,
+	},
+	StatusRunning: {
+		StatusBlocked, StatusBlockedAPI, StatusPROpen, StatusUnknown,
+		StatusDone, StatusFailed, StatusCanceled,
+	},
+	StatusBlocked: {
+		StatusRunning, StatusBlockedAPI, StatusPROpen, StatusUnknown,
+		StatusDone, StatusFailed, StatusCanceled,
+	},
+	StatusBlockedAPI: {
+		StatusRunning, StatusBlocked, StatusPROpen, StatusUnknown,
+		StatusDone, StatusFailed, StatusCanceled,
+	},
+	StatusPROpen: {
+		StatusRunning, StatusBlocked, StatusBlockedAPI, StatusUnknown,
+		StatusDone, StatusFailed, StatusCanceled,
+	},
+	StatusUnknown: {
+		StatusRunning, StatusBlocked, StatusBlockedAPI, StatusPROpen,
+		StatusDone, StatusFailed, StatusCanceled,
+	},
+	StatusDone:     {StatusPROpen},
+	StatusFailed:   {},
+	StatusCanceled: {},
+}
+
+// IsFinished reports whether a run with the status has ended: done, failed
+// or canceled
+func (s Status) IsFinished() bool {
+	return s == StatusDone || s == StatusFailed || s == StatusCanceled
+}
+
+// ValidateTransition returns a *TransitionError if a run may not move from
+// one status to the other
+func ValidateTransition(from, to Status) error {
+	if _, ok := transitions[to]; !ok {
+		return &TransitionError{From: from, To: to}
+	}
+	if from == to {
+		return nil
+	}
+	for _, next := range transitions[from] {
+		if next == to {
+			return nil
+		}
+	}
+	return &TransitionError{From: from, To: to}
+}
+
+// IsValidStatus reports whether s is a run status
+func IsValidStatus(s string) bool {
+	_, ok := transitions[Status(s)]
+	return ok
+}
+
+// IsForced reports whether a status event carries the override attribute
+func (e *Event) IsForced() bool {
+	return e.Attrs[AttrForce] == "true"
+}
+
+// NewForcedStatusEvent creates a status event that skips transition
+// validation, recording why the override was needed
+func NewForcedStatusEvent(status Status, reason string) *Event {
+	return NewEvent(EventTypeStatus, string(status), map[string]string{
+		AttrForce: "true",
+		"reason":  reason,
+	})
+}
+
+// CheckStatusEvent validates appending event to a run whose current status
+// is current. Non-status events and forced status events always pass.
+func CheckStatusEvent(current Status, event *Event) error {
+	if event.Type != EventTypeStatus || event.IsForced() {
+		return nil
+	}
+	return ValidateTransition(current, Status(event.Name))
+}
+
+// InvalidTransition locates an illegal status change in a run's history
+type InvalidTransition struct {
+	Index int // position of the offending event in Run.Events
+	Event *Event
+	Err   *TransitionError
+}
+
+// InvalidTransitions replays the status events of a run through the
+// transition table and returns every change it would have rejected.
+// Forced events are accepted and become the new baseline.
+func (r *Run) InvalidTransitions() []InvalidTransition {
+	var invalid []InvalidTransition
+	current := StatusQueued
+	for i, e := range r.Events {
+		if e.Type != EventTypeStatus {
+			continue
+		}
+		next := Status(e.Name)
+		if !e.IsForced() {
+			if err := ValidateTransition(current, next); err != nil {
+				invalid = append(invalid, InvalidTransition{Index: i, Event: e, Err: err.(*TransitionError)})
+			}
+		}
+		current = next
+	}
+	return invalid
+}
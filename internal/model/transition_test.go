@@ -0,0 +1,123 @@
+package model
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestValidateTransition(t *testing.T) {
+	tests := []struct {
+		from    Status
+		to      Status
+		wantErr bool
+	}{
+		{StatusQueued, StatusBooting, false},
+		{StatusQueued, StatusRunning, false},
+		{StatusQueued, StatusDone, false},
+		{StatusQueued, StatusBlocked, true},
+		{StatusBooting, StatusRunning, false},
+		{StatusRunning, StatusBlocked, false},
+		{StatusBlocked, StatusRunning, false},
+		{StatusBlockedAPI, StatusRunning, false},
+		{StatusRunning, StatusPROpen, false},
+		{StatusRunning, StatusQueued, true},
+		{StatusRunning, StatusRunning, false},
+		{StatusDone, StatusPROpen, false},
+		{StatusDone, StatusRunning, true},
+		{StatusFailed, StatusRunning, true},
+		{StatusCanceled, StatusRunning, true},
+		{StatusCanceled, StatusCanceled, false},
+		{StatusRunning, Status("bogus"), true},
+	}
+
+	for _, tt := range tests {
+		t.Run(string(tt.from)+"->"+string(tt.to), func(t *testing.T) {
+			err := ValidateTransition(tt.from, tt.to)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ValidateTransition() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err != nil && !errors.Is(err, ErrInvalidTransition) {
+				t.Errorf("error %v does not match ErrInvalidTransition", err)
+			}
+		})
+	}
+}
+
+func TestIsValidStatus(t *testing.T) {
+	for _, s := range []string{"queued", "pr_open", "unknown", "canceled"} {
+		if !IsValidStatus(s) {
+			t.Errorf("IsValidStatus(%q) = false", s)
+		}
+	}
+	for _, s := range []string{"", "bogus", "Done"} {
+		if IsValidStatus(s) {
+			t.Errorf("IsValidStatus(%q) = true", s)
+		}
+	}
+}
+
+func TestTransitionErrorMessage(t *testing.T) {
+	err := ValidateTransition(StatusFailed, StatusRunning)
+	if err.Error() != "invalid status transition: failed -> running" {
+		t.Errorf("unexpected message: %s", err)
+	}
+	err = ValidateTransition(StatusRunning, Status("bogus"))
+	if err.Error() != `invalid status transition: unknown status "bogus"` {
+		t.Errorf("unexpected message: %s", err)
+	}
+}
+
+func TestCheckStatusEvent(t *testing.T) {
+	if err := CheckStatusEvent(StatusFailed, NewEvent(EventTypeStatus, string(StatusRunning), nil)); err == nil {
+		t.Error("expected failed -> running to be rejected")
+	}
+	if err := CheckStatusEvent(StatusFailed, NewForcedStatusEvent(StatusRunning, "manual repair")); err != nil {
+		t.Errorf("forced event rejected: %v", err)
+	}
+	if err := CheckStatusEvent(StatusFailed, NewEvent(EventTypeArtifact, "pr", nil)); err != nil {
+		t.Errorf("non-status event rejected: %v", err)
+	}
+}
+
+func TestNewForcedStatusEvent(t *testing.T) {
+	e := NewForcedStatusEvent(StatusRunning, "revive")
+	if !e.IsForced() {
+		t.Error("expected forced event")
+	}
+	if e.Attrs["reason"] != "revive" {
+		t.Errorf("reason = %q, want %q", e.Attrs["reason"], "revive")
+	}
+	if NewEvent(EventTypeStatus, string(StatusRunning), nil).IsForced() {
+		t.Error("plain event reported as forced")
+	}
+}
+
+func TestRunInvalidTransitions(t *testing.T) {
+	now := time.Now()
+	status := func(s Status) *Event {
+		return &Event{Timestamp: now, Type: EventTypeStatus, Name: string(s)}
+	}
+
+	run := &Run{Events: []*Event{
+		status(StatusQueued),
+		status(StatusRunning),
+		status(StatusFailed),
+		status(StatusRunning), // illegal
+		{Timestamp: now, Type: EventTypeArtifact, Name: "pr"},
+		status(StatusDone),
+		NewForcedStatusEvent(StatusRunning, "revive"),
+		status(StatusBlocked),
+	}}
+
+	invalid := run.InvalidTransitions()
+	if len(invalid) != 1 {
+		t.Fatalf("expected 1 invalid transition, got %d", len(invalid))
+	}
+	if invalid[0].Index != 3 {
+		t.Errorf("Index = %d, want 3", invalid[0].Index)
+	}
+	if invalid[0].Err.From != StatusFailed || invalid[0].Err.To != StatusRunning {
+		t.Errorf("unexpected transition %s -> %s", invalid[0].Err.From, invalid[0].Err.To)
+	}
+}
@@ -0,0 +1,108 @@
+package model
+
+import (
+	"fmt"
+	"strconv"
+)
+
+// Usage is the token consumption and estimated spend of one model in a run
+type Usage struct {
+	Model            string  `json:"model,omitempty"`
+	InputTokens      int64   `json:"input_tokens"`       // uncached input
+	OutputTokens     int64   `json:"output_tokens"`      // including reasoning
+	CacheReadTokens  int64   `json:"cache_read_tokens"`  // input served from the prompt cache
+	CacheWriteTokens int64   `json:"cache_write_tokens"` // input written to the prompt cache
+	Cost             float64 `json:"cost"`               // USD
+}
+
+// Add returns the sum of two usages. The model is kept only if both agree.
+func (u Usage) Add(o Usage) Usage {
+	sum := Usage{
+		InputTokens:      u.InputTokens + o.InputTokens,
+		OutputTokens:     u.OutputTokens + o.OutputTokens,
+		CacheReadTokens:  u.CacheReadTokens + o.CacheReadTokens,
+		CacheWriteTokens: u.CacheWriteTokens + o.CacheWriteTokens,
+		Cost:             u.Cost + o.Cost,
+	}
+	if u.Model == o.Model || o.Model == "" {
+		sum.Model = u.Model
+	} else if u.Model == "" {
+		sum.Model = o.Model
+	}
+	return sum
+}
+
+// TotalTokens returns all tokens counted by the usage
+func (u Usage) TotalTokens() int64 {
+	return u.InputTokens + u.OutputTokens + u.CacheReadTokens + u.CacheWriteTokens
+}
+
+// IsZero reports whether nothing has been recorded
+func (u Usage) IsZero() bool {
+	return u.TotalTokens() == 0 && u.Cost == 0
+}
+
+// unknownUsageModel names usage events whose model could not be determined
+const unknownUsageModel = "unknown"
+
+// NewUsageEvent creates a usage event. Usage events carry the cumulative
+// totals of one model, so the latest event per model supersedes earlier ones:
+//
+//   - <ts> | usage | claude-sonnet-4-5 | input=1200 | output=800 | cache_read=5000 | cache_write=300 | cost=0.0321
+func NewUsageEvent(u Usage) *Event {
+	name := u.Model
+	if name == "" {
+		name = unknownUsageModel
+	}
+	return NewEvent(EventTypeUsage, name, map[string]string{
+		"input":       strconv.FormatInt(u.InputTokens, 10),
+		"output":      strconv.FormatInt(u.OutputTokens, 10),
+		"cache_read":  strconv.FormatInt(u.CacheReadTokens, 10),
+		"cache_write": strconv.FormatInt(u.CacheWriteTokens, 10),
+		"cost":        strconv.FormatFloat(u.Cost, 'f', 4, 64),
+	})
+}
+
+// ParseUsageEvent extracts the usage recorded by a usage event
+func ParseUsageEvent(e *Event) (Usage, bool) {
+	if e.Type != EventTypeUsage {
+		return Usage{}, false
+	}
+	u := Usage{Model: e.Name}
+	if u.Model == unknownUsageModel {
+		u.Model = ""
+	}
+	u.InputTokens, _ = strconv.ParseInt(e.Attrs["input"], 10, 64)
+	u.OutputTokens, _ = strconv.ParseInt(e.Attrs["output"], 10, 64)
+	u.CacheReadTokens, _ = strconv.ParseInt(e.Attrs["cache_read"], 10, 64)
+	u.CacheWriteTokens, _ = strconv.ParseInt(e.Attrs["cache_write"], 10, 64)
+	u.Cost, _ = strconv.ParseFloat(e.Attrs["cost"], 64)
+	return u, true
+}
+
+// FormatCost renders a USD amount for tables ("-" when nothing was spent)
+func FormatCost(cost float64) string {
+	if cost <= 0 {
+		return "-"
+	}
+	if cost < 0.01 {
+		return "<$0.01"
+	}
+	return fmt.Sprintf("$%.2f", cost)
+}
+
+// BudgetExceededReason is the reason attribute of the failed status event
+// recorded when a run's spend passes its budget
+const BudgetExceededReason = "budget_exceeded"
+
+// NewBudgetArtifactEvent records the spend limit (USD) of a run
+func NewBudgetArtifactEvent(limit float64) *Event {
+	return NewArtifactEvent("budget", map[string]string{
+		"limit": strconv.FormatFloat(limit, 'f', 2, 64),
+	})
+}
+
+// OverBudget reports whether the run has a budget and its spend exceeds it
+func (r *Run) OverBudget() bool {
+	return r.Budget > 0 && r.Usage.Cost > r.Budget
+}
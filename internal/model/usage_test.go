@@ -0,0 +1,108 @@
+package model
+
+import (
+	"testing"
+	"time"
+)
+
+func TestUsageEventRoundTrip(t *testing.T) {
+	u := Usage{
+		Model:            "claude-sonnet-4-5",
+		InputTokens:      1200,
+		OutputTokens:     800,
+		CacheReadTokens:  5000,
+		CacheWriteTokens: 300,
+		Cost:             0.0321,
+	}
+
+	parsed, err := ParseEvent(NewUsageEvent(u).String())
+	if err != nil {
+		t.Fatalf("ParseEvent: %v", err)
+	}
+	got, ok := ParseUsageEvent(parsed)
+	if !ok {
+		t.Fatal("expected usage event")
+	}
+	if got != u {
+		t.Errorf("round trip = %+v, want %+v", got, u)
+	}
+
+	if _, ok := ParseUsageEvent(NewStatusEvent(StatusRunning)); ok {
+		t.Error("status event parsed as usage")
+	}
+}
+
+func TestUsageEventUnknownModel(t *testing.T) {
+	e := NewUsageEvent(Usage{InputTokens: 10})
+	if e.Name != "unknown" {
+		t.Errorf("Name = %q, want unknown", e.Name)
+	}
+	u, _ := ParseUsageEvent(e)
+	if u.Model != "" {
+		t.Errorf("Model = %q, want empty", u.Model)
+	}
+}
+
+func TestRunUsageTotals(t *testing.T) {
+	run := &Run{Events: []*Event{
+		NewStatusEvent(StatusRunning),
+		NewBudgetArtifactEvent(5),
+		NewUsageEvent(Usage{Model: "claude-opus-4-5", InputTokens: 100, Cost: 1}),
+		NewUsageEvent(Usage{Model: "claude-haiku-4-5", InputTokens: 50, Cost: 0.25}),
+		// Cumulative: supersedes the first opus event
+		NewUsageEvent(Usage{Model: "claude-opus-4-5", InputTokens: 300, OutputTokens: 20, Cost: 3}),
+	}}
+	run.DeriveState()
+
+	if run.Usage.InputTokens != 350 || run.Usage.OutputTokens != 20 {
+		t.Errorf("tokens = %d in / %d out, want 350 / 20", run.Usage.InputTokens, run.Usage.OutputTokens)
+	}
+	if run.Usage.Cost != 3.25 {
+		t.Errorf("Cost = %v, want 3.25", run.Usage.Cost)
+	}
+	if run.Budget != 5 {
+		t.Errorf("Budget = %v, want 5", run.Budget)
+	}
+	if run.OverBudget() {
+		t.Error("run should be within budget")
+	}
+
+	run.ApplyEvents(NewUsageEvent(Usage{Model: "claude-opus-4-5", InputTokens: 900, Cost: 4.9}))
+	if !run.OverBudget() {
+		t.Errorf("run should be over budget at $%.2f", run.Usage.Cost)
+	}
+
+	// Totals survive persisting and restoring the derived state
+	restored := &Run{}
+	restored.RestoreState(run.State())
+	if restored.Usage != run.Usage || restored.Budget != run.Budget {
+		t.Errorf("restored usage = %+v / %v, want %+v / %v", restored.Usage, restored.Budget, run.Usage, run.Budget)
+	}
+}
+
+func TestRunWithoutBudgetIsNeverOverBudget(t *testing.T) {
+	run := &Run{Events: []*Event{
+		{Timestamp: time.Now(), Type: EventTypeUsage, Name: "gpt-5", Attrs: map[string]string{"cost": "100"}},
+	}}
+	run.DeriveState()
+	if run.OverBudget() {
+		t.Error("run without budget reported over budget")
+	}
+}
+
+func TestFormatCost(t *testing.T) {
+	tests := []struct {
+		cost float64
+		want string
+	}{
+		{0, "-"},
+		{0.004, "<$0.01"},
+		{0.5, "$0.50"},
+		{12.345, "$12.35"},
+	}
+	for _, tt := range tests {
+		if got := FormatCost(tt.cost); got != tt.want {
+			t.Errorf("FormatCost(%v) = %q, want %q", tt.cost, got, tt.want)
+		}
+	}
+}
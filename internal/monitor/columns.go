@@ -26,6 +26,7 @@ const (
 	ColStarted     ColumnID = "started"
 	ColUpdated     ColumnID = "updated"
 	ColTopic       ColumnID = "topic"
+	ColCost        ColumnID = "cost"
 )
 
 type ColumnDef struct {
@@ -50,6 +51,7 @@ var columnRegistry = map[ColumnID]ColumnDef{
 	ColStarted:     {ID: ColStarted, Header: "STARTED", Width: 7},
 	ColUpdated:     {ID: ColUpdated, Header: "UPDATED", Width: 7},
 	ColTopic:       {ID: ColTopic, Header: "TOPIC", Width: 6, Flexible: true},
+	ColCost:        {ID: ColCost, Header: "COST", Width: 7},
 }
 
 var defaultColumns = []ColumnID{
@@ -62,6 +64,7 @@ var defaultColumns = []ColumnID{
 	ColMerged,
 	ColStarted,
 	ColUpdated,
+	ColCost,
 	ColAlive,
 	ColIssueStatus,
 	ColBranch,
@@ -128,6 +131,8 @@ func GetColumnValue(col ColumnID, row *RunRow, now time.Time) string {
 		return formatRelativeTime(row.Updated, now)
 	case ColTopic:
 		return row.Topic
+	case ColCost:
+		return row.Cost
 	default:
 		return "-"
 	}
@@ -9,3 +9,10 @@ const (
 	runTableWorktreeWidth  = 16
 	runDetailsMaxLines     = 7
 )
+
+const (
+	// watchRefreshInterval is the fallback refresh while store changes are
+	// pushed; it keeps agent liveness and relative times current.
+	watchRefreshInterval = 10 * time.Second
+	watchCoalesceWindow  = 100 * time.Millisecond
+)
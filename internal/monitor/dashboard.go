@@ -64,10 +64,13 @@ type Dashboard struct {
 	width   int
 	height  int
 
-	mode    dashboardMode
-	message string
-	capture captureState
-	issue   issueState
+	mode     dashboardMode
+	message  string
+	capture  captureState
+	issue    issueState
+	diff     captureState // changes of the selected run, shown instead of the capture
+	diffBase string       // branch the selected run's diff is taken against
+	showDiff bool
 
 	stop   stopState
 	newRun newRunState
@@ -78,8 +81,11 @@ type Dashboard struct {
 
 	lastRefresh     time.Time
 	refreshing      bool
+	refreshPending  bool
 	refreshInterval time.Duration
 	filterPreset    int
+
+	watch *storeWatch
 }
 
 type refreshMsg struct {
@@ -106,6 +112,12 @@ type captureMsg struct {
 	err     error
 }
 
+type diffMsg struct {
+	runRef  string
+	content string
+	err     error
+}
+
 type issueContentMsg struct {
 	issueID string
 	content string
@@ -133,6 +145,7 @@ func NewDashboard(m *Monitor) *Dashboard {
 
 // Run starts the bubbletea program.
 func (d *Dashboard) Run() error {
+	defer func() { d.watch.stop() }()
 	program := tea.NewProgram(d, tea.WithAltScreen())
 	_, err := program.Run()
 	return err
@@ -141,7 +154,11 @@ func (d *Dashboard) Run() error {
 // Init implements tea.Model.
 func (d *Dashboard) Init() tea.Cmd {
 	d.refreshing = true
-	return tea.Batch(d.refreshCmd(), d.tickCmd())
+	d.watch = startStoreWatch(d.monitor.store)
+	if d.watch != nil {
+		d.refreshInterval = watchRefreshInterval
+	}
+	return tea.Batch(d.refreshCmd(), d.tickCmd(), d.watch.next())
 }
 
 // Update implements tea.Model.
@@ -162,7 +179,20 @@ func (d *Dashboard) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			}
 		}
 		d.ensureCursorVisible()
+		if d.refreshPending {
+			// A change landed while this refresh was in flight
+			d.refreshPending = false
+			d.refreshing = true
+			return d, tea.Batch(d.startRunPanels(), d.refreshCmd())
+		}
 		return d, d.startRunPanels()
+	case storeChangedMsg:
+		if d.refreshing {
+			d.refreshPending = true
+			return d, d.watch.next()
+		}
+		d.refreshing = true
+		return d, tea.Batch(d.refreshCmd(), d.watch.next())
 	case issuesMsg:
 		d.newRun.issues = msg.issues
 		d.newRun.cursor = 0
@@ -195,6 +225,25 @@ func (d *Dashboard) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		d.capture.content = trimmed
 		d.capture.message = ""
 		return d, nil
+	case diffMsg:
+		if msg.runRef != d.diff.runRef {
+			return d, nil
+		}
+		d.diff.loading = false
+		if msg.err != nil {
+			d.diff.content = ""
+			d.diff.message = fmt.Sprintf("No diff available: %v", msg.err)
+			return d, nil
+		}
+		trimmed := strings.TrimRight(msg.content, "\n")
+		if strings.TrimSpace(trimmed) == "" {
+			d.diff.content = ""
+			d.diff.message = "No changes."
+			return d, nil
+		}
+		d.diff.content = trimmed
+		d.diff.message = ""
+		return d, nil
 	case issueContentMsg:
 		if msg.issueID != d.issue.issueID {
 			return d, nil
@@ -348,6 +397,9 @@ func (d *Dashboard) handleDashboardKey(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 		}
 		d.message = "no run selected"
 		return d, nil
+	case d.keymap.Diff:
+		d.showDiff = !d.showDiff
+		return d, d.startRunPanels()
 	case d.keymap.EditIssue:
 		if d.cursor >= 0 && d.cursor < len(d.runs) {
 			run := d.runs[d.cursor].Run
@@ -477,7 +529,12 @@ func (d *Dashboard) tickCmd() tea.Cmd {
 }
 
 func (d *Dashboard) startRunPanels() tea.Cmd {
-	captureCmd := d.startCapture()
+	var captureCmd tea.Cmd
+	if d.showDiff {
+		captureCmd = d.startDiff()
+	} else {
+		captureCmd = d.startCapture()
+	}
 	issueCmd := d.startIssueContent()
 	if captureCmd == nil {
 		return issueCmd
@@ -504,6 +561,23 @@ func (d *Dashboard) startCapture() tea.Cmd {
 	return d.captureCmd(run, runRef)
 }
 
+func (d *Dashboard) startDiff() tea.Cmd {
+	run := d.selectedRun()
+	if run == nil {
+		d.diff = captureState{message: "No diff available."}
+		return nil
+	}
+	runRef := run.Ref().String()
+	if d.diff.runRef != runRef {
+		d.diff.content = ""
+		d.diffBase = d.monitor.DiffBase(run)
+	}
+	d.diff.runRef = runRef
+	d.diff.message = ""
+	d.diff.loading = true
+	return d.diffCmd(run, runRef)
+}
+
 func (d *Dashboard) startIssueContent() tea.Cmd {
 	run := d.selectedRun()
 	if run == nil || strings.TrimSpace(run.IssueID) == "" {
@@ -532,6 +606,17 @@ func (d *Dashboard) captureCmd(run *model.Run, runRef string) tea.Cmd {
 	}
 }
 
+func (d *Dashboard) diffCmd(run *model.Run, runRef string) tea.Cmd {
+	return func() tea.Msg {
+		content, err := d.monitor.RunDiff(run)
+		return diffMsg{
+			runRef:  runRef,
+			content: content,
+			err:     err,
+		}
+	}
+}
+
 func (d *Dashboard) issueContentCmd(issueID string) tea.Cmd {
 	return func() tea.Msg {
 		content, err := d.monitor.IssueContent(issueID)
@@ -768,6 +853,7 @@ func (d *Dashboard) viewHelp() string {
 		"  enter      Open selected run",
 		"  I          Open issue file in nvim",
 		"  e          Execute shell in run's worktree",
+		"  d          Toggle diff pane (changes against base branch)",
 		"  s          Stop run (select from active runs)",
 		"  n          New run (select issue to start)",
 		"  R          Resolve run and mark issue as resolved",
@@ -1009,11 +1095,17 @@ func (d *Dashboard) renderContext(height int) string {
 	width := d.safeWidth()
 	issueLabel := "ISSUE"
 	captureLabel := "CAPTURE"
+	if d.showDiff {
+		captureLabel = "DIFF"
+	}
 	if run := d.selectedRun(); run != nil {
 		if strings.TrimSpace(run.IssueID) != "" {
 			issueLabel = fmt.Sprintf("ISSUE %s", run.IssueID)
 		}
-		captureLabel = fmt.Sprintf("CAPTURE %s", run.Ref().String())
+		captureLabel = fmt.Sprintf("%s %s", captureLabel, run.Ref().String())
+		if d.showDiff && d.diff.runRef == run.Ref().String() {
+			captureLabel += fmt.Sprintf(" vs %s", d.diffBase)
+		}
 	}
 	issueHeader := d.styles.Header.Render(truncate(issueLabel, width))
 	captureHeader := d.styles.Header.Render(truncate(captureLabel, width))
@@ -1026,7 +1118,12 @@ func (d *Dashboard) renderContext(height int) string {
 
 	contentHeight := height - 2
 	issueLines := d.issueLines(width)
-	captureLines := d.captureLines(width)
+	var captureLines []string
+	if d.showDiff {
+		captureLines = d.diffLines(width)
+	} else {
+		captureLines = d.captureLines(width)
+	}
 	issueContentLines := contentHeight / 2
 	if issueContentLines < 1 {
 		issueContentLines = 1
@@ -1044,7 +1141,15 @@ func (d *Dashboard) renderContext(height int) string {
 		}
 	}
 	if len(captureLines) > captureContentLines && captureContentLines > 0 {
-		captureLines = captureLines[len(captureLines)-captureContentLines:]
+		if d.showDiff {
+			// A diff reads from the top: stat first, then the patch
+			captureLines = captureLines[:captureContentLines]
+			if captureContentLines > 1 {
+				captureLines[captureContentLines-1] = "..."
+			}
+		} else {
+			captureLines = captureLines[len(captureLines)-captureContentLines:]
+		}
 	}
 
 	lines := []string{issueHeader}
@@ -1072,6 +1177,35 @@ func (d *Dashboard) captureLines(width int) []string {
 	return wrapText(content, width)
 }
 
+// diffLines colors the diff and cuts long lines to the pane width
+func (d *Dashboard) diffLines(width int) []string {
+	if d.diff.loading && d.diff.content == "" {
+		return []string{d.styles.Faint.Render("Loading diff...")}
+	}
+	if d.diff.message != "" {
+		return []string{d.styles.Faint.Render(d.diff.message)}
+	}
+	if strings.TrimSpace(d.diff.content) == "" {
+		return []string{d.styles.Faint.Render("No changes.")}
+	}
+	var lines []string
+	for _, line := range strings.Split(d.diff.content, "\n") {
+		line = truncateToWidth(strings.ReplaceAll(line, "\t", "    "), width)
+		switch {
+		case strings.HasPrefix(line, "+++"), strings.HasPrefix(line, "---"):
+			line = d.styles.Header.Render(line)
+		case strings.HasPrefix(line, "+"):
+			line = d.styles.DiffAdd.Render(line)
+		case strings.HasPrefix(line, "-"):
+			line = d.styles.DiffDel.Render(line)
+		case strings.HasPrefix(line, "@@"):
+			line = d.styles.DiffHunk.Render(line)
+		}
+		lines = append(lines, line)
+	}
+	return lines
+}
+
 // renderCapture renders the capture pane for testing.
 func (d *Dashboard) renderCapture(height int) string {
 	if height <= 0 {
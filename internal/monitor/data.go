@@ -24,6 +24,7 @@ type RunRow struct {
 	Started      time.Time
 	Updated      time.Time
 	Topic        string
+	Cost         string
 	Run          *model.Run
 }
 
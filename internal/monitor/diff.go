@@ -0,0 +1,62 @@
+package monitor
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/s22625/orch/internal/git"
+	"github.com/s22625/orch/internal/model"
+)
+
+// runDiff is the last diff of a run and the worktree state it was taken at
+type runDiff struct {
+	base    string
+	state   string
+	content string
+}
+
+// DiffBase returns the branch a run's diff is taken against
+func (m *Monitor) DiffBase(run *model.Run) string {
+	return run.DiffBase(m.baseBranch)
+}
+
+// RunDiff returns what a run changed against the base branch it was created
+// from, as a diffstat followed by the patch (same data as orch diff). The
+// diff is reused until the run's HEAD or worktree changes.
+func (m *Monitor) RunDiff(run *model.Run) (string, error) {
+	if run == nil {
+		return "", fmt.Errorf("run not found")
+	}
+	if run.WorktreePath == "" {
+		return "", fmt.Errorf("run has no worktree")
+	}
+	if _, err := os.Stat(run.WorktreePath); err != nil {
+		return "", fmt.Errorf("worktree is gone: %s", run.WorktreePath)
+	}
+
+	ref := run.Ref().String()
+	base := m.DiffBase(run)
+	state, stateErr := git.WorktreeState(run.WorktreePath)
+	if stateErr == nil {
+		m.diffMu.Lock()
+		cached := m.diffs[ref]
+		m.diffMu.Unlock()
+		if cached != nil && cached.base == base && cached.state == state {
+			return cached.content, nil
+		}
+	}
+
+	content, err := git.Diff(run.WorktreePath, base, git.DiffSummaryPatch)
+	if err != nil {
+		return "", err
+	}
+	if stateErr == nil {
+		m.diffMu.Lock()
+		if m.diffs == nil {
+			m.diffs = make(map[string]*runDiff)
+		}
+		m.diffs[ref] = &runDiff{base: base, state: state, content: content}
+		m.diffMu.Unlock()
+	}
+	return content, nil
+}
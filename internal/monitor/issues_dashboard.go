@@ -105,7 +105,10 @@ type IssueDashboard struct {
 
 	lastRefresh     time.Time
 	refreshing      bool
+	refreshPending  bool
 	refreshInterval time.Duration
+
+	watch *storeWatch
 }
 
 type issuesRefreshMsg struct {
@@ -148,6 +151,7 @@ func NewIssueDashboard(m *Monitor) *IssueDashboard {
 
 // Run starts the bubbletea program.
 func (d *IssueDashboard) Run() error {
+	defer func() { d.watch.stop() }()
 	program := tea.NewProgram(d, tea.WithAltScreen())
 	_, err := program.Run()
 	return err
@@ -156,7 +160,11 @@ func (d *IssueDashboard) Run() error {
 // Init implements tea.Model.
 func (d *IssueDashboard) Init() tea.Cmd {
 	d.refreshing = true
-	return tea.Batch(d.refreshCmd(), d.tickCmd())
+	d.watch = startStoreWatch(d.monitor.store)
+	if d.watch != nil {
+		d.refreshInterval = watchRefreshInterval
+	}
+	return tea.Batch(d.refreshCmd(), d.tickCmd(), d.watch.next())
 }
 
 // Update implements tea.Model.
@@ -178,7 +186,19 @@ func (d *IssueDashboard) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			}
 		}
 		d.ensureCursorVisible()
+		if d.refreshPending {
+			d.refreshPending = false
+			d.refreshing = true
+			return d, d.refreshCmd()
+		}
 		return d, nil
+	case storeChangedMsg:
+		if d.refreshing {
+			d.refreshPending = true
+			return d, d.watch.next()
+		}
+		d.refreshing = true
+		return d, tea.Batch(d.refreshCmd(), d.watch.next())
 	case issueRunsMsg:
 		if msg.issueID != d.selectRun.issueID {
 			return d, nil
@@ -10,6 +10,7 @@ type KeyMap struct {
 	Open        string
 	EditIssue   string
 	Exec        string
+	Diff        string
 	Stop        string
 	NewRun      string
 	Resolve     string
@@ -31,6 +32,7 @@ func DefaultKeyMap() KeyMap {
 		Open:        "enter",
 		EditIssue:   "I",
 		Exec:        "e",
+		Diff:        "d",
 		Stop:        "s",
 		NewRun:      "n",
 		Resolve:     "R",
@@ -46,6 +48,6 @@ func DefaultKeyMap() KeyMap {
 
 // HelpLine renders the footer help text.
 func (k KeyMap) HelpLine() string {
-	return fmt.Sprintf("[%s] runs  [%s] issues  [%s] chat  [%s] open  [%s] issue  [%s] exec  [%s] stop  [%s] new  [%s] resolve  [%s] merge  [%s] refresh  [%s] sort  [%s] filter  [%s] presets  [%s] quit  [%s] help",
-		k.Runs, k.Issues, k.Chat, k.Open, k.EditIssue, k.Exec, k.Stop, k.NewRun, k.Resolve, k.Merge, k.Refresh, k.Sort, k.Filter, k.QuickFilter, k.Quit, k.Help)
+	return fmt.Sprintf("[%s] runs  [%s] issues  [%s] chat  [%s] open  [%s] issue  [%s] exec  [%s] diff  [%s] stop  [%s] new  [%s] resolve  [%s] merge  [%s] refresh  [%s] sort  [%s] filter  [%s] presets  [%s] quit  [%s] help",
+		k.Runs, k.Issues, k.Chat, k.Open, k.EditIssue, k.Exec, k.Diff, k.Stop, k.NewRun, k.Resolve, k.Merge, k.Refresh, k.Sort, k.Filter, k.QuickFilter, k.Quit, k.Help)
 }
@@ -154,7 +154,8 @@ func (m *Monitor) recordPRStatus(run *model.Run, url, state string) error {
 			return fmt.Errorf("failed to record PR artifact: %w", err)
 		}
 	}
-	if strings.EqualFold(state, "OPEN") && run.Status != model.StatusPROpen {
+	// Failed/canceled runs keep their status; the PR artifact is enough
+	if strings.EqualFold(state, "OPEN") && run.Status != model.StatusPROpen && model.ValidateTransition(run.Status, model.StatusPROpen) == nil {
 		if err := m.store.AppendEvent(run.Ref(), model.NewStatusEvent(model.StatusPROpen)); err != nil {
 			return fmt.Errorf("failed to update status: %w", err)
 		}
@@ -10,6 +10,7 @@ import (
 	"os/exec"
 	"path/filepath"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/s22625/orch/internal/agent"
@@ -75,6 +76,10 @@ type Monitor struct {
 	uiSettings      *UISettings
 	orchDir         string
 	opencodePresets []config.OpenCodePreset
+	baseBranch      string // configured base_branch
+
+	diffMu sync.Mutex
+	diffs  map[string]*runDiff // last diff per run ref, see RunDiff
 }
 
 // RunWindow links a run to a dashboard index.
@@ -105,8 +110,10 @@ func New(st store.Store, opts Options) *Monitor {
 	}
 	orchDir := GetOrchDir(st.VaultPath())
 	var presets []config.OpenCodePreset
+	baseBranch := ""
 	if cfg, err := config.Load(); err == nil {
 		presets = cfg.OpenCodePresets
+		baseBranch = cfg.BaseBranch
 	}
 	return &Monitor{
 		session:         session,
@@ -124,6 +131,7 @@ func New(st store.Store, opts Options) *Monitor {
 		uiSettings:      uiSettings,
 		orchDir:         orchDir,
 		opencodePresets: presets,
+		baseBranch:      baseBranch,
 	}
 }
 
@@ -352,7 +360,7 @@ func (m *Monitor) OpenRun(run *model.Run) error {
 	if run == nil {
 		return fmt.Errorf("run not found")
 	}
-	attacher := GetRunAttacher(run.Agent)
+	attacher := GetRunAttacher(run)
 	return attacher.Attach(m, run)
 }
 
@@ -366,22 +374,10 @@ func (m *Monitor) Quit() error {
 	return tmux.KillSession(m.session)
 }
 
-// StopRun kills the run tmux session and marks the run canceled.
+// StopRun kills the run session and marks the run canceled.
 func (m *Monitor) StopRun(run *model.Run) error {
-	if isTerminalStatus(run.Status) {
-		return nil
-	}
-
-	sessionName := run.TmuxSession
-	if sessionName == "" {
-		sessionName = model.GenerateTmuxSession(run.IssueID, run.RunID)
-	}
-
-	if tmux.HasSession(sessionName) {
-		_ = tmux.KillSession(sessionName)
-	}
-
-	return m.store.AppendEvent(run.Ref(), model.NewStatusEvent(model.StatusCanceled))
+	_, err := agent.StopRun(m.store, run)
+	return err
 }
 
 func (m *Monitor) StartRun(issueID string, agentType string) (string, error) {
@@ -418,31 +414,11 @@ func (m *Monitor) StartRun(issueID string, agentType string) (string, error) {
 }
 
 func (m *Monitor) parseAgentPreset(agentType string) (agentName, model, variant string) {
-	idx := strings.Index(agentType, ":")
-	if idx == -1 {
-		return agentType, "", ""
-	}
-
-	agentName = agentType[:idx]
-	presetName := agentType[idx+1:]
-
-	for _, preset := range m.opencodePresets {
-		if preset.Name == presetName {
-			return agentName, preset.Model, preset.Variant
-		}
-	}
-
-	return agentName, "", presetName
+	return config.ParseAgentPreset(agentType, m.opencodePresets)
 }
 
 func (m *Monitor) GetAvailableAgents() []string {
-	agents := []string{
-		string(agent.AgentClaude),
-		string(agent.AgentCodex),
-		string(agent.AgentGemini),
-		string(agent.AgentOpenCode),
-		string(agent.AgentCustom),
-	}
+	agents := agent.AgentNames()
 
 	available := make([]string, 0, len(agents))
 	for _, agentName := range agents {
@@ -863,6 +839,7 @@ func (m *Monitor) buildRunRows(windows []*RunWindow) ([]RunRow, error) {
 			Started:      w.Run.StartedAt,
 			Updated:      w.Run.UpdatedAt,
 			Topic:        topic,
+			Cost:         model.FormatCost(w.Run.Usage.Cost),
 			Run:          w.Run,
 		})
 	}
@@ -1,12 +1,17 @@
 package monitor
 
 import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"reflect"
 	"strings"
 	"testing"
 	"time"
 
 	"github.com/s22625/orch/internal/config"
 	"github.com/s22625/orch/internal/model"
+	"github.com/s22625/orch/internal/terminal"
 )
 
 func TestSessionNameForVault(t *testing.T) {
@@ -323,3 +328,122 @@ func TestDashboardRenderCapture(t *testing.T) {
 		})
 	}
 }
+
+func TestGetRunAttacher(t *testing.T) {
+	tests := []struct {
+		name string
+		run  *model.Run
+		want RunAttacher
+	}{
+		{"tmux", &model.Run{Agent: "claude"}, &TmuxRunAttacher{}},
+		{"tmux explicit", &model.Run{Agent: "codex", Terminal: terminal.BackendTmux}, &TmuxRunAttacher{}},
+		{"zellij", &model.Run{Agent: "claude", Terminal: terminal.BackendZellij}, &TerminalRunAttacher{}},
+		{"pty", &model.Run{Agent: "claude", Terminal: terminal.BackendPTY}, &TerminalRunAttacher{}},
+		{"opencode", &model.Run{Agent: "opencode", Terminal: terminal.BackendZellij}, &OpenCodeRunAttacher{}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := GetRunAttacher(tt.run)
+			if reflect.TypeOf(got) != reflect.TypeOf(tt.want) {
+				t.Errorf("GetRunAttacher() = %T, want %T", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDashboardRenderDiff(t *testing.T) {
+	run := &model.Run{IssueID: "test-001", RunID: "20231225-120000", BaseBranch: "origin/develop"}
+	d := &Dashboard{
+		runs:     []RunRow{{Index: 0, IssueID: "test-001", Run: run}},
+		showDiff: true,
+		diff: captureState{
+			runRef:  run.Ref().String(),
+			content: " a.go | 2 +-\n 1 file changed\n\n@@ -1 +1 @@\n-old\n+new",
+		},
+		diffBase: "origin/develop",
+		width:    120,
+		height:   80,
+		styles:   DefaultStyles(),
+	}
+
+	result := d.renderContext(20)
+	for _, want := range []string{"DIFF test-001#20231225-120000 vs origin/develop", "a.go | 2 +-", "+new"} {
+		if !strings.Contains(result, want) {
+			t.Errorf("renderContext() = %q, want to contain %q", result, want)
+		}
+	}
+	if strings.Contains(result, "CAPTURE") {
+		t.Errorf("renderContext() shows the capture pane with the diff toggled on:\n%s", result)
+	}
+
+	// The stat stays in view when the patch is cut
+	lines := d.diffLines(120)
+	d.diff.content += strings.Repeat("\n+more", 50)
+	result = d.renderContext(8)
+	if !strings.Contains(result, "a.go | 2 +-") || !strings.Contains(result, "...") {
+		t.Errorf("renderContext() = %q, want the top of the diff", result)
+	}
+	if len(lines) != 6 {
+		t.Errorf("diffLines() = %d lines, want 6", len(lines))
+	}
+
+	if _, err := (&Monitor{}).RunDiff(&model.Run{}); err == nil {
+		t.Error("RunDiff without a worktree should fail")
+	}
+
+	// The base is resolved when a run is selected, not on every render
+	other := &model.Run{IssueID: "test-002", RunID: "20231225-130000"}
+	d.monitor = &Monitor{baseBranch: "release"}
+	d.runs = []RunRow{{Index: 0, IssueID: "test-002", Run: other}}
+	d.startDiff()
+	if d.diffBase != "release" {
+		t.Errorf("diffBase = %q, want the configured base", d.diffBase)
+	}
+}
+
+func TestRunDiffCache(t *testing.T) {
+	repo := t.TempDir()
+	runGit := func(args ...string) {
+		t.Helper()
+		cmd := exec.Command("git", append([]string{"-C", repo}, args...)...)
+		if out, err := cmd.CombinedOutput(); err != nil {
+			t.Fatalf("git %v: %v (%s)", args, err, out)
+		}
+	}
+	runGit("init", "-q")
+	runGit("config", "user.email", "test@example.com")
+	runGit("config", "user.name", "Test")
+	writeFile := func(content string) {
+		t.Helper()
+		if err := os.WriteFile(filepath.Join(repo, "a.txt"), []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	writeFile("old\n")
+	runGit("add", "a.txt")
+	runGit("commit", "-q", "-m", "init")
+	runGit("branch", "-M", "main")
+
+	m := &Monitor{}
+	run := &model.Run{IssueID: "test-001", RunID: "20231225-120000", WorktreePath: repo}
+	writeFile("new\n")
+	first, err := m.RunDiff(run)
+	if err != nil {
+		t.Fatalf("RunDiff: %v", err)
+	}
+	if !strings.Contains(first, "1 file changed") || !strings.Contains(first, "+new") {
+		t.Errorf("diff = %q, want the stat and the patch", first)
+	}
+
+	// Unchanged worktree: the cached diff is returned
+	m.diffs[run.Ref().String()].content = "cached"
+	if got, _ := m.RunDiff(run); got != "cached" {
+		t.Errorf("RunDiff = %q, want the cached diff", got)
+	}
+
+	// A changed worktree is diffed again
+	writeFile("newer content\n")
+	if got, _ := m.RunDiff(run); !strings.Contains(got, "+newer content") {
+		t.Errorf("RunDiff = %q, want a fresh diff", got)
+	}
+}
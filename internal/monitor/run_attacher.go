@@ -7,6 +7,7 @@ import (
 
 	"github.com/s22625/orch/internal/agent"
 	"github.com/s22625/orch/internal/model"
+	"github.com/s22625/orch/internal/terminal"
 	"github.com/s22625/orch/internal/tmux"
 )
 
@@ -14,11 +15,20 @@ type RunAttacher interface {
 	Attach(m *Monitor, run *model.Run) error
 }
 
-func GetRunAttacher(agentType string) RunAttacher {
-	baseAgent := extractAgentName(agentType)
+func GetRunAttacher(run *model.Run) RunAttacher {
+	baseAgent := extractAgentName(run.Agent)
 	if baseAgent == string(agent.AgentOpenCode) {
 		return &OpenCodeRunAttacher{}
 	}
+	// Sessions outside tmux cannot be linked into the monitor session; an
+	// unknown backend is left for orch attach to report
+	backend, err := terminal.Get(run.Terminal)
+	if err != nil {
+		return &TerminalRunAttacher{}
+	}
+	if _, ok := backend.(terminal.WindowBackend); !ok {
+		return &TerminalRunAttacher{}
+	}
 	return &TmuxRunAttacher{}
 }
 
@@ -99,25 +109,46 @@ func (a *OpenCodeRunAttacher) Attach(m *Monitor, run *model.Run) error {
 		attachCmd = fmt.Sprintf("%s --dir %s", attachCmd, run.WorktreePath)
 	}
 
+	windowName := fmt.Sprintf("opencode-%s", run.ShortID())
+	if err := m.openCommandWindow(windowName, run.WorktreePath, attachCmd); err != nil {
+		return fmt.Errorf("failed to create opencode window for %s: %w", run.Ref().String(), err)
+	}
+	return nil
+}
+
+// TerminalRunAttacher opens runs whose sessions live outside tmux (zellij,
+// pty) in a monitor window running orch attach
+type TerminalRunAttacher struct{}
+
+func (a *TerminalRunAttacher) Attach(m *Monitor, run *model.Run) error {
+	args := append([]string{m.orchPath}, m.globalFlags...)
+	args = append(args, "attach", run.Ref().String())
+
+	windowName := fmt.Sprintf("attach-%s", run.ShortID())
+	if err := m.openCommandWindow(windowName, run.WorktreePath, shellJoin(args)); err != nil {
+		return fmt.Errorf("failed to create attach window for %s: %w", run.Ref().String(), err)
+	}
+	return nil
+}
+
+// openCommandWindow selects the monitor window with the given name, creating
+// it with the command first if there is none
+func (m *Monitor) openCommandWindow(windowName, workDir, command string) error {
 	monitorWindows, err := tmux.ListWindows(m.session)
 	if err != nil {
 		return err
 	}
-
-	windowName := fmt.Sprintf("opencode-%s", run.ShortID())
 	for _, w := range monitorWindows {
 		if w.Name == windowName {
 			return tmux.SelectWindow(m.session, w.Index)
 		}
 	}
 
-	workDir := run.WorktreePath
 	if workDir == "" {
 		workDir, _ = os.Getwd()
 	}
-
-	if err := tmux.NewWindow(m.session, windowName, workDir, attachCmd); err != nil {
-		return fmt.Errorf("failed to create opencode window for %s: %w", run.Ref().String(), err)
+	if err := tmux.NewWindow(m.session, windowName, workDir, command); err != nil {
+		return err
 	}
 
 	updatedWindows, err := tmux.ListWindows(m.session)
@@ -16,6 +16,9 @@ type Styles struct {
 	Alive    map[string]lipgloss.Style
 	PRState  map[string]lipgloss.Style
 	Merged   map[string]lipgloss.Style
+	DiffAdd  lipgloss.Style
+	DiffDel  lipgloss.Style
+	DiffHunk lipgloss.Style
 }
 
 // DefaultStyles returns the standard dashboard styles.
@@ -27,6 +30,9 @@ func DefaultStyles() Styles {
 		Text:     lipgloss.NewStyle(),
 		Selected: lipgloss.NewStyle().Foreground(lipgloss.Color("230")).Background(lipgloss.Color("237")),
 		Faint:    lipgloss.NewStyle().Foreground(lipgloss.Color("241")),
+		DiffAdd:  lipgloss.NewStyle().Foreground(lipgloss.Color("2")),
+		DiffDel:  lipgloss.NewStyle().Foreground(lipgloss.Color("1")),
+		DiffHunk: lipgloss.NewStyle().Foreground(lipgloss.Color("6")),
 		Status: map[model.Status]lipgloss.Style{
 			model.StatusRunning:    lipgloss.NewStyle().Foreground(lipgloss.Color("2")),
 			model.StatusBlocked:    lipgloss.NewStyle().Foreground(lipgloss.Color("3")),
@@ -0,0 +1,63 @@
+package monitor
+
+import (
+	"context"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/s22625/orch/internal/store"
+)
+
+// storeChangedMsg is sent when the store reported changes since the last wait
+type storeChangedMsg struct{}
+
+// storeWatch feeds store change notifications into a bubbletea program so
+// dashboards refresh as soon as a run or issue changes instead of on a timer.
+type storeWatch struct {
+	changes <-chan store.Change
+	cancel  context.CancelFunc
+}
+
+// startStoreWatch subscribes to store changes. It returns nil when the
+// backend cannot watch, in which case callers keep polling.
+func startStoreWatch(st store.Store) *storeWatch {
+	ctx, cancel := context.WithCancel(context.Background())
+	changes, err := st.Watch(ctx, nil)
+	if err != nil || changes == nil {
+		cancel()
+		return nil
+	}
+	return &storeWatch{changes: changes, cancel: cancel}
+}
+
+// next waits for the next change. Changes arriving within
+// watchCoalesceWindow (a status event followed by its artifacts, say) are
+// folded into one message so they cause a single refresh.
+func (w *storeWatch) next() tea.Cmd {
+	if w == nil {
+		return nil
+	}
+	return func() tea.Msg {
+		if _, ok := <-w.changes; !ok {
+			return nil
+		}
+		timer := time.NewTimer(watchCoalesceWindow)
+		defer timer.Stop()
+		for {
+			select {
+			case _, ok := <-w.changes:
+				if !ok {
+					return storeChangedMsg{}
+				}
+			case <-timer.C:
+				return storeChangedMsg{}
+			}
+		}
+	}
+}
+
+func (w *storeWatch) stop() {
+	if w != nil {
+		w.cancel()
+	}
+}
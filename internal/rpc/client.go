@@ -0,0 +1,286 @@
+package rpc
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/s22625/orch/internal/model"
+)
+
+// DefaultCallTimeout bounds a call whose context has no deadline. Starting a
+// run creates a worktree and launches the agent, so it is generous.
+const DefaultCallTimeout = 2 * time.Minute
+
+// SocketPath returns the daemon socket of a vault
+func SocketPath(vaultPath string) string {
+	return filepath.Join(vaultPath, ".orch", "daemon.sock")
+}
+
+// Client calls the daemon over one socket connection. Calls are serialized;
+// Subscribe uses a connection of its own.
+type Client struct {
+	path string
+	conn net.Conn
+	enc  *json.Encoder
+	dec  *json.Decoder
+
+	hello HelloResult
+
+	mu     sync.Mutex
+	nextID int64
+}
+
+// Dial connects to the daemon of a vault and checks that it speaks this
+// protocol version
+func Dial(vaultPath string) (*Client, error) {
+	return DialSocket(SocketPath(vaultPath))
+}
+
+// DialSocket connects to a daemon socket
+func DialSocket(path string) (*Client, error) {
+	c := &Client{path: path}
+	if err := c.connect(); err != nil {
+		return nil, err
+	}
+	if err := c.Call(context.Background(), MethodHello, nil, &c.hello); err != nil {
+		c.Close()
+		return nil, err
+	}
+	if c.hello.Version < Version {
+		c.Close()
+		return nil, fmt.Errorf("daemon speaks protocol %d, need %d (run orch daemon-restart)", c.hello.Version, Version)
+	}
+	return c, nil
+}
+
+// Hello returns what the daemon reported when the client connected
+func (c *Client) Hello() HelloResult {
+	return c.hello
+}
+
+func (c *Client) connect() error {
+	conn, err := net.DialTimeout("unix", c.path, 5*time.Second)
+	if err != nil {
+		return fmt.Errorf("failed to connect to daemon: %w", err)
+	}
+	c.conn = conn
+	c.enc = json.NewEncoder(conn)
+	c.dec = json.NewDecoder(bufio.NewReader(conn))
+	return nil
+}
+
+// Close closes the connection
+func (c *Client) Close() error {
+	return c.conn.Close()
+}
+
+// Call invokes a method; result may be nil. Errors returned by the daemon
+// are *Error.
+func (c *Client) Call(ctx context.Context, method string, params, result interface{}) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.nextID++
+	req := Request{JSONRPC: "2.0", ID: c.nextID, Method: method, Version: Version}
+	if params != nil {
+		raw, err := json.Marshal(params)
+		if err != nil {
+			return err
+		}
+		req.Params = raw
+	}
+
+	deadline, ok := ctx.Deadline()
+	if !ok {
+		deadline = time.Now().Add(DefaultCallTimeout)
+	}
+	c.conn.SetDeadline(deadline)
+	defer c.conn.SetDeadline(time.Time{})
+
+	if err := c.enc.Encode(&req); err != nil {
+		return fmt.Errorf("failed to send request: %w", err)
+	}
+	for {
+		var resp Response
+		if err := c.dec.Decode(&resp); err != nil {
+			return fmt.Errorf("failed to read response: %w", err)
+		}
+		if resp.ID != req.ID {
+			continue // notification or stale reply
+		}
+		if resp.Error != nil {
+			return resp.Error
+		}
+		if result == nil || len(resp.Result) == 0 {
+			return nil
+		}
+		return json.Unmarshal(resp.Result, result)
+	}
+}
+
+// ListRuns lists runs with their derived state (no events)
+func (c *Client) ListRuns(ctx context.Context, params *ListRunsParams) ([]*model.Run, error) {
+	if params == nil {
+		params = &ListRunsParams{}
+	}
+	var result ListRunsResult
+	if err := c.Call(ctx, MethodListRuns, params, &result); err != nil {
+		return nil, err
+	}
+	runs := make([]*model.Run, 0, len(result.Runs))
+	for _, r := range result.Runs {
+		runs = append(runs, r.ModelRun())
+	}
+	return runs, nil
+}
+
+// GetRun returns a run with its events
+func (c *Client) GetRun(ctx context.Context, ref string) (*model.Run, error) {
+	var result Run
+	if err := c.Call(ctx, MethodGetRun, &RefParams{Ref: ref}, &result); err != nil {
+		return nil, err
+	}
+	return result.ModelRun(), nil
+}
+
+// StopRun kills a run's session and records it canceled
+func (c *Client) StopRun(ctx context.Context, ref string) (*StopResult, error) {
+	var result StopResult
+	if err := c.Call(ctx, MethodStopRun, &RefParams{Ref: ref}, &result); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+// StartRun starts a run for an issue (orch run)
+func (c *Client) StartRun(ctx context.Context, params *StartParams) (*StartResult, error) {
+	var result StartResult
+	if err := c.Call(ctx, MethodStartRun, params, &result); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+// ContinueRun continues a run in a new run (orch continue)
+func (c *Client) ContinueRun(ctx context.Context, params *StartParams) (*StartResult, error) {
+	var result StartResult
+	if err := c.Call(ctx, MethodContinueRun, params, &result); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+// Capture returns the latest output of a run's agent
+func (c *Client) Capture(ctx context.Context, ref string, lines int) (string, error) {
+	var result CaptureResult
+	if err := c.Call(ctx, MethodCapture, &CaptureParams{Ref: ref, Lines: lines}, &result); err != nil {
+		return "", err
+	}
+	return result.Output, nil
+}
+
+// Send sends a message to a run's agent
+func (c *Client) Send(ctx context.Context, ref, message string, noEnter bool) error {
+	return c.Call(ctx, MethodSend, &SendParams{Ref: ref, Message: message, NoEnter: noEnter}, nil)
+}
+
+// AppendEvent appends an event to a run
+func (c *Client) AppendEvent(ctx context.Context, ref string, event *model.Event) error {
+	return c.Call(ctx, MethodAppendEvent, &AppendEventParams{Ref: ref, Event: NewEvent(event)}, nil)
+}
+
+// Subscribe streams store changes matching params on a new connection until
+// ctx is done or the daemon goes away, then closes the channel
+func (c *Client) Subscribe(ctx context.Context, params *SubscribeParams) (<-chan Change, error) {
+	sub := &Client{path: c.path}
+	if err := sub.connect(); err != nil {
+		return nil, err
+	}
+	if params == nil {
+		params = &SubscribeParams{}
+	}
+	if err := sub.Call(ctx, MethodSubscribe, params, nil); err != nil {
+		sub.Close()
+		return nil, err
+	}
+
+	changes := make(chan Change, 64)
+	go func() {
+		<-ctx.Done()
+		sub.Close()
+	}()
+	go func() {
+		defer close(changes)
+		for {
+			var resp Response
+			if err := sub.dec.Decode(&resp); err != nil {
+				return
+			}
+			if resp.Method != NotificationChange {
+				continue
+			}
+			var change Change
+			if json.Unmarshal(resp.Params, &change) != nil {
+				continue
+			}
+			select {
+			case changes <- change:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+	return changes, nil
+}
+
+// AttachPTY connects to a pty session on a new connection. The returned
+// stream reads the session's recent output followed by live output and
+// writes keystrokes to it; closing it detaches.
+func (c *Client) AttachPTY(ctx context.Context, params *PTYAttachParams) (io.ReadWriteCloser, error) {
+	conn, err := net.DialTimeout("unix", c.path, 5*time.Second)
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to daemon: %w", err)
+	}
+	raw, err := json.Marshal(params)
+	if err != nil {
+		conn.Close()
+		return nil, err
+	}
+	if deadline, ok := ctx.Deadline(); ok {
+		conn.SetDeadline(deadline)
+	}
+	req := Request{JSONRPC: "2.0", ID: 1, Method: MethodPTYAttach, Params: raw, Version: Version}
+	if err := json.NewEncoder(conn).Encode(&req); err != nil {
+		conn.Close()
+		return nil, fmt.Errorf("failed to send request: %w", err)
+	}
+	// No buffering beyond the response, so the terminal bytes that follow
+	// are read from the connection
+	dec := json.NewDecoder(conn)
+	var resp Response
+	if err := dec.Decode(&resp); err != nil {
+		conn.Close()
+		return nil, fmt.Errorf("failed to read response: %w", err)
+	}
+	if resp.Error != nil {
+		conn.Close()
+		return nil, resp.Error
+	}
+	conn.SetDeadline(time.Time{})
+	return &attachStream{Reader: io.MultiReader(dec.Buffered(), conn), conn: conn}, nil
+}
+
+type attachStream struct {
+	io.Reader
+	conn net.Conn
+}
+
+func (s *attachStream) Write(p []byte) (int, error) { return s.conn.Write(p) }
+func (s *attachStream) Close() error                { return s.conn.Close() }
@@ -0,0 +1,339 @@
+package rpc_test
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/s22625/orch/internal/agent"
+	"github.com/s22625/orch/internal/daemon"
+	"github.com/s22625/orch/internal/model"
+	"github.com/s22625/orch/internal/rpc"
+	"github.com/s22625/orch/internal/store"
+	"github.com/s22625/orch/internal/store/file"
+	"github.com/s22625/orch/internal/terminal"
+)
+
+// fakeAgent stands in for the agent of every run
+type fakeAgent struct {
+	output string
+	sent   []string
+}
+
+func (a *fakeAgent) IsAlive(run *model.Run) bool { return true }
+func (a *fakeAgent) GetStatus(run *model.Run, output string, state *agent.RunState, outputChanged, hasPrompt bool) model.Status {
+	return ""
+}
+func (a *fakeAgent) CaptureOutput(run *model.Run) (string, error) { return a.output, nil }
+func (a *fakeAgent) DetectPrompt(output string) bool              { return false }
+func (a *fakeAgent) SendMessage(ctx context.Context, run *model.Run, message string, opts *agent.SendOptions) error {
+	a.sent = append(a.sent, message)
+	return nil
+}
+
+type testServer struct {
+	store  store.Store
+	agent  *fakeAgent
+	pty    *terminal.PTYHost
+	client *rpc.Client
+	vault  string
+	args   [][]string
+}
+
+// newTestServer runs a socket server in process on a fresh vault with one
+// running run (orch-1#20240101-000000)
+func newTestServer(t *testing.T) *testServer {
+	t.Helper()
+	// Unix socket paths are short; keep the vault under /tmp
+	vault, err := os.MkdirTemp("/tmp", "orch-rpc-")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { os.RemoveAll(vault) })
+	os.MkdirAll(filepath.Join(vault, "issues"), 0755)
+	os.MkdirAll(filepath.Join(vault, ".orch"), 0755)
+	os.WriteFile(filepath.Join(vault, "issues", "orch-1.md"), []byte("---\ntype: issue\n---\n# Test"), 0644)
+
+	st, err := file.New(vault)
+	if err != nil {
+		t.Fatal(err)
+	}
+	run, err := st.CreateRun("orch-1", "20240101-000000", map[string]string{"agent": "claude"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	st.AppendEvent(run.Ref(), model.NewStatusEvent(model.StatusRunning))
+	st.AppendEvent(run.Ref(), model.NewArtifactEvent("branch", map[string]string{"name": "issue/orch-1/run-20240101-000000"}))
+
+	ts := &testServer{store: st, agent: &fakeAgent{output: "line1\nline2\nline3\n"}, vault: vault}
+	server := daemon.NewSocketServer(vault, st, log.New(io.Discard, "", 0))
+	server.SetAgentManager(func(*model.Run) agent.AgentManager { return ts.agent })
+	server.SetCommandRunner(func(ctx context.Context, dir string, args []string) ([]byte, error) {
+		ts.args = append(ts.args, args)
+		if args[1] == "missing" {
+			return []byte(`{"ok": false, "error": "issue not found: missing"}`), errors.New("exit status 2")
+		}
+		return []byte(`{"ok": true, "issue_id": "orch-1", "run_id": "20240102-000000", "branch": "issue/orch-1/run-20240102-000000", "status": "running"}`), nil
+	})
+	ts.pty = terminal.NewPTYHost()
+	t.Cleanup(ts.pty.Close)
+	server.SetPTYHost(ts.pty)
+	server.SetBackend("file")
+	if err := server.Start(); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(server.Stop)
+
+	client, err := rpc.Dial(vault)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { client.Close() })
+	ts.client = client
+	return ts
+}
+
+func TestListAndGetRuns(t *testing.T) {
+	ts := newTestServer(t)
+	ctx := context.Background()
+
+	runs, err := ts.client.ListRuns(ctx, &rpc.ListRunsParams{Status: []string{"running"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(runs) != 1 {
+		t.Fatalf("runs = %d, want 1", len(runs))
+	}
+	run := runs[0]
+	if run.Status != model.StatusRunning || run.Agent != "claude" || run.Branch != "issue/orch-1/run-20240101-000000" {
+		t.Errorf("run = %+v", run)
+	}
+
+	if runs, _ := ts.client.ListRuns(ctx, &rpc.ListRunsParams{Status: []string{"done"}}); len(runs) != 0 {
+		t.Errorf("done runs = %d, want 0", len(runs))
+	}
+
+	// By short ID, with events
+	got, err := ts.client.GetRun(ctx, run.ShortID())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.RunID != run.RunID || len(got.Events) != 2 || got.Events[0].Type != model.EventTypeStatus {
+		t.Errorf("run = %+v, events = %v", got, got.Events)
+	}
+
+	_, err = ts.client.GetRun(ctx, "orch-1#19990101-000000")
+	var rpcErr *rpc.Error
+	if !errors.As(err, &rpcErr) || rpcErr.Code != rpc.CodeRunNotFound {
+		t.Errorf("err = %v, want run not found", err)
+	}
+}
+
+func TestAppendEventAndStop(t *testing.T) {
+	ts := newTestServer(t)
+	ctx := context.Background()
+	ref := "orch-1#20240101-000000"
+
+	if err := ts.client.AppendEvent(ctx, ref, model.NewEvent(model.EventTypeNote, "review", map[string]string{"text": "looks good"})); err != nil {
+		t.Fatal(err)
+	}
+	// The state machine still applies
+	err := ts.client.AppendEvent(ctx, ref, model.NewStatusEvent(model.StatusQueued))
+	var rpcErr *rpc.Error
+	if !errors.As(err, &rpcErr) || rpcErr.Code != rpc.CodeInvalidParams {
+		t.Errorf("invalid transition err = %v", err)
+	}
+
+	result, err := ts.client.StopRun(ctx, ref)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.Status != "canceled" || result.AlreadyDone {
+		t.Errorf("stop = %+v", result)
+	}
+	if result, _ := ts.client.StopRun(ctx, ref); !result.AlreadyDone {
+		t.Errorf("second stop = %+v", result)
+	}
+
+	run, _ := ts.store.GetRun(&model.RunRef{IssueID: "orch-1", RunID: "20240101-000000"})
+	if run.Status != model.StatusCanceled {
+		t.Errorf("status = %s, want canceled", run.Status)
+	}
+	if e := run.Events[len(run.Events)-2]; e.Type != model.EventTypeNote || e.Attrs["text"] != "looks good" {
+		t.Errorf("note event = %+v", e)
+	}
+}
+
+func TestCaptureAndSend(t *testing.T) {
+	ts := newTestServer(t)
+	ctx := context.Background()
+
+	output, err := ts.client.Capture(ctx, "orch-1", 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if output != "line2\nline3\n" {
+		t.Errorf("output = %q", output)
+	}
+	if err := ts.client.Send(ctx, "orch-1", "continue", false); err != nil {
+		t.Fatal(err)
+	}
+	if len(ts.agent.sent) != 1 || ts.agent.sent[0] != "continue" {
+		t.Errorf("sent = %v", ts.agent.sent)
+	}
+}
+
+func TestStartAndContinue(t *testing.T) {
+	ts := newTestServer(t)
+	ctx := context.Background()
+
+	result, err := ts.client.StartRun(ctx, &rpc.StartParams{IssueID: "orch-1", Args: []string{"--agent", "codex"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.RunID != "20240102-000000" || result.Status != "running" {
+		t.Errorf("start = %+v", result)
+	}
+	want := "run orch-1 --agent codex --json --vault " + ts.vault
+	if got := strings.Join(ts.args[0], " "); got != want {
+		t.Errorf("args = %q, want %q", got, want)
+	}
+
+	if _, err := ts.client.ContinueRun(ctx, &rpc.StartParams{Ref: "orch-1"}); err != nil {
+		t.Fatal(err)
+	}
+	if ts.args[1][0] != "continue" || ts.args[1][1] != "orch-1" {
+		t.Errorf("args = %v", ts.args[1])
+	}
+
+	_, err = ts.client.StartRun(ctx, &rpc.StartParams{IssueID: "missing"})
+	var rpcErr *rpc.Error
+	if !errors.As(err, &rpcErr) || rpcErr.Code != rpc.CodeCommandFailed || !strings.Contains(rpcErr.Message, "issue not found") {
+		t.Errorf("err = %v", err)
+	}
+}
+
+func TestSubscribe(t *testing.T) {
+	ts := newTestServer(t)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	changes, err := ts.client.Subscribe(ctx, &rpc.SubscribeParams{Types: []string{"event_appended"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	ref := &model.RunRef{IssueID: "orch-1", RunID: "20240101-000000"}
+	if err := ts.store.AppendEvent(ref, model.NewStatusEvent(model.StatusBlocked)); err != nil {
+		t.Fatal(err)
+	}
+
+	select {
+	case change := <-changes:
+		if change.Type != "event_appended" || change.RunID != ref.RunID || change.Event == nil || change.Event.Name != "blocked" {
+			t.Errorf("change = %+v", change)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("no change delivered")
+	}
+
+	// The calling connection is still usable while subscribed
+	if _, err := ts.client.GetRun(context.Background(), "orch-1"); err != nil {
+		t.Fatal(err)
+	}
+
+	cancel()
+	select {
+	case _, ok := <-changes:
+		for ok {
+			_, ok = <-changes
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("subscription not closed")
+	}
+}
+
+func TestProtocolErrors(t *testing.T) {
+	ts := newTestServer(t)
+	ctx := context.Background()
+
+	var rpcErr *rpc.Error
+	err := ts.client.Call(ctx, "runs.nope", nil, nil)
+	if !errors.As(err, &rpcErr) || rpcErr.Code != rpc.CodeMethodNotFound {
+		t.Errorf("unknown method err = %v", err)
+	}
+	err = ts.client.Call(ctx, rpc.MethodGetRun, map[string]int{"ref": 1}, nil)
+	if !errors.As(err, &rpcErr) || rpcErr.Code != rpc.CodeInvalidParams {
+		t.Errorf("bad params err = %v", err)
+	}
+
+	var hello rpc.HelloResult
+	if err := ts.client.Call(ctx, rpc.MethodHello, nil, &hello); err != nil || hello.Version != rpc.Version {
+		t.Errorf("hello = %+v, %v", hello, err)
+	}
+	if got := ts.client.Hello(); got.Version != rpc.Version || got.Backend != "file" {
+		t.Errorf("Hello() = %+v, want version %d and backend file", got, rpc.Version)
+	}
+}
+
+func TestPTYSessions(t *testing.T) {
+	if !terminal.NewPTYHost().Available() {
+		t.Skip("no pty support")
+	}
+	t.Setenv("SHELL", "/bin/sh")
+	ts := newTestServer(t)
+	pty := terminal.NewPTYClient(rpc.SocketPath(ts.vault))
+
+	if err := pty.NewSession(&terminal.SessionConfig{Name: "s", WorkDir: ts.vault, Command: "echo hello-$((2+3))"}); err != nil {
+		t.Fatalf("NewSession: %v", err)
+	}
+	if !pty.HasSession("s") || pty.HasSession("other") {
+		t.Fatal("HasSession")
+	}
+	if err := terminal.WaitForReady(pty, "s", "hello-5", 5*time.Second); err != nil {
+		t.Fatal(err)
+	}
+
+	// Attach sees the output so far and types into the session
+	stream, err := ts.client.AttachPTY(context.Background(), &rpc.PTYAttachParams{Name: "s", Width: 80, Height: 24})
+	if err != nil {
+		t.Fatalf("AttachPTY: %v", err)
+	}
+	var seen strings.Builder
+	buf := make([]byte, 4096)
+	for !strings.Contains(seen.String(), "hello-5") {
+		n, err := stream.Read(buf)
+		if err != nil {
+			t.Fatalf("attach snapshot = %q, %v", seen.String(), err)
+		}
+		seen.Write(buf[:n])
+	}
+	stream.Write([]byte("echo typed-$((1+1))\r"))
+	if err := terminal.WaitForReady(pty, "s", "typed-2", 5*time.Second); err != nil {
+		t.Fatal(err)
+	}
+	stream.Close()
+
+	if err := pty.SendKeys("s", "echo sent-$((3+3))", true); err != nil {
+		t.Fatalf("SendKeys: %v", err)
+	}
+	if err := terminal.WaitForReady(pty, "s", "sent-6", 5*time.Second); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := pty.Kill("s"); err != nil {
+		t.Fatalf("Kill: %v", err)
+	}
+	var notFound *terminal.SessionNotFoundError
+	if _, err := pty.Capture("missing", 10); !errors.As(err, &notFound) {
+		t.Errorf("Capture(missing) = %v", err)
+	}
+	if _, err := ts.client.AttachPTY(context.Background(), &rpc.PTYAttachParams{Name: "missing"}); err == nil {
+		t.Error("attaching a missing session should fail")
+	}
+}
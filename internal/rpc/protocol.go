@@ -0,0 +1,314 @@
+// Package rpc is the local control API of the orch daemon: JSON-RPC 2.0
+// messages, one JSON document per line, over the daemon's unix socket
+// ($VAULT/.orch/daemon.sock).
+package rpc
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/s22625/orch/internal/model"
+)
+
+// Version is the protocol version. Requests may carry the version the client
+// speaks; the daemon rejects versions newer than its own.
+const Version = 1
+
+// Methods
+const (
+	MethodHello       = "hello"
+	MethodListRuns    = "runs.list"
+	MethodGetRun      = "runs.get"
+	MethodStopRun     = "runs.stop"
+	MethodStartRun    = "runs.start"
+	MethodContinueRun = "runs.continue"
+	MethodCapture     = "runs.capture"
+	MethodSend        = "runs.send"
+	MethodAppendEvent = "events.append"
+	MethodSubscribe   = "events.subscribe"
+
+	// Sessions of the pty terminal backend, which the daemon hosts
+	MethodPTYNew     = "pty.new"
+	MethodPTYHas     = "pty.has"
+	MethodPTYSend    = "pty.send"
+	MethodPTYCapture = "pty.capture"
+	MethodPTYKill    = "pty.kill"
+	MethodPTYAttach  = "pty.attach"
+
+	// NotificationChange is sent on a subscribed connection for every
+	// store change (params: Change)
+	NotificationChange = "change"
+)
+
+// Error codes: the JSON-RPC ones and orch's own
+const (
+	CodeParseError     = -32700
+	CodeInvalidRequest = -32600
+	CodeMethodNotFound = -32601
+	CodeInvalidParams  = -32602
+	CodeInternalError  = -32603
+
+	CodeRunNotFound        = 1
+	CodeUnsupportedVersion = 2
+	CodeAgentError         = 3
+	CodeCommandFailed      = 4
+	CodeSessionNotFound    = 5
+)
+
+// Request is a call from a client
+type Request struct {
+	JSONRPC string          `json:"jsonrpc"` // "2.0"
+	ID      int64           `json:"id"`
+	Method  string          `json:"method"`
+	Params  json.RawMessage `json:"params,omitempty"`
+	Version int             `json:"version,omitempty"` // protocol version of the client
+}
+
+// Response is the reply to a Request, or a notification (Method set, no ID)
+type Response struct {
+	JSONRPC string          `json:"jsonrpc"`
+	ID      int64           `json:"id,omitempty"`
+	Result  json.RawMessage `json:"result,omitempty"`
+	Error   *Error          `json:"error,omitempty"`
+	Method  string          `json:"method,omitempty"`
+	Params  json.RawMessage `json:"params,omitempty"`
+}
+
+// Error is a failed call
+type Error struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+}
+
+func (e *Error) Error() string {
+	return fmt.Sprintf("daemon error %d: %s", e.Code, e.Message)
+}
+
+// Errorf returns an Error with a formatted message
+func Errorf(code int, format string, args ...interface{}) *Error {
+	return &Error{Code: code, Message: fmt.Sprintf(format, args...)}
+}
+
+// HelloResult identifies the daemon
+type HelloResult struct {
+	Version int    `json:"version"`
+	PID     int    `json:"pid"`
+	Vault   string `json:"vault"`
+	Backend string `json:"backend"` // store backend the daemon serves
+}
+
+// RefParams names a run: short ID, ISSUE#RUN, or ISSUE for its latest run
+type RefParams struct {
+	Ref string `json:"ref"`
+}
+
+// ListRunsParams filters runs.list
+type ListRunsParams struct {
+	IssueID string   `json:"issue_id,omitempty"`
+	Status  []string `json:"status,omitempty"`
+	Limit   int      `json:"limit,omitempty"`
+	Since   string   `json:"since,omitempty"` // RFC3339
+}
+
+// ListRunsResult is the result of runs.list
+type ListRunsResult struct {
+	Runs []*Run `json:"runs"`
+}
+
+// Run is a run with its derived state. Events are only sent by runs.get.
+type Run struct {
+	IssueID       string          `json:"issue_id"`
+	RunID         string          `json:"run_id"`
+	ShortID       string          `json:"short_id"`
+	Path          string          `json:"path"`
+	Agent         string          `json:"agent,omitempty"`
+	Model         string          `json:"model,omitempty"`
+	ModelVariant  string          `json:"model_variant,omitempty"`
+	ContinuedFrom string          `json:"continued_from,omitempty"`
+	State         *model.RunState `json:"state"`
+	Events        []*Event        `json:"events,omitempty"`
+}
+
+// Event is a run event
+type Event struct {
+	Time  time.Time         `json:"ts"`
+	Type  string            `json:"type"`
+	Name  string            `json:"name"`
+	Attrs map[string]string `json:"attrs,omitempty"`
+}
+
+// NewRun converts a run for the wire
+func NewRun(run *model.Run, withEvents bool) *Run {
+	r := &Run{
+		IssueID:       run.IssueID,
+		RunID:         run.RunID,
+		ShortID:       run.ShortID(),
+		Path:          run.Path,
+		Agent:         run.Agent,
+		Model:         run.Model,
+		ModelVariant:  run.ModelVariant,
+		ContinuedFrom: run.ContinuedFrom,
+		State:         run.State(),
+	}
+	if withEvents {
+		for _, e := range run.Events {
+			r.Events = append(r.Events, NewEvent(e))
+		}
+	}
+	return r
+}
+
+// ModelRun rebuilds the run, with its derived fields set from State
+func (r *Run) ModelRun() *model.Run {
+	run := &model.Run{
+		IssueID:       r.IssueID,
+		RunID:         r.RunID,
+		Path:          r.Path,
+		Agent:         r.Agent,
+		Model:         r.Model,
+		ModelVariant:  r.ModelVariant,
+		ContinuedFrom: r.ContinuedFrom,
+		Events:        []*model.Event{},
+	}
+	for _, e := range r.Events {
+		run.Events = append(run.Events, e.ModelEvent())
+	}
+	state := r.State
+	if state == nil {
+		state = model.NewRunState()
+	}
+	run.RestoreState(state)
+	return run
+}
+
+// NewEvent converts an event for the wire
+func NewEvent(e *model.Event) *Event {
+	return &Event{Time: e.Timestamp, Type: string(e.Type), Name: e.Name, Attrs: e.Attrs}
+}
+
+// ModelEvent converts the event back
+func (e *Event) ModelEvent() *model.Event {
+	attrs := e.Attrs
+	if attrs == nil {
+		attrs = map[string]string{}
+	}
+	return &model.Event{Timestamp: e.Time, Type: model.EventType(e.Type), Name: e.Name, Attrs: attrs}
+}
+
+// StopResult is the result of runs.stop
+type StopResult struct {
+	Status        string `json:"status"`                   // status after the call
+	AlreadyDone   bool   `json:"already_done,omitempty"`   // the run had already ended
+	KilledSession string `json:"killed_session,omitempty"` // tmux session that was killed
+}
+
+// StartParams starts a run like `orch run ISSUE ARGS...` (runs.start) or
+// continues one like `orch continue REF ARGS...` (runs.continue). Dir is the
+// directory the command runs in, which selects the repository.
+type StartParams struct {
+	IssueID string   `json:"issue_id,omitempty"` // runs.start
+	Ref     string   `json:"ref,omitempty"`      // runs.continue
+	Args    []string `json:"args,omitempty"`
+	Dir     string   `json:"dir,omitempty"`
+}
+
+// StartResult is the JSON output of the orch run / orch continue command
+type StartResult struct {
+	IssueID      string `json:"issue_id"`
+	RunID        string `json:"run_id"`
+	Branch       string `json:"branch,omitempty"`
+	WorktreePath string `json:"worktree_path,omitempty"`
+	TmuxSession  string `json:"tmux_session,omitempty"`
+	Status       string `json:"status,omitempty"`
+}
+
+// CaptureParams are the params of runs.capture
+type CaptureParams struct {
+	Ref   string `json:"ref"`
+	Lines int    `json:"lines,omitempty"` // default 100
+}
+
+// CaptureResult is the result of runs.capture
+type CaptureResult struct {
+	Output string `json:"output"`
+}
+
+// SendParams are the params of runs.send
+type SendParams struct {
+	Ref     string `json:"ref"`
+	Message string `json:"message"`
+	NoEnter bool   `json:"no_enter,omitempty"`
+}
+
+// AppendEventParams are the params of events.append. Status events are
+// checked against the state machine like any other append.
+type AppendEventParams struct {
+	Ref   string `json:"ref"`
+	Event *Event `json:"event"`
+}
+
+// SubscribeParams filters events.subscribe
+type SubscribeParams struct {
+	IssueID string   `json:"issue_id,omitempty"`
+	RunID   string   `json:"run_id,omitempty"`
+	Types   []string `json:"types,omitempty"` // run_created, event_appended, issue_changed
+}
+
+// Change is a store change delivered to subscribers
+type Change struct {
+	Type    string `json:"type"`
+	IssueID string `json:"issue_id"`
+	RunID   string `json:"run_id,omitempty"`
+	Event   *Event `json:"event,omitempty"`
+}
+
+// OK is the result of calls that return nothing else
+type OK struct {
+	OK bool `json:"ok"`
+}
+
+// PTYNewParams are the params of pty.new
+type PTYNewParams struct {
+	Name    string   `json:"name"`
+	WorkDir string   `json:"work_dir,omitempty"`
+	Command string   `json:"command,omitempty"` // typed into the session's shell
+	Env     []string `json:"env,omitempty"`     // KEY=VALUE
+
+	// Transcript files to record the output to (optional)
+	TranscriptRaw  string `json:"transcript_raw,omitempty"`
+	TranscriptText string `json:"transcript_text,omitempty"`
+	TranscriptCast string `json:"transcript_cast,omitempty"`
+}
+
+// PTYParams name a session for pty.has and pty.kill
+type PTYParams struct {
+	Name string `json:"name"`
+}
+
+// PTYHasResult is the result of pty.has
+type PTYHasResult struct {
+	Exists bool `json:"exists"`
+}
+
+// PTYSendParams are the params of pty.send
+type PTYSendParams struct {
+	Name  string `json:"name"`
+	Keys  string `json:"keys"`
+	Enter bool   `json:"enter,omitempty"`
+}
+
+// PTYCaptureParams are the params of pty.capture
+type PTYCaptureParams struct {
+	Name  string `json:"name"`
+	Lines int    `json:"lines,omitempty"` // default 100
+}
+
+// PTYAttachParams are the params of pty.attach. After the result the
+// connection carries raw terminal bytes both ways until either side closes
+// it. Width and height resize the session to the client's terminal.
+type PTYAttachParams struct {
+	Name   string `json:"name"`
+	Width  int    `json:"width,omitempty"`
+	Height int    `json:"height,omitempty"`
+}
@@ -0,0 +1,62 @@
+package server
+
+import (
+	"context"
+
+	"github.com/s22625/orch/internal/rpc"
+)
+
+// DaemonActions carries out actions through the daemon of a vault,
+// connecting for each call so a daemon restart does not break the server
+type DaemonActions struct {
+	VaultPath string
+	Dir       string // where runs are started when a request names no dir
+}
+
+func (a *DaemonActions) dial() (*rpc.Client, error) {
+	return rpc.Dial(a.VaultPath)
+}
+
+// StartRun starts a run like orch run
+func (a *DaemonActions) StartRun(ctx context.Context, params *rpc.StartParams) (*rpc.StartResult, error) {
+	client, err := a.dial()
+	if err != nil {
+		return nil, err
+	}
+	defer client.Close()
+	p := *params
+	if p.Dir == "" {
+		p.Dir = a.Dir
+	}
+	return client.StartRun(ctx, &p)
+}
+
+// StopRun stops a run like orch stop
+func (a *DaemonActions) StopRun(ctx context.Context, ref string) (*rpc.StopResult, error) {
+	client, err := a.dial()
+	if err != nil {
+		return nil, err
+	}
+	defer client.Close()
+	return client.StopRun(ctx, ref)
+}
+
+// Send sends a message like orch send
+func (a *DaemonActions) Send(ctx context.Context, ref, message string, noEnter bool) error {
+	client, err := a.dial()
+	if err != nil {
+		return err
+	}
+	defer client.Close()
+	return client.Send(ctx, ref, message, noEnter)
+}
+
+// Capture returns a run's latest output like orch capture
+func (a *DaemonActions) Capture(ctx context.Context, ref string, lines int) (string, error) {
+	client, err := a.dial()
+	if err != nil {
+		return "", err
+	}
+	defer client.Close()
+	return client.Capture(ctx, ref, lines)
+}
@@ -0,0 +1,320 @@
+// Package server is the HTTP API behind orch serve: JSON endpoints for
+// issues, runs, events and captures, and Server-Sent Events for live run
+// events, for dashboards on other machines.
+package server
+
+import (
+	"context"
+	"crypto/subtle"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/s22625/orch/internal/model"
+	"github.com/s22625/orch/internal/rpc"
+	"github.com/s22625/orch/internal/store"
+)
+
+// DefaultListen is the address orch serve listens on by default
+const DefaultListen = "127.0.0.1:7777"
+
+// heartbeatInterval is how often an idle event stream sends a comment, so
+// proxies keep the connection open and clients notice a dead server
+var heartbeatInterval = 15 * time.Second
+
+// Actions carries out captures and the mutating requests. orch serve
+// forwards them to the daemon's control API, which runs the same code as the
+// CLI commands.
+type Actions interface {
+	StartRun(ctx context.Context, params *rpc.StartParams) (*rpc.StartResult, error)
+	StopRun(ctx context.Context, ref string) (*rpc.StopResult, error)
+	Send(ctx context.Context, ref, message string, noEnter bool) error
+	Capture(ctx context.Context, ref string, lines int) (string, error)
+}
+
+// Server serves the HTTP API. Reads go to the store; actions go through
+// Actions.
+type Server struct {
+	store   store.Store
+	actions Actions
+	token   string
+	logger  *log.Logger
+	mux     *http.ServeMux
+}
+
+// New returns a server. Mutating endpoints require token as a bearer token;
+// with an empty token they are disabled.
+func New(st store.Store, actions Actions, token string, logger *log.Logger) *Server {
+	if logger == nil {
+		logger = log.New(io.Discard, "", 0)
+	}
+	s := &Server{store: st, actions: actions, token: token, logger: logger, mux: http.NewServeMux()}
+
+	s.mux.HandleFunc("GET /api/health", s.handleHealth)
+	s.mux.HandleFunc("GET /api/issues", s.handleListIssues)
+	s.mux.HandleFunc("GET /api/issues/{id}", s.handleGetIssue)
+	s.mux.HandleFunc("GET /api/runs", s.handleListRuns)
+	s.mux.HandleFunc("GET /api/runs/{ref}", s.handleGetRun)
+	s.mux.HandleFunc("GET /api/runs/{ref}/events", s.handleRunEvents)
+	s.mux.HandleFunc("GET /api/runs/{ref}/capture", s.handleCapture)
+	s.mux.HandleFunc("GET /api/events", s.handleEvents)
+
+	s.mux.HandleFunc("POST /api/runs", s.authorized(s.handleStartRun))
+	s.mux.HandleFunc("POST /api/runs/{ref}/stop", s.authorized(s.handleStopRun))
+	s.mux.HandleFunc("POST /api/runs/{ref}/send", s.authorized(s.handleSend))
+	return s
+}
+
+// ServeHTTP implements http.Handler
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	// Dashboards are usually served from elsewhere
+	w.Header().Set("Access-Control-Allow-Origin", "*")
+	if r.Method == http.MethodOptions {
+		w.Header().Set("Access-Control-Allow-Methods", "GET, POST")
+		w.Header().Set("Access-Control-Allow-Headers", "Authorization, Content-Type, Last-Event-ID")
+		w.WriteHeader(http.StatusNoContent)
+		return
+	}
+	s.mux.ServeHTTP(w, r)
+}
+
+// authorized rejects requests without the configured bearer token
+func (s *Server) authorized(next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if s.token == "" {
+			writeError(w, http.StatusForbidden, errors.New("mutating endpoints are disabled: set serve.token in config"))
+			return
+		}
+		token, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+		if !ok || subtle.ConstantTimeCompare([]byte(token), []byte(s.token)) != 1 {
+			writeError(w, http.StatusUnauthorized, errors.New("invalid or missing token"))
+			return
+		}
+		next(w, r)
+	}
+}
+
+func writeJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	enc.Encode(v)
+}
+
+func writeError(w http.ResponseWriter, status int, err error) {
+	writeJSON(w, status, map[string]string{"error": err.Error()})
+}
+
+// writeActionError maps a control API error to an HTTP status
+func writeActionError(w http.ResponseWriter, err error) {
+	var rpcErr *rpc.Error
+	if !errors.As(err, &rpcErr) {
+		writeError(w, http.StatusServiceUnavailable, err)
+		return
+	}
+	status := http.StatusInternalServerError
+	switch rpcErr.Code {
+	case rpc.CodeRunNotFound:
+		status = http.StatusNotFound
+	case rpc.CodeInvalidParams:
+		status = http.StatusBadRequest
+	case rpc.CodeAgentError, rpc.CodeCommandFailed:
+		status = http.StatusUnprocessableEntity
+	}
+	writeError(w, status, errors.New(rpcErr.Message))
+}
+
+func (s *Server) handleHealth(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, map[string]interface{}{"ok": true, "vault": s.store.VaultPath()})
+}
+
+// Issue is an issue as the API returns it
+type Issue struct {
+	ID      string     `json:"id"`
+	Title   string     `json:"title"`
+	Topic   string     `json:"topic,omitempty"`
+	Summary string     `json:"summary,omitempty"`
+	Status  string     `json:"status"`
+	Path    string     `json:"path,omitempty"`
+	Body    string     `json:"body,omitempty"` // single issue only
+	Runs    []*rpc.Run `json:"runs,omitempty"` // single issue only
+}
+
+func newIssue(issue *model.Issue) *Issue {
+	return &Issue{
+		ID:      issue.ID,
+		Title:   issue.Title,
+		Topic:   issue.Topic,
+		Summary: issue.Summary,
+		Status:  string(issue.Status),
+		Path:    issue.Path,
+	}
+}
+
+func (s *Server) handleListIssues(w http.ResponseWriter, r *http.Request) {
+	issues, err := s.store.ListIssues()
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err)
+		return
+	}
+	status := r.URL.Query().Get("status")
+	result := make([]*Issue, 0, len(issues))
+	for _, issue := range issues {
+		if status != "" && string(issue.Status) != status {
+			continue
+		}
+		result = append(result, newIssue(issue))
+	}
+	writeJSON(w, http.StatusOK, map[string]interface{}{"issues": result})
+}
+
+func (s *Server) handleGetIssue(w http.ResponseWriter, r *http.Request) {
+	issue, err := s.store.ResolveIssue(r.PathValue("id"))
+	if err != nil {
+		writeError(w, http.StatusNotFound, err)
+		return
+	}
+	runs, err := s.store.ListRuns(&store.ListRunsFilter{IssueID: issue.ID})
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err)
+		return
+	}
+	result := newIssue(issue)
+	result.Body = issue.Body
+	for _, run := range runs {
+		result.Runs = append(result.Runs, rpc.NewRun(run, false))
+	}
+	writeJSON(w, http.StatusOK, result)
+}
+
+// handleListRuns lists runs; query: issue, status (comma separated), limit, since
+func (s *Server) handleListRuns(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+	filter := &store.ListRunsFilter{IssueID: q.Get("issue"), Since: q.Get("since")}
+	for _, status := range splitList(q.Get("status")) {
+		filter.Status = append(filter.Status, model.Status(status))
+	}
+	if v := q.Get("limit"); v != "" {
+		limit, err := strconv.Atoi(v)
+		if err != nil || limit < 0 {
+			writeError(w, http.StatusBadRequest, fmt.Errorf("invalid limit: %s", v))
+			return
+		}
+		filter.Limit = limit
+	}
+	runs, err := s.store.ListRuns(filter)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err)
+		return
+	}
+	result := &rpc.ListRunsResult{Runs: make([]*rpc.Run, 0, len(runs))}
+	for _, run := range runs {
+		result.Runs = append(result.Runs, rpc.NewRun(run, false))
+	}
+	writeJSON(w, http.StatusOK, result)
+}
+
+func splitList(s string) []string {
+	var items []string
+	for _, item := range strings.Split(s, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
+
+// resolveRun resolves the {ref} path value, writing a 404 when it fails
+func (s *Server) resolveRun(w http.ResponseWriter, r *http.Request) *model.Run {
+	ref := r.PathValue("ref")
+	run, err := store.ResolveRun(s.store, ref)
+	if err != nil || run == nil {
+		writeError(w, http.StatusNotFound, fmt.Errorf("run not found: %s", ref))
+		return nil
+	}
+	return run
+}
+
+func (s *Server) handleGetRun(w http.ResponseWriter, r *http.Request) {
+	run := s.resolveRun(w, r)
+	if run == nil {
+		return
+	}
+	writeJSON(w, http.StatusOK, rpc.NewRun(run, true))
+}
+
+func (s *Server) handleCapture(w http.ResponseWriter, r *http.Request) {
+	run := s.resolveRun(w, r)
+	if run == nil {
+		return
+	}
+	lines, _ := strconv.Atoi(r.URL.Query().Get("lines"))
+	output, err := s.actions.Capture(r.Context(), run.Ref().String(), lines)
+	if err != nil {
+		writeActionError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, &rpc.CaptureResult{Output: output})
+}
+
+func (s *Server) handleStartRun(w http.ResponseWriter, r *http.Request) {
+	var params rpc.StartParams
+	if err := json.NewDecoder(r.Body).Decode(&params); err != nil {
+		writeError(w, http.StatusBadRequest, fmt.Errorf("invalid body: %w", err))
+		return
+	}
+	if params.IssueID == "" {
+		writeError(w, http.StatusBadRequest, errors.New("issue_id is required"))
+		return
+	}
+	result, err := s.actions.StartRun(r.Context(), &params)
+	if err != nil {
+		writeActionError(w, err)
+		return
+	}
+	s.logger.Printf("%s %s: started %s#%s", r.RemoteAddr, r.URL.Path, result.IssueID, result.RunID)
+	writeJSON(w, http.StatusCreated, result)
+}
+
+func (s *Server) handleStopRun(w http.ResponseWriter, r *http.Request) {
+	run := s.resolveRun(w, r)
+	if run == nil {
+		return
+	}
+	result, err := s.actions.StopRun(r.Context(), run.Ref().String())
+	if err != nil {
+		writeActionError(w, err)
+		return
+	}
+	s.logger.Printf("%s %s: %s is %s", r.RemoteAddr, r.URL.Path, run.Ref().String(), result.Status)
+	writeJSON(w, http.StatusOK, result)
+}
+
+func (s *Server) handleSend(w http.ResponseWriter, r *http.Request) {
+	run := s.resolveRun(w, r)
+	if run == nil {
+		return
+	}
+	var params rpc.SendParams
+	if err := json.NewDecoder(r.Body).Decode(&params); err != nil {
+		writeError(w, http.StatusBadRequest, fmt.Errorf("invalid body: %w", err))
+		return
+	}
+	if params.Message == "" {
+		writeError(w, http.StatusBadRequest, errors.New("message is required"))
+		return
+	}
+	if err := s.actions.Send(r.Context(), run.Ref().String(), params.Message, params.NoEnter); err != nil {
+		writeActionError(w, err)
+		return
+	}
+	s.logger.Printf("%s %s: sent to %s", r.RemoteAddr, r.URL.Path, run.Ref().String())
+	writeJSON(w, http.StatusOK, &rpc.OK{OK: true})
+}
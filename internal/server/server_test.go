@@ -0,0 +1,302 @@
+package server
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/s22625/orch/internal/model"
+	"github.com/s22625/orch/internal/rpc"
+	"github.com/s22625/orch/internal/store"
+	"github.com/s22625/orch/internal/store/file"
+)
+
+type fakeActions struct {
+	started []*rpc.StartParams
+	stopped []string
+	sent    []string
+}
+
+func (a *fakeActions) StartRun(ctx context.Context, params *rpc.StartParams) (*rpc.StartResult, error) {
+	a.started = append(a.started, params)
+	if params.IssueID == "missing" {
+		return nil, rpc.Errorf(rpc.CodeCommandFailed, "orch run: issue not found: missing")
+	}
+	return &rpc.StartResult{IssueID: params.IssueID, RunID: "20240102-000000", Status: "running"}, nil
+}
+
+func (a *fakeActions) StopRun(ctx context.Context, ref string) (*rpc.StopResult, error) {
+	a.stopped = append(a.stopped, ref)
+	return &rpc.StopResult{Status: "canceled"}, nil
+}
+
+func (a *fakeActions) Send(ctx context.Context, ref, message string, noEnter bool) error {
+	a.sent = append(a.sent, ref+": "+message)
+	return nil
+}
+
+func (a *fakeActions) Capture(ctx context.Context, ref string, lines int) (string, error) {
+	return "output of " + ref + "\n", nil
+}
+
+const testToken = "s3cret"
+
+var testRef = &model.RunRef{IssueID: "orch-1", RunID: "20240101-000000"}
+
+func newTestServer(t *testing.T) (*httptest.Server, store.Store, *fakeActions) {
+	t.Helper()
+	vault := t.TempDir()
+	os.MkdirAll(filepath.Join(vault, "issues"), 0755)
+	os.WriteFile(filepath.Join(vault, "issues", "orch-1.md"), []byte("---\ntype: issue\nid: orch-1\ntitle: First\n---\n# First\n"), 0644)
+
+	st, err := file.New(vault)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := st.CreateRun(testRef.IssueID, testRef.RunID, map[string]string{"agent": "claude"}); err != nil {
+		t.Fatal(err)
+	}
+	st.AppendEvent(testRef, model.NewStatusEvent(model.StatusRunning))
+
+	actions := &fakeActions{}
+	ts := httptest.NewServer(New(st, actions, testToken, nil))
+	t.Cleanup(ts.Close)
+	return ts, st, actions
+}
+
+func getJSON(t *testing.T, url string, v interface{}) int {
+	t.Helper()
+	resp, err := http.Get(url)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	if v != nil {
+		json.NewDecoder(resp.Body).Decode(v)
+	}
+	return resp.StatusCode
+}
+
+func post(t *testing.T, url, token, body string) *http.Response {
+	t.Helper()
+	req, _ := http.NewRequest(http.MethodPost, url, strings.NewReader(body))
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	return resp
+}
+
+func TestReadEndpoints(t *testing.T) {
+	ts, _, _ := newTestServer(t)
+	ref := url.PathEscape(testRef.String())
+
+	var runs rpc.ListRunsResult
+	if code := getJSON(t, ts.URL+"/api/runs?status=running,blocked", &runs); code != http.StatusOK || len(runs.Runs) != 1 {
+		t.Fatalf("GET /api/runs = %d, %+v", code, runs)
+	}
+	if runs.Runs[0].State.Status != model.StatusRunning || runs.Runs[0].Events != nil {
+		t.Errorf("run = %+v", runs.Runs[0])
+	}
+	if code := getJSON(t, ts.URL+"/api/runs?limit=x", nil); code != http.StatusBadRequest {
+		t.Errorf("bad limit = %d", code)
+	}
+
+	var run rpc.Run
+	if code := getJSON(t, ts.URL+"/api/runs/"+ref, &run); code != http.StatusOK || len(run.Events) != 1 {
+		t.Errorf("GET run = %d, %+v", code, run)
+	}
+	if code := getJSON(t, ts.URL+"/api/runs/"+run.ShortID, nil); code != http.StatusOK {
+		t.Errorf("GET run by short ID = %d", code)
+	}
+	if code := getJSON(t, ts.URL+"/api/runs/"+url.PathEscape("orch-1#19990101-000000"), nil); code != http.StatusNotFound {
+		t.Errorf("GET missing run = %d", code)
+	}
+
+	var capture rpc.CaptureResult
+	if code := getJSON(t, ts.URL+"/api/runs/"+ref+"/capture", &capture); code != http.StatusOK || capture.Output != "output of orch-1#20240101-000000\n" {
+		t.Errorf("capture = %d, %+v", code, capture)
+	}
+
+	var issues struct{ Issues []*Issue }
+	if code := getJSON(t, ts.URL+"/api/issues", &issues); code != http.StatusOK || len(issues.Issues) != 1 || issues.Issues[0].ID != "orch-1" {
+		t.Errorf("issues = %d, %+v", code, issues)
+	}
+	var issue Issue
+	if code := getJSON(t, ts.URL+"/api/issues/orch-1", &issue); code != http.StatusOK || len(issue.Runs) != 1 || issue.Body == "" {
+		t.Errorf("issue = %d, %+v", code, issue)
+	}
+	if code := getJSON(t, ts.URL+"/api/issues/nope", nil); code != http.StatusNotFound {
+		t.Errorf("missing issue = %d", code)
+	}
+}
+
+func TestMutatingEndpointsRequireToken(t *testing.T) {
+	ts, _, actions := newTestServer(t)
+	stop := ts.URL + "/api/runs/" + url.PathEscape(testRef.String()) + "/stop"
+
+	if resp := post(t, stop, "", ""); resp.StatusCode != http.StatusUnauthorized {
+		t.Errorf("no token = %d", resp.StatusCode)
+	}
+	if resp := post(t, stop, "wrong", ""); resp.StatusCode != http.StatusUnauthorized {
+		t.Errorf("wrong token = %d", resp.StatusCode)
+	}
+	if len(actions.stopped) != 0 {
+		t.Fatalf("stopped without a token: %v", actions.stopped)
+	}
+	if resp := post(t, stop, testToken, ""); resp.StatusCode != http.StatusOK {
+		t.Errorf("stop = %d", resp.StatusCode)
+	}
+	if len(actions.stopped) != 1 || actions.stopped[0] != testRef.String() {
+		t.Errorf("stopped = %v", actions.stopped)
+	}
+
+	send := ts.URL + "/api/runs/orch-1/send"
+	if resp := post(t, send, testToken, `{"message": ""}`); resp.StatusCode != http.StatusBadRequest {
+		t.Errorf("empty message = %d", resp.StatusCode)
+	}
+	if resp := post(t, send, testToken, `{"message": "continue"}`); resp.StatusCode != http.StatusOK {
+		t.Errorf("send = %d", resp.StatusCode)
+	}
+	if len(actions.sent) != 1 || actions.sent[0] != testRef.String()+": continue" {
+		t.Errorf("sent = %v", actions.sent)
+	}
+
+	if resp := post(t, ts.URL+"/api/runs", testToken, `{"issue_id": "orch-1", "args": ["--agent", "codex"]}`); resp.StatusCode != http.StatusCreated {
+		t.Errorf("start = %d", resp.StatusCode)
+	}
+	if len(actions.started) != 1 || actions.started[0].Args[1] != "codex" {
+		t.Errorf("started = %+v", actions.started)
+	}
+	if resp := post(t, ts.URL+"/api/runs", testToken, `{"issue_id": "missing"}`); resp.StatusCode != http.StatusUnprocessableEntity {
+		t.Errorf("failed start = %d", resp.StatusCode)
+	}
+}
+
+func TestMutatingEndpointsDisabledWithoutToken(t *testing.T) {
+	_, st, actions := newTestServer(t)
+	ts := httptest.NewServer(New(st, actions, "", nil))
+	defer ts.Close()
+
+	if resp := post(t, ts.URL+"/api/runs/orch-1/stop", "", ""); resp.StatusCode != http.StatusForbidden {
+		t.Errorf("stop = %d, want 403", resp.StatusCode)
+	}
+	if code := getJSON(t, ts.URL+"/api/runs", nil); code != http.StatusOK {
+		t.Errorf("reads = %d", code)
+	}
+}
+
+// sseEvent is one parsed Server-Sent Event
+type sseEvent struct {
+	id, event, data string
+}
+
+// readSSE parses events from a stream onto a channel
+func readSSE(t *testing.T, ctx context.Context, url string, header http.Header) <-chan sseEvent {
+	t.Helper()
+	req, _ := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	for k, v := range header {
+		req.Header[k] = v
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ct := resp.Header.Get("Content-Type"); ct != "text/event-stream" {
+		t.Fatalf("content type = %q", ct)
+	}
+	events := make(chan sseEvent, 16)
+	go func() {
+		defer resp.Body.Close()
+		defer close(events)
+		var e sseEvent
+		scanner := bufio.NewScanner(resp.Body)
+		for scanner.Scan() {
+			line := scanner.Text()
+			switch {
+			case line == "":
+				if e.event != "" {
+					events <- e
+				}
+				e = sseEvent{}
+			case strings.HasPrefix(line, "id: "):
+				e.id = strings.TrimPrefix(line, "id: ")
+			case strings.HasPrefix(line, "event: "):
+				e.event = strings.TrimPrefix(line, "event: ")
+			case strings.HasPrefix(line, "data: "):
+				e.data = strings.TrimPrefix(line, "data: ")
+			}
+		}
+	}()
+	return events
+}
+
+func next(t *testing.T, events <-chan sseEvent) sseEvent {
+	t.Helper()
+	select {
+	case e, ok := <-events:
+		if !ok {
+			t.Fatal("stream closed")
+		}
+		return e
+	case <-time.After(5 * time.Second):
+		t.Fatal("no event")
+	}
+	return sseEvent{}
+}
+
+func TestRunEventStream(t *testing.T) {
+	ts, st, _ := newTestServer(t)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	streamURL := ts.URL + "/api/runs/" + url.PathEscape(testRef.String()) + "/events"
+
+	events := readSSE(t, ctx, streamURL, nil)
+	// History first
+	if e := next(t, events); e.id != "1" || e.event != "event" || !strings.Contains(e.data, `"name":"running"`) {
+		t.Errorf("history = %+v", e)
+	}
+
+	st.AppendEvent(testRef, model.NewStatusEvent(model.StatusBlocked))
+	if e := next(t, events); e.id != "2" || !strings.Contains(e.data, `"name":"blocked"`) {
+		t.Errorf("live = %+v", e)
+	}
+
+	// Resuming after the last seen id only sends what came after
+	st.AppendEvent(testRef, model.NewStatusEvent(model.StatusRunning))
+	resumed := readSSE(t, ctx, streamURL, http.Header{"Last-Event-ID": {"2"}})
+	if e := next(t, resumed); e.id != "3" || !strings.Contains(e.data, `"name":"running"`) {
+		t.Errorf("resumed = %+v", e)
+	}
+}
+
+func TestChangeStream(t *testing.T) {
+	ts, st, _ := newTestServer(t)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	events := readSSE(t, ctx, ts.URL+"/api/events?types=event_appended&run="+testRef.RunID, nil)
+	// Headers are only sent once the handler is watching
+	st.AppendEvent(testRef, model.NewStatusEvent(model.StatusBlocked))
+
+	e := next(t, events)
+	var change rpc.Change
+	if err := json.Unmarshal([]byte(e.data), &change); err != nil {
+		t.Fatal(err)
+	}
+	if e.event != "change" || change.Type != "event_appended" || change.RunID != testRef.RunID || change.Event.Name != "blocked" {
+		t.Errorf("change = %+v", change)
+	}
+}
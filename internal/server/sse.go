@@ -0,0 +1,179 @@
+package server
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/s22625/orch/internal/rpc"
+	"github.com/s22625/orch/internal/store"
+)
+
+// sseWriter writes Server-Sent Events
+type sseWriter struct {
+	w       http.ResponseWriter
+	flusher http.Flusher
+}
+
+func newSSEWriter(w http.ResponseWriter) (*sseWriter, error) {
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		return nil, errors.New("streaming is not supported")
+	}
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("X-Accel-Buffering", "no")
+	w.WriteHeader(http.StatusOK)
+	flusher.Flush()
+	return &sseWriter{w: w, flusher: flusher}, nil
+}
+
+// send writes one event; id is omitted when empty
+func (s *sseWriter) send(event, id string, data interface{}) error {
+	payload, err := json.Marshal(data)
+	if err != nil {
+		return err
+	}
+	if id != "" {
+		if _, err := fmt.Fprintf(s.w, "id: %s\n", id); err != nil {
+			return err
+		}
+	}
+	if _, err := fmt.Fprintf(s.w, "event: %s\ndata: %s\n\n", event, payload); err != nil {
+		return err
+	}
+	s.flusher.Flush()
+	return nil
+}
+
+func (s *sseWriter) heartbeat() error {
+	if _, err := fmt.Fprint(s.w, ": ping\n\n"); err != nil {
+		return err
+	}
+	s.flusher.Flush()
+	return nil
+}
+
+// handleEvents streams store changes as "change" events; query: issue, run,
+// types (comma separated: run_created, event_appended, issue_changed)
+func (s *Server) handleEvents(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+	filter := &store.WatchFilter{IssueID: q.Get("issue")}
+	for _, t := range splitList(q.Get("types")) {
+		filter.Types = append(filter.Types, store.ChangeType(t))
+	}
+	runID := q.Get("run")
+
+	changes, err := s.store.Watch(r.Context(), filter)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err)
+		return
+	}
+	sse, err := newSSEWriter(w)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	ticker := time.NewTicker(heartbeatInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-r.Context().Done():
+			return
+		case <-ticker.C:
+			if sse.heartbeat() != nil {
+				return
+			}
+		case change, ok := <-changes:
+			if !ok {
+				return
+			}
+			if runID != "" && change.RunID != runID {
+				continue
+			}
+			wire := &rpc.Change{Type: string(change.Type), IssueID: change.IssueID, RunID: change.RunID}
+			if change.Event != nil {
+				wire.Event = rpc.NewEvent(change.Event)
+			}
+			if sse.send("change", "", wire) != nil {
+				return
+			}
+		}
+	}
+}
+
+// handleRunEvents streams a run's events as "event" events, the history
+// first and then new ones as they are appended. The SSE id is the event's
+// 1-based position in the run, so a reconnecting EventSource resumes after
+// Last-Event-ID (or ?after=N) without gaps or repeats.
+func (s *Server) handleRunEvents(w http.ResponseWriter, r *http.Request) {
+	run := s.resolveRun(w, r)
+	if run == nil {
+		return
+	}
+	after := r.Header.Get("Last-Event-ID")
+	if after == "" {
+		after = r.URL.Query().Get("after")
+	}
+	sent, _ := strconv.Atoi(after)
+
+	// Watch before reading the history so nothing appended in between is lost
+	changes, err := s.store.Watch(r.Context(), &store.WatchFilter{
+		IssueID: run.IssueID,
+		Types:   []store.ChangeType{store.ChangeEventAppended},
+	})
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err)
+		return
+	}
+	sse, err := newSSEWriter(w)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	// flush sends the events past the last one sent. Re-reading the run keeps
+	// ids exact when changes arrive for events already in the history.
+	flush := func() error {
+		current, err := s.store.GetRun(run.Ref())
+		if err != nil {
+			return err
+		}
+		for ; sent < len(current.Events); sent++ {
+			if err := sse.send("event", strconv.Itoa(sent+1), rpc.NewEvent(current.Events[sent])); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	if flush() != nil {
+		return
+	}
+
+	ticker := time.NewTicker(heartbeatInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-r.Context().Done():
+			return
+		case <-ticker.C:
+			if sse.heartbeat() != nil {
+				return
+			}
+		case change, ok := <-changes:
+			if !ok {
+				return
+			}
+			if change.RunID != run.RunID {
+				continue
+			}
+			if flush() != nil {
+				return
+			}
+		}
+	}
+}
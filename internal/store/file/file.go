@@ -20,6 +20,11 @@ type FileStore struct {
 	issueMu    sync.RWMutex
 	issueCache map[string]*model.Issue // id -> issue
 	cacheDirty bool
+
+	indexMu sync.Mutex
+	index   *runIndex // lazily loaded from .orch/run-index.json
+
+	sidecar bool // issues live elsewhere; CreateRun does not look them up
 }
 
 // New creates a new FileStore
@@ -44,6 +49,21 @@ func New(vaultPath string) (*FileStore, error) {
 	}, nil
 }
 
+// NewSidecar creates a FileStore in dir that only keeps runs, for backends
+// whose issues live in another system. The directory is created if needed,
+// and CreateRun trusts the caller to have verified the issue.
+func NewSidecar(dir string) (*FileStore, error) {
+	if err := os.MkdirAll(filepath.Join(dir, "runs"), 0755); err != nil {
+		return nil, fmt.Errorf("failed to create run sidecar: %w", err)
+	}
+	s, err := New(dir)
+	if err != nil {
+		return nil, err
+	}
+	s.sidecar = true
+	return s, nil
+}
+
 // VaultPath returns the vault root path
 func (s *FileStore) VaultPath() string {
 	return s.vaultPath
@@ -366,9 +386,10 @@ func (s *FileStore) ListIssues() ([]*model.Issue, error) {
 // CreateRun creates a new run for an issue
 func (s *FileStore) CreateRun(issueID, runID string, metadata map[string]string) (*model.Run, error) {
 	// Verify issue exists
-	_, err := s.ResolveIssue(issueID)
-	if err != nil {
-		return nil, err
+	if !s.sidecar {
+		if _, err := s.ResolveIssue(issueID); err != nil {
+			return nil, err
+		}
 	}
 
 	// Create runs directory for issue if needed
@@ -377,11 +398,7 @@ func (s *FileStore) CreateRun(issueID, runID string, metadata map[string]string)
 		return nil, fmt.Errorf("failed to create runs directory: %w", err)
 	}
 
-	// Create run document
 	runPath := s.runPath(issueID, runID)
-	if _, err := os.Stat(runPath); err == nil {
-		return nil, fmt.Errorf("run already exists: %s#%s", issueID, runID)
-	}
 
 	// Build frontmatter
 	var sb strings.Builder
@@ -395,7 +412,19 @@ func (s *FileStore) CreateRun(issueID, runID string, metadata map[string]string)
 	sb.WriteString("---\n\n")
 	sb.WriteString("# Events\n\n")
 
-	if err := os.WriteFile(runPath, []byte(sb.String()), 0644); err != nil {
+	// O_EXCL makes creation atomic when two processes pick the same run ID
+	f, err := os.OpenFile(runPath, os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0644)
+	if err != nil {
+		if os.IsExist(err) {
+			return nil, fmt.Errorf("run already exists: %s#%s", issueID, runID)
+		}
+		return nil, fmt.Errorf("failed to create run document: %w", err)
+	}
+	_, err = f.WriteString(sb.String())
+	if cerr := f.Close(); err == nil {
+		err = cerr
+	}
+	if err != nil {
 		return nil, fmt.Errorf("failed to create run document: %w", err)
 	}
 
@@ -412,20 +441,54 @@ func (s *FileStore) CreateRun(issueID, runID string, metadata map[string]string)
 	return run, nil
 }
 
-// AppendEvent appends an event to a run
+// AppendEvent appends an event to a run. Status events are checked against
+// the transition table unless they carry the force attribute.
 func (s *FileStore) AppendEvent(ref *model.RunRef, event *model.Event) error {
-	run, err := s.GetRun(ref)
-	if err != nil {
-		return err
+	return s.appendEvent(ref, event, nil)
+}
+
+// AppendEventIf appends an event only if the run's current status is expected.
+// The check and the write happen under the run file's lock, so a concurrent
+// writer (e.g. orch stop recording canceled) cannot slip in between.
+func (s *FileStore) AppendEventIf(ref *model.RunRef, expected model.Status, event *model.Event) error {
+	return s.appendEvent(ref, event, &expected)
+}
+
+func (s *FileStore) appendEvent(ref *model.RunRef, event *model.Event, expected *model.Status) error {
+	runID := ref.RunID
+	if ref.IsLatest() {
+		latest, err := s.latestRunID(ref.IssueID)
+		if err != nil {
+			return err
+		}
+		runID = latest
 	}
+	path := s.runPath(ref.IssueID, runID)
 
-	// Append event line to file
-	f, err := os.OpenFile(run.Path, os.O_APPEND|os.O_WRONLY, 0644)
+	f, err := lockRunFile(path, os.O_APPEND|os.O_WRONLY)
 	if err != nil {
+		if os.IsNotExist(err) {
+			return fmt.Errorf("run not found: %s#%s", ref.IssueID, runID)
+		}
 		return fmt.Errorf("failed to open run file: %w", err)
 	}
-	defer f.Close()
+	defer unlockRunFile(f)
 
+	validate := event.Type == model.EventTypeStatus && !event.IsForced()
+	if expected != nil || validate {
+		current, err := s.currentStatus(ref.IssueID, runID, path)
+		if err != nil {
+			return err
+		}
+		if expected != nil && current != *expected {
+			return store.StatusConflictError(&model.RunRef{IssueID: ref.IssueID, RunID: runID}, *expected, current)
+		}
+		if err := model.CheckStatusEvent(current, event); err != nil {
+			return err
+		}
+	}
+
+	// Append event line to file
 	line := event.String() + "\n"
 	if _, err := f.WriteString(line); err != nil {
 		return fmt.Errorf("failed to append event: %w", err)
@@ -439,6 +502,19 @@ func (s *FileStore) AppendEvent(ref *model.RunRef, event *model.Event) error {
 	return nil
 }
 
+// currentStatus returns a run's status from the run index, parsing only
+// events appended since it was last indexed. Callers hold the run file lock.
+func (s *FileStore) currentStatus(issueID, runID, path string) (model.Status, error) {
+	s.indexMu.Lock()
+	defer s.indexMu.Unlock()
+
+	run, _, err := s.indexedRun(s.loadIndex(), issueID, runID, path)
+	if err != nil {
+		return "", err
+	}
+	return run.Status, nil
+}
+
 // GetRun retrieves a run by reference
 func (s *FileStore) GetRun(ref *model.RunRef) (*model.Run, error) {
 	if ref.IsLatest() {
@@ -451,13 +527,22 @@ func (s *FileStore) GetRun(ref *model.RunRef) (*model.Run, error) {
 
 // GetLatestRun retrieves the latest run for an issue
 func (s *FileStore) GetLatestRun(issueID string) (*model.Run, error) {
+	runID, err := s.latestRunID(issueID)
+	if err != nil {
+		return nil, err
+	}
+	return s.loadRun(issueID, runID, s.runPath(issueID, runID))
+}
+
+// latestRunID returns the newest run ID for an issue without parsing runs
+func (s *FileStore) latestRunID(issueID string) (string, error) {
 	runsDir := s.runsDir(issueID)
 	entries, err := os.ReadDir(runsDir)
 	if err != nil {
 		if os.IsNotExist(err) {
-			return nil, fmt.Errorf("no runs found for issue: %s", issueID)
+			return "", fmt.Errorf("no runs found for issue: %s", issueID)
 		}
-		return nil, err
+		return "", err
 	}
 
 	// Find latest run by filename (they're timestamped)
@@ -473,10 +558,9 @@ func (s *FileStore) GetLatestRun(issueID string) (*model.Run, error) {
 	}
 
 	if latestName == "" {
-		return nil, fmt.Errorf("no runs found for issue: %s", issueID)
+		return "", fmt.Errorf("no runs found for issue: %s", issueID)
 	}
-
-	return s.loadRun(issueID, latestName, s.runPath(issueID, latestName))
+	return latestName, nil
 }
 
 // GetRunByShortID finds a run by its short ID prefix (2-6 hex chars)
@@ -499,32 +583,12 @@ func (s *FileStore) GetRunByShortID(shortID string) (*model.Run, error) {
 		return nil, fmt.Errorf("run not found: %s", shortID)
 	}
 	if len(matches) > 1 {
-		return nil, formatAmbiguousError(shortID, matches)
-	}
-
-	return matches[0], nil
-}
-
-// formatAmbiguousError formats an error message for ambiguous short ID matches
-func formatAmbiguousError(shortID string, matches []*model.Run) error {
-	var sb strings.Builder
-	sb.WriteString(fmt.Sprintf("ambiguous run ID '%s': matches %d runs\n", shortID, len(matches)))
-
-	// Show up to 5 matches with their details
-	limit := 5
-	if len(matches) < limit {
-		limit = len(matches)
-	}
-	for i := 0; i < limit; i++ {
-		run := matches[i]
-		sb.WriteString(fmt.Sprintf("  %s  %s#%s\n", run.ShortID(), run.IssueID, run.RunID))
-	}
-	if len(matches) > 5 {
-		sb.WriteString(fmt.Sprintf("  ... and %d more\n", len(matches)-5))
+		return nil, store.AmbiguousShortIDError(shortID, matches)
 	}
-	sb.WriteString("Hint: use more characters to disambiguate")
 
-	return fmt.Errorf("%s", sb.String())
+	// ListRuns returns summaries; load the full event history
+	match := matches[0]
+	return s.loadRun(match.IssueID, match.RunID, match.Path)
 }
 
 // loadRun loads a run from its file
@@ -541,62 +605,82 @@ func (s *FileStore) loadRun(issueID, runID, path string) (*model.Run, error) {
 		IssueID: issueID,
 		RunID:   runID,
 		Path:    path,
-		Events:  []*model.Event{},
 	}
 
-	// Parse frontmatter
+	header, events, _ := parseRunDocument(content)
+	header.apply(run)
+	run.Events = events
+	run.DeriveState()
+	s.resolveWorktreePath(run)
+
+	return run, nil
+}
+
+// resolveWorktreePath resolves relative worktree paths against the vault path.
+// This handles runs created before worktree paths were made absolute.
+func (s *FileStore) resolveWorktreePath(run *model.Run) {
+	if run.WorktreePath != "" && !filepath.IsAbs(run.WorktreePath) {
+		run.WorktreePath = filepath.Join(s.vaultPath, run.WorktreePath)
+	}
+}
+
+// eventPattern matches event lines ("- 2023-12-20T10:00:00+09:00 | ...")
+var eventPattern = regexp.MustCompile(`^-\s+\d{4}-\d{2}-\d{2}`)
+
+// parseRunDocument parses a run document's frontmatter and event lines.
+// inBody reports whether the frontmatter was closed (or absent), i.e.
+// whether any further appended lines belong to the event body.
+func parseRunDocument(content []byte) (header runHeader, events []*model.Event, inBody bool) {
+	events = []*model.Event{}
+
 	lines := strings.Split(string(content), "\n")
 	bodyStart := 0
+	inBody = true
 	if len(lines) > 0 && strings.TrimSpace(lines[0]) == "---" {
-		inFrontmatter := true
+		inBody = false
 		for i := 1; i < len(lines); i++ {
 			line := lines[i]
 			if strings.TrimSpace(line) == "---" {
-				inFrontmatter = false
+				inBody = true
 				bodyStart = i + 1
 				break
 			}
-			if inFrontmatter {
-				parts := strings.SplitN(line, ":", 2)
-				if len(parts) == 2 {
-					key := strings.TrimSpace(parts[0])
-					value := strings.TrimSpace(parts[1])
-					switch key {
-					case "agent":
-						run.Agent = value
-					case "model":
-						run.Model = value
-					case "model_variant":
-						run.ModelVariant = value
-					case "continued_from":
-						run.ContinuedFrom = value
-					}
+			parts := strings.SplitN(line, ":", 2)
+			if len(parts) == 2 {
+				key := strings.TrimSpace(parts[0])
+				value := strings.TrimSpace(parts[1])
+				switch key {
+				case "agent":
+					header.Agent = value
+				case "model":
+					header.Model = value
+				case "model_variant":
+					header.ModelVariant = value
+				case "continued_from":
+					header.ContinuedFrom = value
 				}
 			}
 		}
 	}
 
-	// Parse events from body
-	eventPattern := regexp.MustCompile(`^-\s+\d{4}-\d{2}-\d{2}`)
-	for i := bodyStart; i < len(lines); i++ {
-		line := lines[i]
-		if eventPattern.MatchString(line) {
-			event, err := model.ParseEvent(line)
-			if err == nil {
-				run.Events = append(run.Events, event)
-			}
+	for _, line := range lines[bodyStart:] {
+		if event := parseEventLine(line); event != nil {
+			events = append(events, event)
 		}
 	}
+	return header, events, inBody
+}
 
-	run.DeriveState()
-
-	// Resolve relative worktree paths against the vault path
-	// This handles runs created before worktree paths were made absolute
-	if run.WorktreePath != "" && !filepath.IsAbs(run.WorktreePath) {
-		run.WorktreePath = filepath.Join(s.vaultPath, run.WorktreePath)
+// parseEventLine parses a single event line, returning nil for other lines
+func parseEventLine(line string) *model.Event {
+	if !eventPattern.MatchString(line) {
+		return nil
 	}
-
-	return run, nil
+	event, err := model.ParseEvent(line)
+	if err != nil {
+		return nil
+	}
+	return event
 }
 
 // ListRuns lists runs matching the filter
@@ -641,7 +725,14 @@ func (s *FileStore) ListRuns(filter *store.ListRunsFilter) ([]*model.Run, error)
 		}
 	}
 
-	// Load runs from each issue directory
+	// Load runs from each issue directory, parsing only what changed
+	// since the index last saw each run document
+	s.indexMu.Lock()
+	defer s.indexMu.Unlock()
+	idx := s.loadIndex()
+	dirty := false
+	seen := make(map[string]bool)
+
 	for _, issueID := range issueDirs {
 		issueRunsDir := filepath.Join(runsRoot, issueID)
 		entries, err := os.ReadDir(issueRunsDir)
@@ -655,10 +746,12 @@ func (s *FileStore) ListRuns(filter *store.ListRunsFilter) ([]*model.Run, error)
 			}
 
 			runID := strings.TrimSuffix(e.Name(), ".md")
-			run, err := s.loadRun(issueID, runID, filepath.Join(issueRunsDir, e.Name()))
+			seen[issueID+"#"+runID] = true
+			run, changed, err := s.indexedRun(idx, issueID, runID, filepath.Join(issueRunsDir, e.Name()))
 			if err != nil {
 				continue
 			}
+			dirty = dirty || changed
 
 			// Apply filters
 			if len(statusSet) > 0 && !statusSet[run.Status] {
@@ -672,6 +765,21 @@ func (s *FileStore) ListRuns(filter *store.ListRunsFilter) ([]*model.Run, error)
 		}
 	}
 
+	// Drop entries for deleted run documents
+	for key := range idx.Runs {
+		issueID, _, _ := strings.Cut(key, "#")
+		if filter != nil && filter.IssueID != "" && issueID != filter.IssueID {
+			continue
+		}
+		if !seen[key] {
+			delete(idx.Runs, key)
+			dirty = true
+		}
+	}
+	if dirty {
+		s.saveIndex(idx)
+	}
+
 	// Sort by updated_at descending
 	sort.Slice(runs, func(i, j int) bool {
 		return runs[i].UpdatedAt.After(runs[j].UpdatedAt)
@@ -744,3 +852,92 @@ func (s *FileStore) SetIssueStatus(issueID string, status model.IssueStatus) err
 
 // Ensure FileStore implements Store
 var _ store.Store = (*FileStore)(nil)
+
+// WriteIssue writes an issue document to path, replacing any existing file.
+// Frontmatter keys are written in sorted order after type/id.
+func WriteIssue(path string, issue *model.Issue) error {
+	frontmatter := make(map[string]string, len(issue.Frontmatter))
+	for k, v := range issue.Frontmatter {
+		frontmatter[k] = v
+	}
+	delete(frontmatter, "type")
+	delete(frontmatter, "id")
+	if issue.Title != "" {
+		frontmatter["title"] = issue.Title
+	}
+	if issue.Status != "" {
+		frontmatter["status"] = string(issue.Status)
+	}
+
+	keys := make([]string, 0, len(frontmatter))
+	for k := range frontmatter {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	var sb strings.Builder
+	sb.WriteString("---\n")
+	sb.WriteString("type: issue\n")
+	sb.WriteString(fmt.Sprintf("id: %s\n", issue.ID))
+	for _, k := range keys {
+		sb.WriteString(fmt.Sprintf("%s: %s\n", k, frontmatter[k]))
+	}
+	sb.WriteString("---\n")
+	sb.WriteString(issue.Body)
+
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return fmt.Errorf("failed to create issue directory: %w", err)
+	}
+	if err := os.WriteFile(path, []byte(sb.String()), 0644); err != nil {
+		return fmt.Errorf("failed to write issue file: %w", err)
+	}
+	return nil
+}
+
+// WriteRun writes a complete run document including its events.
+// Unlike CreateRun it preserves the given creation time and does not
+// require the issue to exist, which makes it suitable for migrations.
+func (s *FileStore) WriteRun(run *model.Run, created time.Time, metadata map[string]string) error {
+	if err := os.MkdirAll(s.runsDir(run.IssueID), 0755); err != nil {
+		return fmt.Errorf("failed to create runs directory: %w", err)
+	}
+
+	keys := make([]string, 0, len(metadata))
+	for k := range metadata {
+		if k == "issue" || k == "run" || k == "created" {
+			continue
+		}
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	var sb strings.Builder
+	sb.WriteString("---\n")
+	sb.WriteString(fmt.Sprintf("issue: %s\n", run.IssueID))
+	sb.WriteString(fmt.Sprintf("run: %s\n", run.RunID))
+	sb.WriteString(fmt.Sprintf("created: %s\n", created.Format(time.RFC3339)))
+	for _, k := range keys {
+		sb.WriteString(fmt.Sprintf("%s: %s\n", k, metadata[k]))
+	}
+	sb.WriteString("---\n\n")
+	sb.WriteString("# Events\n\n")
+	for _, event := range run.Events {
+		sb.WriteString(event.String())
+		sb.WriteString("\n")
+	}
+
+	// Truncate under the lock so concurrent appends are not interleaved
+	f, err := lockRunFile(s.runPath(run.IssueID, run.RunID), os.O_CREATE|os.O_WRONLY)
+	if err != nil {
+		return fmt.Errorf("failed to write run document: %w", err)
+	}
+	defer unlockRunFile(f)
+
+	if err := f.Truncate(0); err != nil {
+		return fmt.Errorf("failed to write run document: %w", err)
+	}
+	if _, err := f.WriteString(sb.String()); err != nil {
+		return fmt.Errorf("failed to write run document: %w", err)
+	}
+	return nil
+}
@@ -1,10 +1,12 @@
 package file
 
 import (
+	"errors"
 	"fmt"
 	"os"
 	"path/filepath"
 	"strings"
+	"sync"
 	"testing"
 
 	"github.com/s22625/orch/internal/model"
@@ -224,6 +226,104 @@ func TestAppendEvent(t *testing.T) {
 	}
 }
 
+func TestAppendEventIf(t *testing.T) {
+	vault, cleanup := setupTestVault(t)
+	defer cleanup()
+
+	createTestIssue(t, vault, "test123", "---\ntype: issue\ntitle: Test\n---\n# Test")
+
+	s, _ := New(vault)
+	run, _ := s.CreateRun("test123", "20231220-100000", nil)
+
+	if err := s.AppendEventIf(run.Ref(), model.StatusQueued, model.NewStatusEvent(model.StatusRunning)); err != nil {
+		t.Fatalf("AppendEventIf(queued) error = %v", err)
+	}
+
+	// orch stop records canceled; a stale writer expecting running must not overwrite it
+	if err := s.AppendEvent(run.Ref(), model.NewStatusEvent(model.StatusCanceled)); err != nil {
+		t.Fatal(err)
+	}
+	err := s.AppendEventIf(run.Ref(), model.StatusQueued, model.NewStatusEvent(model.StatusDone))
+	if !errors.Is(err, store.ErrStatusConflict) {
+		t.Fatalf("AppendEventIf(stale) error = %v, want ErrStatusConflict", err)
+	}
+
+	updated, _ := s.GetRun(run.Ref())
+	if updated.Status != model.StatusCanceled || len(updated.Events) != 2 {
+		t.Errorf("status = %s with %d events, want canceled with 2", updated.Status, len(updated.Events))
+	}
+
+	// Latest refs resolve to the newest run
+	if err := s.AppendEventIf(&model.RunRef{IssueID: "test123"}, model.StatusCanceled, model.NewForcedStatusEvent(model.StatusRunning, "test")); err != nil {
+		t.Errorf("AppendEventIf(latest) error = %v", err)
+	}
+	if err := s.AppendEventIf(&model.RunRef{IssueID: "test123", RunID: "missing"}, model.StatusQueued, model.NewStatusEvent(model.StatusRunning)); err == nil {
+		t.Error("expected error for missing run")
+	}
+}
+
+func TestAppendEventRejectsInvalidTransition(t *testing.T) {
+	vault, cleanup := setupTestVault(t)
+	defer cleanup()
+
+	createTestIssue(t, vault, "test123", "---\ntype: issue\ntitle: Test\n---\n# Test")
+
+	s, _ := New(vault)
+	run, _ := s.CreateRun("test123", "20231220-100000", nil)
+
+	if err := s.AppendEvent(run.Ref(), model.NewStatusEvent(model.StatusFailed)); err != nil {
+		t.Fatal(err)
+	}
+	err := s.AppendEvent(run.Ref(), model.NewStatusEvent(model.StatusRunning))
+	if !errors.Is(err, model.ErrInvalidTransition) {
+		t.Fatalf("AppendEvent(failed -> running) error = %v, want ErrInvalidTransition", err)
+	}
+
+	if err := s.AppendEvent(run.Ref(), model.NewForcedStatusEvent(model.StatusRunning, "revive")); err != nil {
+		t.Fatalf("AppendEvent(forced) error = %v", err)
+	}
+	updated, _ := s.GetRun(run.Ref())
+	if updated.Status != model.StatusRunning || len(updated.Events) != 2 {
+		t.Errorf("status = %s with %d events, want running with 2", updated.Status, len(updated.Events))
+	}
+}
+
+func TestAppendEventConcurrent(t *testing.T) {
+	vault, cleanup := setupTestVault(t)
+	defer cleanup()
+
+	createTestIssue(t, vault, "test123", "---\ntype: issue\ntitle: Test\n---\n# Test")
+
+	s, _ := New(vault)
+	run, _ := s.CreateRun("test123", "20231220-100000", nil)
+
+	// Separate stores model separate processes: only the file lock is shared
+	const writers, perWriter = 8, 25
+	var wg sync.WaitGroup
+	for w := 0; w < writers; w++ {
+		wg.Add(1)
+		go func(w int) {
+			defer wg.Done()
+			ws, _ := New(vault)
+			for i := 0; i < perWriter; i++ {
+				event := model.NewArtifactEvent("note", map[string]string{"writer": fmt.Sprint(w), "i": fmt.Sprint(i)})
+				if err := ws.AppendEvent(run.Ref(), event); err != nil {
+					t.Errorf("AppendEvent() error = %v", err)
+				}
+			}
+		}(w)
+	}
+	wg.Wait()
+
+	loaded, err := s.GetRun(run.Ref())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(loaded.Events) != writers*perWriter {
+		t.Errorf("expected %d events, got %d", writers*perWriter, len(loaded.Events))
+	}
+}
+
 func TestGetRun(t *testing.T) {
 	vault, cleanup := setupTestVault(t)
 	defer cleanup()
@@ -312,6 +412,7 @@ func TestListRunsWithStatusFilter(t *testing.T) {
 	s.AppendEvent(run1.Ref(), model.NewStatusEvent(model.StatusRunning))
 
 	run2, _ := s.CreateRun("test123", "20231220-110000", nil)
+	s.AppendEvent(run2.Ref(), model.NewStatusEvent(model.StatusRunning))
 	s.AppendEvent(run2.Ref(), model.NewStatusEvent(model.StatusBlocked))
 
 	run3, _ := s.CreateRun("test123", "20231220-120000", nil)
@@ -556,3 +657,97 @@ id: test123
 		t.Errorf("expected status resolved, got %s", issue.Status)
 	}
 }
+
+func TestListRunsIncrementalIndex(t *testing.T) {
+	vault, cleanup := setupTestVault(t)
+	defer cleanup()
+
+	createTestIssue(t, vault, "test123", "---\ntype: issue\ntitle: Test\n---\n# Test")
+
+	s, _ := New(vault)
+	run, _ := s.CreateRun("test123", "20231220-100000", map[string]string{"agent": "claude"})
+	s.AppendEvent(run.Ref(), model.NewStatusEvent(model.StatusRunning))
+
+	runs, err := s.ListRuns(nil)
+	if err != nil || len(runs) != 1 {
+		t.Fatalf("ListRuns() = %d runs, err %v", len(runs), err)
+	}
+	if runs[0].Status != model.StatusRunning || runs[0].Agent != "claude" {
+		t.Errorf("status=%v agent=%q", runs[0].Status, runs[0].Agent)
+	}
+	if _, err := os.Stat(filepath.Join(vault, ".orch", indexFile)); err != nil {
+		t.Fatalf("index not written: %v", err)
+	}
+
+	// Appended events are picked up from the stored offset
+	s.AppendEvent(run.Ref(), model.NewArtifactEvent("pr", map[string]string{"url": "https://example.com/pr/1"}))
+	s.AppendEvent(run.Ref(), model.NewStatusEvent(model.StatusPROpen))
+	offset := s.index.Runs["test123#20231220-100000"].Offset
+
+	// A fresh store reads the persisted index
+	s2, _ := New(vault)
+	runs, _ = s2.ListRuns(nil)
+	if runs[0].Status != model.StatusPROpen || runs[0].PRUrl != "https://example.com/pr/1" {
+		t.Errorf("after append: status=%v pr=%q", runs[0].Status, runs[0].PRUrl)
+	}
+	if got := s2.index.Runs["test123#20231220-100000"].Offset; got <= offset {
+		t.Errorf("offset did not advance: %d <= %d", got, offset)
+	}
+
+	// A partially written line is ignored until its newline lands
+	f, _ := os.OpenFile(run.Path, os.O_APPEND|os.O_WRONLY, 0644)
+	partial := model.NewStatusEvent(model.StatusDone).String()
+	f.WriteString(partial[:10])
+	f.Close()
+	runs, _ = s2.ListRuns(nil)
+	if runs[0].Status != model.StatusPROpen {
+		t.Errorf("partial line applied: status=%v", runs[0].Status)
+	}
+	f, _ = os.OpenFile(run.Path, os.O_APPEND|os.O_WRONLY, 0644)
+	f.WriteString(partial[10:] + "\n")
+	f.Close()
+	runs, _ = s2.ListRuns(nil)
+	if runs[0].Status != model.StatusDone {
+		t.Errorf("completed line not applied: status=%v", runs[0].Status)
+	}
+
+	// GetRun still returns the full event history
+	full, _ := s2.GetRun(run.Ref())
+	if len(full.Events) != 4 {
+		t.Errorf("GetRun() events = %d, want 4", len(full.Events))
+	}
+}
+
+func TestListRunsIndexDetectsRewrite(t *testing.T) {
+	vault, cleanup := setupTestVault(t)
+	defer cleanup()
+
+	createTestIssue(t, vault, "test123", "---\ntype: issue\ntitle: Test\n---\n# Test")
+
+	s, _ := New(vault)
+	run, _ := s.CreateRun("test123", "20231220-100000", nil)
+	s.AppendEvent(run.Ref(), model.NewStatusEvent(model.StatusRunning))
+	s.ListRuns(nil)
+
+	// Rewrite the document with different (and longer) content
+	content := "---\nissue: test123\nrun: 20231220-100000\nagent: codex\n---\n\n# Events\n\n" +
+		model.NewStatusEvent(model.StatusFailed).String() + "\n" +
+		model.NewArtifactEvent("branch", map[string]string{"name": "feature/rewritten"}).String() + "\n"
+	if err := os.WriteFile(run.Path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	runs, _ := s.ListRuns(nil)
+	if runs[0].Status != model.StatusFailed || runs[0].Agent != "codex" || runs[0].Branch != "feature/rewritten" {
+		t.Errorf("rewrite not detected: status=%v agent=%q branch=%q", runs[0].Status, runs[0].Agent, runs[0].Branch)
+	}
+
+	// Deleted runs drop out of the index
+	os.Remove(run.Path)
+	if runs, _ := s.ListRuns(nil); len(runs) != 0 {
+		t.Errorf("expected no runs after delete, got %d", len(runs))
+	}
+	if len(s.index.Runs) != 0 {
+		t.Errorf("index still has %d entries", len(s.index.Runs))
+	}
+}
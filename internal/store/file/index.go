@@ -0,0 +1,230 @@
+package file
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/s22625/orch/internal/model"
+)
+
+// indexFile is the run index file name under the vault's .orch directory
+const indexFile = "run-index.json"
+
+// indexVersion is bumped whenever the entry format or derivation changes;
+// an index with another version is discarded and rebuilt.
+const indexVersion = 3
+
+// runIndex caches, per run document, how far it has been parsed and the
+// state derived from the events seen so far. Run documents are append-only,
+// so a later ListRuns only needs to parse lines written after Offset.
+type runIndex struct {
+	Version int                    `json:"version"`
+	Runs    map[string]*indexEntry `json:"runs"` // keyed by ISSUE_ID#RUN_ID
+}
+
+type indexEntry struct {
+	Size    int64           `json:"size"`
+	ModTime time.Time       `json:"mtime"`
+	Offset  int64           `json:"offset"`  // bytes parsed; always just past a newline
+	Tail    string          `json:"tail"`    // last line before Offset, to detect rewrites
+	InBody  bool            `json:"in_body"` // Offset is past the frontmatter
+	Header  runHeader       `json:"header"`
+	State   *model.RunState `json:"state"`
+}
+
+// runHeader holds the frontmatter fields copied onto model.Run
+type runHeader struct {
+	Agent         string `json:"agent,omitempty"`
+	Model         string `json:"model,omitempty"`
+	ModelVariant  string `json:"model_variant,omitempty"`
+	ContinuedFrom string `json:"continued_from,omitempty"`
+}
+
+func (h runHeader) apply(run *model.Run) {
+	run.Agent = h.Agent
+	run.Model = h.Model
+	run.ModelVariant = h.ModelVariant
+	run.ContinuedFrom = h.ContinuedFrom
+}
+
+// indexPath returns the run index location for the vault
+func (s *FileStore) indexPath() string {
+	return filepath.Join(s.vaultPath, ".orch", indexFile)
+}
+
+// loadIndex returns the in-memory index, reading it from disk on first use.
+// Callers must hold indexMu.
+func (s *FileStore) loadIndex() *runIndex {
+	if s.index != nil {
+		return s.index
+	}
+
+	idx := &runIndex{Version: indexVersion, Runs: make(map[string]*indexEntry)}
+	if data, err := os.ReadFile(s.indexPath()); err == nil {
+		var onDisk runIndex
+		if json.Unmarshal(data, &onDisk) == nil && onDisk.Version == indexVersion && onDisk.Runs != nil {
+			idx = &onDisk
+		}
+	}
+	s.index = idx
+	return idx
+}
+
+// saveIndex writes the index atomically. Failures are ignored: the index is
+// a cache and every entry is revalidated against the run file on use.
+// Callers must hold indexMu.
+func (s *FileStore) saveIndex(idx *runIndex) {
+	data, err := json.Marshal(idx)
+	if err != nil {
+		return
+	}
+	dir := filepath.Dir(s.indexPath())
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return
+	}
+	tmp, err := os.CreateTemp(dir, indexFile+".*.tmp")
+	if err != nil {
+		return
+	}
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		os.Remove(tmp.Name())
+		return
+	}
+	tmp.Close()
+	if err := os.Rename(tmp.Name(), s.indexPath()); err != nil {
+		os.Remove(tmp.Name())
+	}
+}
+
+// indexedRun returns the run summary for a run document, parsing only what
+// changed since the index entry was recorded. The returned run carries the
+// derived state but not the event history; use loadRun for that.
+// changed reports whether the entry was created or updated.
+func (s *FileStore) indexedRun(idx *runIndex, issueID, runID, path string) (run *model.Run, changed bool, err error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, false, err
+	}
+
+	key := issueID + "#" + runID
+	entry := idx.Runs[key]
+
+	switch {
+	case entry != nil && entry.Size == info.Size() && entry.ModTime.Equal(info.ModTime()):
+		// Unchanged since last parse
+	case entry != nil && info.Size() >= entry.Offset:
+		updated, ok := s.extendEntry(entry, path, info)
+		if !ok {
+			updated, err = s.buildEntry(path, info)
+			if err != nil {
+				return nil, false, err
+			}
+		}
+		entry, changed = updated, true
+	default:
+		entry, err = s.buildEntry(path, info)
+		if err != nil {
+			return nil, false, err
+		}
+		changed = true
+	}
+	idx.Runs[key] = entry
+
+	run = &model.Run{
+		IssueID: issueID,
+		RunID:   runID,
+		Path:    path,
+		Events:  []*model.Event{},
+	}
+	entry.Header.apply(run)
+	run.RestoreState(entry.State)
+	s.resolveWorktreePath(run)
+	return run, changed, nil
+}
+
+// buildEntry parses a whole run document
+func (s *FileStore) buildEntry(path string, info os.FileInfo) (*indexEntry, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	// Only index complete lines; a partially written trailing line is
+	// picked up by the next call once its newline lands.
+	complete := content[:bytes.LastIndexByte(content, '\n')+1]
+	header, events, inBody := parseRunDocument(complete)
+
+	state := model.NewRunState()
+	for _, e := range events {
+		state.Apply(e)
+	}
+
+	return &indexEntry{
+		Size:    info.Size(),
+		ModTime: info.ModTime(),
+		Offset:  int64(len(complete)),
+		Tail:    lastLine(complete),
+		InBody:  inBody,
+		Header:  header,
+		State:   state,
+	}, nil
+}
+
+// extendEntry parses lines appended after entry.Offset. It returns false
+// when the document no longer starts with what was indexed (rewritten),
+// in which case the caller rebuilds the entry from scratch.
+func (s *FileStore) extendEntry(entry *indexEntry, path string, info os.FileInfo) (*indexEntry, bool) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, false
+	}
+	defer f.Close()
+
+	start := entry.Offset - int64(len(entry.Tail)) - 1
+	if !entry.InBody || start < 0 {
+		return nil, false
+	}
+	if _, err := f.Seek(start, io.SeekStart); err != nil {
+		return nil, false
+	}
+	data, err := io.ReadAll(f)
+	if err != nil {
+		return nil, false
+	}
+
+	prefix := entry.Tail + "\n"
+	if !bytes.HasPrefix(data, []byte(prefix)) {
+		return nil, false
+	}
+	appended := data[len(prefix):]
+	complete := appended[:bytes.LastIndexByte(appended, '\n')+1]
+
+	updated := *entry
+	updated.State = entry.State.Clone()
+	for _, line := range bytes.Split(complete, []byte("\n")) {
+		if event := parseEventLine(string(line)); event != nil {
+			updated.State.Apply(event)
+		}
+	}
+	updated.Size = info.Size()
+	updated.ModTime = info.ModTime()
+	updated.Offset = entry.Offset + int64(len(complete))
+	if len(complete) > 0 {
+		updated.Tail = lastLine(complete)
+	}
+	return &updated, true
+}
+
+// lastLine returns the last newline-terminated line of data without its newline
+func lastLine(data []byte) string {
+	data = bytes.TrimSuffix(data, []byte("\n"))
+	if i := bytes.LastIndexByte(data, '\n'); i >= 0 {
+		return string(data[i+1:])
+	}
+	return string(data)
+}
@@ -0,0 +1,34 @@
+package file
+
+import (
+	"os"
+	"syscall"
+)
+
+// lockRunFile opens a run document and takes an exclusive advisory lock on
+// it. Every process that mutates run documents (daemon, CLI, monitor) goes
+// through this, so writes are serialized across processes. The lock is
+// released by unlockRunFile or when the process exits.
+func lockRunFile(path string, flag int) (*os.File, error) {
+	f, err := os.OpenFile(path, flag, 0644)
+	if err != nil {
+		return nil, err
+	}
+	for {
+		err = syscall.Flock(int(f.Fd()), syscall.LOCK_EX)
+		if err != syscall.EINTR {
+			break
+		}
+	}
+	if err != nil {
+		f.Close()
+		return nil, err
+	}
+	return f, nil
+}
+
+// unlockRunFile releases the lock and closes the file
+func unlockRunFile(f *os.File) {
+	syscall.Flock(int(f.Fd()), syscall.LOCK_UN)
+	f.Close()
+}
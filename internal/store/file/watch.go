@@ -0,0 +1,246 @@
+package file
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/fsnotify/fsnotify"
+	"github.com/s22625/orch/internal/model"
+	"github.com/s22625/orch/internal/store"
+)
+
+// Watch streams vault changes using filesystem notifications. Run
+// documents are tailed from the offset last seen, so an append costs one
+// read of the new bytes rather than a re-list of every run.
+func (s *FileStore) Watch(ctx context.Context, filter *store.WatchFilter) (<-chan store.Change, error) {
+	fw, err := fsnotify.NewWatcher()
+	if err != nil {
+		return nil, err
+	}
+
+	w := &vaultWatcher{
+		store:   s,
+		fw:      fw,
+		filter:  filter,
+		offsets: make(map[string]int64),
+		issues:  make(map[string]watchedIssue),
+	}
+	if err := w.addTree(s.vaultPath, false); err != nil {
+		fw.Close()
+		return nil, err
+	}
+	if issues, err := s.ListIssues(); err == nil {
+		for _, issue := range issues {
+			w.issues[issue.Path] = watchedIssue{id: issue.ID, fingerprint: issueFingerprint(issue)}
+		}
+	}
+
+	out := make(chan store.Change, 64)
+	go w.loop(ctx, out)
+	return out, nil
+}
+
+// vaultWatcher turns fsnotify events into store changes
+type vaultWatcher struct {
+	store   *FileStore
+	fw      *fsnotify.Watcher
+	filter  *store.WatchFilter
+	offsets map[string]int64        // run document path -> bytes already reported
+	issues  map[string]watchedIssue // by issue file path
+	pending []store.Change
+}
+
+// watchedIssue remembers what was last reported for an issue file, so the
+// several write events of one save produce a single change
+type watchedIssue struct {
+	id          string
+	fingerprint string
+}
+
+func issueFingerprint(issue *model.Issue) string {
+	return string(issue.Status) + "\x00" + issue.Title + "\x00" + issue.Summary + "\x00" + issue.Topic + "\x00" + issue.Body
+}
+
+func (w *vaultWatcher) loop(ctx context.Context, out chan<- store.Change) {
+	defer close(out)
+	defer w.fw.Close()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case ev, ok := <-w.fw.Events:
+			if !ok {
+				return
+			}
+			w.handle(ev)
+		case _, ok := <-w.fw.Errors:
+			// Overflows lose events; consumers still get later changes
+			if !ok {
+				return
+			}
+		}
+
+		for _, c := range w.pending {
+			if !w.filter.Matches(c) {
+				continue
+			}
+			select {
+			case out <- c:
+			case <-ctx.Done():
+				return
+			}
+		}
+		w.pending = w.pending[:0]
+	}
+}
+
+// addTree watches dir and its subdirectories. With created set, run
+// documents already inside are reported as new (they may have been written
+// before the watch was added); otherwise their current size is the baseline.
+func (w *vaultWatcher) addTree(dir string, created bool) error {
+	return walkWithSymlinks(dir, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return nil
+		}
+		if info.IsDir() {
+			if w.skipDir(path) {
+				return filepath.SkipDir
+			}
+			return w.fw.Add(path)
+		}
+		if issueID, runID, ok := w.runFile(path); ok {
+			if created {
+				w.tailRun(path, issueID, runID)
+			} else {
+				w.offsets[path] = info.Size()
+			}
+		}
+		return nil
+	})
+}
+
+// skipDir excludes tool directories and run log directories
+func (w *vaultWatcher) skipDir(path string) bool {
+	rel, err := filepath.Rel(w.store.vaultPath, path)
+	if err != nil || rel == "." {
+		return false
+	}
+	if strings.HasPrefix(filepath.Base(path), ".") {
+		return true // .orch, .git, .obsidian
+	}
+	parts := strings.Split(rel, string(filepath.Separator))
+	return parts[0] == "runs" && len(parts) > 2
+}
+
+// runFile reports whether path is a run document (runs/<ISSUE>/<RUN>.md)
+func (w *vaultWatcher) runFile(path string) (issueID, runID string, ok bool) {
+	rel, err := filepath.Rel(w.store.vaultPath, path)
+	if err != nil {
+		return "", "", false
+	}
+	parts := strings.Split(rel, string(filepath.Separator))
+	if len(parts) != 3 || parts[0] != "runs" || !strings.HasSuffix(parts[2], ".md") {
+		return "", "", false
+	}
+	return parts[1], strings.TrimSuffix(parts[2], ".md"), true
+}
+
+func (w *vaultWatcher) handle(ev fsnotify.Event) {
+	path := ev.Name
+
+	if ev.Has(fsnotify.Create) {
+		if info, err := os.Stat(path); err == nil && info.IsDir() {
+			if !w.skipDir(path) {
+				w.addTree(path, true)
+			}
+			return
+		}
+	}
+
+	if issueID, runID, ok := w.runFile(path); ok {
+		if ev.Has(fsnotify.Remove) || ev.Has(fsnotify.Rename) {
+			delete(w.offsets, path)
+			return
+		}
+		if ev.Has(fsnotify.Create) || ev.Has(fsnotify.Write) {
+			w.tailRun(path, issueID, runID)
+		}
+		return
+	}
+
+	rel, err := filepath.Rel(w.store.vaultPath, path)
+	if err != nil || !strings.HasSuffix(path, ".md") || strings.HasPrefix(rel, "runs"+string(filepath.Separator)) {
+		return
+	}
+	w.issueChanged(path, ev.Has(fsnotify.Remove) || ev.Has(fsnotify.Rename))
+}
+
+// tailRun reports a new run and any complete event lines past the last offset
+func (w *vaultWatcher) tailRun(path, issueID, runID string) {
+	offset, known := w.offsets[path]
+	if !known {
+		w.pending = append(w.pending, store.Change{Type: store.ChangeRunCreated, IssueID: issueID, RunID: runID})
+	}
+
+	f, err := os.Open(path)
+	if err != nil {
+		return
+	}
+	defer f.Close()
+
+	if info, err := f.Stat(); err == nil && info.Size() < offset {
+		offset = 0 // rewritten in place (WriteRun); replay from the start
+	}
+	if _, err := f.Seek(offset, io.SeekStart); err != nil {
+		return
+	}
+	data, err := io.ReadAll(f)
+	if err != nil {
+		return
+	}
+
+	// A partially written line is picked up once its newline lands
+	complete := data[:bytes.LastIndexByte(data, '\n')+1]
+	for _, line := range bytes.Split(complete, []byte("\n")) {
+		if event := parseEventLine(string(line)); event != nil {
+			w.pending = append(w.pending, store.Change{
+				Type:    store.ChangeEventAppended,
+				IssueID: issueID,
+				RunID:   runID,
+				Event:   event,
+			})
+		}
+	}
+	w.offsets[path] = offset + int64(len(complete))
+}
+
+// issueChanged reports a created, edited or removed issue document
+func (w *vaultWatcher) issueChanged(path string, removed bool) {
+	known, wasIssue := w.issues[path]
+
+	var issue *model.Issue
+	if !removed {
+		issue, _ = w.store.parseIssueFile(path)
+	}
+	if issue == nil {
+		if wasIssue {
+			delete(w.issues, path)
+			w.store.markCacheDirty()
+			w.pending = append(w.pending, store.Change{Type: store.ChangeIssueChanged, IssueID: known.id})
+		}
+		return
+	}
+
+	current := watchedIssue{id: issue.ID, fingerprint: issueFingerprint(issue)}
+	if wasIssue && known == current {
+		return
+	}
+	w.issues[path] = current
+	w.store.markCacheDirty()
+	w.pending = append(w.pending, store.Change{Type: store.ChangeIssueChanged, IssueID: issue.ID})
+}
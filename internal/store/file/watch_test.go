@@ -0,0 +1,112 @@
+package file
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/s22625/orch/internal/model"
+	"github.com/s22625/orch/internal/store"
+)
+
+// nextChange waits for the next change or fails the test
+func nextChange(t *testing.T, changes <-chan store.Change) store.Change {
+	t.Helper()
+	select {
+	case c := <-changes:
+		return c
+	case <-time.After(5 * time.Second):
+		t.Fatal("timed out waiting for change")
+		return store.Change{}
+	}
+}
+
+func TestWatch(t *testing.T) {
+	vault, cleanup := setupTestVault(t)
+	defer cleanup()
+
+	createTestIssue(t, vault, "test123", "---\ntype: issue\ntitle: Test\n---\n# Test")
+	s, _ := New(vault)
+	existing, _ := s.CreateRun("test123", "20231220-090000", nil)
+	s.AppendEvent(existing.Ref(), model.NewStatusEvent(model.StatusRunning))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	changes, err := s.Watch(ctx, nil)
+	if err != nil {
+		t.Fatalf("Watch() error = %v", err)
+	}
+
+	// Events already on disk are not replayed; new ones are
+	if err := s.AppendEvent(existing.Ref(), model.NewStatusEvent(model.StatusDone)); err != nil {
+		t.Fatal(err)
+	}
+	c := nextChange(t, changes)
+	if c.Type != store.ChangeEventAppended || c.RunID != existing.RunID || c.Event.Name != string(model.StatusDone) {
+		t.Errorf("got %+v, want done event for existing run", c)
+	}
+
+	// Runs under a new issue directory are picked up
+	createTestIssue(t, vault, "test456", "---\ntype: issue\ntitle: Other\n---\n# Other")
+	c = nextChange(t, changes)
+	if c.Type != store.ChangeIssueChanged || c.IssueID != "test456" {
+		t.Errorf("got %+v, want issue_changed test456", c)
+	}
+
+	run, err := s.CreateRun("test456", "20231220-100000", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	c = nextChange(t, changes)
+	if c.Type != store.ChangeRunCreated || c.IssueID != "test456" || c.RunID != run.RunID {
+		t.Errorf("got %+v, want run_created test456", c)
+	}
+	s.AppendEvent(run.Ref(), model.NewStatusEvent(model.StatusBooting))
+	for c = nextChange(t, changes); c.Type != store.ChangeEventAppended; c = nextChange(t, changes) {
+	}
+	if c.RunID != run.RunID || c.Event.Name != string(model.StatusBooting) {
+		t.Errorf("got %+v, want booting event", c)
+	}
+
+	// Issue status changes made through the store are reported
+	if err := s.SetIssueStatus("test123", model.IssueStatusResolved); err != nil {
+		t.Fatal(err)
+	}
+	c = nextChange(t, changes)
+	if c.Type != store.ChangeIssueChanged || c.IssueID != "test123" {
+		t.Errorf("got %+v, want issue_changed test123", c)
+	}
+
+	cancel()
+	for range changes {
+	}
+}
+
+func TestWatchFilter(t *testing.T) {
+	vault, cleanup := setupTestVault(t)
+	defer cleanup()
+
+	createTestIssue(t, vault, "test123", "---\ntype: issue\ntitle: Test\n---\n# Test")
+	createTestIssue(t, vault, "test456", "---\ntype: issue\ntitle: Other\n---\n# Other")
+	s, _ := New(vault)
+	other, _ := s.CreateRun("test456", "20231220-090000", nil)
+	run, _ := s.CreateRun("test123", "20231220-100000", nil)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	changes, err := s.Watch(ctx, &store.WatchFilter{IssueID: "test123", Types: []store.ChangeType{store.ChangeEventAppended}})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	s.AppendEvent(other.Ref(), model.NewStatusEvent(model.StatusRunning))
+	os.WriteFile(filepath.Join(vault, "issues", "test123.md"), []byte("---\ntype: issue\ntitle: Edited\n---\n# Edited"), 0644)
+	s.AppendEvent(run.Ref(), model.NewStatusEvent(model.StatusRunning))
+
+	c := nextChange(t, changes)
+	if c.Type != store.ChangeEventAppended || c.IssueID != "test123" {
+		t.Errorf("got %+v, want only test123 events", c)
+	}
+}
@@ -0,0 +1,200 @@
+package github
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// DefaultAPIURL is the public GitHub REST endpoint
+const DefaultAPIURL = "https://api.github.com"
+
+// listPageSize is the page size used when listing issues (GitHub maximum)
+const listPageSize = 100
+
+// Issue is the subset of the GitHub issue resource orch uses
+type Issue struct {
+	Number      int       `json:"number"`
+	Title       string    `json:"title"`
+	Body        string    `json:"body"`
+	State       string    `json:"state"`                  // open, closed
+	StateReason string    `json:"state_reason,omitempty"` // completed, not_planned, reopened
+	HTMLURL     string    `json:"html_url"`
+	Labels      []Label   `json:"labels"`
+	UpdatedAt   time.Time `json:"updated_at"`
+
+	// PullRequest is set when the item is a pull request; those are skipped
+	PullRequest *struct{} `json:"pull_request,omitempty"`
+}
+
+// Label is a GitHub issue label
+type Label struct {
+	Name string `json:"name"`
+}
+
+// HasLabel reports whether the issue carries the named label
+func (i *Issue) HasLabel(name string) bool {
+	if name == "" {
+		return false
+	}
+	for _, l := range i.Labels {
+		if strings.EqualFold(l.Name, name) {
+			return true
+		}
+	}
+	return false
+}
+
+// LabelNames returns the issue's label names in order
+func (i *Issue) LabelNames() []string {
+	names := make([]string, 0, len(i.Labels))
+	for _, l := range i.Labels {
+		names = append(names, l.Name)
+	}
+	return names
+}
+
+// IssueUpdate is the body of a PATCH /repos/{owner}/{repo}/issues/{number}
+type IssueUpdate struct {
+	State       string   `json:"state,omitempty"`
+	StateReason string   `json:"state_reason,omitempty"`
+	Labels      []string `json:"labels"`
+}
+
+// Client is the GitHub API surface the store needs. HTTPClient talks to the
+// real REST API; tests point it at an in-process fake server.
+type Client interface {
+	GetIssue(ctx context.Context, number int) (*Issue, error)
+	ListIssues(ctx context.Context) ([]*Issue, error)
+	UpdateIssue(ctx context.Context, number int, update *IssueUpdate) (*Issue, error)
+}
+
+// APIError is returned for non-2xx responses
+type APIError struct {
+	StatusCode int
+	Message    string
+}
+
+func (e *APIError) Error() string {
+	if e.Message == "" {
+		return fmt.Sprintf("github api: %d %s", e.StatusCode, http.StatusText(e.StatusCode))
+	}
+	return fmt.Sprintf("github api: %d %s", e.StatusCode, e.Message)
+}
+
+// HTTPClient implements Client over the GitHub REST API
+type HTTPClient struct {
+	BaseURL string
+	Owner   string
+	Repo    string
+	Token   string
+	HTTP    *http.Client
+}
+
+// NewHTTPClient creates a client for owner/repo. An empty baseURL uses DefaultAPIURL.
+func NewHTTPClient(baseURL, owner, repo, token string) *HTTPClient {
+	if baseURL == "" {
+		baseURL = DefaultAPIURL
+	}
+	return &HTTPClient{
+		BaseURL: strings.TrimSuffix(baseURL, "/"),
+		Owner:   owner,
+		Repo:    repo,
+		Token:   token,
+		HTTP:    &http.Client{Timeout: 30 * time.Second},
+	}
+}
+
+// GetIssue fetches a single issue
+func (c *HTTPClient) GetIssue(ctx context.Context, number int) (*Issue, error) {
+	var issue Issue
+	if err := c.do(ctx, http.MethodGet, c.issuePath(number), nil, &issue); err != nil {
+		return nil, err
+	}
+	return &issue, nil
+}
+
+// ListIssues fetches all issues (open and closed), excluding pull requests
+func (c *HTTPClient) ListIssues(ctx context.Context) ([]*Issue, error) {
+	var all []*Issue
+	for page := 1; ; page++ {
+		path := fmt.Sprintf("/repos/%s/%s/issues?state=all&per_page=%d&page=%d", c.Owner, c.Repo, listPageSize, page)
+		var batch []*Issue
+		if err := c.do(ctx, http.MethodGet, path, nil, &batch); err != nil {
+			return nil, err
+		}
+		for _, issue := range batch {
+			if issue.PullRequest == nil {
+				all = append(all, issue)
+			}
+		}
+		if len(batch) < listPageSize {
+			return all, nil
+		}
+	}
+}
+
+// UpdateIssue patches an issue's state and labels
+func (c *HTTPClient) UpdateIssue(ctx context.Context, number int, update *IssueUpdate) (*Issue, error) {
+	var issue Issue
+	if err := c.do(ctx, http.MethodPatch, c.issuePath(number), update, &issue); err != nil {
+		return nil, err
+	}
+	return &issue, nil
+}
+
+func (c *HTTPClient) issuePath(number int) string {
+	return fmt.Sprintf("/repos/%s/%s/issues/%d", c.Owner, c.Repo, number)
+}
+
+func (c *HTTPClient) do(ctx context.Context, method, path string, body, out any) error {
+	var reqBody io.Reader
+	if body != nil {
+		data, err := json.Marshal(body)
+		if err != nil {
+			return err
+		}
+		reqBody = bytes.NewReader(data)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, c.BaseURL+path, reqBody)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Accept", "application/vnd.github+json")
+	req.Header.Set("X-GitHub-Api-Version", "2022-11-28")
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	if c.Token != "" {
+		req.Header.Set("Authorization", "Bearer "+c.Token)
+	}
+
+	resp, err := c.HTTP.Do(req)
+	if err != nil {
+		return fmt.Errorf("github api: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		var apiErr struct {
+			Message string `json:"message"`
+		}
+		data, _ := io.ReadAll(io.LimitReader(resp.Body, 4096))
+		_ = json.Unmarshal(data, &apiErr)
+		return &APIError{StatusCode: resp.StatusCode, Message: apiErr.Message}
+	}
+
+	if out == nil {
+		return nil
+	}
+	if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+		return fmt.Errorf("github api: invalid response: %w", err)
+	}
+	return nil
+}
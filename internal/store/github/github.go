@@ -0,0 +1,320 @@
+package github
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/s22625/orch/internal/model"
+	"github.com/s22625/orch/internal/store"
+	"github.com/s22625/orch/internal/store/sidecar"
+)
+
+// issueCacheTTL bounds how long ListIssues results are reused. The monitor
+// refreshes every few seconds and would otherwise burn through rate limits.
+const issueCacheTTL = 30 * time.Second
+
+// requestTimeout bounds a single store operation against the API
+const requestTimeout = 30 * time.Second
+
+// StatusLabels maps orch issue statuses to GitHub labels.
+// An empty label means the status is expressed by issue state alone.
+type StatusLabels struct {
+	Open     string
+	Resolved string
+	Closed   string
+}
+
+// DefaultStatusLabels returns the labels used when config leaves them unset
+func DefaultStatusLabels() StatusLabels {
+	return StatusLabels{
+		Resolved: "orch:resolved",
+		Closed:   "orch:closed",
+	}
+}
+
+// Status derives the orch issue status from labels, falling back to state.
+// A closed issue without a status label is resolved when it was closed as
+// completed (e.g. by a merged PR) and closed otherwise.
+func (l StatusLabels) Status(issue *Issue) model.IssueStatus {
+	switch {
+	case issue.HasLabel(l.Resolved):
+		return model.IssueStatusResolved
+	case issue.HasLabel(l.Closed):
+		return model.IssueStatusClosed
+	case issue.HasLabel(l.Open):
+		return model.IssueStatusOpen
+	case issue.State != "closed":
+		return model.IssueStatusOpen
+	case issue.StateReason == "completed":
+		return model.IssueStatusResolved
+	default:
+		return model.IssueStatusClosed
+	}
+}
+
+// label returns the label for a status
+func (l StatusLabels) label(status model.IssueStatus) string {
+	switch status {
+	case model.IssueStatusResolved:
+		return l.Resolved
+	case model.IssueStatusClosed:
+		return l.Closed
+	default:
+		return l.Open
+	}
+}
+
+// isStatusLabel reports whether name is one of the status labels
+func (l StatusLabels) isStatusLabel(name string) bool {
+	for _, label := range []string{l.Open, l.Resolved, l.Closed} {
+		if label != "" && strings.EqualFold(label, name) {
+			return true
+		}
+	}
+	return false
+}
+
+// GitHubStore implements store.Store with issues living in a GitHub
+// repository. Issue IDs are issue numbers. Runs and events are kept in a
+// local sidecar under the vault, keyed by issue number.
+type GitHubStore struct {
+	*sidecar.Runs
+
+	vaultPath string
+	repo      string
+	client    Client
+	labels    StatusLabels
+
+	mu       sync.Mutex
+	issues   []*model.Issue
+	cachedAt time.Time
+}
+
+// SidecarPath returns where runs for repo are stored inside a vault
+func SidecarPath(vaultPath, repo string) string {
+	return filepath.Join(vaultPath, ".orch", "github", filepath.FromSlash(repo))
+}
+
+// New creates a GitHub store for repo ("owner/name")
+func New(vaultPath, repo string, client Client, labels StatusLabels) (*GitHubStore, error) {
+	if _, _, err := SplitRepo(repo); err != nil {
+		return nil, err
+	}
+
+	absPath, err := sidecar.ResolveVault(vaultPath)
+	if err != nil {
+		return nil, err
+	}
+	runs, err := sidecar.New(SidecarPath(absPath, repo), normalizeIssueID)
+	if err != nil {
+		return nil, err
+	}
+
+	return &GitHubStore{
+		Runs:      runs,
+		vaultPath: absPath,
+		repo:      repo,
+		client:    client,
+		labels:    labels,
+	}, nil
+}
+
+// SplitRepo splits "owner/name" into its parts
+func SplitRepo(repo string) (string, string, error) {
+	owner, name, ok := strings.Cut(repo, "/")
+	if !ok || owner == "" || name == "" || strings.Contains(name, "/") {
+		return "", "", fmt.Errorf("invalid github repo %q (expected owner/name)", repo)
+	}
+	return owner, name, nil
+}
+
+// TokenFromEnv returns an API token from GITHUB_TOKEN, GH_TOKEN, or
+// `gh auth token`, in that order. An empty result means unauthenticated.
+func TokenFromEnv() string {
+	for _, key := range []string{"GITHUB_TOKEN", "GH_TOKEN"} {
+		if v := os.Getenv(key); v != "" {
+			return v
+		}
+	}
+	out, err := exec.Command("gh", "auth", "token").Output()
+	if err != nil {
+		return ""
+	}
+	return strings.TrimSpace(string(out))
+}
+
+// VaultPath returns the vault root path
+func (s *GitHubStore) VaultPath() string {
+	return s.vaultPath
+}
+
+// Repo returns the owner/name of the backing repository
+func (s *GitHubStore) Repo() string {
+	return s.repo
+}
+
+// normalizeIssueID accepts "42" or "#42" and returns the bare number runs
+// are keyed by
+func normalizeIssueID(issueID string) string {
+	return strings.TrimPrefix(issueID, "#")
+}
+
+// parseIssueNumber accepts "42" or "#42"
+func parseIssueNumber(issueID string) (int, error) {
+	n, err := strconv.Atoi(normalizeIssueID(issueID))
+	if err != nil || n <= 0 {
+		return 0, fmt.Errorf("invalid github issue id: %s (expected an issue number)", issueID)
+	}
+	return n, nil
+}
+
+func (s *GitHubStore) toModel(gi *Issue) *model.Issue {
+	id := strconv.Itoa(gi.Number)
+	status := s.labels.Status(gi)
+
+	summary := gi.Title
+	if len(summary) > 50 {
+		summary = summary[:47] + "..."
+	}
+
+	return &model.Issue{
+		ID:      id,
+		Title:   gi.Title,
+		Summary: summary,
+		Status:  status,
+		Body:    gi.Body,
+		Path:    gi.HTMLURL,
+		Frontmatter: map[string]string{
+			"type":   "issue",
+			"id":     id,
+			"title":  gi.Title,
+			"status": string(status),
+			"url":    gi.HTMLURL,
+			"labels": strings.Join(gi.LabelNames(), ","),
+		},
+	}
+}
+
+// ResolveIssue fetches an issue by number
+func (s *GitHubStore) ResolveIssue(issueID string) (*model.Issue, error) {
+	number, err := parseIssueNumber(issueID)
+	if err != nil {
+		return nil, err
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), requestTimeout)
+	defer cancel()
+
+	gi, err := s.client.GetIssue(ctx, number)
+	if err != nil {
+		if apiErr, ok := err.(*APIError); ok && apiErr.StatusCode == 404 {
+			return nil, fmt.Errorf("issue not found: %s", issueID)
+		}
+		return nil, err
+	}
+	if gi.PullRequest != nil {
+		return nil, fmt.Errorf("issue not found: %s (#%d is a pull request)", issueID, number)
+	}
+	return s.toModel(gi), nil
+}
+
+// ListIssues returns all issues in the repository (cached briefly)
+func (s *GitHubStore) ListIssues() ([]*model.Issue, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.issues != nil && time.Since(s.cachedAt) < issueCacheTTL {
+		return s.issues, nil
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), requestTimeout)
+	defer cancel()
+
+	list, err := s.client.ListIssues(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	issues := make([]*model.Issue, 0, len(list))
+	for _, gi := range list {
+		issues = append(issues, s.toModel(gi))
+	}
+	s.issues = issues
+	s.cachedAt = time.Now()
+	return issues, nil
+}
+
+// SetIssueStatus swaps the status label and opens or closes the issue
+func (s *GitHubStore) SetIssueStatus(issueID string, status model.IssueStatus) error {
+	number, err := parseIssueNumber(issueID)
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), requestTimeout)
+	defer cancel()
+
+	gi, err := s.client.GetIssue(ctx, number)
+	if err != nil {
+		return err
+	}
+
+	labels := make([]string, 0, len(gi.Labels)+1)
+	for _, name := range gi.LabelNames() {
+		if !s.labels.isStatusLabel(name) {
+			labels = append(labels, name)
+		}
+	}
+	if label := s.labels.label(status); label != "" {
+		labels = append(labels, label)
+	}
+
+	update := &IssueUpdate{Labels: labels}
+	switch status {
+	case model.IssueStatusResolved:
+		update.State, update.StateReason = "closed", "completed"
+	case model.IssueStatusClosed:
+		update.State, update.StateReason = "closed", "not_planned"
+	default:
+		update.State = "open"
+	}
+
+	if _, err := s.client.UpdateIssue(ctx, number, update); err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	s.issues = nil
+	s.mu.Unlock()
+	return nil
+}
+
+// CreateRun verifies the issue on GitHub and creates the run in the sidecar
+func (s *GitHubStore) CreateRun(issueID, runID string, metadata map[string]string) (*model.Run, error) {
+	issue, err := s.ResolveIssue(issueID)
+	if err != nil {
+		return nil, err
+	}
+	return s.Runs.CreateRun(issue.ID, runID, metadata)
+}
+
+// Watch polls for changes; GitHub has no push feed for issue edits, so runs and issues
+// are both diffed against the previous snapshot
+func (s *GitHubStore) Watch(ctx context.Context, filter *store.WatchFilter) (<-chan store.Change, error) {
+	if filter != nil {
+		normalized := *filter
+		normalized.IssueID = normalizeIssueID(filter.IssueID)
+		filter = &normalized
+	}
+	return store.PollWatch(ctx, s, filter, store.DefaultPollInterval)
+}
+
+// Ensure GitHubStore implements Store
+var _ store.Store = (*GitHubStore)(nil)
@@ -0,0 +1,314 @@
+package github
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/s22625/orch/internal/model"
+	"github.com/s22625/orch/internal/store"
+)
+
+// fakeGitHub is an in-process stand-in for the GitHub issues REST API
+type fakeGitHub struct {
+	mu      sync.Mutex
+	issues  map[int]*Issue
+	patches int
+	auth    string
+}
+
+func newFakeGitHub(t *testing.T) (*fakeGitHub, *httptest.Server) {
+	t.Helper()
+	f := &fakeGitHub{issues: make(map[int]*Issue)}
+	srv := httptest.NewServer(http.HandlerFunc(f.serve))
+	t.Cleanup(srv.Close)
+	return f, srv
+}
+
+func (f *fakeGitHub) add(issue *Issue) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if issue.State == "" {
+		issue.State = "open"
+	}
+	issue.HTMLURL = fmt.Sprintf("https://github.com/acme/widgets/issues/%d", issue.Number)
+	f.issues[issue.Number] = issue
+}
+
+func (f *fakeGitHub) serve(w http.ResponseWriter, r *http.Request) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.auth = r.Header.Get("Authorization")
+
+	const prefix = "/repos/acme/widgets/issues"
+	if !strings.HasPrefix(r.URL.Path, prefix) {
+		http.NotFound(w, r)
+		return
+	}
+	rest := strings.TrimPrefix(strings.TrimPrefix(r.URL.Path, prefix), "/")
+
+	if rest == "" && r.Method == http.MethodGet {
+		page, _ := strconv.Atoi(r.URL.Query().Get("page"))
+		perPage, _ := strconv.Atoi(r.URL.Query().Get("per_page"))
+		numbers := make([]int, 0, len(f.issues))
+		for n := range f.issues {
+			numbers = append(numbers, n)
+		}
+		sort.Ints(numbers)
+		var all []*Issue
+		for _, n := range numbers {
+			all = append(all, f.issues[n])
+		}
+		start := (page - 1) * perPage
+		if start > len(all) {
+			start = len(all)
+		}
+		end := start + perPage
+		if end > len(all) {
+			end = len(all)
+		}
+		json.NewEncoder(w).Encode(all[start:end])
+		return
+	}
+
+	number, err := strconv.Atoi(rest)
+	issue, ok := f.issues[number]
+	if err != nil || !ok {
+		w.WriteHeader(http.StatusNotFound)
+		json.NewEncoder(w).Encode(map[string]string{"message": "Not Found"})
+		return
+	}
+
+	switch r.Method {
+	case http.MethodGet:
+		json.NewEncoder(w).Encode(issue)
+	case http.MethodPatch:
+		var update IssueUpdate
+		if err := json.NewDecoder(r.Body).Decode(&update); err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		f.patches++
+		if update.State != "" {
+			issue.State = update.State
+			issue.StateReason = update.StateReason
+		}
+		issue.Labels = nil
+		for _, name := range update.Labels {
+			issue.Labels = append(issue.Labels, Label{Name: name})
+		}
+		json.NewEncoder(w).Encode(issue)
+	default:
+		w.WriteHeader(http.StatusMethodNotAllowed)
+	}
+}
+
+func newTestStore(t *testing.T, srv *httptest.Server) *GitHubStore {
+	t.Helper()
+	client := NewHTTPClient(srv.URL, "acme", "widgets", "test-token")
+	s, err := New(t.TempDir(), "acme/widgets", client, DefaultStatusLabels())
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	return s
+}
+
+func TestNewInvalidRepo(t *testing.T) {
+	if _, err := New(t.TempDir(), "widgets", nil, DefaultStatusLabels()); err == nil {
+		t.Error("expected error for repo without owner")
+	}
+}
+
+func TestResolveIssue(t *testing.T) {
+	fake, srv := newFakeGitHub(t)
+	fake.add(&Issue{Number: 42, Title: "Fix login timeout", Body: "Details", Labels: []Label{{Name: "bug"}}})
+
+	s := newTestStore(t, srv)
+	issue, err := s.ResolveIssue("42")
+	if err != nil {
+		t.Fatalf("ResolveIssue() error = %v", err)
+	}
+	if issue.ID != "42" || issue.Title != "Fix login timeout" || issue.Body != "Details" {
+		t.Errorf("unexpected issue: %+v", issue)
+	}
+	if issue.Status != model.IssueStatusOpen {
+		t.Errorf("Status = %v, want open", issue.Status)
+	}
+	if issue.Path != "https://github.com/acme/widgets/issues/42" {
+		t.Errorf("Path = %q", issue.Path)
+	}
+	if fake.auth != "Bearer test-token" {
+		t.Errorf("Authorization = %q", fake.auth)
+	}
+
+	if _, err := s.ResolveIssue("#42"); err != nil {
+		t.Errorf("ResolveIssue(#42) error = %v", err)
+	}
+	if _, err := s.ResolveIssue("7"); err == nil || !strings.Contains(err.Error(), "issue not found") {
+		t.Errorf("ResolveIssue(7) error = %v, want issue not found", err)
+	}
+	if _, err := s.ResolveIssue("not-a-number"); err == nil {
+		t.Error("expected error for non-numeric issue id")
+	}
+}
+
+func TestStatusFromLabels(t *testing.T) {
+	labels := DefaultStatusLabels()
+	tests := []struct {
+		name  string
+		issue *Issue
+		want  model.IssueStatus
+	}{
+		{"open", &Issue{State: "open"}, model.IssueStatusOpen},
+		{"resolved label", &Issue{State: "open", Labels: []Label{{Name: "orch:resolved"}}}, model.IssueStatusResolved},
+		{"closed label", &Issue{State: "closed", Labels: []Label{{Name: "orch:closed"}}}, model.IssueStatusClosed},
+		{"closed completed", &Issue{State: "closed", StateReason: "completed"}, model.IssueStatusResolved},
+		{"closed not planned", &Issue{State: "closed", StateReason: "not_planned"}, model.IssueStatusClosed},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := labels.Status(tt.issue); got != tt.want {
+				t.Errorf("Status() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestListIssuesPaginatesAndSkipsPRs(t *testing.T) {
+	fake, srv := newFakeGitHub(t)
+	for n := 1; n <= listPageSize+5; n++ {
+		fake.add(&Issue{Number: n, Title: fmt.Sprintf("Issue %d", n)})
+	}
+	fake.issues[3].PullRequest = &struct{}{}
+
+	s := newTestStore(t, srv)
+	issues, err := s.ListIssues()
+	if err != nil {
+		t.Fatalf("ListIssues() error = %v", err)
+	}
+	if len(issues) != listPageSize+4 {
+		t.Errorf("expected %d issues, got %d", listPageSize+4, len(issues))
+	}
+	for _, issue := range issues {
+		if issue.ID == "3" {
+			t.Error("pull request returned as issue")
+		}
+	}
+}
+
+func TestSetIssueStatus(t *testing.T) {
+	fake, srv := newFakeGitHub(t)
+	fake.add(&Issue{Number: 42, Title: "Fix", Labels: []Label{{Name: "bug"}, {Name: "orch:closed"}}})
+
+	s := newTestStore(t, srv)
+	if err := s.SetIssueStatus("42", model.IssueStatusResolved); err != nil {
+		t.Fatalf("SetIssueStatus() error = %v", err)
+	}
+
+	gi := fake.issues[42]
+	if gi.State != "closed" || gi.StateReason != "completed" {
+		t.Errorf("state = %s/%s, want closed/completed", gi.State, gi.StateReason)
+	}
+	if got := strings.Join(gi.LabelNames(), ","); got != "bug,orch:resolved" {
+		t.Errorf("labels = %s, want bug,orch:resolved", got)
+	}
+
+	issue, err := s.ResolveIssue("42")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if issue.Status != model.IssueStatusResolved {
+		t.Errorf("Status = %v, want resolved", issue.Status)
+	}
+
+	if err := s.SetIssueStatus("42", model.IssueStatusOpen); err != nil {
+		t.Fatalf("SetIssueStatus(open) error = %v", err)
+	}
+	if gi.State != "open" || strings.Join(gi.LabelNames(), ",") != "bug" {
+		t.Errorf("reopen: state=%s labels=%v", gi.State, gi.LabelNames())
+	}
+}
+
+func TestHashRefsResolveEverywhere(t *testing.T) {
+	fake, srv := newFakeGitHub(t)
+	fake.add(&Issue{Number: 42, Title: "Fix"})
+	s := newTestStore(t, srv)
+	run, err := s.CreateRun("42", "20231220-100000", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ref := &model.RunRef{IssueID: "#42", RunID: run.RunID}
+	if err := s.AppendEvent(ref, model.NewStatusEvent(model.StatusRunning)); err != nil {
+		t.Fatalf("AppendEvent(#42) error = %v", err)
+	}
+	if err := s.AppendEventIf(ref, model.StatusRunning, model.NewStatusEvent(model.StatusDone)); err != nil {
+		t.Fatalf("AppendEventIf(#42) error = %v", err)
+	}
+	loaded, err := s.GetRun(ref)
+	if err != nil || loaded.Status != model.StatusDone {
+		t.Fatalf("GetRun(#42) = %v, %v", loaded, err)
+	}
+	if runs, err := s.ListRuns(&store.ListRunsFilter{IssueID: "#42"}); err != nil || len(runs) != 1 {
+		t.Errorf("ListRuns(#42) = %d runs, %v", len(runs), err)
+	}
+	if latest, err := s.GetRun(&model.RunRef{IssueID: "#42"}); err != nil || latest.RunID != run.RunID {
+		t.Errorf("GetRun(#42 latest) = %v, %v", latest, err)
+	}
+}
+
+func TestRunsLiveInSidecar(t *testing.T) {
+	fake, srv := newFakeGitHub(t)
+	fake.add(&Issue{Number: 42, Title: "Fix"})
+
+	s := newTestStore(t, srv)
+	if _, err := s.CreateRun("99", "20231220-100000", nil); err == nil {
+		t.Error("expected error creating run for unknown issue")
+	}
+
+	run, err := s.CreateRun("#42", "20231220-100000", map[string]string{"agent": "claude"})
+	if err != nil {
+		t.Fatalf("CreateRun() error = %v", err)
+	}
+	if run.IssueID != "42" {
+		t.Errorf("IssueID = %q, want 42", run.IssueID)
+	}
+	if !strings.HasPrefix(run.Path, SidecarPath(s.VaultPath(), "acme/widgets")) {
+		t.Errorf("run path %s not in sidecar", run.Path)
+	}
+	if _, err := s.CreateRun("42", "20231220-100000", nil); err == nil {
+		t.Error("expected error for duplicate run")
+	}
+
+	if err := s.AppendEvent(run.Ref(), model.NewStatusEvent(model.StatusRunning)); err != nil {
+		t.Fatalf("AppendEvent() error = %v", err)
+	}
+
+	loaded, err := s.GetRun(run.Ref())
+	if err != nil {
+		t.Fatalf("GetRun() error = %v", err)
+	}
+	if loaded.Agent != "claude" || loaded.Status != model.StatusRunning {
+		t.Errorf("loaded run agent=%q status=%v", loaded.Agent, loaded.Status)
+	}
+
+	runs, err := s.ListRuns(&store.ListRunsFilter{IssueID: "42"})
+	if err != nil || len(runs) != 1 {
+		t.Fatalf("ListRuns() = %d runs, err %v", len(runs), err)
+	}
+	if _, err := s.GetRunByShortID(run.ShortID()); err != nil {
+		t.Errorf("GetRunByShortID() error = %v", err)
+	}
+	if latest, err := s.GetLatestRun("42"); err != nil || latest.RunID != run.RunID {
+		t.Errorf("GetLatestRun() = %v, %v", latest, err)
+	}
+	if fake.patches != 0 {
+		t.Errorf("run operations should not modify GitHub issues, got %d patches", fake.patches)
+	}
+}
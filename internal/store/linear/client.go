@@ -0,0 +1,119 @@
+package linear
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// DefaultAPIURL is the Linear GraphQL endpoint
+const DefaultAPIURL = "https://api.linear.app/graphql"
+
+// Client executes GraphQL operations. HTTPClient talks to Linear; tests
+// point it at an in-process fake server.
+type Client interface {
+	// Do runs query with variables and decodes the "data" member into out
+	Do(ctx context.Context, query string, variables map[string]any, out any) error
+}
+
+// GraphQLError is a single entry of a GraphQL "errors" response
+type GraphQLError struct {
+	Message string `json:"message"`
+}
+
+// Error aggregates GraphQL errors returned with a 200 response
+type Error struct {
+	Errors []GraphQLError
+}
+
+func (e *Error) Error() string {
+	msgs := make([]string, 0, len(e.Errors))
+	for _, ge := range e.Errors {
+		msgs = append(msgs, ge.Message)
+	}
+	return "linear api: " + strings.Join(msgs, "; ")
+}
+
+// HTTPError is returned for non-2xx responses
+type HTTPError struct {
+	StatusCode int
+	Body       string
+}
+
+func (e *HTTPError) Error() string {
+	return fmt.Sprintf("linear api: %d %s", e.StatusCode, strings.TrimSpace(e.Body))
+}
+
+// HTTPClient implements Client over HTTP
+type HTTPClient struct {
+	URL    string
+	APIKey string
+	HTTP   *http.Client
+}
+
+// NewHTTPClient creates a client. An empty url uses DefaultAPIURL.
+func NewHTTPClient(url, apiKey string) *HTTPClient {
+	if url == "" {
+		url = DefaultAPIURL
+	}
+	return &HTTPClient{
+		URL:    url,
+		APIKey: apiKey,
+		HTTP:   &http.Client{Timeout: 30 * time.Second},
+	}
+}
+
+// Do posts a GraphQL request
+func (c *HTTPClient) Do(ctx context.Context, query string, variables map[string]any, out any) error {
+	payload, err := json.Marshal(map[string]any{
+		"query":     query,
+		"variables": variables,
+	})
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.URL, bytes.NewReader(payload))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if c.APIKey != "" {
+		// Personal API keys are sent as-is; OAuth tokens carry their own "Bearer " prefix
+		req.Header.Set("Authorization", c.APIKey)
+	}
+
+	resp, err := c.HTTP.Do(req)
+	if err != nil {
+		return fmt.Errorf("linear api: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 4096))
+		return &HTTPError{StatusCode: resp.StatusCode, Body: string(body)}
+	}
+
+	var envelope struct {
+		Data   json.RawMessage `json:"data"`
+		Errors []GraphQLError  `json:"errors"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&envelope); err != nil {
+		return fmt.Errorf("linear api: invalid response: %w", err)
+	}
+	if len(envelope.Errors) > 0 {
+		return &Error{Errors: envelope.Errors}
+	}
+	if out == nil || len(envelope.Data) == 0 {
+		return nil
+	}
+	if err := json.Unmarshal(envelope.Data, out); err != nil {
+		return fmt.Errorf("linear api: invalid data: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,366 @@
+package linear
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/s22625/orch/internal/model"
+	"github.com/s22625/orch/internal/store"
+	"github.com/s22625/orch/internal/store/sidecar"
+)
+
+// issueCacheTTL bounds how long ListIssues results are reused
+const issueCacheTTL = 30 * time.Second
+
+// requestTimeout bounds a single store operation against the API
+const requestTimeout = 30 * time.Second
+
+// listPageSize is the page size used when listing issues
+const listPageSize = 100
+
+const issueFields = `
+	id
+	identifier
+	title
+	description
+	url
+	state { id name type }
+	team { id key }
+`
+
+const issueQuery = `query Issue($id: String!) {
+	issue(id: $id) {` + issueFields + `}
+}`
+
+const issuesQuery = `query Issues($first: Int!, $after: String, $filter: IssueFilter) {
+	issues(first: $first, after: $after, filter: $filter) {
+		nodes {` + issueFields + `}
+		pageInfo { hasNextPage endCursor }
+	}
+}`
+
+const workflowStatesQuery = `query WorkflowStates($teamId: ID!) {
+	workflowStates(filter: { team: { id: { eq: $teamId } } }) {
+		nodes { id name type }
+	}
+}`
+
+const issueUpdateMutation = `mutation IssueUpdate($id: String!, $stateId: String!) {
+	issueUpdate(id: $id, input: { stateId: $stateId }) {
+		success
+	}
+}`
+
+// WorkflowState is a Linear workflow state
+type WorkflowState struct {
+	ID   string `json:"id"`
+	Name string `json:"name"`
+	Type string `json:"type"` // triage, backlog, unstarted, started, completed, canceled
+}
+
+// Team is the team an issue belongs to
+type Team struct {
+	ID  string `json:"id"`
+	Key string `json:"key"`
+}
+
+// Issue is the subset of the Linear issue object orch uses
+type Issue struct {
+	ID          string        `json:"id"`
+	Identifier  string        `json:"identifier"` // e.g. ENG-123
+	Title       string        `json:"title"`
+	Description string        `json:"description"`
+	URL         string        `json:"url"`
+	State       WorkflowState `json:"state"`
+	Team        Team          `json:"team"`
+}
+
+// StateMapping maps Linear workflow states to orch issue statuses.
+//
+// States are matched by name (case-insensitive) first, then by workflow
+// state type. Targets picks the state used when orch sets a status; when
+// unset, the first team state whose type matches the default is used.
+type StateMapping struct {
+	ByName  map[string]model.IssueStatus
+	Targets map[model.IssueStatus]string
+}
+
+// defaultTypeStatus maps workflow state types to statuses
+var defaultTypeStatus = map[string]model.IssueStatus{
+	"triage":    model.IssueStatusOpen,
+	"backlog":   model.IssueStatusOpen,
+	"unstarted": model.IssueStatusOpen,
+	"started":   model.IssueStatusOpen,
+	"completed": model.IssueStatusResolved,
+	"canceled":  model.IssueStatusClosed,
+}
+
+// defaultTargetType is the state type chosen for a status without a target
+var defaultTargetType = map[model.IssueStatus]string{
+	model.IssueStatusOpen:     "unstarted",
+	model.IssueStatusResolved: "completed",
+	model.IssueStatusClosed:   "canceled",
+}
+
+// Status maps a workflow state to an issue status
+func (m StateMapping) Status(state WorkflowState) model.IssueStatus {
+	for name, status := range m.ByName {
+		if strings.EqualFold(name, state.Name) {
+			return status
+		}
+	}
+	if status, ok := defaultTypeStatus[state.Type]; ok {
+		return status
+	}
+	return model.IssueStatusOpen
+}
+
+// target picks the workflow state to move an issue into for status
+func (m StateMapping) target(status model.IssueStatus, states []WorkflowState) (WorkflowState, error) {
+	if name := m.Targets[status]; name != "" {
+		for _, s := range states {
+			if strings.EqualFold(s.Name, name) {
+				return s, nil
+			}
+		}
+		return WorkflowState{}, fmt.Errorf("linear workflow state not found: %s", name)
+	}
+
+	want := defaultTargetType[status]
+	for _, s := range states {
+		if s.Type == want {
+			return s, nil
+		}
+	}
+	for _, s := range states {
+		if m.Status(s) == status {
+			return s, nil
+		}
+	}
+	return WorkflowState{}, fmt.Errorf("no linear workflow state maps to %s", status)
+}
+
+// LinearStore implements store.Store with issues read from Linear. Issue
+// IDs are Linear identifiers (ENG-123). Runs and events are recorded in a
+// local sidecar under the vault.
+type LinearStore struct {
+	*sidecar.Runs
+
+	vaultPath string
+	team      string
+	client    Client
+	mapping   StateMapping
+
+	mu       sync.Mutex
+	issues   []*model.Issue
+	cachedAt time.Time
+}
+
+// SidecarPath returns where Linear runs are stored inside a vault
+func SidecarPath(vaultPath string) string {
+	return filepath.Join(vaultPath, ".orch", "linear")
+}
+
+// New creates a Linear store. team optionally restricts ListIssues to a
+// team key (e.g. "ENG").
+func New(vaultPath, team string, client Client, mapping StateMapping) (*LinearStore, error) {
+	absPath, err := sidecar.ResolveVault(vaultPath)
+	if err != nil {
+		return nil, err
+	}
+	runs, err := sidecar.New(SidecarPath(absPath), nil)
+	if err != nil {
+		return nil, err
+	}
+
+	return &LinearStore{
+		Runs:      runs,
+		vaultPath: absPath,
+		team:      team,
+		client:    client,
+		mapping:   mapping,
+	}, nil
+}
+
+// VaultPath returns the vault root path
+func (s *LinearStore) VaultPath() string {
+	return s.vaultPath
+}
+
+func (s *LinearStore) toModel(li *Issue) *model.Issue {
+	status := s.mapping.Status(li.State)
+
+	summary := li.Title
+	if len(summary) > 50 {
+		summary = summary[:47] + "..."
+	}
+
+	return &model.Issue{
+		ID:      li.Identifier,
+		Title:   li.Title,
+		Summary: summary,
+		Status:  status,
+		Body:    li.Description,
+		Path:    li.URL,
+		Frontmatter: map[string]string{
+			"type":   "issue",
+			"id":     li.Identifier,
+			"title":  li.Title,
+			"status": string(status),
+			"url":    li.URL,
+			"state":  li.State.Name,
+			"team":   li.Team.Key,
+		},
+	}
+}
+
+func (s *LinearStore) fetchIssue(ctx context.Context, issueID string) (*Issue, error) {
+	var resp struct {
+		Issue *Issue `json:"issue"`
+	}
+	if err := s.client.Do(ctx, issueQuery, map[string]any{"id": issueID}, &resp); err != nil {
+		if gqlErr, ok := err.(*Error); ok && isNotFound(gqlErr) {
+			return nil, fmt.Errorf("issue not found: %s", issueID)
+		}
+		return nil, err
+	}
+	if resp.Issue == nil {
+		return nil, fmt.Errorf("issue not found: %s", issueID)
+	}
+	return resp.Issue, nil
+}
+
+// isNotFound reports whether Linear rejected the lookup as a missing entity
+func isNotFound(err *Error) bool {
+	for _, ge := range err.Errors {
+		msg := strings.ToLower(ge.Message)
+		if strings.Contains(msg, "not found") || strings.Contains(msg, "could not find") {
+			return true
+		}
+	}
+	return false
+}
+
+// ResolveIssue fetches an issue by identifier
+func (s *LinearStore) ResolveIssue(issueID string) (*model.Issue, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), requestTimeout)
+	defer cancel()
+
+	li, err := s.fetchIssue(ctx, issueID)
+	if err != nil {
+		return nil, err
+	}
+	return s.toModel(li), nil
+}
+
+// ListIssues returns issues visible to the API key, filtered by team when
+// configured (cached briefly)
+func (s *LinearStore) ListIssues() ([]*model.Issue, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.issues != nil && time.Since(s.cachedAt) < issueCacheTTL {
+		return s.issues, nil
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), requestTimeout)
+	defer cancel()
+
+	vars := map[string]any{"first": listPageSize}
+	if s.team != "" {
+		vars["filter"] = map[string]any{
+			"team": map[string]any{"key": map[string]any{"eq": s.team}},
+		}
+	}
+
+	var issues []*model.Issue
+	for {
+		var resp struct {
+			Issues struct {
+				Nodes    []*Issue `json:"nodes"`
+				PageInfo struct {
+					HasNextPage bool   `json:"hasNextPage"`
+					EndCursor   string `json:"endCursor"`
+				} `json:"pageInfo"`
+			} `json:"issues"`
+		}
+		if err := s.client.Do(ctx, issuesQuery, vars, &resp); err != nil {
+			return nil, err
+		}
+		for _, li := range resp.Issues.Nodes {
+			issues = append(issues, s.toModel(li))
+		}
+		if !resp.Issues.PageInfo.HasNextPage || resp.Issues.PageInfo.EndCursor == "" {
+			break
+		}
+		vars["after"] = resp.Issues.PageInfo.EndCursor
+	}
+
+	s.issues = issues
+	s.cachedAt = time.Now()
+	return issues, nil
+}
+
+// SetIssueStatus moves the issue into the workflow state mapped to status
+func (s *LinearStore) SetIssueStatus(issueID string, status model.IssueStatus) error {
+	ctx, cancel := context.WithTimeout(context.Background(), requestTimeout)
+	defer cancel()
+
+	li, err := s.fetchIssue(ctx, issueID)
+	if err != nil {
+		return err
+	}
+
+	var statesResp struct {
+		WorkflowStates struct {
+			Nodes []WorkflowState `json:"nodes"`
+		} `json:"workflowStates"`
+	}
+	if err := s.client.Do(ctx, workflowStatesQuery, map[string]any{"teamId": li.Team.ID}, &statesResp); err != nil {
+		return err
+	}
+
+	state, err := s.mapping.target(status, statesResp.WorkflowStates.Nodes)
+	if err != nil {
+		return err
+	}
+
+	var updateResp struct {
+		IssueUpdate struct {
+			Success bool `json:"success"`
+		} `json:"issueUpdate"`
+	}
+	if err := s.client.Do(ctx, issueUpdateMutation, map[string]any{"id": li.ID, "stateId": state.ID}, &updateResp); err != nil {
+		return err
+	}
+	if !updateResp.IssueUpdate.Success {
+		return fmt.Errorf("linear api: failed to update %s", issueID)
+	}
+
+	s.mu.Lock()
+	s.issues = nil
+	s.mu.Unlock()
+	return nil
+}
+
+// CreateRun verifies the issue on Linear and creates the run in the sidecar
+func (s *LinearStore) CreateRun(issueID, runID string, metadata map[string]string) (*model.Run, error) {
+	issue, err := s.ResolveIssue(issueID)
+	if err != nil {
+		return nil, err
+	}
+	return s.Runs.CreateRun(issue.ID, runID, metadata)
+}
+
+// Watch polls for changes; Linear webhooks need a public endpoint, so runs and issues
+// are both diffed against the previous snapshot
+func (s *LinearStore) Watch(ctx context.Context, filter *store.WatchFilter) (<-chan store.Change, error) {
+	return store.PollWatch(ctx, s, filter, store.DefaultPollInterval)
+}
+
+// Ensure LinearStore implements Store
+var _ store.Store = (*LinearStore)(nil)
@@ -0,0 +1,307 @@
+package linear
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sort"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/s22625/orch/internal/model"
+	"github.com/s22625/orch/internal/store"
+)
+
+var testStates = []WorkflowState{
+	{ID: "s-backlog", Name: "Backlog", Type: "backlog"},
+	{ID: "s-todo", Name: "Todo", Type: "unstarted"},
+	{ID: "s-review", Name: "In Review", Type: "started"},
+	{ID: "s-done", Name: "Done", Type: "completed"},
+	{ID: "s-canceled", Name: "Canceled", Type: "canceled"},
+}
+
+// fakeLinear is an in-process stand-in for the Linear GraphQL API. It
+// dispatches on the operation name of each query.
+type fakeLinear struct {
+	mu       sync.Mutex
+	issues   map[string]*Issue // by identifier
+	pageSize int
+	auth     string
+	updates  int
+}
+
+func newFakeLinear(t *testing.T) (*fakeLinear, *httptest.Server) {
+	t.Helper()
+	f := &fakeLinear{issues: make(map[string]*Issue), pageSize: listPageSize}
+	srv := httptest.NewServer(http.HandlerFunc(f.serve))
+	t.Cleanup(srv.Close)
+	return f, srv
+}
+
+func (f *fakeLinear) add(identifier, title, stateName string) *Issue {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	var state WorkflowState
+	for _, s := range testStates {
+		if s.Name == stateName {
+			state = s
+		}
+	}
+	team := strings.SplitN(identifier, "-", 2)[0]
+	issue := &Issue{
+		ID:          "uuid-" + identifier,
+		Identifier:  identifier,
+		Title:       title,
+		Description: "Description of " + identifier,
+		URL:         "https://linear.app/acme/issue/" + identifier,
+		State:       state,
+		Team:        Team{ID: "team-" + team, Key: team},
+	}
+	f.issues[identifier] = issue
+	return issue
+}
+
+func (f *fakeLinear) byID(id string) *Issue {
+	for _, issue := range f.issues {
+		if issue.ID == id || issue.Identifier == id {
+			return issue
+		}
+	}
+	return nil
+}
+
+func (f *fakeLinear) serve(w http.ResponseWriter, r *http.Request) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.auth = r.Header.Get("Authorization")
+
+	var req struct {
+		Query     string         `json:"query"`
+		Variables map[string]any `json:"variables"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+	vars := req.Variables
+
+	var data any
+	switch {
+	case strings.HasPrefix(req.Query, "query Issue("):
+		issue := f.byID(vars["id"].(string))
+		if issue == nil {
+			json.NewEncoder(w).Encode(map[string]any{
+				"errors": []map[string]string{{"message": "Entity not found: Issue"}},
+			})
+			return
+		}
+		data = map[string]any{"issue": issue}
+
+	case strings.HasPrefix(req.Query, "query Issues("):
+		team := ""
+		if filter, ok := vars["filter"].(map[string]any); ok {
+			team = filter["team"].(map[string]any)["key"].(map[string]any)["eq"].(string)
+		}
+		var ids []string
+		for id, issue := range f.issues {
+			if team == "" || issue.Team.Key == team {
+				ids = append(ids, id)
+			}
+		}
+		sort.Strings(ids)
+		start := 0
+		if after, ok := vars["after"].(string); ok {
+			fmt.Sscanf(after, "cursor-%d", &start)
+		}
+		end := start + f.pageSize
+		if end > len(ids) {
+			end = len(ids)
+		}
+		nodes := []*Issue{}
+		for _, id := range ids[start:end] {
+			nodes = append(nodes, f.issues[id])
+		}
+		data = map[string]any{"issues": map[string]any{
+			"nodes": nodes,
+			"pageInfo": map[string]any{
+				"hasNextPage": end < len(ids),
+				"endCursor":   fmt.Sprintf("cursor-%d", end),
+			},
+		}}
+
+	case strings.HasPrefix(req.Query, "query WorkflowStates("):
+		data = map[string]any{"workflowStates": map[string]any{"nodes": testStates}}
+
+	case strings.HasPrefix(req.Query, "mutation IssueUpdate("):
+		issue := f.byID(vars["id"].(string))
+		success := false
+		for _, s := range testStates {
+			if issue != nil && s.ID == vars["stateId"] {
+				issue.State = s
+				success = true
+			}
+		}
+		f.updates++
+		data = map[string]any{"issueUpdate": map[string]any{"success": success}}
+
+	default:
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+
+	json.NewEncoder(w).Encode(map[string]any{"data": data})
+}
+
+func newTestStore(t *testing.T, srv *httptest.Server, team string, mapping StateMapping) *LinearStore {
+	t.Helper()
+	s, err := New(t.TempDir(), team, NewHTTPClient(srv.URL, "lin_api_test"), mapping)
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	return s
+}
+
+func TestResolveIssue(t *testing.T) {
+	fake, srv := newFakeLinear(t)
+	fake.add("ENG-1", "Fix login timeout", "Todo")
+
+	s := newTestStore(t, srv, "", StateMapping{})
+	issue, err := s.ResolveIssue("ENG-1")
+	if err != nil {
+		t.Fatalf("ResolveIssue() error = %v", err)
+	}
+	if issue.ID != "ENG-1" || issue.Title != "Fix login timeout" {
+		t.Errorf("unexpected issue: %+v", issue)
+	}
+	if issue.Body != "Description of ENG-1" {
+		t.Errorf("Body = %q", issue.Body)
+	}
+	if issue.Status != model.IssueStatusOpen {
+		t.Errorf("Status = %v, want open", issue.Status)
+	}
+	if fake.auth != "lin_api_test" {
+		t.Errorf("Authorization = %q", fake.auth)
+	}
+
+	if _, err := s.ResolveIssue("ENG-404"); err == nil || !strings.Contains(err.Error(), "issue not found") {
+		t.Errorf("ResolveIssue(ENG-404) error = %v, want issue not found", err)
+	}
+}
+
+func TestStateMapping(t *testing.T) {
+	mapping := StateMapping{ByName: map[string]model.IssueStatus{"in review": model.IssueStatusResolved}}
+	tests := []struct {
+		state string
+		want  model.IssueStatus
+	}{
+		{"Backlog", model.IssueStatusOpen},
+		{"Todo", model.IssueStatusOpen},
+		{"In Review", model.IssueStatusResolved},
+		{"Done", model.IssueStatusResolved},
+		{"Canceled", model.IssueStatusClosed},
+	}
+	for _, tt := range tests {
+		for _, s := range testStates {
+			if s.Name == tt.state {
+				if got := mapping.Status(s); got != tt.want {
+					t.Errorf("Status(%s) = %v, want %v", tt.state, got, tt.want)
+				}
+			}
+		}
+	}
+}
+
+func TestListIssuesPaginatesAndFiltersTeam(t *testing.T) {
+	fake, srv := newFakeLinear(t)
+	fake.pageSize = 2
+	fake.add("ENG-1", "One", "Todo")
+	fake.add("ENG-2", "Two", "Done")
+	fake.add("ENG-3", "Three", "Canceled")
+	fake.add("OPS-1", "Other team", "Todo")
+
+	s := newTestStore(t, srv, "ENG", StateMapping{})
+	issues, err := s.ListIssues()
+	if err != nil {
+		t.Fatalf("ListIssues() error = %v", err)
+	}
+	if len(issues) != 3 {
+		t.Fatalf("expected 3 ENG issues, got %d", len(issues))
+	}
+	want := map[string]model.IssueStatus{
+		"ENG-1": model.IssueStatusOpen,
+		"ENG-2": model.IssueStatusResolved,
+		"ENG-3": model.IssueStatusClosed,
+	}
+	for _, issue := range issues {
+		if want[issue.ID] != issue.Status {
+			t.Errorf("%s status = %v, want %v", issue.ID, issue.Status, want[issue.ID])
+		}
+	}
+}
+
+func TestSetIssueStatus(t *testing.T) {
+	fake, srv := newFakeLinear(t)
+	fake.add("ENG-1", "One", "In Review")
+
+	s := newTestStore(t, srv, "", StateMapping{})
+	if err := s.SetIssueStatus("ENG-1", model.IssueStatusResolved); err != nil {
+		t.Fatalf("SetIssueStatus() error = %v", err)
+	}
+	if got := fake.issues["ENG-1"].State.Name; got != "Done" {
+		t.Errorf("state = %s, want Done", got)
+	}
+
+	// Configured target state wins over the type default
+	s = newTestStore(t, srv, "", StateMapping{Targets: map[model.IssueStatus]string{model.IssueStatusOpen: "backlog"}})
+	if err := s.SetIssueStatus("ENG-1", model.IssueStatusOpen); err != nil {
+		t.Fatalf("SetIssueStatus(open) error = %v", err)
+	}
+	if got := fake.issues["ENG-1"].State.Name; got != "Backlog" {
+		t.Errorf("state = %s, want Backlog", got)
+	}
+
+	s = newTestStore(t, srv, "", StateMapping{Targets: map[model.IssueStatus]string{model.IssueStatusClosed: "Nope"}})
+	if err := s.SetIssueStatus("ENG-1", model.IssueStatusClosed); err == nil {
+		t.Error("expected error for unknown target state")
+	}
+}
+
+func TestRunsRecordedLocally(t *testing.T) {
+	fake, srv := newFakeLinear(t)
+	fake.add("ENG-1", "One", "Todo")
+
+	s := newTestStore(t, srv, "", StateMapping{})
+	if _, err := s.CreateRun("ENG-9", "20231220-100000", nil); err == nil {
+		t.Error("expected error creating run for unknown issue")
+	}
+
+	run, err := s.CreateRun("ENG-1", "20231220-100000", map[string]string{"agent": "codex"})
+	if err != nil {
+		t.Fatalf("CreateRun() error = %v", err)
+	}
+	if !strings.HasPrefix(run.Path, SidecarPath(s.VaultPath())) {
+		t.Errorf("run path %s not in sidecar", run.Path)
+	}
+	if _, err := s.CreateRun("ENG-1", "20231220-100000", nil); err == nil {
+		t.Error("expected error for duplicate run")
+	}
+
+	if err := s.AppendEvent(run.Ref(), model.NewStatusEvent(model.StatusDone)); err != nil {
+		t.Fatalf("AppendEvent() error = %v", err)
+	}
+	runs, err := s.ListRuns(&store.ListRunsFilter{Status: []model.Status{model.StatusDone}})
+	if err != nil || len(runs) != 1 {
+		t.Fatalf("ListRuns() = %d runs, err %v", len(runs), err)
+	}
+	if runs[0].Agent != "codex" {
+		t.Errorf("Agent = %q, want codex", runs[0].Agent)
+	}
+	if _, err := s.GetRunByShortID(run.ShortID()); err != nil {
+		t.Errorf("GetRunByShortID() error = %v", err)
+	}
+	if fake.updates != 0 {
+		t.Errorf("run operations should not update Linear, got %d updates", fake.updates)
+	}
+}
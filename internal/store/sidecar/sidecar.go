@@ -0,0 +1,99 @@
+// Package sidecar keeps the runs of backends whose issues live in an issue
+// tracker (GitHub, Linear). The tracker has no place for run events, so runs
+// are recorded in a file store under the vault using the file backend's run
+// document format.
+package sidecar
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/s22625/orch/internal/model"
+	"github.com/s22625/orch/internal/store"
+	"github.com/s22625/orch/internal/store/file"
+)
+
+// Runs implements the run methods of store.Store on the sidecar. Backends
+// embed it and define CreateRun themselves, verifying the issue with the
+// tracker before calling Runs.CreateRun.
+type Runs struct {
+	runs      *file.FileStore
+	normalize func(issueID string) string
+}
+
+// ResolveVault returns the absolute path of vaultPath, which must be an
+// existing directory
+func ResolveVault(vaultPath string) (string, error) {
+	absPath, err := filepath.Abs(vaultPath)
+	if err != nil {
+		return "", fmt.Errorf("invalid vault path: %w", err)
+	}
+	info, err := os.Stat(absPath)
+	if err != nil {
+		return "", fmt.Errorf("vault path does not exist: %w", err)
+	}
+	if !info.IsDir() {
+		return "", fmt.Errorf("vault path is not a directory: %s", absPath)
+	}
+	return absPath, nil
+}
+
+// New opens the sidecar in dir, creating it if needed. normalize maps the
+// issue IDs callers pass (e.g. "#42") to the ones runs are keyed by; nil
+// keeps them as given.
+func New(dir string, normalize func(issueID string) string) (*Runs, error) {
+	runs, err := file.NewSidecar(dir)
+	if err != nil {
+		return nil, err
+	}
+	if normalize == nil {
+		normalize = func(issueID string) string { return issueID }
+	}
+	return &Runs{runs: runs, normalize: normalize}, nil
+}
+
+func (r *Runs) normalizeRef(ref *model.RunRef) *model.RunRef {
+	return &model.RunRef{IssueID: r.normalize(ref.IssueID), RunID: ref.RunID}
+}
+
+// CreateRun creates the run in the sidecar. The issue is not looked up;
+// it fails if the run already exists.
+func (r *Runs) CreateRun(issueID, runID string, metadata map[string]string) (*model.Run, error) {
+	return r.runs.CreateRun(r.normalize(issueID), runID, metadata)
+}
+
+// AppendEvent appends an event to a run in the sidecar
+func (r *Runs) AppendEvent(ref *model.RunRef, event *model.Event) error {
+	return r.runs.AppendEvent(r.normalizeRef(ref), event)
+}
+
+// AppendEventIf appends an event if the run's status matches expected
+func (r *Runs) AppendEventIf(ref *model.RunRef, expected model.Status, event *model.Event) error {
+	return r.runs.AppendEventIf(r.normalizeRef(ref), expected, event)
+}
+
+// ListRuns lists runs from the sidecar
+func (r *Runs) ListRuns(filter *store.ListRunsFilter) ([]*model.Run, error) {
+	if filter != nil {
+		normalized := *filter
+		normalized.IssueID = r.normalize(filter.IssueID)
+		filter = &normalized
+	}
+	return r.runs.ListRuns(filter)
+}
+
+// GetRun retrieves a run from the sidecar
+func (r *Runs) GetRun(ref *model.RunRef) (*model.Run, error) {
+	return r.runs.GetRun(r.normalizeRef(ref))
+}
+
+// GetRunByShortID finds a run by short ID prefix
+func (r *Runs) GetRunByShortID(shortID string) (*model.Run, error) {
+	return r.runs.GetRunByShortID(shortID)
+}
+
+// GetLatestRun retrieves the latest run for an issue
+func (r *Runs) GetLatestRun(issueID string) (*model.Run, error) {
+	return r.runs.GetLatestRun(r.normalize(issueID))
+}
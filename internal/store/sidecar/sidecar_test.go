@@ -0,0 +1,69 @@
+package sidecar
+
+import (
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/s22625/orch/internal/model"
+	"github.com/s22625/orch/internal/store"
+)
+
+func TestCreateRunIsExclusive(t *testing.T) {
+	runs, err := New(t.TempDir(), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Racing creates of one run: exactly one wins, and its events survive
+	const racers = 8
+	var wg sync.WaitGroup
+	created := make(chan *model.Run, racers)
+	for i := 0; i < racers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if run, err := runs.CreateRun("ENG-1", "20231220-100000", nil); err == nil {
+				created <- run
+			} else if !strings.Contains(err.Error(), "already exists") {
+				t.Errorf("CreateRun() error = %v", err)
+			}
+		}()
+	}
+	wg.Wait()
+	close(created)
+	if len(created) != 1 {
+		t.Fatalf("%d creates succeeded, want 1", len(created))
+	}
+	run := <-created
+	if err := runs.AppendEvent(run.Ref(), model.NewStatusEvent(model.StatusRunning)); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := runs.CreateRun("ENG-1", "20231220-100000", nil); err == nil {
+		t.Error("expected error for duplicate run")
+	}
+	loaded, err := runs.GetRun(run.Ref())
+	if err != nil || loaded.Status != model.StatusRunning {
+		t.Errorf("GetRun() = %v, %v; want the run still running", loaded, err)
+	}
+}
+
+func TestNormalize(t *testing.T) {
+	runs, err := New(t.TempDir(), func(issueID string) string { return strings.TrimPrefix(issueID, "#") })
+	if err != nil {
+		t.Fatal(err)
+	}
+	run, err := runs.CreateRun("#42", "20231220-100000", nil)
+	if err != nil {
+		t.Fatalf("CreateRun() error = %v", err)
+	}
+	if run.IssueID != "42" {
+		t.Errorf("IssueID = %q, want 42", run.IssueID)
+	}
+	if _, err := runs.GetRun(&model.RunRef{IssueID: "#42", RunID: run.RunID}); err != nil {
+		t.Errorf("GetRun(#42) error = %v", err)
+	}
+	if list, err := runs.ListRuns(&store.ListRunsFilter{IssueID: "#42"}); err != nil || len(list) != 1 {
+		t.Errorf("ListRuns(#42) = %d runs, %v", len(list), err)
+	}
+}
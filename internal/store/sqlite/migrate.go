@@ -0,0 +1,183 @@
+package sqlite
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/s22625/orch/internal/model"
+	"github.com/s22625/orch/internal/store"
+	"github.com/s22625/orch/internal/store/file"
+)
+
+// MigrationStats summarizes an import or export
+type MigrationStats struct {
+	Issues  int `json:"issues"`
+	Runs    int `json:"runs"`
+	Events  int `json:"events"`
+	Skipped int `json:"skipped"` // runs already present in the destination
+}
+
+// Import copies issues, runs and events from src (typically a markdown
+// vault) into the database. Issues are upserted; runs that already exist
+// are skipped so the import can be re-run safely.
+func (s *SQLiteStore) Import(src store.Store) (*MigrationStats, error) {
+	issues, err := src.ListIssues()
+	if err != nil {
+		return nil, fmt.Errorf("failed to list issues: %w", err)
+	}
+	runs, err := src.ListRuns(&store.ListRunsFilter{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list runs: %w", err)
+	}
+
+	tx, err := s.db.Begin()
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	stats := &MigrationStats{}
+	for _, issue := range issues {
+		if err := putIssue(tx, issue); err != nil {
+			return nil, err
+		}
+		stats.Issues++
+	}
+
+	for _, summary := range runs {
+		var exists int
+		err := tx.QueryRow(`SELECT 1 FROM runs WHERE issue_id = ? AND run_id = ?`, summary.IssueID, summary.RunID).Scan(&exists)
+		if err == nil {
+			stats.Skipped++
+			continue
+		}
+		if err != sql.ErrNoRows {
+			return nil, err
+		}
+
+		// ListRuns may return summaries without events
+		run, err := src.GetRun(summary.Ref())
+		if err != nil {
+			return nil, fmt.Errorf("failed to load run %s: %w", summary.Ref().String(), err)
+		}
+
+		created := run.StartedAt
+		if created.IsZero() {
+			created = time.Now()
+		}
+		row := *run
+		row.Status = model.StatusQueued
+		row.StartedAt, row.UpdatedAt = time.Time{}, time.Time{}
+		if err := insertRun(tx, &row, created, runMetadata(run)); err != nil {
+			return nil, err
+		}
+		for _, event := range run.Events {
+			if err := appendEvent(tx, run.Ref(), event); err != nil {
+				return nil, err
+			}
+			stats.Events++
+		}
+		stats.Runs++
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+	return stats, nil
+}
+
+// Export writes every issue and run in the database to a markdown vault
+// at vaultPath using the file backend layout. Existing documents with the
+// same IDs are overwritten.
+func (s *SQLiteStore) Export(vaultPath string) (*MigrationStats, error) {
+	dst, err := file.New(vaultPath)
+	if err != nil {
+		return nil, err
+	}
+
+	issues, err := s.ListIssues()
+	if err != nil {
+		return nil, err
+	}
+
+	stats := &MigrationStats{}
+	for _, issue := range issues {
+		if err := file.WriteIssue(s.exportIssuePath(dst.VaultPath(), issue), issue); err != nil {
+			return nil, err
+		}
+		stats.Issues++
+	}
+
+	runs, err := s.ListRuns(&store.ListRunsFilter{})
+	if err != nil {
+		return nil, err
+	}
+	for _, summary := range runs {
+		run, err := s.GetRun(summary.Ref())
+		if err != nil {
+			return nil, err
+		}
+		created, metadata, err := s.runHeader(run.Ref())
+		if err != nil {
+			return nil, err
+		}
+		if err := dst.WriteRun(run, created, metadata); err != nil {
+			return nil, err
+		}
+		stats.Runs++
+		stats.Events += len(run.Events)
+	}
+
+	return stats, nil
+}
+
+// exportIssuePath keeps an issue's location relative to the vault when it
+// came from one, and falls back to issues/<ID>.md otherwise.
+func (s *SQLiteStore) exportIssuePath(vaultPath string, issue *model.Issue) string {
+	if issue.Path != "" {
+		if rel, err := filepath.Rel(s.vaultPath, issue.Path); err == nil && !strings.HasPrefix(rel, "..") {
+			return filepath.Join(vaultPath, rel)
+		}
+	}
+	return filepath.Join(vaultPath, "issues", issue.ID+".md")
+}
+
+// runHeader returns the stored creation time and frontmatter metadata for a run
+func (s *SQLiteStore) runHeader(ref *model.RunRef) (time.Time, map[string]string, error) {
+	var created, meta string
+	err := s.db.QueryRow(`SELECT created_at, metadata FROM runs WHERE issue_id = ? AND run_id = ?`, ref.IssueID, ref.RunID).Scan(&created, &meta)
+	if err != nil {
+		return time.Time{}, nil, err
+	}
+	createdAt, err := time.Parse(time.RFC3339, created)
+	if err != nil {
+		return time.Time{}, nil, fmt.Errorf("invalid created_at for %s: %w", ref.String(), err)
+	}
+	metadata := make(map[string]string)
+	if err := json.Unmarshal([]byte(meta), &metadata); err != nil {
+		return time.Time{}, nil, fmt.Errorf("invalid metadata for %s: %w", ref.String(), err)
+	}
+	return createdAt, metadata, nil
+}
+
+// runMetadata rebuilds the frontmatter fields a run document carries
+func runMetadata(run *model.Run) map[string]string {
+	metadata := make(map[string]string)
+	if run.Agent != "" {
+		metadata["agent"] = run.Agent
+	}
+	if run.Model != "" {
+		metadata["model"] = run.Model
+	}
+	if run.ModelVariant != "" {
+		metadata["model_variant"] = run.ModelVariant
+	}
+	if run.ContinuedFrom != "" {
+		metadata["continued_from"] = run.ContinuedFrom
+	}
+	return metadata
+}
@@ -0,0 +1,698 @@
+package sqlite
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/s22625/orch/internal/model"
+	"github.com/s22625/orch/internal/store"
+	"github.com/s22625/orch/internal/store/file"
+
+	_ "modernc.org/sqlite" // pure-Go driver, registers "sqlite"
+)
+
+// dbFile is the database file name under the vault's .orch directory
+const dbFile = "orch.db"
+
+const schema = `
+CREATE TABLE IF NOT EXISTS issues (
+	id          TEXT PRIMARY KEY,
+	title       TEXT NOT NULL DEFAULT '',
+	topic       TEXT NOT NULL DEFAULT '',
+	summary     TEXT NOT NULL DEFAULT '',
+	status      TEXT NOT NULL DEFAULT 'open',
+	body        TEXT NOT NULL DEFAULT '',
+	path        TEXT NOT NULL DEFAULT '',
+	frontmatter TEXT NOT NULL DEFAULT '{}'
+);
+
+CREATE TABLE IF NOT EXISTS runs (
+	issue_id       TEXT NOT NULL,
+	run_id         TEXT NOT NULL,
+	short_id       TEXT NOT NULL,
+	created_at     TEXT NOT NULL,
+	agent          TEXT NOT NULL DEFAULT '',
+	model          TEXT NOT NULL DEFAULT '',
+	model_variant  TEXT NOT NULL DEFAULT '',
+	continued_from TEXT NOT NULL DEFAULT '',
+	metadata       TEXT NOT NULL DEFAULT '{}',
+	status         TEXT NOT NULL DEFAULT 'queued',
+	started_at     INTEGER NOT NULL DEFAULT 0,
+	updated_at     INTEGER NOT NULL DEFAULT 0,
+	state          TEXT NOT NULL DEFAULT '',
+	PRIMARY KEY (issue_id, run_id)
+);
+CREATE INDEX IF NOT EXISTS runs_status ON runs(status);
+CREATE INDEX IF NOT EXISTS runs_updated ON runs(updated_at);
+CREATE INDEX IF NOT EXISTS runs_short_id ON runs(short_id);
+
+CREATE TABLE IF NOT EXISTS events (
+	seq      INTEGER PRIMARY KEY AUTOINCREMENT,
+	issue_id TEXT NOT NULL,
+	run_id   TEXT NOT NULL,
+	ts       TEXT NOT NULL,
+	type     TEXT NOT NULL,
+	name     TEXT NOT NULL,
+	attrs    TEXT NOT NULL DEFAULT '{}'
+);
+CREATE INDEX IF NOT EXISTS events_run ON events(issue_id, run_id, seq);
+`
+
+// SQLiteStore implements store.Store on top of an embedded SQLite database.
+// Issues are authored as markdown in the vault; unknown issue IDs are
+// picked up from the vault on demand so `orch issue create` keeps working.
+type SQLiteStore struct {
+	vaultPath string
+	dbPath    string
+	db        *sql.DB
+}
+
+// DBPath returns the default database location for a vault
+func DBPath(vaultPath string) string {
+	return filepath.Join(vaultPath, ".orch", dbFile)
+}
+
+// New opens (or creates) the SQLite store for a vault
+func New(vaultPath string) (*SQLiteStore, error) {
+	absPath, err := filepath.Abs(vaultPath)
+	if err != nil {
+		return nil, fmt.Errorf("invalid vault path: %w", err)
+	}
+
+	info, err := os.Stat(absPath)
+	if err != nil {
+		return nil, fmt.Errorf("vault path does not exist: %w", err)
+	}
+	if !info.IsDir() {
+		return nil, fmt.Errorf("vault path is not a directory: %s", absPath)
+	}
+
+	return Open(absPath, DBPath(absPath))
+}
+
+// Open opens a SQLite store backed by the database at dbPath
+func Open(vaultPath, dbPath string) (*SQLiteStore, error) {
+	if err := os.MkdirAll(filepath.Dir(dbPath), 0755); err != nil {
+		return nil, fmt.Errorf("failed to create database directory: %w", err)
+	}
+
+	// WAL + busy timeout so the daemon, monitor and CLI can share the file.
+	// Immediate transactions take the write lock up front, so a status read
+	// inside AppendEventIf cannot go stale before the insert.
+	dsn := "file:" + dbPath + "?_pragma=busy_timeout(5000)&_pragma=journal_mode(WAL)&_pragma=synchronous(NORMAL)&_txlock=immediate"
+	db, err := sql.Open("sqlite", dsn)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open database: %w", err)
+	}
+
+	if _, err := db.Exec(schema); err != nil {
+		db.Close()
+		return nil, fmt.Errorf("failed to initialize schema: %w", err)
+	}
+
+	s := &SQLiteStore{
+		vaultPath: vaultPath,
+		dbPath:    dbPath,
+		db:        db,
+	}
+	if err := s.migrateState(); err != nil {
+		db.Close()
+		return nil, fmt.Errorf("failed to migrate run state: %w", err)
+	}
+	return s, nil
+}
+
+// migrateState adds the state column to databases created before it
+// existed and folds the events of runs that have no state yet
+func (s *SQLiteStore) migrateState() error {
+	var hasState int
+	if err := s.db.QueryRow(`SELECT COUNT(*) FROM pragma_table_info('runs') WHERE name = 'state'`).Scan(&hasState); err != nil {
+		return err
+	}
+	if hasState == 0 {
+		if _, err := s.db.Exec(`ALTER TABLE runs ADD COLUMN state TEXT NOT NULL DEFAULT ''`); err != nil {
+			return err
+		}
+	}
+
+	runs, err := s.queryRuns(true, `WHERE state = ''`)
+	if err != nil || len(runs) == 0 {
+		return err
+	}
+	tx, err := s.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+	for _, run := range runs {
+		state, err := json.Marshal(run.State())
+		if err != nil {
+			return err
+		}
+		if _, err := tx.Exec(`UPDATE runs SET state = ? WHERE issue_id = ? AND run_id = ?`, string(state), run.IssueID, run.RunID); err != nil {
+			return err
+		}
+	}
+	return tx.Commit()
+}
+
+// Close closes the underlying database
+func (s *SQLiteStore) Close() error {
+	return s.db.Close()
+}
+
+// VaultPath returns the vault root path
+func (s *SQLiteStore) VaultPath() string {
+	return s.vaultPath
+}
+
+// DBPath returns the database file path
+func (s *SQLiteStore) DBPath() string {
+	return s.dbPath
+}
+
+const issueColumns = `id, title, topic, summary, status, body, path, frontmatter`
+
+type rowScanner interface {
+	Scan(dest ...any) error
+}
+
+func scanIssue(row rowScanner) (*model.Issue, error) {
+	var (
+		issue       model.Issue
+		status      string
+		frontmatter string
+	)
+	if err := row.Scan(&issue.ID, &issue.Title, &issue.Topic, &issue.Summary, &status, &issue.Body, &issue.Path, &frontmatter); err != nil {
+		return nil, err
+	}
+	issue.Status = model.ParseIssueStatus(status)
+	issue.Frontmatter = make(map[string]string)
+	if err := json.Unmarshal([]byte(frontmatter), &issue.Frontmatter); err != nil {
+		return nil, fmt.Errorf("invalid frontmatter for issue %s: %w", issue.ID, err)
+	}
+	return &issue, nil
+}
+
+// ResolveIssue retrieves an issue by ID
+func (s *SQLiteStore) ResolveIssue(issueID string) (*model.Issue, error) {
+	issue, err := s.getIssue(issueID)
+	if err == nil {
+		return issue, nil
+	}
+	if !errors.Is(err, sql.ErrNoRows) {
+		return nil, err
+	}
+
+	// Pick up issues written to the vault as markdown since the last sync
+	if err := s.SyncIssues(); err != nil {
+		return nil, err
+	}
+	issue, err = s.getIssue(issueID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, fmt.Errorf("issue not found: %s", issueID)
+	}
+	return issue, err
+}
+
+func (s *SQLiteStore) getIssue(issueID string) (*model.Issue, error) {
+	row := s.db.QueryRow(`SELECT `+issueColumns+` FROM issues WHERE id = ?`, issueID)
+	return scanIssue(row)
+}
+
+// ListIssues returns all issues, including markdown issues added to the vault
+func (s *SQLiteStore) ListIssues() ([]*model.Issue, error) {
+	if err := s.SyncIssues(); err != nil {
+		return nil, err
+	}
+
+	rows, err := s.db.Query(`SELECT ` + issueColumns + ` FROM issues ORDER BY id`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var issues []*model.Issue
+	for rows.Next() {
+		issue, err := scanIssue(rows)
+		if err != nil {
+			return nil, err
+		}
+		issues = append(issues, issue)
+	}
+	return issues, rows.Err()
+}
+
+// SetIssueStatus updates an issue's status
+func (s *SQLiteStore) SetIssueStatus(issueID string, status model.IssueStatus) error {
+	issue, err := s.ResolveIssue(issueID)
+	if err != nil {
+		return err
+	}
+	issue.Status = status
+	if issue.Frontmatter == nil {
+		issue.Frontmatter = make(map[string]string)
+	}
+	issue.Frontmatter["status"] = string(status)
+	return s.PutIssue(issue)
+}
+
+// PutIssue inserts or replaces an issue
+func (s *SQLiteStore) PutIssue(issue *model.Issue) error {
+	return putIssue(s.db, issue)
+}
+
+type execer interface {
+	Exec(query string, args ...any) (sql.Result, error)
+}
+
+func putIssue(db execer, issue *model.Issue) error {
+	frontmatter, err := json.Marshal(nonNilMap(issue.Frontmatter))
+	if err != nil {
+		return err
+	}
+	status := issue.Status
+	if status == "" {
+		status = model.IssueStatusOpen
+	}
+	_, err = db.Exec(`INSERT OR REPLACE INTO issues (`+issueColumns+`) VALUES (?, ?, ?, ?, ?, ?, ?, ?)`,
+		issue.ID, issue.Title, issue.Topic, issue.Summary, string(status), issue.Body, issue.Path, string(frontmatter))
+	if err != nil {
+		return fmt.Errorf("failed to write issue %s: %w", issue.ID, err)
+	}
+	return nil
+}
+
+// SyncIssues adds markdown issues from the vault that are not yet in the
+// database. Existing rows are left untouched so status changes made through
+// the store are not overwritten by stale frontmatter.
+func (s *SQLiteStore) SyncIssues() error {
+	fs, err := file.New(s.vaultPath)
+	if err != nil {
+		return err
+	}
+	issues, err := fs.ListIssues()
+	if err != nil {
+		return err
+	}
+
+	tx, err := s.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	for _, issue := range issues {
+		frontmatter, err := json.Marshal(nonNilMap(issue.Frontmatter))
+		if err != nil {
+			return err
+		}
+		if _, err := tx.Exec(`INSERT OR IGNORE INTO issues (`+issueColumns+`) VALUES (?, ?, ?, ?, ?, ?, ?, ?)`,
+			issue.ID, issue.Title, issue.Topic, issue.Summary, string(issue.Status), issue.Body, issue.Path, string(frontmatter)); err != nil {
+			return fmt.Errorf("failed to sync issue %s: %w", issue.ID, err)
+		}
+	}
+	return tx.Commit()
+}
+
+// CreateRun creates a new run for an issue
+func (s *SQLiteStore) CreateRun(issueID, runID string, metadata map[string]string) (*model.Run, error) {
+	if _, err := s.ResolveIssue(issueID); err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	run := &model.Run{
+		IssueID:       issueID,
+		RunID:         runID,
+		Status:        model.StatusQueued,
+		Events:        []*model.Event{},
+		StartedAt:     now,
+		UpdatedAt:     now,
+		Agent:         metadata["agent"],
+		Model:         metadata["model"],
+		ModelVariant:  metadata["model_variant"],
+		ContinuedFrom: metadata["continued_from"],
+	}
+
+	// Timestamps stay zero in the index until the first event arrives,
+	// mirroring how the file backend derives them from events.
+	row := *run
+	row.StartedAt, row.UpdatedAt = time.Time{}, time.Time{}
+	if err := insertRun(s.db, &row, now, metadata); err != nil {
+		if strings.Contains(err.Error(), "UNIQUE") {
+			return nil, fmt.Errorf("run already exists: %s#%s", issueID, runID)
+		}
+		return nil, err
+	}
+
+	return run, nil
+}
+
+func insertRun(db execer, run *model.Run, created time.Time, metadata map[string]string) error {
+	meta, err := json.Marshal(nonNilMap(metadata))
+	if err != nil {
+		return err
+	}
+	status := run.Status
+	if status == "" {
+		status = model.StatusQueued
+	}
+	state, err := json.Marshal(model.NewRunState())
+	if err != nil {
+		return err
+	}
+	_, err = db.Exec(`INSERT INTO runs (issue_id, run_id, short_id, created_at, agent, model, model_variant, continued_from, metadata, status, started_at, updated_at, state)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+		run.IssueID, run.RunID, run.ShortID(), created.Format(time.RFC3339),
+		run.Agent, run.Model, run.ModelVariant, run.ContinuedFrom, string(meta),
+		string(status), unixNano(run.StartedAt), unixNano(run.UpdatedAt), string(state))
+	if err != nil {
+		return fmt.Errorf("failed to create run: %w", err)
+	}
+	return nil
+}
+
+// AppendEvent appends an event to a run. Status events are checked against
+// the transition table unless they carry the force attribute.
+func (s *SQLiteStore) AppendEvent(ref *model.RunRef, event *model.Event) error {
+	return s.appendEventTx(ref, event, nil)
+}
+
+// AppendEventIf appends an event only if the run's current status is expected
+func (s *SQLiteStore) AppendEventIf(ref *model.RunRef, expected model.Status, event *model.Event) error {
+	return s.appendEventTx(ref, event, &expected)
+}
+
+func (s *SQLiteStore) appendEventTx(ref *model.RunRef, event *model.Event, expected *model.Status) error {
+	if ref.IsLatest() {
+		run, err := s.GetLatestRun(ref.IssueID)
+		if err != nil {
+			return err
+		}
+		ref = run.Ref()
+	}
+
+	tx, err := s.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	validate := event.Type == model.EventTypeStatus && !event.IsForced()
+	if expected != nil || validate {
+		var current string
+		err := tx.QueryRow(`SELECT status FROM runs WHERE issue_id = ? AND run_id = ?`, ref.IssueID, ref.RunID).Scan(&current)
+		if errors.Is(err, sql.ErrNoRows) {
+			return fmt.Errorf("run not found: %s#%s", ref.IssueID, ref.RunID)
+		}
+		if err != nil {
+			return err
+		}
+		if expected != nil && model.Status(current) != *expected {
+			return store.StatusConflictError(ref, *expected, model.Status(current))
+		}
+		if err := model.CheckStatusEvent(model.Status(current), event); err != nil {
+			return err
+		}
+	}
+
+	if err := appendEvent(tx, ref, event); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+func appendEvent(tx *sql.Tx, ref *model.RunRef, event *model.Event) error {
+	var stateJSON string
+	err := tx.QueryRow(`SELECT state FROM runs WHERE issue_id = ? AND run_id = ?`, ref.IssueID, ref.RunID).Scan(&stateJSON)
+	if errors.Is(err, sql.ErrNoRows) {
+		return fmt.Errorf("run not found: %s#%s", ref.IssueID, ref.RunID)
+	}
+	if err != nil {
+		return err
+	}
+	state := model.NewRunState()
+	if stateJSON != "" {
+		if err := json.Unmarshal([]byte(stateJSON), state); err != nil {
+			return fmt.Errorf("invalid state for %s#%s: %w", ref.IssueID, ref.RunID, err)
+		}
+	}
+
+	attrs, err := json.Marshal(nonNilMap(event.Attrs))
+	if err != nil {
+		return err
+	}
+	if _, err := tx.Exec(`INSERT INTO events (issue_id, run_id, ts, type, name, attrs) VALUES (?, ?, ?, ?, ?, ?)`,
+		ref.IssueID, ref.RunID, event.Timestamp.Format(time.RFC3339), string(event.Type), event.Name, string(attrs)); err != nil {
+		return fmt.Errorf("failed to append event: %w", err)
+	}
+
+	// Keep the folded state and the denormalized columns in step, so
+	// ListRuns neither reads events nor misses the indexes. Timestamps use
+	// second precision to match what events round-trip as.
+	stored := *event
+	stored.Timestamp = event.Timestamp.Truncate(time.Second)
+	stored.Attrs = nonNilMap(event.Attrs)
+	state.Apply(&stored)
+	newState, err := json.Marshal(state)
+	if err != nil {
+		return err
+	}
+	ts := unixNano(stored.Timestamp)
+	status := ""
+	if event.Type == model.EventTypeStatus {
+		status = event.Name
+	}
+	_, err = tx.Exec(`UPDATE runs SET
+			status = CASE WHEN ? != '' THEN ? ELSE status END,
+			started_at = CASE WHEN started_at = 0 THEN ? ELSE started_at END,
+			updated_at = ?,
+			state = ?
+		WHERE issue_id = ? AND run_id = ?`,
+		status, status, ts, ts, string(newState), ref.IssueID, ref.RunID)
+	return err
+}
+
+// GetRun retrieves a run by reference
+func (s *SQLiteStore) GetRun(ref *model.RunRef) (*model.Run, error) {
+	if ref.IsLatest() {
+		return s.GetLatestRun(ref.IssueID)
+	}
+
+	runs, err := s.queryRuns(true, `WHERE issue_id = ? AND run_id = ?`, ref.IssueID, ref.RunID)
+	if err != nil {
+		return nil, err
+	}
+	if len(runs) == 0 {
+		return nil, fmt.Errorf("run not found: %s#%s", ref.IssueID, ref.RunID)
+	}
+	return runs[0], nil
+}
+
+// GetLatestRun retrieves the latest run for an issue
+func (s *SQLiteStore) GetLatestRun(issueID string) (*model.Run, error) {
+	// Run IDs are timestamped, so the lexically greatest is the latest
+	runs, err := s.queryRuns(true, `WHERE issue_id = ? ORDER BY run_id DESC LIMIT 1`, issueID)
+	if err != nil {
+		return nil, err
+	}
+	if len(runs) == 0 {
+		return nil, fmt.Errorf("no runs found for issue: %s", issueID)
+	}
+	return runs[0], nil
+}
+
+// GetRunByShortID finds a run by its short ID prefix (2-6 hex chars)
+// Returns an error if no match found or if multiple runs match (ambiguous)
+func (s *SQLiteStore) GetRunByShortID(shortID string) (*model.Run, error) {
+	matches, err := s.queryRuns(true, `WHERE short_id LIKE ? ORDER BY updated_at DESC`, escapeLike(shortID)+"%")
+	if err != nil {
+		return nil, err
+	}
+	if len(matches) == 0 {
+		return nil, fmt.Errorf("run not found: %s", shortID)
+	}
+	if len(matches) > 1 {
+		return nil, store.AmbiguousShortIDError(shortID, matches)
+	}
+	return matches[0], nil
+}
+
+// ListRuns lists runs matching the filter. The runs are summaries restored
+// from the stored state, without events; use GetRun for the history.
+func (s *SQLiteStore) ListRuns(filter *store.ListRunsFilter) ([]*model.Run, error) {
+	var (
+		where []string
+		args  []any
+	)
+	if filter != nil {
+		if filter.IssueID != "" {
+			where = append(where, "issue_id = ?")
+			args = append(args, filter.IssueID)
+		}
+		if len(filter.Status) > 0 {
+			placeholders := make([]string, len(filter.Status))
+			for i, st := range filter.Status {
+				placeholders[i] = "?"
+				args = append(args, string(st))
+			}
+			where = append(where, "status IN ("+strings.Join(placeholders, ", ")+")")
+		}
+		if filter.Since != "" {
+			since, err := time.Parse(time.RFC3339, filter.Since)
+			if err != nil {
+				return nil, fmt.Errorf("invalid since timestamp: %w", err)
+			}
+			where = append(where, "updated_at >= ?")
+			args = append(args, since.UnixNano())
+		}
+	}
+
+	clause := ""
+	if len(where) > 0 {
+		clause = "WHERE " + strings.Join(where, " AND ")
+	}
+	clause += " ORDER BY updated_at DESC"
+	if filter != nil && filter.Limit > 0 {
+		clause += fmt.Sprintf(" LIMIT %d", filter.Limit)
+	}
+
+	return s.queryRuns(false, clause, args...)
+}
+
+// queryRuns loads the runs selected by the given clause. With withEvents
+// their events are loaded and folded; otherwise the stored state is
+// restored, and only runs without one fall back to their events.
+func (s *SQLiteStore) queryRuns(withEvents bool, clause string, args ...any) ([]*model.Run, error) {
+	rows, err := s.db.Query(`SELECT issue_id, run_id, agent, model, model_variant, continued_from, state FROM runs `+clause, args...)
+	if err != nil {
+		return nil, err
+	}
+
+	var runs, unfolded []*model.Run
+	byKey := make(map[string]*model.Run)
+	for rows.Next() {
+		run := &model.Run{Events: []*model.Event{}}
+		var stateJSON string
+		if err := rows.Scan(&run.IssueID, &run.RunID, &run.Agent, &run.Model, &run.ModelVariant, &run.ContinuedFrom, &stateJSON); err != nil {
+			rows.Close()
+			return nil, err
+		}
+		runs = append(runs, run)
+
+		state := model.NewRunState()
+		if !withEvents && stateJSON != "" && json.Unmarshal([]byte(stateJSON), state) == nil {
+			run.RestoreState(state)
+			continue
+		}
+		unfolded = append(unfolded, run)
+		byKey[run.IssueID+"#"+run.RunID] = run
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	if len(unfolded) == 0 {
+		return runs, nil
+	}
+
+	if err := s.loadEvents(unfolded, byKey); err != nil {
+		return nil, err
+	}
+	for _, run := range unfolded {
+		run.DeriveState()
+	}
+
+	return runs, nil
+}
+
+// loadEvents fetches events for the given runs in batches
+func (s *SQLiteStore) loadEvents(runs []*model.Run, byKey map[string]*model.Run) error {
+	const batchSize = 200
+	for start := 0; start < len(runs); start += batchSize {
+		end := start + batchSize
+		if end > len(runs) {
+			end = len(runs)
+		}
+
+		conds := make([]string, 0, end-start)
+		args := make([]any, 0, 2*(end-start))
+		for _, run := range runs[start:end] {
+			conds = append(conds, "(issue_id = ? AND run_id = ?)")
+			args = append(args, run.IssueID, run.RunID)
+		}
+
+		rows, err := s.db.Query(`SELECT issue_id, run_id, ts, type, name, attrs FROM events WHERE `+strings.Join(conds, " OR ")+` ORDER BY seq`, args...)
+		if err != nil {
+			return err
+		}
+		for rows.Next() {
+			var issueID, runID, ts, eventType, name, attrs string
+			if err := rows.Scan(&issueID, &runID, &ts, &eventType, &name, &attrs); err != nil {
+				rows.Close()
+				return err
+			}
+			run := byKey[issueID+"#"+runID]
+			if run == nil {
+				continue
+			}
+			event, err := decodeEvent(ts, eventType, name, attrs)
+			if err != nil {
+				continue
+			}
+			run.Events = append(run.Events, event)
+		}
+		rows.Close()
+		if err := rows.Err(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func decodeEvent(ts, eventType, name, attrs string) (*model.Event, error) {
+	timestamp, err := time.Parse(time.RFC3339, ts)
+	if err != nil {
+		return nil, err
+	}
+	event := &model.Event{
+		Timestamp: timestamp,
+		Type:      model.EventType(eventType),
+		Name:      name,
+		Attrs:     make(map[string]string),
+	}
+	if err := json.Unmarshal([]byte(attrs), &event.Attrs); err != nil {
+		return nil, err
+	}
+	event.Raw = event.String()
+	return event, nil
+}
+
+func nonNilMap(m map[string]string) map[string]string {
+	if m == nil {
+		return map[string]string{}
+	}
+	return m
+}
+
+func unixNano(t time.Time) int64 {
+	if t.IsZero() {
+		return 0
+	}
+	return t.UnixNano()
+}
+
+func escapeLike(s string) string {
+	s = strings.ReplaceAll(s, "%", "")
+	return strings.ReplaceAll(s, "_", "")
+}
+
+// Ensure SQLiteStore implements Store
+var _ store.Store = (*SQLiteStore)(nil)
@@ -0,0 +1,387 @@
+package sqlite
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/s22625/orch/internal/model"
+	"github.com/s22625/orch/internal/store"
+	"github.com/s22625/orch/internal/store/file"
+)
+
+func setupTestVault(t *testing.T) string {
+	t.Helper()
+	dir := t.TempDir()
+	os.MkdirAll(filepath.Join(dir, "issues"), 0755)
+	os.MkdirAll(filepath.Join(dir, "runs"), 0755)
+	return dir
+}
+
+func createTestIssue(t *testing.T, vaultPath, issueID, content string) {
+	t.Helper()
+	path := filepath.Join(vaultPath, "issues", issueID+".md")
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func newTestStore(t *testing.T, vault string) *SQLiteStore {
+	t.Helper()
+	s, err := New(vault)
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	t.Cleanup(func() { s.Close() })
+	return s
+}
+
+func TestNewInvalidPath(t *testing.T) {
+	if _, err := New("/nonexistent/path"); err == nil {
+		t.Error("expected error for nonexistent path")
+	}
+}
+
+func TestResolveIssueSyncsMarkdown(t *testing.T) {
+	vault := setupTestVault(t)
+	createTestIssue(t, vault, "test123", "---\ntype: issue\ntitle: Test Issue\nstatus: open\n---\n# Test Issue\n")
+
+	s := newTestStore(t, vault)
+	issue, err := s.ResolveIssue("test123")
+	if err != nil {
+		t.Fatalf("ResolveIssue() error = %v", err)
+	}
+	if issue.Title != "Test Issue" {
+		t.Errorf("Title = %q, want %q", issue.Title, "Test Issue")
+	}
+
+	if _, err := s.ResolveIssue("missing"); err == nil {
+		t.Error("expected error for missing issue")
+	}
+}
+
+func TestSetIssueStatus(t *testing.T) {
+	vault := setupTestVault(t)
+	createTestIssue(t, vault, "test123", "---\ntype: issue\ntitle: Test\nstatus: open\n---\n# Test\n")
+
+	s := newTestStore(t, vault)
+	if err := s.SetIssueStatus("test123", model.IssueStatusResolved); err != nil {
+		t.Fatalf("SetIssueStatus() error = %v", err)
+	}
+	issue, err := s.ResolveIssue("test123")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if issue.Status != model.IssueStatusResolved {
+		t.Errorf("Status = %v, want resolved", issue.Status)
+	}
+
+	if err := s.SetIssueStatus("missing", model.IssueStatusResolved); err == nil {
+		t.Error("expected error for missing issue")
+	}
+}
+
+func TestCreateRunAndAppendEvent(t *testing.T) {
+	vault := setupTestVault(t)
+	createTestIssue(t, vault, "test123", "---\ntype: issue\ntitle: Test\n---\n# Test")
+	s := newTestStore(t, vault)
+
+	metadata := map[string]string{
+		"agent":          "claude",
+		"continued_from": "test123#20231220-090000",
+	}
+	run, err := s.CreateRun("test123", "20231220-100000", metadata)
+	if err != nil {
+		t.Fatalf("CreateRun() error = %v", err)
+	}
+	if _, err := s.CreateRun("test123", "20231220-100000", nil); err == nil {
+		t.Error("expected error for duplicate run")
+	}
+
+	if err := s.AppendEvent(run.Ref(), model.NewStatusEvent(model.StatusRunning)); err != nil {
+		t.Fatalf("AppendEvent() error = %v", err)
+	}
+	if err := s.AppendEvent(run.Ref(), model.NewArtifactEvent("worktree", map[string]string{"path": "/tmp/wt"})); err != nil {
+		t.Fatalf("AppendEvent() error = %v", err)
+	}
+
+	loaded, err := s.GetRun(run.Ref())
+	if err != nil {
+		t.Fatalf("GetRun() error = %v", err)
+	}
+	if loaded.Agent != "claude" || loaded.ContinuedFrom != metadata["continued_from"] {
+		t.Errorf("metadata not loaded: agent=%q continued_from=%q", loaded.Agent, loaded.ContinuedFrom)
+	}
+	if len(loaded.Events) != 2 {
+		t.Fatalf("expected 2 events, got %d", len(loaded.Events))
+	}
+	if loaded.Status != model.StatusRunning {
+		t.Errorf("Status = %v, want running", loaded.Status)
+	}
+	if loaded.WorktreePath != "/tmp/wt" {
+		t.Errorf("WorktreePath = %q, want /tmp/wt", loaded.WorktreePath)
+	}
+}
+
+func TestAppendEventIf(t *testing.T) {
+	vault := setupTestVault(t)
+	createTestIssue(t, vault, "test123", "---\ntype: issue\ntitle: Test\n---\n# Test")
+	s := newTestStore(t, vault)
+
+	run, err := s.CreateRun("test123", "20231220-100000", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := s.AppendEventIf(run.Ref(), model.StatusQueued, model.NewStatusEvent(model.StatusCanceled)); err != nil {
+		t.Fatalf("AppendEventIf(queued) error = %v", err)
+	}
+	err = s.AppendEventIf(run.Ref(), model.StatusQueued, model.NewStatusEvent(model.StatusRunning))
+	if !errors.Is(err, store.ErrStatusConflict) {
+		t.Fatalf("AppendEventIf(stale) error = %v, want ErrStatusConflict", err)
+	}
+
+	loaded, err := s.GetRun(run.Ref())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if loaded.Status != model.StatusCanceled || len(loaded.Events) != 1 {
+		t.Errorf("status = %s with %d events, want canceled with 1", loaded.Status, len(loaded.Events))
+	}
+}
+
+func TestAppendEventRejectsInvalidTransition(t *testing.T) {
+	vault := setupTestVault(t)
+	createTestIssue(t, vault, "test123", "---\ntype: issue\ntitle: Test\n---\n# Test")
+	s := newTestStore(t, vault)
+
+	run, err := s.CreateRun("test123", "20231220-100000", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := s.AppendEvent(run.Ref(), model.NewStatusEvent(model.StatusCanceled)); err != nil {
+		t.Fatal(err)
+	}
+	err = s.AppendEvent(run.Ref(), model.NewStatusEvent(model.StatusRunning))
+	if !errors.Is(err, model.ErrInvalidTransition) {
+		t.Fatalf("AppendEvent(canceled -> running) error = %v, want ErrInvalidTransition", err)
+	}
+	if err := s.AppendEvent(run.Ref(), model.NewForcedStatusEvent(model.StatusRunning, "revive")); err != nil {
+		t.Fatalf("AppendEvent(forced) error = %v", err)
+	}
+
+	loaded, err := s.GetRun(run.Ref())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if loaded.Status != model.StatusRunning || len(loaded.Events) != 2 {
+		t.Errorf("status = %s with %d events, want running with 2", loaded.Status, len(loaded.Events))
+	}
+}
+
+func TestListRunsFilters(t *testing.T) {
+	vault := setupTestVault(t)
+	createTestIssue(t, vault, "issue-a", "---\ntype: issue\ntitle: A\n---\n# A")
+	createTestIssue(t, vault, "issue-b", "---\ntype: issue\ntitle: B\n---\n# B")
+	s := newTestStore(t, vault)
+
+	a, _ := s.CreateRun("issue-a", "20231220-100000", nil)
+	b, _ := s.CreateRun("issue-b", "20231220-110000", nil)
+	s.CreateRun("issue-b", "20231220-120000", nil)
+	s.AppendEvent(a.Ref(), model.NewStatusEvent(model.StatusRunning))
+	s.AppendEvent(b.Ref(), model.NewStatusEvent(model.StatusDone))
+
+	runs, err := s.ListRuns(&store.ListRunsFilter{})
+	if err != nil {
+		t.Fatalf("ListRuns() error = %v", err)
+	}
+	if len(runs) != 3 {
+		t.Errorf("expected 3 runs, got %d", len(runs))
+	}
+
+	runs, _ = s.ListRuns(&store.ListRunsFilter{IssueID: "issue-b"})
+	if len(runs) != 2 {
+		t.Errorf("expected 2 runs for issue-b, got %d", len(runs))
+	}
+
+	runs, _ = s.ListRuns(&store.ListRunsFilter{Status: []model.Status{model.StatusRunning}})
+	if len(runs) != 1 || runs[0].IssueID != "issue-a" {
+		t.Errorf("status filter returned %v", runs)
+	}
+
+	runs, _ = s.ListRuns(&store.ListRunsFilter{Limit: 1})
+	if len(runs) != 1 {
+		t.Errorf("expected 1 run with limit, got %d", len(runs))
+	}
+
+	latest, err := s.GetLatestRun("issue-b")
+	if err != nil {
+		t.Fatalf("GetLatestRun() error = %v", err)
+	}
+	if latest.RunID != "20231220-120000" {
+		t.Errorf("GetLatestRun() = %s, want 20231220-120000", latest.RunID)
+	}
+}
+
+func TestListRunsUsesStoredState(t *testing.T) {
+	vault := setupTestVault(t)
+	createTestIssue(t, vault, "test123", "---\ntype: issue\ntitle: Test\n---\n# Test")
+	s := newTestStore(t, vault)
+
+	run, _ := s.CreateRun("test123", "20231220-100000", nil)
+	s.AppendEvent(run.Ref(), model.NewStatusEvent(model.StatusRunning))
+	s.AppendEvent(run.Ref(), model.NewArtifactEvent("branch", map[string]string{"name": "issue/test123/run-1"}))
+
+	// With the events gone, only the stored state can answer
+	if _, err := s.db.Exec(`DELETE FROM events`); err != nil {
+		t.Fatal(err)
+	}
+	runs, err := s.ListRuns(&store.ListRunsFilter{})
+	if err != nil || len(runs) != 1 {
+		t.Fatalf("ListRuns() = %v, %v", runs, err)
+	}
+	if runs[0].Status != model.StatusRunning || runs[0].Branch != "issue/test123/run-1" || len(runs[0].Events) != 0 {
+		t.Errorf("listed run: status %s, branch %q, %d events", runs[0].Status, runs[0].Branch, len(runs[0].Events))
+	}
+	if runs[0].StartedAt.IsZero() || runs[0].UpdatedAt.IsZero() {
+		t.Errorf("listed run has no timestamps: %v..%v", runs[0].StartedAt, runs[0].UpdatedAt)
+	}
+}
+
+func TestMigrateState(t *testing.T) {
+	vault := setupTestVault(t)
+	createTestIssue(t, vault, "test123", "---\ntype: issue\ntitle: Test\n---\n# Test")
+	s := newTestStore(t, vault)
+	run, _ := s.CreateRun("test123", "20231220-100000", nil)
+	s.AppendEvent(run.Ref(), model.NewStatusEvent(model.StatusDone))
+
+	// A database from before the state column
+	if _, err := s.db.Exec(`ALTER TABLE runs DROP COLUMN state`); err != nil {
+		t.Fatal(err)
+	}
+	s.Close()
+
+	s = newTestStore(t, vault)
+	var state string
+	if err := s.db.QueryRow(`SELECT state FROM runs`).Scan(&state); err != nil || state == "" {
+		t.Fatalf("state after migration = %q, %v", state, err)
+	}
+	runs, _ := s.ListRuns(&store.ListRunsFilter{})
+	if len(runs) != 1 || runs[0].Status != model.StatusDone {
+		t.Errorf("ListRuns() after migration = %v", runs)
+	}
+}
+
+func TestGetRunByShortID(t *testing.T) {
+	vault := setupTestVault(t)
+	createTestIssue(t, vault, "test123", "---\ntype: issue\ntitle: Test\n---\n# Test")
+	s := newTestStore(t, vault)
+
+	run, _ := s.CreateRun("test123", "20231220-100000", nil)
+	for _, prefix := range []string{run.ShortID(), run.ShortID()[:4], run.ShortID()[:2]} {
+		found, err := s.GetRunByShortID(prefix)
+		if err != nil {
+			t.Fatalf("GetRunByShortID(%q) error = %v", prefix, err)
+		}
+		if found.RunID != run.RunID {
+			t.Errorf("GetRunByShortID(%q) = %s, want %s", prefix, found.RunID, run.RunID)
+		}
+	}
+
+	if _, err := s.GetRunByShortID("zzzzzz"); err == nil {
+		t.Error("expected error for unknown short ID")
+	}
+}
+
+func TestImportExportRoundTrip(t *testing.T) {
+	src := setupTestVault(t)
+	createTestIssue(t, src, "test123", "---\ntype: issue\ntitle: Test\nstatus: open\n---\n# Test\n")
+
+	fs, err := file.New(src)
+	if err != nil {
+		t.Fatal(err)
+	}
+	run, _ := fs.CreateRun("test123", "20231220-100000", map[string]string{"agent": "codex"})
+	fs.AppendEvent(run.Ref(), model.NewStatusEvent(model.StatusRunning))
+	fs.AppendEvent(run.Ref(), model.NewArtifactEvent("branch", map[string]string{"name": "issue/test123/run-20231220-100000"}))
+
+	s := newTestStore(t, src)
+	stats, err := s.Import(fs)
+	if err != nil {
+		t.Fatalf("Import() error = %v", err)
+	}
+	if stats.Issues != 1 || stats.Runs != 1 || stats.Events != 2 {
+		t.Errorf("Import() stats = %+v", stats)
+	}
+
+	stats, err = s.Import(fs)
+	if err != nil {
+		t.Fatalf("second Import() error = %v", err)
+	}
+	if stats.Runs != 0 || stats.Skipped != 1 {
+		t.Errorf("re-import stats = %+v, want 1 skipped", stats)
+	}
+
+	dst := t.TempDir()
+	if _, err := s.Export(dst); err != nil {
+		t.Fatalf("Export() error = %v", err)
+	}
+
+	exported, err := file.New(dst)
+	if err != nil {
+		t.Fatal(err)
+	}
+	issue, err := exported.ResolveIssue("test123")
+	if err != nil {
+		t.Fatalf("exported ResolveIssue() error = %v", err)
+	}
+	if issue.Title != "Test" {
+		t.Errorf("exported issue title = %q", issue.Title)
+	}
+	got, err := exported.GetRun(run.Ref())
+	if err != nil {
+		t.Fatalf("exported GetRun() error = %v", err)
+	}
+	if got.Agent != "codex" || got.Status != model.StatusRunning || got.Branch != "issue/test123/run-20231220-100000" {
+		t.Errorf("exported run = agent %q status %v branch %q", got.Agent, got.Status, got.Branch)
+	}
+}
+
+func TestWatch(t *testing.T) {
+	vault := setupTestVault(t)
+	createTestIssue(t, vault, "test123", "---\ntype: issue\ntitle: Test\n---\n# Test")
+	s := newTestStore(t, vault)
+	existing, err := s.CreateRun("test123", "20231220-090000", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	s.AppendEvent(existing.Ref(), model.NewStatusEvent(model.StatusRunning))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	changes, err := s.Watch(ctx, nil)
+	if err != nil {
+		t.Fatalf("Watch() error = %v", err)
+	}
+
+	run, _ := s.CreateRun("test123", "20231220-100000", nil)
+	s.AppendEvent(run.Ref(), model.NewStatusEvent(model.StatusBooting))
+	s.SetIssueStatus("test123", model.IssueStatusResolved)
+
+	want := []store.ChangeType{store.ChangeRunCreated, store.ChangeEventAppended, store.ChangeIssueChanged}
+	for _, typ := range want {
+		select {
+		case c := <-changes:
+			if c.Type != typ || c.IssueID != "test123" {
+				t.Fatalf("got %+v, want %s", c, typ)
+			}
+			if c.Type == store.ChangeEventAppended && (c.RunID != run.RunID || c.Event.Name != string(model.StatusBooting)) {
+				t.Errorf("got %+v, want booting event for new run", c)
+			}
+		case <-time.After(3 * store.DefaultPollInterval):
+			t.Fatalf("timed out waiting for %s", typ)
+		}
+	}
+}
@@ -0,0 +1,131 @@
+package sqlite
+
+import (
+	"context"
+	"time"
+
+	"github.com/s22625/orch/internal/store"
+)
+
+// Watch polls the runs and events tables past the last row seen, so each
+// tick costs a few indexed queries regardless of how many runs exist.
+// Issues are compared against the previous snapshot of the issues table.
+func (s *SQLiteStore) Watch(ctx context.Context, filter *store.WatchFilter) (<-chan store.Change, error) {
+	w := &dbWatcher{store: s, filter: filter, issues: make(map[string]string)}
+	if _, err := w.poll(); err != nil {
+		return nil, err
+	}
+
+	out := make(chan store.Change, 64)
+	go func() {
+		defer close(out)
+		ticker := time.NewTicker(store.DefaultPollInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+			changes, err := w.poll()
+			if err != nil {
+				continue
+			}
+			for _, c := range changes {
+				select {
+				case out <- c:
+				case <-ctx.Done():
+					return
+				}
+			}
+		}
+	}()
+	return out, nil
+}
+
+// dbWatcher remembers the high-water marks of the last poll
+type dbWatcher struct {
+	store   *SQLiteStore
+	filter  *store.WatchFilter
+	primed  bool
+	lastRun int64
+	lastSeq int64
+	issues  map[string]string // issue ID -> fingerprint
+}
+
+func (w *dbWatcher) poll() ([]store.Change, error) {
+	db := w.store.db
+	var changes []store.Change
+
+	rows, err := db.Query(`SELECT rowid, issue_id, run_id FROM runs WHERE rowid > ? ORDER BY rowid`, w.lastRun)
+	if err != nil {
+		return nil, err
+	}
+	for rows.Next() {
+		var c store.Change
+		if err := rows.Scan(&w.lastRun, &c.IssueID, &c.RunID); err != nil {
+			rows.Close()
+			return nil, err
+		}
+		c.Type = store.ChangeRunCreated
+		changes = append(changes, c)
+	}
+	rows.Close()
+
+	rows, err = db.Query(`SELECT seq, issue_id, run_id, ts, type, name, attrs FROM events WHERE seq > ? ORDER BY seq`, w.lastSeq)
+	if err != nil {
+		return nil, err
+	}
+	for rows.Next() {
+		var issueID, runID, ts, eventType, name, attrs string
+		if err := rows.Scan(&w.lastSeq, &issueID, &runID, &ts, &eventType, &name, &attrs); err != nil {
+			rows.Close()
+			return nil, err
+		}
+		event, err := decodeEvent(ts, eventType, name, attrs)
+		if err != nil {
+			continue
+		}
+		changes = append(changes, store.Change{Type: store.ChangeEventAppended, IssueID: issueID, RunID: runID, Event: event})
+	}
+	rows.Close()
+
+	rows, err = db.Query(`SELECT id, status, title, summary, topic, body FROM issues`)
+	if err != nil {
+		return nil, err
+	}
+	current := make(map[string]bool)
+	for rows.Next() {
+		var id, status, title, summary, topic, body string
+		if err := rows.Scan(&id, &status, &title, &summary, &topic, &body); err != nil {
+			rows.Close()
+			return nil, err
+		}
+		current[id] = true
+		fp := status + "\x00" + title + "\x00" + summary + "\x00" + topic + "\x00" + body
+		if old, known := w.issues[id]; !known || old != fp {
+			w.issues[id] = fp
+			changes = append(changes, store.Change{Type: store.ChangeIssueChanged, IssueID: id})
+		}
+	}
+	rows.Close()
+	for id := range w.issues {
+		if !current[id] {
+			delete(w.issues, id)
+			changes = append(changes, store.Change{Type: store.ChangeIssueChanged, IssueID: id})
+		}
+	}
+
+	if !w.primed {
+		w.primed = true
+		return nil, nil
+	}
+
+	filtered := changes[:0]
+	for _, c := range changes {
+		if w.filter.Matches(c) {
+			filtered = append(filtered, c)
+		}
+	}
+	return filtered, nil
+}
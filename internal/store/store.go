@@ -1,9 +1,24 @@
 package store
 
 import (
+	"context"
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+
 	"github.com/s22625/orch/internal/model"
 )
 
+// ErrStatusConflict is returned (wrapped) by AppendEventIf when the run's
+// current status is not the expected one
+var ErrStatusConflict = errors.New("run status changed")
+
+// StatusConflictError reports the status AppendEventIf found instead of the expected one
+func StatusConflictError(ref *model.RunRef, expected, actual model.Status) error {
+	return fmt.Errorf("%w: %s is %s, expected %s", ErrStatusConflict, ref.String(), actual, expected)
+}
+
 // ListRunsFilter specifies criteria for filtering runs
 type ListRunsFilter struct {
 	IssueID string
@@ -26,10 +41,19 @@ type Store interface {
 	// CreateRun creates a new run for an issue
 	CreateRun(issueID, runID string, metadata map[string]string) (*model.Run, error)
 
-	// AppendEvent appends an event to a run
+	// AppendEvent appends an event to a run. Status events must be a legal
+	// transition from the run's current status (model.ValidateTransition)
+	// unless they carry the model.AttrForce override.
 	AppendEvent(ref *model.RunRef, event *model.Event) error
 
-	// ListRuns lists runs matching the filter
+	// AppendEventIf appends an event only if the run's current status is
+	// expected, atomically with respect to other writers (compare-and-set).
+	// Returns an error wrapping ErrStatusConflict when the status differs.
+	AppendEventIf(ref *model.RunRef, expected model.Status, event *model.Event) error
+
+	// ListRuns lists runs matching the filter. Runs carry derived state
+	// (status, phase, artifacts, timestamps); backends may leave Events
+	// empty, so use GetRun when the event history is needed.
 	ListRuns(filter *ListRunsFilter) ([]*model.Run, error)
 
 	// GetRun retrieves a run by reference
@@ -44,4 +68,58 @@ type Store interface {
 
 	// VaultPath returns the vault root path
 	VaultPath() string
+
+	// Watch streams run-created, event-appended and issue-changed
+	// notifications matching filter (nil for all) until ctx is done, then
+	// closes the channel. Only changes made after Watch returns are sent.
+	Watch(ctx context.Context, filter *WatchFilter) (<-chan Change, error)
+}
+
+// AmbiguousShortIDError formats an error for a short ID prefix that matches multiple runs
+func AmbiguousShortIDError(shortID string, matches []*model.Run) error {
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf("ambiguous run ID '%s': matches %d runs\n", shortID, len(matches)))
+
+	// Show up to 5 matches with their details
+	limit := 5
+	if len(matches) < limit {
+		limit = len(matches)
+	}
+	for i := 0; i < limit; i++ {
+		run := matches[i]
+		sb.WriteString(fmt.Sprintf("  %s  %s#%s\n", run.ShortID(), run.IssueID, run.RunID))
+	}
+	if len(matches) > 5 {
+		sb.WriteString(fmt.Sprintf("  ... and %d more\n", len(matches)-5))
+	}
+	sb.WriteString("Hint: use more characters to disambiguate")
+
+	return fmt.Errorf("%s", sb.String())
+}
+
+// shortIDRegex matches a 2-6 char hex string (git-style short ID prefix)
+var shortIDRegex = regexp.MustCompile(`^[0-9a-f]{2,6}$`)
+
+// ResolveRun resolves a run by short ID or run reference. Accepts:
+//   - 2-6 char hex short ID prefix (e.g., "a3", "a3b4", "a3b4c5")
+//   - Full run ref (e.g., "my-task#20231220-100000")
+//   - Issue ID for latest run (e.g., "my-task")
+func ResolveRun(st Store, refStr string) (*model.Run, error) {
+	if shortIDRegex.MatchString(refStr) {
+		run, err := st.GetRunByShortID(refStr)
+		if err == nil {
+			return run, nil
+		}
+		// If it's exactly 6 chars and failed, report the short ID error
+		// For shorter prefixes, fall through to try as regular ref
+		if len(refStr) == 6 {
+			return nil, err
+		}
+	}
+
+	ref, err := model.ParseRunRef(refStr)
+	if err != nil {
+		return nil, err
+	}
+	return st.GetRun(ref)
 }
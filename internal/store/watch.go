@@ -0,0 +1,199 @@
+package store
+
+import (
+	"context"
+	"time"
+
+	"github.com/s22625/orch/internal/model"
+)
+
+// ChangeType identifies what a Change reports
+type ChangeType string
+
+const (
+	ChangeRunCreated    ChangeType = "run_created"
+	ChangeEventAppended ChangeType = "event_appended"
+	ChangeIssueChanged  ChangeType = "issue_changed"
+)
+
+// Change is a single notification delivered by Store.Watch
+type Change struct {
+	Type    ChangeType
+	IssueID string
+	RunID   string       // empty for issue changes
+	Event   *model.Event // the appended event (event_appended only)
+}
+
+// Ref returns the run reference of a run change
+func (c Change) Ref() *model.RunRef {
+	return &model.RunRef{IssueID: c.IssueID, RunID: c.RunID}
+}
+
+// WatchFilter restricts which changes Watch delivers
+type WatchFilter struct {
+	IssueID string       // only changes for this issue
+	Types   []ChangeType // only these change types (all when empty)
+}
+
+// Matches reports whether a change passes the filter. A nil filter matches everything.
+func (f *WatchFilter) Matches(c Change) bool {
+	if f == nil {
+		return true
+	}
+	if f.IssueID != "" && c.IssueID != f.IssueID {
+		return false
+	}
+	return f.wants(c.Type)
+}
+
+func (f *WatchFilter) wants(t ChangeType) bool {
+	if f == nil || len(f.Types) == 0 {
+		return true
+	}
+	for _, want := range f.Types {
+		if want == t {
+			return true
+		}
+	}
+	return false
+}
+
+// DefaultPollInterval is how often PollWatch re-lists the store
+const DefaultPollInterval = 2 * time.Second
+
+// PollWatch implements Watch for backends without native change
+// notifications by diffing ListRuns/ListIssues snapshots every interval.
+// The initial snapshot is taken before returning, so only changes made
+// after the call are reported. The channel is closed when ctx is done.
+func PollWatch(ctx context.Context, s Store, filter *WatchFilter, interval time.Duration) (<-chan Change, error) {
+	if interval <= 0 {
+		interval = DefaultPollInterval
+	}
+
+	p := &poller{store: s, filter: filter, runs: make(map[string]int), issues: make(map[string]string)}
+	if _, err := p.poll(); err != nil {
+		return nil, err
+	}
+
+	out := make(chan Change, 64)
+	go func() {
+		defer close(out)
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+			changes, err := p.poll()
+			if err != nil {
+				continue // transient (e.g. API unavailable); retry next tick
+			}
+			for _, c := range changes {
+				select {
+				case out <- c:
+				case <-ctx.Done():
+					return
+				}
+			}
+		}
+	}()
+	return out, nil
+}
+
+// poller holds the last snapshot seen by PollWatch
+type poller struct {
+	store  Store
+	filter *WatchFilter
+	primed bool
+	runs   map[string]int    // ISSUE#RUN -> events seen
+	issues map[string]string // issue ID -> fingerprint
+}
+
+func (p *poller) poll() ([]Change, error) {
+	var changes []Change
+	wantRuns := p.filter.wants(ChangeRunCreated) || p.filter.wants(ChangeEventAppended)
+
+	if wantRuns {
+		listFilter := &ListRunsFilter{}
+		if p.filter != nil {
+			listFilter.IssueID = p.filter.IssueID
+		}
+		runs, err := p.store.ListRuns(listFilter)
+		if err != nil {
+			return nil, err
+		}
+		current := make(map[string]bool, len(runs))
+		for _, run := range runs {
+			key := run.IssueID + "#" + run.RunID
+			current[key] = true
+			count := run.State().Events
+			seen, known := p.runs[key]
+			p.runs[key] = count
+			if !p.primed {
+				continue
+			}
+			if !known {
+				changes = append(changes, Change{Type: ChangeRunCreated, IssueID: run.IssueID, RunID: run.RunID})
+			}
+			if count > seen {
+				changes = append(changes, p.appended(run, seen)...)
+			}
+		}
+		for key := range p.runs {
+			if !current[key] {
+				delete(p.runs, key)
+			}
+		}
+	}
+
+	if p.filter.wants(ChangeIssueChanged) {
+		issues, err := p.store.ListIssues()
+		if err != nil {
+			return nil, err
+		}
+		current := make(map[string]bool, len(issues))
+		for _, issue := range issues {
+			current[issue.ID] = true
+			fp := string(issue.Status) + "\x00" + issue.Title + "\x00" + issue.Summary + "\x00" + issue.Topic + "\x00" + issue.Body
+			old, known := p.issues[issue.ID]
+			p.issues[issue.ID] = fp
+			if p.primed && (!known || old != fp) {
+				changes = append(changes, Change{Type: ChangeIssueChanged, IssueID: issue.ID})
+			}
+		}
+		for id := range p.issues {
+			if !current[id] {
+				delete(p.issues, id)
+				changes = append(changes, Change{Type: ChangeIssueChanged, IssueID: id})
+			}
+		}
+	}
+
+	p.primed = true
+
+	filtered := changes[:0]
+	for _, c := range changes {
+		if p.filter.Matches(c) {
+			filtered = append(filtered, c)
+		}
+	}
+	return filtered, nil
+}
+
+// appended loads the events of run after the first seen
+func (p *poller) appended(run *model.Run, seen int) []Change {
+	if !p.filter.wants(ChangeEventAppended) {
+		return nil
+	}
+	full, err := p.store.GetRun(run.Ref())
+	if err != nil || seen > len(full.Events) {
+		return nil
+	}
+	changes := make([]Change, 0, len(full.Events)-seen)
+	for _, e := range full.Events[seen:] {
+		changes = append(changes, Change{Type: ChangeEventAppended, IssueID: run.IssueID, RunID: run.RunID, Event: e})
+	}
+	return changes
+}
@@ -0,0 +1,91 @@
+package store_test
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/s22625/orch/internal/model"
+	"github.com/s22625/orch/internal/store"
+	"github.com/s22625/orch/internal/store/file"
+)
+
+func TestWatchFilterMatches(t *testing.T) {
+	c := store.Change{Type: store.ChangeEventAppended, IssueID: "orch-1", RunID: "r1"}
+	tests := []struct {
+		name   string
+		filter *store.WatchFilter
+		want   bool
+	}{
+		{"nil", nil, true},
+		{"empty", &store.WatchFilter{}, true},
+		{"issue", &store.WatchFilter{IssueID: "orch-1"}, true},
+		{"other issue", &store.WatchFilter{IssueID: "orch-2"}, false},
+		{"type", &store.WatchFilter{Types: []store.ChangeType{store.ChangeRunCreated, store.ChangeEventAppended}}, true},
+		{"other type", &store.WatchFilter{Types: []store.ChangeType{store.ChangeIssueChanged}}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.filter.Matches(c); got != tt.want {
+				t.Errorf("Matches() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestPollWatch(t *testing.T) {
+	vault := t.TempDir()
+	os.MkdirAll(filepath.Join(vault, "issues"), 0755)
+	os.WriteFile(filepath.Join(vault, "issues", "orch-1.md"), []byte("---\ntype: issue\ntitle: Test\n---\n# Test"), 0644)
+
+	st, err := file.New(vault)
+	if err != nil {
+		t.Fatal(err)
+	}
+	existing, _ := st.CreateRun("orch-1", "20231220-090000", nil)
+	st.AppendEvent(existing.Ref(), model.NewStatusEvent(model.StatusRunning))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	changes, err := store.PollWatch(ctx, st, nil, 20*time.Millisecond)
+	if err != nil {
+		t.Fatalf("PollWatch() error = %v", err)
+	}
+
+	run, _ := st.CreateRun("orch-1", "20231220-100000", nil)
+	st.AppendEvent(run.Ref(), model.NewStatusEvent(model.StatusBooting))
+	st.AppendEvent(run.Ref(), model.NewStatusEvent(model.StatusRunning))
+
+	var got []store.Change
+	timeout := time.After(5 * time.Second)
+	for len(got) < 3 {
+		select {
+		case c := <-changes:
+			got = append(got, c)
+		case <-timeout:
+			t.Fatalf("timed out, got %+v", got)
+		}
+	}
+	if got[0].Type != store.ChangeRunCreated || got[0].RunID != run.RunID {
+		t.Errorf("first change = %+v, want run_created", got[0])
+	}
+	if got[1].Event == nil || got[1].Event.Name != string(model.StatusBooting) || got[2].Event.Name != string(model.StatusRunning) {
+		t.Errorf("event changes = %+v %+v, want booting then running", got[1], got[2])
+	}
+
+	os.WriteFile(filepath.Join(vault, "issues", "orch-1.md"), []byte("---\ntype: issue\ntitle: Edited\n---\n# Edited"), 0644)
+	select {
+	case c := <-changes:
+		if c.Type != store.ChangeIssueChanged || c.IssueID != "orch-1" {
+			t.Errorf("got %+v, want issue_changed", c)
+		}
+	case <-timeout:
+		t.Fatal("timed out waiting for issue change")
+	}
+
+	cancel()
+	for range changes {
+	}
+}
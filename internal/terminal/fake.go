@@ -0,0 +1,123 @@
+package terminal
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+)
+
+// Fake is an in-memory backend for tests. Sessions show what is written
+// with SetOutput and record the keys sent to them.
+type Fake struct {
+	name string
+
+	mu       sync.Mutex
+	sessions map[string]*FakeSession
+}
+
+// FakeSession is a session of a Fake backend
+type FakeSession struct {
+	Config   SessionConfig
+	Output   string
+	Sent     []string // keys of each SendKeys, with "\n" for Enter
+	Attached int
+}
+
+// NewFake returns a fake backend registered under name
+func NewFake(name string) *Fake {
+	return &Fake{name: name, sessions: make(map[string]*FakeSession)}
+}
+
+var _ Backend = (*Fake)(nil)
+
+func (f *Fake) Name() string { return f.name }
+
+func (f *Fake) Available() bool { return true }
+
+func (f *Fake) NewSession(cfg *SessionConfig) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if _, ok := f.sessions[cfg.Name]; ok {
+		return fmt.Errorf("session %s already exists", cfg.Name)
+	}
+	f.sessions[cfg.Name] = &FakeSession{Config: *cfg}
+	return nil
+}
+
+func (f *Fake) HasSession(name string) bool {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	_, ok := f.sessions[name]
+	return ok
+}
+
+func (f *Fake) SendKeys(name, keys string, enter bool) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	s, ok := f.sessions[name]
+	if !ok {
+		return &SessionNotFoundError{Name: name}
+	}
+	if enter {
+		keys += "\n"
+	}
+	s.Sent = append(s.Sent, keys)
+	return nil
+}
+
+func (f *Fake) Capture(name string, lines int) (string, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	s, ok := f.sessions[name]
+	if !ok {
+		return "", &SessionNotFoundError{Name: name}
+	}
+	all := strings.Split(strings.TrimRight(s.Output, "\n"), "\n")
+	if lines > 0 && len(all) > lines {
+		all = all[len(all)-lines:]
+	}
+	return strings.Join(all, "\n") + "\n", nil
+}
+
+func (f *Fake) Attach(name string) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	s, ok := f.sessions[name]
+	if !ok {
+		return &SessionNotFoundError{Name: name}
+	}
+	s.Attached++
+	return nil
+}
+
+func (f *Fake) Kill(name string) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if _, ok := f.sessions[name]; !ok {
+		return &SessionNotFoundError{Name: name}
+	}
+	delete(f.sessions, name)
+	return nil
+}
+
+// SetOutput sets what the session shows
+func (f *Fake) SetOutput(name, output string) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if s, ok := f.sessions[name]; ok {
+		s.Output = output
+	}
+}
+
+// Session returns a copy of the session's state, or nil if it does not exist
+func (f *Fake) Session(name string) *FakeSession {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	s, ok := f.sessions[name]
+	if !ok {
+		return nil
+	}
+	cp := *s
+	cp.Sent = append([]string(nil), s.Sent...)
+	return &cp
+}
@@ -0,0 +1,391 @@
+package terminal
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/s22625/orch/internal/transcript"
+)
+
+// Size of pty sessions until a client attaches with its own
+const (
+	DefaultPTYWidth  = 200
+	DefaultPTYHeight = 50
+)
+
+// ptyBufferSize is how much recent output a pty session keeps for capture
+// and for redrawing the screen of a client that attaches
+const ptyBufferSize = 256 * 1024
+
+// PTYHost runs pty sessions in the current process. The daemon hosts them
+// and serves the pty backend to other orch processes over its socket
+// (PTYClient); sessions end when the daemon exits.
+type PTYHost struct {
+	mu       sync.Mutex
+	sessions map[string]*ptySession
+}
+
+// NewPTYHost returns a host without sessions
+func NewPTYHost() *PTYHost {
+	return &PTYHost{sessions: make(map[string]*ptySession)}
+}
+
+var _ Backend = (*PTYHost)(nil)
+
+func (h *PTYHost) Name() string { return BackendPTY }
+
+func (h *PTYHost) Available() bool { return ptySupported }
+
+// NewSession starts the user's shell on a new pty and types the command
+// into it, like a tmux session
+func (h *PTYHost) NewSession(cfg *SessionConfig) error {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if _, ok := h.sessions[cfg.Name]; ok {
+		return fmt.Errorf("session %s already exists", cfg.Name)
+	}
+
+	s, err := startPTYSession(cfg)
+	if err != nil {
+		return err
+	}
+	h.sessions[cfg.Name] = s
+	go func() {
+		<-s.done
+		h.mu.Lock()
+		if h.sessions[cfg.Name] == s {
+			delete(h.sessions, cfg.Name)
+		}
+		h.mu.Unlock()
+	}()
+
+	if cfg.Command != "" {
+		if _, err := s.master.Write([]byte(cfg.Command + "\r")); err != nil {
+			s.kill()
+			return fmt.Errorf("failed to send command to session: %w", err)
+		}
+	}
+	return nil
+}
+
+func (h *PTYHost) session(name string) (*ptySession, error) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	s, ok := h.sessions[name]
+	if !ok {
+		return nil, &SessionNotFoundError{Name: name}
+	}
+	return s, nil
+}
+
+func (h *PTYHost) HasSession(name string) bool {
+	_, err := h.session(name)
+	return err == nil
+}
+
+func (h *PTYHost) SendKeys(name, keys string, enter bool) error {
+	s, err := h.session(name)
+	if err != nil {
+		return err
+	}
+	if enter {
+		keys += "\r"
+	}
+	_, err = s.master.Write([]byte(keys))
+	return err
+}
+
+// Capture strips the recent output of escape sequences. Unlike tmux, which
+// renders the screen, this is the output stream, so text a full-screen
+// program redraws in place appears once per redraw.
+func (h *PTYHost) Capture(name string, lines int) (string, error) {
+	s, err := h.session(name)
+	if err != nil {
+		return "", err
+	}
+	text := transcript.Strip(string(s.recent()))
+	all := strings.Split(strings.TrimRight(text, "\n"), "\n")
+	if lines > 0 && len(all) > lines {
+		all = all[len(all)-lines:]
+	}
+	return strings.Join(all, "\n") + "\n", nil
+}
+
+// Attach is served by the daemon (AttachStream); within the host process
+// there is no terminal to attach
+func (h *PTYHost) Attach(name string) error {
+	return fmt.Errorf("pty sessions are attached through the daemon (orch attach)")
+}
+
+// AttachStream connects rw to a session: it writes the recent output and
+// then live output to rw and types what is read from rw, until either side
+// closes. A positive size resizes the session first.
+func (h *PTYHost) AttachStream(name string, rw io.ReadWriter, width, height int) error {
+	s, err := h.session(name)
+	if err != nil {
+		return err
+	}
+	if width > 0 && height > 0 {
+		setPTYSize(s.master, width, height)
+	}
+
+	out := make(chan []byte, 64)
+	snapshot := s.subscribe(out)
+	defer s.unsubscribe(out)
+	if _, err := rw.Write(snapshot); err != nil {
+		return nil
+	}
+
+	input := make(chan struct{})
+	go func() {
+		defer close(input)
+		io.Copy(s.master, rw)
+	}()
+	for {
+		select {
+		case data, ok := <-out:
+			if !ok {
+				return nil // session ended
+			}
+			if _, err := rw.Write(data); err != nil {
+				return nil
+			}
+		case <-input:
+			return nil // client detached
+		}
+	}
+}
+
+func (h *PTYHost) Kill(name string) error {
+	s, err := h.session(name)
+	if err != nil {
+		return err
+	}
+	s.kill()
+	return nil
+}
+
+// Sessions returns the names of the live sessions
+func (h *PTYHost) Sessions() []string {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	names := make([]string, 0, len(h.sessions))
+	for name := range h.sessions {
+		names = append(names, name)
+	}
+	return names
+}
+
+// Close kills all sessions
+func (h *PTYHost) Close() {
+	h.mu.Lock()
+	sessions := make([]*ptySession, 0, len(h.sessions))
+	for _, s := range h.sessions {
+		sessions = append(sessions, s)
+	}
+	h.mu.Unlock()
+	for _, s := range sessions {
+		s.kill()
+	}
+}
+
+// ptySession is a shell running on a pty
+type ptySession struct {
+	cmd    *exec.Cmd
+	master *os.File
+	done   chan struct{} // closed once the shell has exited
+
+	mu          sync.Mutex
+	buf         []byte // last ptyBufferSize bytes of output
+	subscribers map[chan []byte]bool
+}
+
+func startPTYSession(cfg *SessionConfig) (*ptySession, error) {
+	master, slave, err := openPTY()
+	if err != nil {
+		return nil, err
+	}
+	defer slave.Close()
+	if err := setPTYSize(master, DefaultPTYWidth, DefaultPTYHeight); err != nil {
+		master.Close()
+		return nil, err
+	}
+
+	shell := os.Getenv("SHELL")
+	if shell == "" {
+		shell = "/bin/sh"
+	}
+	cmd := exec.Command(shell)
+	cmd.Dir = cfg.WorkDir
+	cmd.Env = append(append(os.Environ(), "TERM=xterm-256color"), cfg.Env...)
+	cmd.Stdin, cmd.Stdout, cmd.Stderr = slave, slave, slave
+	cmd.SysProcAttr = ptyProcAttr()
+	if err := cmd.Start(); err != nil {
+		master.Close()
+		return nil, fmt.Errorf("failed to start shell: %w", err)
+	}
+
+	s := &ptySession{
+		cmd:         cmd,
+		master:      master,
+		done:        make(chan struct{}),
+		subscribers: make(map[chan []byte]bool),
+	}
+	rec, err := openRecording(cfg.Transcript)
+	if err != nil {
+		rec = nil // the session runs without a transcript
+	}
+	go s.readLoop(rec)
+	return s, nil
+}
+
+// readLoop keeps the output until the shell exits
+func (s *ptySession) readLoop(rec *recording) {
+	defer rec.Close()
+	buf := make([]byte, 32*1024)
+	for {
+		n, err := s.master.Read(buf)
+		if n > 0 {
+			data := append([]byte(nil), buf[:n]...)
+			rec.Write(data)
+			s.mu.Lock()
+			s.buf = append(s.buf, data...)
+			if over := len(s.buf) - ptyBufferSize; over > 0 {
+				s.buf = append([]byte(nil), s.buf[over:]...)
+			}
+			for ch := range s.subscribers {
+				select {
+				case ch <- data:
+				default: // a client that cannot keep up misses output
+				}
+			}
+			s.mu.Unlock()
+		}
+		if err != nil {
+			break // EIO once the shell and its children have exited
+		}
+	}
+	s.cmd.Wait()
+	s.master.Close()
+	s.mu.Lock()
+	for ch := range s.subscribers {
+		close(ch)
+		delete(s.subscribers, ch)
+	}
+	close(s.done)
+	s.mu.Unlock()
+}
+
+func (s *ptySession) recent() []byte {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]byte(nil), s.buf...)
+}
+
+// subscribe registers ch for live output and returns the output so far
+func (s *ptySession) subscribe(ch chan []byte) []byte {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	select {
+	case <-s.done:
+		close(ch)
+	default:
+		s.subscribers[ch] = true
+	}
+	return append([]byte(nil), s.buf...)
+}
+
+func (s *ptySession) unsubscribe(ch chan []byte) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.subscribers[ch] {
+		delete(s.subscribers, ch)
+		close(ch)
+	}
+}
+
+// ptyKillGrace is how long a hung-up session has to exit before it is
+// killed
+const ptyKillGrace = 2 * time.Second
+
+// kill hangs up the shell and the job in the foreground, and kills them if
+// they are still there after ptyKillGrace
+func (s *ptySession) kill() {
+	groups := []int{s.cmd.Process.Pid}
+	if fg, err := foregroundGroup(s.master); err == nil && fg > 0 && fg != groups[0] {
+		groups = append(groups, fg)
+	}
+	signalGroups(groups, syscall.SIGHUP)
+	select {
+	case <-s.done:
+	case <-time.After(ptyKillGrace):
+		signalGroups(groups, syscall.SIGKILL)
+		s.master.Close()
+	}
+}
+
+// recording writes session output to transcript files
+type recording struct {
+	files  []*os.File
+	writer io.Writer
+}
+
+func openRecording(paths *transcript.Paths) (*recording, error) {
+	if paths == nil {
+		return nil, nil
+	}
+	rec := &recording{}
+	open := func(path string) (*os.File, error) {
+		f, err := os.OpenFile(path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0644)
+		if err == nil {
+			rec.files = append(rec.files, f)
+		}
+		return f, err
+	}
+	raw, err := open(paths.Raw)
+	if err != nil {
+		return nil, err
+	}
+	text, err := open(paths.Text)
+	if err != nil {
+		rec.Close()
+		return nil, err
+	}
+	writers := []io.Writer{raw, transcript.NewStripper(text)}
+	if paths.Cast != "" {
+		f, err := open(paths.Cast)
+		if err != nil {
+			rec.Close()
+			return nil, err
+		}
+		cast, err := transcript.AppendCast(f, DefaultPTYWidth, DefaultPTYHeight)
+		if err != nil {
+			rec.Close()
+			return nil, err
+		}
+		writers = append(writers, cast)
+	}
+	rec.writer = io.MultiWriter(writers...)
+	return rec, nil
+}
+
+func (r *recording) Write(p []byte) {
+	if r != nil {
+		r.writer.Write(p)
+	}
+}
+
+func (r *recording) Close() {
+	if r == nil {
+		return
+	}
+	for _, f := range r.files {
+		f.Close()
+	}
+}
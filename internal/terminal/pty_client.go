@@ -0,0 +1,157 @@
+package terminal
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/charmbracelet/x/term"
+	"github.com/s22625/orch/internal/rpc"
+)
+
+// DetachKey ends an attach to a pty session (Ctrl-])
+const DetachKey = 0x1d
+
+// PTYClient is the pty backend outside the daemon: it reaches the sessions
+// the daemon hosts through its socket
+type PTYClient struct {
+	socketPath string
+}
+
+// NewPTYClient returns a client of the daemon listening on socketPath
+func NewPTYClient(socketPath string) *PTYClient {
+	return &PTYClient{socketPath: socketPath}
+}
+
+var _ Backend = (*PTYClient)(nil)
+
+func (c *PTYClient) Name() string { return BackendPTY }
+
+func (c *PTYClient) Available() bool { return ptySupported }
+
+func (c *PTYClient) call(method string, params, result interface{}) error {
+	client, err := rpc.DialSocket(c.socketPath)
+	if err != nil {
+		return err
+	}
+	defer client.Close()
+	return client.Call(context.Background(), method, params, result)
+}
+
+func (c *PTYClient) NewSession(cfg *SessionConfig) error {
+	params := &rpc.PTYNewParams{Name: cfg.Name, WorkDir: cfg.WorkDir, Command: cfg.Command, Env: cfg.Env}
+	if cfg.Transcript != nil {
+		params.TranscriptRaw = cfg.Transcript.Raw
+		params.TranscriptText = cfg.Transcript.Text
+		params.TranscriptCast = cfg.Transcript.Cast
+	}
+	return c.call(rpc.MethodPTYNew, params, nil)
+}
+
+func (c *PTYClient) HasSession(name string) bool {
+	var result rpc.PTYHasResult
+	if err := c.call(rpc.MethodPTYHas, &rpc.PTYParams{Name: name}, &result); err != nil {
+		return false
+	}
+	return result.Exists
+}
+
+func (c *PTYClient) SendKeys(name, keys string, enter bool) error {
+	return c.sessionCall(name, rpc.MethodPTYSend, &rpc.PTYSendParams{Name: name, Keys: keys, Enter: enter}, nil)
+}
+
+func (c *PTYClient) Capture(name string, lines int) (string, error) {
+	var result rpc.CaptureResult
+	if err := c.sessionCall(name, rpc.MethodPTYCapture, &rpc.PTYCaptureParams{Name: name, Lines: lines}, &result); err != nil {
+		return "", err
+	}
+	return result.Output, nil
+}
+
+func (c *PTYClient) Kill(name string) error {
+	return c.sessionCall(name, rpc.MethodPTYKill, &rpc.PTYParams{Name: name}, nil)
+}
+
+// sessionCall is call for a method on a session; the daemon's "not found"
+// becomes a SessionNotFoundError
+func (c *PTYClient) sessionCall(name, method string, params, result interface{}) error {
+	return sessionError(name, c.call(method, params, result))
+}
+
+func sessionError(name string, err error) error {
+	var rpcErr *rpc.Error
+	if errors.As(err, &rpcErr) && rpcErr.Code == rpc.CodeSessionNotFound {
+		return &SessionNotFoundError{Name: name}
+	}
+	return err
+}
+
+// Attach connects this terminal to the session until the session ends or
+// the user presses DetachKey
+func (c *PTYClient) Attach(name string) error {
+	stdin, stdout := os.Stdin.Fd(), os.Stdout.Fd()
+	if !term.IsTerminal(stdin) {
+		return fmt.Errorf("attaching requires a terminal")
+	}
+	params := &rpc.PTYAttachParams{Name: name}
+	if width, height, err := term.GetSize(stdout); err == nil {
+		params.Width, params.Height = width, height
+	}
+
+	client, err := rpc.DialSocket(c.socketPath)
+	if err != nil {
+		return err
+	}
+	stream, err := client.AttachPTY(context.Background(), params)
+	client.Close()
+	if err != nil {
+		return sessionError(name, err)
+	}
+	defer stream.Close()
+
+	state, err := term.MakeRaw(stdin)
+	if err != nil {
+		return fmt.Errorf("failed to set terminal to raw mode: %w", err)
+	}
+	detached := make(chan struct{})
+	go func() {
+		if copyUntilDetach(stream, os.Stdin) {
+			close(detached)
+		}
+		stream.Close()
+	}()
+	io.Copy(os.Stdout, stream)
+	term.Restore(stdin, state)
+
+	select {
+	case <-detached:
+		fmt.Fprintf(os.Stdout, "\r\n[detached from %s]\r\n", name)
+	default:
+		fmt.Fprintf(os.Stdout, "\r\n[session %s ended]\r\n", name)
+	}
+	return nil
+}
+
+// copyUntilDetach copies keystrokes to w until DetachKey, which it reports,
+// or the end of r
+func copyUntilDetach(w io.Writer, r io.Reader) bool {
+	buf := make([]byte, 1024)
+	for {
+		n, err := r.Read(buf)
+		if i := bytes.IndexByte(buf[:n], DetachKey); i >= 0 {
+			w.Write(buf[:i])
+			return true
+		}
+		if n > 0 {
+			if _, err := w.Write(buf[:n]); err != nil {
+				return false
+			}
+		}
+		if err != nil {
+			return false
+		}
+	}
+}
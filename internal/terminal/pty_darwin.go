@@ -0,0 +1,47 @@
+package terminal
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"unsafe"
+
+	"golang.org/x/sys/unix"
+)
+
+// openPTY opens a pseudo-terminal pair
+func openPTY() (master, slave *os.File, err error) {
+	// Non-blocking, so reads go through the poller and Close interrupts them
+	fd, err := unix.Open("/dev/ptmx", unix.O_RDWR|unix.O_NOCTTY|unix.O_CLOEXEC|unix.O_NONBLOCK, 0)
+	if err != nil {
+		return nil, nil, fmt.Errorf("open /dev/ptmx: %w", err)
+	}
+	master = os.NewFile(uintptr(fd), "/dev/ptmx")
+	if err := ioctl(fd, unix.TIOCPTYGRANT, 0); err != nil {
+		master.Close()
+		return nil, nil, fmt.Errorf("grant pty: %w", err)
+	}
+	if err := ioctl(fd, unix.TIOCPTYUNLK, 0); err != nil {
+		master.Close()
+		return nil, nil, fmt.Errorf("unlock pty: %w", err)
+	}
+	buf := make([]byte, 128)
+	if err := ioctl(fd, unix.TIOCPTYGNAME, uintptr(unsafe.Pointer(&buf[0]))); err != nil {
+		master.Close()
+		return nil, nil, fmt.Errorf("pty name: %w", err)
+	}
+	name := string(buf[:bytes.IndexByte(buf, 0)])
+	sfd, err := unix.Open(name, unix.O_RDWR|unix.O_NOCTTY|unix.O_CLOEXEC, 0)
+	if err != nil {
+		master.Close()
+		return nil, nil, fmt.Errorf("open %s: %w", name, err)
+	}
+	return master, os.NewFile(uintptr(sfd), name), nil
+}
+
+func ioctl(fd int, req uint, arg uintptr) error {
+	if _, _, errno := unix.Syscall(unix.SYS_IOCTL, uintptr(fd), uintptr(req), arg); errno != 0 {
+		return errno
+	}
+	return nil
+}
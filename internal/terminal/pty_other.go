@@ -0,0 +1,31 @@
+//go:build !linux && !darwin
+
+package terminal
+
+import (
+	"errors"
+	"os"
+	"syscall"
+)
+
+const ptySupported = false
+
+var errPTYUnsupported = errors.New("the pty terminal backend is not supported on this platform")
+
+func openPTY() (master, slave *os.File, err error) {
+	return nil, nil, errPTYUnsupported
+}
+
+func setPTYSize(f *os.File, width, height int) error {
+	return errPTYUnsupported
+}
+
+func ptyProcAttr() *syscall.SysProcAttr {
+	return nil
+}
+
+func foregroundGroup(master *os.File) (int, error) {
+	return 0, errPTYUnsupported
+}
+
+func signalGroups(groups []int, sig syscall.Signal) {}
@@ -0,0 +1,166 @@
+//go:build linux || darwin
+
+package terminal
+
+import (
+	"bytes"
+	"io"
+	"net"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/s22625/orch/internal/transcript"
+)
+
+// syncBuffer is a bytes.Buffer safe to read while it is written
+type syncBuffer struct {
+	mu  sync.Mutex
+	buf bytes.Buffer
+}
+
+func (b *syncBuffer) Write(p []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.Write(p)
+}
+
+func (b *syncBuffer) String() string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.String()
+}
+
+func newTestHost(t *testing.T) *PTYHost {
+	t.Helper()
+	t.Setenv("SHELL", "/bin/sh")
+	host := NewPTYHost()
+	t.Cleanup(host.Close)
+	return host
+}
+
+// waitFor polls cond until it holds or a few seconds pass
+func waitFor(t *testing.T, what string, cond func() bool) {
+	t.Helper()
+	deadline := time.Now().Add(5 * time.Second)
+	for time.Now().Before(deadline) {
+		if cond() {
+			return
+		}
+		time.Sleep(20 * time.Millisecond)
+	}
+	t.Fatalf("timed out waiting for %s", what)
+}
+
+func TestPTYHostSession(t *testing.T) {
+	host := newTestHost(t)
+	dir := t.TempDir()
+	paths := transcript.For(dir, "issue-1", "run-1")
+	os.MkdirAll(filepath.Dir(paths.Raw), 0755)
+
+	err := host.NewSession(&SessionConfig{
+		Name:       "s",
+		WorkDir:    dir,
+		Command:    "echo started-$ORCH_TEST_VAR",
+		Env:        []string{"ORCH_TEST_VAR=ok"},
+		Transcript: &paths,
+	})
+	if err != nil {
+		t.Fatalf("NewSession: %v", err)
+	}
+	if err := host.NewSession(&SessionConfig{Name: "s"}); err == nil {
+		t.Fatal("duplicate session should fail")
+	}
+	if !host.HasSession("s") {
+		t.Fatal("session should be alive")
+	}
+
+	if err := WaitForReady(host, "s", "started-ok", 5*time.Second); err != nil {
+		t.Fatal(err)
+	}
+	if err := host.SendKeys("s", "pwd", true); err != nil {
+		t.Fatalf("SendKeys: %v", err)
+	}
+	waitFor(t, "pwd output", func() bool {
+		out, _ := host.Capture("s", 100)
+		return strings.Contains(out, filepath.Base(dir))
+	})
+	if out, _ := host.Capture("s", 1); strings.Count(out, "\n") != 1 {
+		t.Errorf("Capture(1) = %q", out)
+	}
+
+	if err := host.Kill("s"); err != nil {
+		t.Fatalf("Kill: %v", err)
+	}
+	waitFor(t, "session to end", func() bool { return !host.HasSession("s") })
+	if _, err := host.Capture("s", 10); err == nil {
+		t.Error("Capture of a killed session should fail")
+	}
+
+	// The transcript has the output, with escape sequences stripped in text
+	text, _ := os.ReadFile(paths.Text)
+	if !strings.Contains(string(text), "started-ok") {
+		t.Errorf("text transcript = %q", text)
+	}
+	if strings.Contains(string(text), "\x1b") {
+		t.Errorf("text transcript has escape sequences: %q", text)
+	}
+	cast, _ := os.ReadFile(paths.Cast)
+	if !bytes.HasPrefix(cast, []byte(`{"version":2`)) || !bytes.Contains(cast, []byte("started-ok")) {
+		t.Errorf("cast = %q", cast)
+	}
+}
+
+func TestPTYHostShellExit(t *testing.T) {
+	host := newTestHost(t)
+	if err := host.NewSession(&SessionConfig{Name: "s", Command: "exit"}); err != nil {
+		t.Fatalf("NewSession: %v", err)
+	}
+	waitFor(t, "session to end", func() bool { return !host.HasSession("s") })
+}
+
+func TestPTYHostAttachStream(t *testing.T) {
+	host := newTestHost(t)
+	if err := host.NewSession(&SessionConfig{Name: "s", Command: "echo before-attach"}); err != nil {
+		t.Fatalf("NewSession: %v", err)
+	}
+	if err := WaitForReady(host, "s", "before-attach", 5*time.Second); err != nil {
+		t.Fatal(err)
+	}
+
+	server, client := net.Pipe()
+	done := make(chan error, 1)
+	go func() {
+		done <- host.AttachStream("s", server, 100, 30)
+		server.Close()
+	}()
+
+	var out syncBuffer
+	go io.Copy(&out, client)
+
+	// The recent output is replayed, then what is typed shows up live
+	waitFor(t, "snapshot", func() bool { return strings.Contains(out.String(), "before-attach") })
+	client.Write([]byte("echo after-$((1+1))\r"))
+	waitFor(t, "live output", func() bool { return strings.Contains(out.String(), "after-2") })
+
+	// Detaching leaves the session running
+	client.Close()
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Fatalf("AttachStream: %v", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("AttachStream did not return after the client closed")
+	}
+	if !host.HasSession("s") {
+		t.Fatal("session should outlive the attach")
+	}
+
+	if err := host.AttachStream("missing", server, 0, 0); err == nil {
+		t.Error("attaching a missing session should fail")
+	}
+}
@@ -0,0 +1,57 @@
+//go:build linux || darwin
+
+package terminal
+
+import (
+	"os"
+	"syscall"
+
+	"golang.org/x/sys/unix"
+)
+
+// ptySupported reports whether this platform can open pseudo-terminals
+const ptySupported = true
+
+// setPTYSize sets the terminal size of a pty
+func setPTYSize(f *os.File, width, height int) error {
+	return control(f, func(fd int) error {
+		return unix.IoctlSetWinsize(fd, unix.TIOCSWINSZ, &unix.Winsize{Col: uint16(width), Row: uint16(height)})
+	})
+}
+
+// ptyProcAttr makes the child a session leader with the pty (its stdin)
+// as controlling terminal
+func ptyProcAttr() *syscall.SysProcAttr {
+	return &syscall.SysProcAttr{Setsid: true, Setctty: true, Ctty: 0}
+}
+
+// foregroundGroup returns the process group in the foreground of a pty
+func foregroundGroup(master *os.File) (int, error) {
+	var pgrp int
+	err := control(master, func(fd int) (err error) {
+		pgrp, err = unix.IoctlGetInt(fd, unix.TIOCGPGRP)
+		return err
+	})
+	return pgrp, err
+}
+
+// control runs fn on the descriptor of f. Unlike f.Fd() it keeps f
+// non-blocking and fails once f is closed.
+func control(f *os.File, fn func(fd int) error) error {
+	conn, err := f.SyscallConn()
+	if err != nil {
+		return err
+	}
+	var fnErr error
+	if err := conn.Control(func(fd uintptr) { fnErr = fn(int(fd)) }); err != nil {
+		return err
+	}
+	return fnErr
+}
+
+// signalGroups signals whole process groups
+func signalGroups(groups []int, sig syscall.Signal) {
+	for _, g := range groups {
+		syscall.Kill(-g, sig)
+	}
+}
@@ -0,0 +1,149 @@
+// Package terminal hosts agent sessions. A Backend starts a session running
+// a command in a terminal, types into it, reads what it shows and lets a
+// human attach to it. tmux is the default; zellij sessions work the same
+// way, and the pty backend runs sessions inside the daemon for machines
+// without either.
+package terminal
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/s22625/orch/internal/transcript"
+)
+
+// Backend names
+const (
+	BackendTmux   = "tmux"
+	BackendZellij = "zellij"
+	BackendPTY    = "pty"
+)
+
+// Backend runs sessions in terminals
+type Backend interface {
+	// Name is what runs record in their session artifact
+	Name() string
+	// Available reports whether sessions can be started
+	Available() bool
+
+	NewSession(cfg *SessionConfig) error
+	// HasSession reports whether the session is alive
+	HasSession(name string) bool
+	// SendKeys types keys literally, then presses Enter if enter is set
+	SendKeys(name, keys string, enter bool) error
+	// Capture returns the last lines the session shows, without escape
+	// sequences
+	Capture(name string, lines int) (string, error)
+	// Attach connects the current terminal to the session until the user
+	// detaches
+	Attach(name string) error
+	Kill(name string) error
+}
+
+// SessionConfig describes a new session
+type SessionConfig struct {
+	Name    string
+	WorkDir string
+	Command string   // typed into the session's shell; empty for a plain shell
+	Env     []string // KEY=VALUE
+
+	// Transcript, if set, records everything the session prints
+	Transcript *transcript.Paths
+}
+
+var (
+	mu       sync.RWMutex
+	backends = map[string]Backend{
+		BackendTmux:   NewTmux(),
+		BackendZellij: NewZellij(),
+	}
+)
+
+// Register adds a backend or replaces the one of the same name
+func Register(b Backend) {
+	mu.Lock()
+	defer mu.Unlock()
+	backends[b.Name()] = b
+}
+
+// Get returns a registered backend; "" is tmux
+func Get(name string) (Backend, error) {
+	if name == "" {
+		name = BackendTmux
+	}
+	mu.RLock()
+	defer mu.RUnlock()
+	if b, ok := backends[name]; ok {
+		return b, nil
+	}
+	return nil, fmt.Errorf("unknown terminal backend %q (available: %s)", name, strings.Join(names(), ", "))
+}
+
+// Default returns the tmux backend
+func Default() Backend {
+	b, _ := Get(BackendTmux)
+	return b
+}
+
+// names lists registered backends; mu must be held
+func names() []string {
+	list := make([]string, 0, len(backends))
+	for name := range backends {
+		list = append(list, name)
+	}
+	sort.Strings(list)
+	return list
+}
+
+// transcriptless is implemented by backends that cannot record sessions and
+// ignore SessionConfig.Transcript
+type transcriptless interface {
+	noTranscript()
+}
+
+// RecordsTranscript reports whether sessions of b record
+// SessionConfig.Transcript
+func RecordsTranscript(b Backend) bool {
+	_, ok := b.(transcriptless)
+	return !ok
+}
+
+// WaitForReady polls the session until its output matches pattern or
+// timeout passes. pattern is a regular expression whose ^ and $ match at
+// line boundaries.
+func WaitForReady(b Backend, name, pattern string, timeout time.Duration) error {
+	if pattern == "" {
+		return nil
+	}
+	re, err := regexp.Compile("(?m)" + pattern)
+	if err != nil {
+		return fmt.Errorf("invalid ready pattern: %w", err)
+	}
+
+	deadline := time.Now().Add(timeout)
+	pollInterval := 200 * time.Millisecond
+
+	for time.Now().Before(deadline) {
+		content, err := b.Capture(name, 50)
+		if err == nil && re.MatchString(content) {
+			return nil
+		}
+		time.Sleep(pollInterval)
+	}
+
+	return fmt.Errorf("timeout waiting for agent to be ready (pattern: %q)", pattern)
+}
+
+// SessionNotFoundError is returned for operations on a session that does
+// not exist
+type SessionNotFoundError struct {
+	Name string
+}
+
+func (e *SessionNotFoundError) Error() string {
+	return fmt.Sprintf("session %s not found", e.Name)
+}
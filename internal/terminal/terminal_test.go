@@ -0,0 +1,82 @@
+package terminal
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestGet(t *testing.T) {
+	if b, err := Get(""); err != nil || b.Name() != BackendTmux {
+		t.Fatalf("Get(\"\") = %v, %v; want tmux", b, err)
+	}
+
+	fake := NewFake("fake-get")
+	Register(fake)
+	if b, err := Get("fake-get"); err != nil || b != fake {
+		t.Fatalf("Get(fake-get) = %v, %v", b, err)
+	}
+
+	_, err := Get("screen")
+	if err == nil || !strings.Contains(err.Error(), "tmux") {
+		t.Fatalf("unknown backend error = %v, want list of available backends", err)
+	}
+}
+
+func TestWaitForReady(t *testing.T) {
+	fake := NewFake("fake-ready")
+	if err := fake.NewSession(&SessionConfig{Name: "s"}); err != nil {
+		t.Fatal(err)
+	}
+
+	go func() {
+		time.Sleep(50 * time.Millisecond)
+		fake.SetOutput("s", "booting\n> ready\n")
+	}()
+	if err := WaitForReady(fake, "s", "> ready", 2*time.Second); err != nil {
+		t.Fatalf("WaitForReady: %v", err)
+	}
+
+	if err := WaitForReady(fake, "s", "never", 300*time.Millisecond); err == nil {
+		t.Fatal("WaitForReady should time out")
+	}
+
+	// A regex, anchored per line
+	fake.SetOutput("s", "booting\n$ \n")
+	if err := WaitForReady(fake, "s", `^\$ $`, time.Second); err != nil {
+		t.Fatalf("WaitForReady regex: %v", err)
+	}
+	if err := WaitForReady(fake, "s", "(", time.Second); err == nil {
+		t.Fatal("an invalid pattern should fail")
+	}
+}
+
+func TestFake(t *testing.T) {
+	fake := NewFake("fake")
+	if err := fake.NewSession(&SessionConfig{Name: "s", WorkDir: "/w"}); err != nil {
+		t.Fatal(err)
+	}
+	if err := fake.NewSession(&SessionConfig{Name: "s"}); err == nil {
+		t.Fatal("duplicate session should fail")
+	}
+
+	fake.SendKeys("s", "hello", true)
+	fake.SendKeys("s", "partial", false)
+	fake.SetOutput("s", "a\nb\nc\n")
+	if out, _ := fake.Capture("s", 2); out != "b\nc\n" {
+		t.Errorf("Capture = %q", out)
+	}
+	got := fake.Session("s")
+	if got.Config.WorkDir != "/w" || len(got.Sent) != 2 || got.Sent[0] != "hello\n" || got.Sent[1] != "partial" {
+		t.Errorf("session = %+v", got)
+	}
+
+	if err := fake.Kill("s"); err != nil || fake.HasSession("s") {
+		t.Fatalf("Kill = %v, alive = %v", err, fake.HasSession("s"))
+	}
+	var notFound *SessionNotFoundError
+	if err := fake.SendKeys("s", "x", true); !errors.As(err, &notFound) {
+		t.Errorf("SendKeys after kill = %v", err)
+	}
+}
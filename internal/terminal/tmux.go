@@ -0,0 +1,97 @@
+package terminal
+
+import (
+	"os"
+
+	"github.com/s22625/orch/internal/tmux"
+	"github.com/s22625/orch/internal/transcript"
+)
+
+// Tmux runs sessions as tmux sessions
+type Tmux struct{}
+
+// NewTmux returns the tmux backend
+func NewTmux() *Tmux {
+	return &Tmux{}
+}
+
+// WindowBackend is implemented by backends whose sessions are tmux windows
+// that can be linked into other sessions (the monitor's run windows)
+type WindowBackend interface {
+	Backend
+	// WindowID returns the ID of the session's first window ("" if unknown)
+	WindowID(name string) string
+}
+
+var _ WindowBackend = (*Tmux)(nil)
+
+func (t *Tmux) Name() string { return BackendTmux }
+
+func (t *Tmux) Available() bool { return tmux.IsTmuxAvailable() }
+
+// NewSession creates a detached session; the transcript is recorded with
+// pipe-pane by `orch record-transcript`
+func (t *Tmux) NewSession(cfg *SessionConfig) error {
+	return tmux.NewSession(&tmux.SessionConfig{
+		SessionName: cfg.Name,
+		WorkDir:     cfg.WorkDir,
+		Command:     cfg.Command,
+		Env:         cfg.Env,
+		PipeCommand: transcriptPipe(cfg),
+	})
+}
+
+// NewWindow runs cfg.Command in a new window of the existing session
+// cfg.Name, recording it to cfg.Transcript like NewSession
+func (t *Tmux) NewWindow(cfg *SessionConfig, window string) error {
+	return tmux.NewPipedWindow(cfg.Name, window, cfg.WorkDir, cfg.Command, transcriptPipe(cfg))
+}
+
+// transcriptPipe returns the pipe-pane command recording cfg.Transcript
+// ("" without one)
+func transcriptPipe(cfg *SessionConfig) string {
+	if cfg.Transcript == nil {
+		return ""
+	}
+	exe, err := os.Executable()
+	if err != nil {
+		exe = "orch"
+	}
+	return transcript.PipeCommand(exe, *cfg.Transcript)
+}
+
+func (t *Tmux) HasSession(name string) bool { return tmux.HasSession(name) }
+
+func (t *Tmux) SendKeys(name, keys string, enter bool) error {
+	if enter {
+		return tmux.SendKeys(name, keys)
+	}
+	return tmux.SendKeysLiteral(name, keys)
+}
+
+func (t *Tmux) Capture(name string, lines int) (string, error) {
+	return tmux.CapturePane(name, lines)
+}
+
+// Attach switches the client when already inside tmux
+func (t *Tmux) Attach(name string) error {
+	if tmux.IsInsideTmux() {
+		return tmux.SwitchClient(name)
+	}
+	return tmux.AttachSession(name)
+}
+
+func (t *Tmux) Kill(name string) error { return tmux.KillSession(name) }
+
+func (t *Tmux) WindowID(name string) string {
+	windows, err := tmux.ListWindows(name)
+	if err != nil {
+		return ""
+	}
+	for _, window := range windows {
+		if window.Index == 0 {
+			return window.ID
+		}
+	}
+	return ""
+}
@@ -0,0 +1,158 @@
+package terminal
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+	"time"
+)
+
+var execZellij = exec.Command
+
+// zellijReadyTimeout bounds how long NewSession waits for the shell of a
+// new session to show something before typing the command into it
+const zellijReadyTimeout = 5 * time.Second
+
+// Zellij runs sessions as background zellij sessions. Zellij has no
+// equivalent of tmux pipe-pane, so its sessions are not recorded, and their
+// panes cannot be linked into the monitor's tmux session.
+type Zellij struct{}
+
+// NewZellij returns the zellij backend
+func NewZellij() *Zellij {
+	return &Zellij{}
+}
+
+var _ Backend = (*Zellij)(nil)
+
+func (z *Zellij) Name() string { return BackendZellij }
+
+func (z *Zellij) Available() bool {
+	_, err := exec.LookPath("zellij")
+	return err == nil
+}
+
+func (z *Zellij) noTranscript() {}
+
+// NewSession starts a background session in WorkDir and types the command
+// into its shell. Env reaches the session only if this starts the zellij
+// server, as with tmux.
+func (z *Zellij) NewSession(cfg *SessionConfig) error {
+	if z.HasSession(cfg.Name) {
+		return fmt.Errorf("session %s already exists", cfg.Name)
+	}
+	cmd := execZellij("zellij", "attach", "--create-background", cfg.Name)
+	cmd.Dir = cfg.WorkDir
+	if cmd.Env == nil {
+		cmd.Env = os.Environ()
+	}
+	cmd.Env = append(cmd.Env, cfg.Env...)
+	if out, err := cmd.CombinedOutput(); err != nil {
+		return fmt.Errorf("failed to create zellij session: %w: %s", err, strings.TrimSpace(string(out)))
+	}
+
+	if cfg.Command == "" {
+		return nil
+	}
+	// Keys written before the pane exists are lost
+	deadline := time.Now().Add(zellijReadyTimeout)
+	for time.Now().Before(deadline) {
+		if out, err := z.Capture(cfg.Name, 1); err == nil && strings.TrimSpace(out) != "" {
+			break
+		}
+		time.Sleep(100 * time.Millisecond)
+	}
+	if err := z.SendKeys(cfg.Name, cfg.Command, true); err != nil {
+		return fmt.Errorf("failed to send command to session: %w", err)
+	}
+	return nil
+}
+
+// HasSession reports whether the session is running; exited sessions that
+// zellij keeps for resurrection do not count
+func (z *Zellij) HasSession(name string) bool {
+	out, err := execZellij("zellij", "list-sessions", "--no-formatting").Output()
+	if err != nil {
+		return false
+	}
+	scanner := bufio.NewScanner(bytes.NewReader(out))
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) > 0 && fields[0] == name {
+			return !strings.Contains(scanner.Text(), "EXITED")
+		}
+	}
+	return false
+}
+
+func (z *Zellij) action(name string, args ...string) error {
+	cmd := execZellij("zellij", append([]string{"--session", name, "action"}, args...)...)
+	if out, err := cmd.CombinedOutput(); err != nil {
+		if !z.HasSession(name) {
+			return &SessionNotFoundError{Name: name}
+		}
+		return fmt.Errorf("zellij %s: %w: %s", args[0], err, strings.TrimSpace(string(out)))
+	}
+	return nil
+}
+
+// SendKeys writes the keys to the focused pane; Enter is a carriage return
+func (z *Zellij) SendKeys(name, keys string, enter bool) error {
+	if keys != "" {
+		if err := z.action(name, "write-chars", keys); err != nil {
+			return err
+		}
+	}
+	if enter {
+		return z.action(name, "write", "13")
+	}
+	return nil
+}
+
+// Capture dumps the focused pane with its scrollback
+func (z *Zellij) Capture(name string, lines int) (string, error) {
+	f, err := os.CreateTemp("", "orch-zellij-*.txt")
+	if err != nil {
+		return "", err
+	}
+	path := f.Name()
+	f.Close()
+	defer os.Remove(path)
+
+	if err := z.action(name, "dump-screen", "--full", path); err != nil {
+		return "", err
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return "", err
+	}
+	all := strings.Split(strings.TrimRight(string(data), "\n "), "\n")
+	if lines > 0 && len(all) > lines {
+		all = all[len(all)-lines:]
+	}
+	return strings.Join(all, "\n") + "\n", nil
+}
+
+// Attach runs zellij attach in this terminal; zellij does not nest
+func (z *Zellij) Attach(name string) error {
+	if os.Getenv("ZELLIJ") != "" {
+		return fmt.Errorf("already inside zellij; detach first, then run zellij attach %s", name)
+	}
+	cmd := execZellij("zellij", "attach", name)
+	cmd.Stdin = os.Stdin
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+	return cmd.Run()
+}
+
+// Kill kills the session and deletes it so it is not resurrected
+func (z *Zellij) Kill(name string) error {
+	if out, err := execZellij("zellij", "kill-session", name).CombinedOutput(); err != nil {
+		return fmt.Errorf("failed to kill zellij session: %w: %s", err, strings.TrimSpace(string(out)))
+	}
+	_ = execZellij("zellij", "delete-session", name).Run()
+	return nil
+}
@@ -0,0 +1,165 @@
+package terminal
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"reflect"
+	"strconv"
+	"testing"
+)
+
+type fakeCall struct {
+	output   string
+	exitCode int
+}
+
+// fakeZellij answers zellij commands with canned output, in order
+type fakeZellij struct {
+	calls    []fakeCall
+	recorded [][]string
+}
+
+func (f *fakeZellij) Command(name string, args ...string) *exec.Cmd {
+	call := fakeCall{}
+	if i := len(f.recorded); i < len(f.calls) {
+		call = f.calls[i]
+	}
+	f.recorded = append(f.recorded, append([]string{name}, args...))
+
+	cmd := exec.Command(os.Args[0], "-test.run=TestHelperProcess", "--")
+	cmd.Args = append(cmd.Args, args...)
+	cmd.Env = append(os.Environ(),
+		"GO_WANT_HELPER_PROCESS=1",
+		fmt.Sprintf("FAKE_CMD_OUTPUT=%s", call.output),
+		fmt.Sprintf("FAKE_CMD_EXIT_CODE=%d", call.exitCode),
+	)
+	return cmd
+}
+
+func useFakeZellij(t *testing.T, calls ...fakeCall) *fakeZellij {
+	fake := &fakeZellij{calls: calls}
+	orig := execZellij
+	execZellij = fake.Command
+	t.Cleanup(func() { execZellij = orig })
+	return fake
+}
+
+// TestHelperProcess stands in for zellij; dump-screen writes the output to
+// the file named by its last argument
+func TestHelperProcess(t *testing.T) {
+	if os.Getenv("GO_WANT_HELPER_PROCESS") != "1" {
+		return
+	}
+	args := os.Args
+	for i, arg := range args {
+		if arg == "--" {
+			args = args[i+1:]
+			break
+		}
+	}
+
+	output := os.Getenv("FAKE_CMD_OUTPUT")
+	if len(args) > 0 && contains(args, "dump-screen") {
+		_ = os.WriteFile(args[len(args)-1], []byte(output), 0644)
+	} else {
+		fmt.Fprint(os.Stdout, output)
+	}
+
+	code, _ := strconv.Atoi(os.Getenv("FAKE_CMD_EXIT_CODE"))
+	os.Exit(code)
+}
+
+func contains(args []string, s string) bool {
+	for _, arg := range args {
+		if arg == s {
+			return true
+		}
+	}
+	return false
+}
+
+func TestZellijHasSession(t *testing.T) {
+	list := "orch-other [Created 1m ago]\n" +
+		"orch-live [Created 5m ago] (current)\n" +
+		"orch-dead [Created 1h ago] (EXITED - attach to resurrect)\n"
+
+	tests := []struct {
+		name string
+		want bool
+	}{
+		{"orch-live", true},
+		{"orch-dead", false},
+		{"orch-missing", false},
+		{"orch", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fake := useFakeZellij(t, fakeCall{output: list})
+			if got := NewZellij().HasSession(tt.name); got != tt.want {
+				t.Errorf("HasSession(%q) = %v, want %v", tt.name, got, tt.want)
+			}
+			want := []string{"zellij", "list-sessions", "--no-formatting"}
+			if !reflect.DeepEqual(fake.recorded[0], want) {
+				t.Errorf("args = %v, want %v", fake.recorded[0], want)
+			}
+		})
+	}
+
+	useFakeZellij(t, fakeCall{exitCode: 1})
+	if NewZellij().HasSession("orch-live") {
+		t.Error("HasSession should be false when zellij fails")
+	}
+}
+
+func TestZellijSendKeys(t *testing.T) {
+	fake := useFakeZellij(t)
+	if err := NewZellij().SendKeys("s", "echo hi", true); err != nil {
+		t.Fatalf("SendKeys: %v", err)
+	}
+	want := [][]string{
+		{"zellij", "--session", "s", "action", "write-chars", "echo hi"},
+		{"zellij", "--session", "s", "action", "write", "13"},
+	}
+	if !reflect.DeepEqual(fake.recorded, want) {
+		t.Fatalf("calls = %v, want %v", fake.recorded, want)
+	}
+}
+
+func TestZellijSessionNotFound(t *testing.T) {
+	// The action fails and the session is not listed
+	useFakeZellij(t, fakeCall{exitCode: 1}, fakeCall{output: "other [Created 1m ago]\n"})
+	err := NewZellij().SendKeys("s", "x", false)
+	var notFound *SessionNotFoundError
+	if !errors.As(err, &notFound) || notFound.Name != "s" {
+		t.Fatalf("SendKeys error = %v, want SessionNotFoundError", err)
+	}
+}
+
+func TestZellijCapture(t *testing.T) {
+	fake := useFakeZellij(t, fakeCall{output: "a\nb\nc\n\n\n"})
+	out, err := NewZellij().Capture("s", 2)
+	if err != nil {
+		t.Fatalf("Capture: %v", err)
+	}
+	if out != "b\nc\n" {
+		t.Errorf("Capture = %q, want %q", out, "b\nc\n")
+	}
+	args := fake.recorded[0]
+	if !reflect.DeepEqual(args[:6], []string{"zellij", "--session", "s", "action", "dump-screen", "--full"}) {
+		t.Errorf("args = %v", args)
+	}
+	if _, err := os.Stat(args[len(args)-1]); !os.IsNotExist(err) {
+		t.Errorf("dump file %s not removed", args[len(args)-1])
+	}
+}
+
+func TestRecordsTranscript(t *testing.T) {
+	if !RecordsTranscript(NewTmux()) || !RecordsTranscript(NewPTYHost()) {
+		t.Error("tmux and pty sessions should be recorded")
+	}
+	if RecordsTranscript(NewZellij()) {
+		t.Error("zellij sessions cannot be recorded")
+	}
+}
@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"os"
 	"os/exec"
+	"regexp"
 	"strconv"
 	"strings"
 	"time"
@@ -34,6 +35,7 @@ type SessionConfig struct {
 	Command     string   // Command to run in the session
 	Env         []string // Environment variables (KEY=VALUE format)
 	WindowName  string
+	PipeCommand string // Shell command fed everything the pane prints (see PipePane)
 }
 
 // HasSession checks if a tmux session exists
@@ -42,7 +44,8 @@ func HasSession(name string) bool {
 	return cmd.Run() == nil
 }
 
-// NewSession creates a new tmux session
+// NewSession creates a new tmux session. The session is killed again if it
+// cannot be set up.
 func NewSession(cfg *SessionConfig) error {
 	args := []string{
 		"new-session",
@@ -68,9 +71,18 @@ func NewSession(cfg *SessionConfig) error {
 		return fmt.Errorf("failed to create tmux session: %w", err)
 	}
 
+	// Start piping before the command runs so its first output is kept
+	if cfg.PipeCommand != "" {
+		if err := PipePane(cfg.SessionName, cfg.PipeCommand); err != nil {
+			KillSession(cfg.SessionName)
+			return fmt.Errorf("failed to pipe pane output: %w", err)
+		}
+	}
+
 	// If a command is provided, send it to the session
 	if cfg.Command != "" {
 		if err := SendKeys(cfg.SessionName, cfg.Command); err != nil {
+			KillSession(cfg.SessionName)
 			return fmt.Errorf("failed to send command to session: %w", err)
 		}
 	}
@@ -114,11 +126,24 @@ func CapturePane(session string, lines int) (string, error) {
 	return string(output), nil
 }
 
-// WaitForReady polls the tmux pane until the pattern is found or timeout is reached
+// PipePane feeds the output of a pane to a shell command for as long as the
+// pane lives. An existing pipe is left in place.
+func PipePane(target, command string) error {
+	cmd := execCommand("tmux", "pipe-pane", "-o", "-t", target, command)
+	cmd.Stderr = os.Stderr
+	return cmd.Run()
+}
+
+// WaitForReady polls the tmux pane until it matches pattern, a regular
+// expression whose ^ and $ match at line boundaries, or timeout is reached
 func WaitForReady(session, pattern string, timeout time.Duration) error {
 	if pattern == "" {
 		return nil
 	}
+	re, err := regexp.Compile("(?m)" + pattern)
+	if err != nil {
+		return fmt.Errorf("invalid ready pattern: %w", err)
+	}
 
 	deadline := time.Now().Add(timeout)
 	pollInterval := 200 * time.Millisecond
@@ -131,7 +156,7 @@ func WaitForReady(session, pattern string, timeout time.Duration) error {
 			continue
 		}
 
-		if strings.Contains(content, pattern) {
+		if re.MatchString(content) {
 			return nil
 		}
 
@@ -205,6 +230,48 @@ func NewWindow(session, name, workDir, command string) error {
 	return nil
 }
 
+// NewPipedWindow creates a window in an existing session like NewWindow,
+// piping the pane's output to pipeCommand (see PipePane) before command
+// starts. The window is killed again if it cannot be set up.
+func NewPipedWindow(session, name, workDir, command, pipeCommand string) error {
+	args := []string{"new-window", "-t", session, "-P", "-F", "#{window_id}"}
+	if name != "" {
+		args = append(args, "-n", name)
+	}
+	if workDir != "" {
+		args = append(args, "-c", workDir)
+	}
+
+	cmd := execCommand("tmux", args...)
+	cmd.Stderr = os.Stderr
+	output, err := cmd.Output()
+	if err != nil {
+		return err
+	}
+	windowID := strings.TrimSpace(string(output))
+
+	if pipeCommand != "" {
+		if err := PipePane(windowID, pipeCommand); err != nil {
+			KillWindow(windowID)
+			return fmt.Errorf("failed to pipe pane output: %w", err)
+		}
+	}
+	if command != "" {
+		if err := SendKeys(windowID, command); err != nil {
+			KillWindow(windowID)
+			return fmt.Errorf("failed to send command to window: %w", err)
+		}
+	}
+	return nil
+}
+
+// KillWindow kills a window by ID or target.
+func KillWindow(target string) error {
+	cmd := execCommand("tmux", "kill-window", "-t", target)
+	cmd.Stderr = os.Stderr
+	return cmd.Run()
+}
+
 // IsTmuxAvailable checks if tmux is installed and accessible
 func IsTmuxAvailable() bool {
 	cmd := execCommand("tmux", "-V")
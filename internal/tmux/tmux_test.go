@@ -574,6 +574,92 @@ func TestSetPaneTitle(t *testing.T) {
 	}
 }
 
+func TestNewSessionPipesBeforeCommand(t *testing.T) {
+	exec := &fakeExecutor{}
+	orig := execCommand
+	execCommand = exec.Command
+	t.Cleanup(func() { execCommand = orig })
+
+	err := NewSession(&SessionConfig{
+		SessionName: "sess",
+		Command:     "echo hi",
+		PipeCommand: "cat >> /tmp/sess.log",
+	})
+	if err != nil {
+		t.Fatalf("NewSession error: %v", err)
+	}
+
+	if len(exec.recorded) != 4 {
+		t.Fatalf("expected 4 calls, got %d", len(exec.recorded))
+	}
+	if !equalArgs(exec.recorded[1].args, []string{"pipe-pane", "-o", "-t", "sess", "cat >> /tmp/sess.log"}) {
+		t.Fatalf("pipe-pane args = %v", exec.recorded[1].args)
+	}
+	if exec.recorded[2].args[0] != "send-keys" {
+		t.Fatalf("command sent before piping: %v", exec.recorded[2].args)
+	}
+}
+
+func TestNewSessionKilledWhenPipeFails(t *testing.T) {
+	exec := &fakeExecutor{calls: []fakeCall{{exitCode: 0}, {exitCode: 1}}}
+	orig := execCommand
+	execCommand = exec.Command
+	t.Cleanup(func() { execCommand = orig })
+
+	err := NewSession(&SessionConfig{
+		SessionName: "sess",
+		Command:     "echo hi",
+		PipeCommand: "cat >> /tmp/sess.log",
+	})
+	if err == nil {
+		t.Fatal("expected an error when pipe-pane fails")
+	}
+	if len(exec.recorded) != 3 {
+		t.Fatalf("expected 3 calls, got %d", len(exec.recorded))
+	}
+	if !equalArgs(exec.recorded[2].args, []string{"kill-session", "-t", "sess"}) {
+		t.Fatalf("session not killed: %v", exec.recorded[2].args)
+	}
+}
+
+func TestNewPipedWindow(t *testing.T) {
+	exec := &fakeExecutor{calls: []fakeCall{{output: "@7\n"}}}
+	orig := execCommand
+	execCommand = exec.Command
+	t.Cleanup(func() { execCommand = orig })
+
+	if err := NewPipedWindow("sess", "resume", "/work", "echo hi", "cat >> /tmp/sess.log"); err != nil {
+		t.Fatalf("NewPipedWindow error: %v", err)
+	}
+
+	if len(exec.recorded) != 4 {
+		t.Fatalf("expected 4 calls, got %d", len(exec.recorded))
+	}
+	if !equalArgs(exec.recorded[0].args, []string{"new-window", "-t", "sess", "-P", "-F", "#{window_id}", "-n", "resume", "-c", "/work"}) {
+		t.Fatalf("new-window args = %v", exec.recorded[0].args)
+	}
+	if !equalArgs(exec.recorded[1].args, []string{"pipe-pane", "-o", "-t", "@7", "cat >> /tmp/sess.log"}) {
+		t.Fatalf("pipe-pane args = %v", exec.recorded[1].args)
+	}
+	if exec.recorded[2].args[0] != "send-keys" || exec.recorded[2].args[2] != "@7" {
+		t.Fatalf("command not sent to the new window: %v", exec.recorded[2].args)
+	}
+}
+
+func TestNewPipedWindowKilledWhenPipeFails(t *testing.T) {
+	exec := &fakeExecutor{calls: []fakeCall{{output: "@7"}, {exitCode: 1}}}
+	orig := execCommand
+	execCommand = exec.Command
+	t.Cleanup(func() { execCommand = orig })
+
+	if err := NewPipedWindow("sess", "resume", "", "echo hi", "cat >> /tmp/sess.log"); err == nil {
+		t.Fatal("expected an error when pipe-pane fails")
+	}
+	if len(exec.recorded) != 3 || !equalArgs(exec.recorded[2].args, []string{"kill-window", "-t", "@7"}) {
+		t.Fatalf("window not killed: %+v", exec.recorded)
+	}
+}
+
 func equalArgs(got, want []string) bool {
 	if len(got) != len(want) {
 		return false
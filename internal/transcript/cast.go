@@ -0,0 +1,231 @@
+package transcript
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"time"
+	"unicode/utf8"
+)
+
+// CastHeader is the first line of an asciicast v2 recording
+type CastHeader struct {
+	Version   int               `json:"version"`
+	Width     int               `json:"width"`
+	Height    int               `json:"height"`
+	Timestamp int64             `json:"timestamp,omitempty"`
+	Env       map[string]string `json:"env,omitempty"`
+}
+
+// CastWriter records what is written to it as asciicast v2 output frames,
+// timed from when it was created
+type CastWriter struct {
+	w       io.Writer
+	start   time.Time
+	now     func() time.Time
+	pending []byte // incomplete UTF-8 sequence at the end of the last write
+}
+
+// NewCastWriter starts a recording of a width x height terminal on w. The
+// header is only written when header is set; AppendCast continues an
+// existing recording.
+func NewCastWriter(w io.Writer, width, height int, header bool) (*CastWriter, error) {
+	c := &CastWriter{w: w, start: time.Now(), now: time.Now}
+	if !header {
+		return c, nil
+	}
+	h := CastHeader{
+		Version:   2,
+		Width:     width,
+		Height:    height,
+		Timestamp: c.start.Unix(),
+		Env:       map[string]string{},
+	}
+	for _, key := range []string{"TERM", "SHELL"} {
+		if v := os.Getenv(key); v != "" {
+			h.Env[key] = v
+		}
+	}
+	line, err := json.Marshal(h)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := w.Write(append(line, '\n')); err != nil {
+		return nil, err
+	}
+	return c, nil
+}
+
+// AppendCast records to the asciicast file f (opened for appending). A new
+// file gets a header; an existing recording is continued from the offset of
+// its last frame, so frame times keep increasing across recorder restarts.
+func AppendCast(f *os.File, width, height int) (*CastWriter, error) {
+	info, err := f.Stat()
+	if err != nil {
+		return nil, err
+	}
+	if info.Size() == 0 {
+		return NewCastWriter(f, width, height, true)
+	}
+	offset, err := lastCastOffset(f.Name(), info.Size())
+	if err != nil {
+		return nil, err
+	}
+	c, err := NewCastWriter(f, width, height, false)
+	if err != nil {
+		return nil, err
+	}
+	c.start = c.start.Add(-offset)
+	return c, nil
+}
+
+// castTailSize is how much of a recording lastCastOffset reads at first
+const castTailSize = 64 * 1024
+
+// lastCastOffset returns the time of the last frame of the size-byte
+// recording at path (0 if it has none). Only the tail is read, growing it
+// until a whole frame is found.
+func lastCastOffset(path string, size int64) (time.Duration, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return 0, err
+	}
+	defer f.Close()
+
+	for tail := int64(castTailSize); ; tail *= 2 {
+		from := size - tail
+		if from < 0 {
+			from = 0
+		}
+		buf := make([]byte, size-from)
+		if _, err := f.ReadAt(buf, from); err != nil && err != io.EOF {
+			return 0, err
+		}
+		lines := bytes.Split(buf, []byte("\n"))
+		if from > 0 {
+			lines = lines[1:] // may start mid-line
+		}
+		for i := len(lines) - 1; i >= 0; i-- {
+			var frame []interface{}
+			if json.Unmarshal(bytes.TrimSpace(lines[i]), &frame) != nil || len(frame) != 3 {
+				continue
+			}
+			if at, ok := frame[0].(float64); ok {
+				return time.Duration(at * float64(time.Second)), nil
+			}
+		}
+		if from == 0 {
+			return 0, nil
+		}
+	}
+}
+
+// Write records p as one output frame. A multi-byte character split across
+// writes is held back until it is complete.
+func (c *CastWriter) Write(p []byte) (int, error) {
+	data := append(c.pending, p...)
+	cut := len(data)
+	for i := len(data) - 1; i >= 0 && i >= len(data)-utf8.UTFMax; i-- {
+		if utf8.RuneStart(data[i]) {
+			if !utf8.FullRune(data[i:]) {
+				cut = i
+			}
+			break
+		}
+	}
+	c.pending = append([]byte(nil), data[cut:]...)
+	if cut == 0 {
+		return len(p), nil
+	}
+
+	text, err := json.Marshal(string(data[:cut]))
+	if err != nil {
+		return 0, err
+	}
+	elapsed := c.now().Sub(c.start).Seconds()
+	if _, err := fmt.Fprintf(c.w, "[%.6f, \"o\", %s]\n", elapsed, text); err != nil {
+		return 0, err
+	}
+	return len(p), nil
+}
+
+// PlayOptions control Play
+type PlayOptions struct {
+	Speed     float64       // playback speed factor; 0 means 1
+	IdleLimit time.Duration // longest pause between frames; 0 means no limit
+
+	// Sleep waits between frames (tests replace it)
+	Sleep func(ctx context.Context, d time.Duration) error
+}
+
+// Play writes the output frames of the asciicast v2 recording on r to w
+// with the recorded timing, until the recording ends or ctx is done
+func Play(ctx context.Context, w io.Writer, r io.Reader, opts PlayOptions) error {
+	speed := opts.Speed
+	if speed <= 0 {
+		speed = 1
+	}
+	sleep := opts.Sleep
+	if sleep == nil {
+		sleep = sleepContext
+	}
+
+	reader := bufio.NewReader(r)
+	line, err := reader.ReadBytes('\n')
+	if err != nil && err != io.EOF {
+		return err
+	}
+	var header CastHeader
+	if json.Unmarshal(line, &header) != nil || header.Version != 2 {
+		return fmt.Errorf("not an asciicast v2 recording")
+	}
+
+	var last float64
+	for {
+		line, err := reader.ReadBytes('\n')
+		if line = bytes.TrimSpace(line); len(line) > 0 {
+			var frame []interface{}
+			if json.Unmarshal(line, &frame) == nil && len(frame) == 3 {
+				at, _ := frame[0].(float64)
+				kind, _ := frame[1].(string)
+				data, _ := frame[2].(string)
+				if kind == "o" {
+					gap := time.Duration((at - last) * float64(time.Second))
+					if opts.IdleLimit > 0 && gap > opts.IdleLimit {
+						gap = opts.IdleLimit
+					}
+					if gap > 0 {
+						if err := sleep(ctx, time.Duration(float64(gap)/speed)); err != nil {
+							return err
+						}
+					}
+					if _, err := io.WriteString(w, data); err != nil {
+						return err
+					}
+					last = at
+				}
+			}
+		}
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+	}
+}
+
+func sleepContext(ctx context.Context, d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
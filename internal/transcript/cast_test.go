@@ -0,0 +1,159 @@
+package transcript
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestCastWriter(t *testing.T) {
+	var out bytes.Buffer
+	c, err := NewCastWriter(&out, 100, 30, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	now := c.start
+	c.now = func() time.Time { return now }
+
+	now = now.Add(500 * time.Millisecond)
+	c.Write([]byte("\x1b[1mhi\x1b[0m\r\n"))
+	// "完" split across two writes
+	now = now.Add(time.Second)
+	c.Write([]byte("a\xe5\xae"))
+	now = now.Add(time.Second)
+	c.Write([]byte("\x8c"))
+
+	lines := strings.Split(strings.TrimSpace(out.String()), "\n")
+	if len(lines) != 4 {
+		t.Fatalf("lines = %q", lines)
+	}
+	var header CastHeader
+	if err := json.Unmarshal([]byte(lines[0]), &header); err != nil {
+		t.Fatal(err)
+	}
+	if header.Version != 2 || header.Width != 100 || header.Height != 30 || header.Timestamp == 0 {
+		t.Errorf("header = %+v", header)
+	}
+	want := []string{
+		`[0.500000, "o", "\u001b[1mhi\u001b[0m\r\n"]`,
+		`[1.500000, "o", "a"]`,
+		`[2.500000, "o", "完"]`,
+	}
+	for i, w := range want {
+		if lines[i+1] != w {
+			t.Errorf("frame %d = %s, want %s", i, lines[i+1], w)
+		}
+	}
+}
+
+func TestCastWriterWithoutHeader(t *testing.T) {
+	var out bytes.Buffer
+	c, _ := NewCastWriter(&out, 80, 24, false)
+	c.Write([]byte("x"))
+	if !strings.HasPrefix(out.String(), "[") {
+		t.Errorf("output = %q", out.String())
+	}
+}
+
+func TestAppendCastContinuesOffsets(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "run.cast")
+	record := func(advance time.Duration, data string) {
+		t.Helper()
+		f, err := os.OpenFile(path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0644)
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer f.Close()
+		c, err := AppendCast(f, 80, 24)
+		if err != nil {
+			t.Fatal(err)
+		}
+		now := time.Now().Add(advance)
+		c.now = func() time.Time { return now }
+		c.Write([]byte(data))
+	}
+
+	// The recorder restarts (e.g. a resumed session) after a long frame
+	// that does not fit the first tail read
+	record(3*time.Second, strings.Repeat("x", castTailSize+10))
+	record(time.Second, "resumed")
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	lines := strings.Split(strings.TrimSpace(string(data)), "\n")
+	if len(lines) != 3 || !strings.HasPrefix(lines[0], "{") {
+		t.Fatalf("recording has %d lines, want a header and 2 frames", len(lines))
+	}
+	var first, second []interface{}
+	json.Unmarshal([]byte(lines[1]), &first)
+	json.Unmarshal([]byte(lines[2]), &second)
+	if at := first[0].(float64); at < 3 || at > 3.5 {
+		t.Errorf("first frame at %v, want ~3", at)
+	}
+	if at := second[0].(float64); at < 4 || at > 4.5 {
+		t.Errorf("appended frame at %v, want ~4 (after the first)", at)
+	}
+}
+
+func TestPlay(t *testing.T) {
+	recording := `{"version": 2, "width": 80, "height": 24}
+[0.5, "o", "one "]
+[0.7, "i", "typed"]
+[1.0, "o", "two "]
+[31.0, "o", "three"]
+`
+	var slept []time.Duration
+	var out bytes.Buffer
+	err := Play(context.Background(), &out, strings.NewReader(recording), PlayOptions{
+		Speed:     2,
+		IdleLimit: 2 * time.Second,
+		Sleep: func(ctx context.Context, d time.Duration) error {
+			slept = append(slept, d)
+			return nil
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if out.String() != "one two three" {
+		t.Errorf("output = %q", out.String())
+	}
+	// 0.5s and 0.5s at 2x; the 30s gap is capped at 2s, then halved
+	want := []time.Duration{250 * time.Millisecond, 250 * time.Millisecond, time.Second}
+	if len(slept) != len(want) {
+		t.Fatalf("slept = %v, want %v", slept, want)
+	}
+	for i := range want {
+		if slept[i] != want[i] {
+			t.Errorf("slept = %v, want %v", slept, want)
+			break
+		}
+	}
+}
+
+func TestPlayStopsOnCancel(t *testing.T) {
+	recording := "{\"version\": 2, \"width\": 80, \"height\": 24}\n[1.0, \"o\", \"never\"]\n"
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	var out bytes.Buffer
+	if err := Play(ctx, &out, strings.NewReader(recording), PlayOptions{}); err != context.Canceled {
+		t.Errorf("err = %v, want canceled", err)
+	}
+	if out.Len() != 0 {
+		t.Errorf("output = %q", out.String())
+	}
+}
+
+func TestPlayRejectsOtherFormats(t *testing.T) {
+	err := Play(context.Background(), &bytes.Buffer{}, strings.NewReader(`{"version": 1, "stdout": []}`), PlayOptions{})
+	if err == nil {
+		t.Error("expected an error for asciicast v1")
+	}
+}
@@ -0,0 +1,136 @@
+package transcript
+
+import (
+	"fmt"
+	"io"
+	"path/filepath"
+	"strings"
+)
+
+// Paths are the files of one run's transcript
+type Paths struct {
+	Raw  string // pane output as received, escape sequences included
+	Text string // the same output with terminal control sequences stripped
+	Cast string // asciicast v2 recording of the output for replay
+}
+
+// For returns where the transcript of a run is kept
+func For(orchDir, issueID, runID string) Paths {
+	dir := filepath.Join(orchDir, "transcripts", issueID)
+	return Paths{
+		Raw:  filepath.Join(dir, runID+".raw"),
+		Text: filepath.Join(dir, runID+".log"),
+		Cast: filepath.Join(dir, runID+".cast"),
+	}
+}
+
+// PipeCommand is the tmux pipe-pane command that records a pane into p
+// with `orch record-transcript`. tmux expands formats in it, which is how
+// the recording learns the pane size.
+func PipeCommand(orchExe string, p Paths) string {
+	return fmt.Sprintf("%s record-transcript --cast %s --size #{pane_width}x#{pane_height} %s %s",
+		tmuxQuote(orchExe), tmuxQuote(p.Cast), tmuxQuote(p.Raw), tmuxQuote(p.Text))
+}
+
+// tmuxQuote quotes s for the shell and escapes it from format expansion
+func tmuxQuote(s string) string {
+	return strings.ReplaceAll(shellQuote(s), "#", "##")
+}
+
+func shellQuote(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
+
+// Record copies pane output from r into raw as-is, into text with control
+// sequences stripped and into cast (if not nil), until r is closed
+func Record(r io.Reader, raw, text, cast io.Writer) error {
+	writers := []io.Writer{raw, NewStripper(text)}
+	if cast != nil {
+		writers = append(writers, cast)
+	}
+	_, err := io.Copy(io.MultiWriter(writers...), r)
+	return err
+}
+
+// Parser states of Stripper
+const (
+	stateText      = iota
+	stateEsc       // after ESC
+	stateCSI       // ESC [ ... final byte
+	stateString    // OSC, DCS, SOS, PM, APC: up to BEL or ST
+	stateStringEsc // ESC inside a string, possibly starting ST
+	stateCharset   // ESC ( and friends: one more byte
+)
+
+// Stripper removes terminal escape sequences and control characters other
+// than newline and tab from what is written to it. Sequences may be split
+// across writes.
+type Stripper struct {
+	w     io.Writer
+	state int
+	buf   []byte
+}
+
+// NewStripper returns a Stripper writing plain text to w
+func NewStripper(w io.Writer) *Stripper {
+	return &Stripper{w: w}
+}
+
+// Write strips p and writes what is left; it reports len(p) on success
+func (s *Stripper) Write(p []byte) (int, error) {
+	s.buf = s.buf[:0]
+	for _, b := range p {
+		switch s.state {
+		case stateText:
+			switch {
+			case b == 0x1b:
+				s.state = stateEsc
+			case b == '\n' || b == '\t' || b >= 0x20 && b != 0x7f:
+				s.buf = append(s.buf, b)
+			}
+		case stateEsc:
+			switch b {
+			case '[':
+				s.state = stateCSI
+			case ']', 'P', 'X', '^', '_':
+				s.state = stateString
+			case '(', ')', '*', '+', '-', '.', '/', '#', '%':
+				s.state = stateCharset
+			default:
+				s.state = stateText
+			}
+		case stateCSI:
+			if b >= 0x40 && b <= 0x7e {
+				s.state = stateText
+			}
+		case stateString:
+			switch b {
+			case 0x07:
+				s.state = stateText
+			case 0x1b:
+				s.state = stateStringEsc
+			}
+		case stateStringEsc:
+			if b == '\\' {
+				s.state = stateText
+			} else {
+				s.state = stateString
+			}
+		case stateCharset:
+			s.state = stateText
+		}
+	}
+	if len(s.buf) > 0 {
+		if _, err := s.w.Write(s.buf); err != nil {
+			return 0, err
+		}
+	}
+	return len(p), nil
+}
+
+// Strip returns s without escape sequences and control characters
+func Strip(s string) string {
+	var b strings.Builder
+	NewStripper(&b).Write([]byte(s))
+	return b.String()
+}
@@ -0,0 +1,75 @@
+package transcript
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestStrip(t *testing.T) {
+	tests := []struct {
+		name string
+		in   string
+		want string
+	}{
+		{"plain", "hello\tworld\n", "hello\tworld\n"},
+		{"colors", "\x1b[1;32mok\x1b[0m done\n", "ok done\n"},
+		{"crlf", "line one\r\nline two\r\n", "line one\nline two\n"},
+		{"cursor", "\x1b[2K\x1b[1Gprompt> \x1b[?25h", "prompt> "},
+		{"osc title bel", "\x1b]0;claude\x07text", "text"},
+		{"osc title st", "\x1b]2;title\x1b\\text", "text"},
+		{"charset", "\x1b(Bbox\x1b)0", "box"},
+		{"two byte", "\x1b=\x1b>keys\x1bM", "keys"},
+		{"controls", "a\x08b\x00c\x7f", "abc"},
+		{"utf8", "\x1b[33m✔ 完了\x1b[0m", "✔ 完了"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Strip(tt.in); got != tt.want {
+				t.Errorf("Strip(%q) = %q, want %q", tt.in, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestStripperSplitSequences(t *testing.T) {
+	in := "\x1b[38;5;208mwarn\x1b[0m: \x1b]8;;https://example.com\x1b\\link\x1b]8;;\x1b\\\n"
+	var out bytes.Buffer
+	s := NewStripper(&out)
+	// Feed one byte at a time so every sequence spans writes
+	for i := 0; i < len(in); i++ {
+		if n, err := s.Write([]byte{in[i]}); n != 1 || err != nil {
+			t.Fatalf("Write = %d, %v", n, err)
+		}
+	}
+	if got := out.String(); got != "warn: link\n" {
+		t.Errorf("got %q", got)
+	}
+}
+
+func TestRecord(t *testing.T) {
+	in := "\x1b[1mbold\x1b[0m\r\n"
+	var raw, text bytes.Buffer
+	if err := Record(strings.NewReader(in), &raw, &text, nil); err != nil {
+		t.Fatal(err)
+	}
+	if raw.String() != in {
+		t.Errorf("raw = %q", raw.String())
+	}
+	if text.String() != "bold\n" {
+		t.Errorf("text = %q", text.String())
+	}
+}
+
+func TestPipeCommand(t *testing.T) {
+	p := For("/vault/.orch", "orch-1", "20240101-000000")
+	dir := "/vault/.orch/transcripts/orch-1/20240101-000000"
+	if p.Raw != dir+".raw" || p.Text != dir+".log" || p.Cast != dir+".cast" {
+		t.Fatalf("paths = %+v", p)
+	}
+	got := PipeCommand("/usr/bin/it's #1 orch", p)
+	want := `'/usr/bin/it'\''s ##1 orch' record-transcript --cast '` + dir + `.cast' --size #{pane_width}x#{pane_height} '` + dir + `.raw' '` + dir + `.log'`
+	if got != want {
+		t.Errorf("PipeCommand = %s", got)
+	}
+}